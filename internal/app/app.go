@@ -22,23 +22,28 @@ import (
 	"fmt"
 	"os"
 	"reflect"
+	"time"
 
 	"github.com/fatih/color"
 	cfg "github.com/google/gke-policy-automation/internal/config"
 	"github.com/google/gke-policy-automation/internal/gke"
+	"github.com/google/gke-policy-automation/internal/history"
 	"github.com/google/gke-policy-automation/internal/inputs"
 	"github.com/google/gke-policy-automation/internal/log"
 	"github.com/google/gke-policy-automation/internal/outputs"
 	"github.com/google/gke-policy-automation/internal/policy"
+	"github.com/google/gke-policy-automation/internal/retry"
 	"github.com/google/gke-policy-automation/internal/version"
 )
 
 const (
 	regoPackageBaseBestPractices = "gke.policy"
 	regoPackageBaseScalability   = "gke.scalability"
+	outputsShutdownGracePeriod   = 30 * time.Second
 )
 
 var errNoPolicies = errors.New("no policies to check against")
+var errPolicyViolations = errors.New("policy violations found")
 var consoleInfoColorF = color.New(color.Bold, color.FgHiWhite).Sprintf
 var consoleWarnColorF = color.New(color.Bold, color.FgHiYellow).Sprintf
 
@@ -48,53 +53,48 @@ type PolicyAutomation interface {
 	Check() error
 	CheckBestPractices() error
 	CheckScalability() error
+	CheckTerraformPlan() error
 	ClusterJSONData() error
+	ValidateClusterDump() error
+	GenerateInputSchema() error
 	Version() error
 	PolicyCheck() error
 	PolicyGenerateDocumentation() error
 	ConfigureSCC(orgNumber string) error
-}
-
-type evaluationResults struct {
-	m map[string]*policy.PolicyEvaluationResult
-}
-
-func (r *evaluationResults) Add(result *policy.PolicyEvaluationResult) *evaluationResults {
-	if r.m == nil {
-		r.m = make(map[string]*policy.PolicyEvaluationResult)
-	}
-	currentResult, ok := r.m[result.ClusterID]
-	if !ok {
-		r.m[result.ClusterID] = result
-		return r
-	}
-	currentResult.Policies = append(currentResult.Policies, result.Policies...)
-	return r
-}
-
-func (r *evaluationResults) List() []*policy.PolicyEvaluationResult {
-	values := make([]*policy.PolicyEvaluationResult, 0, len(r.m))
-	for _, value := range r.m {
-		values = append(values, value)
-	}
-	return values
+	Remediate() error
+	HistoryTrends(filter history.Filter) error
+	HistoryViolations(filter history.Filter) error
+	HistoryRemediation(filter history.Filter) error
 }
 
 type PolicyAutomationApp struct {
 	ctx                   context.Context
+	cancel                context.CancelFunc
+	outputsCtx            context.Context
+	outputsCancel         context.CancelFunc
 	config                *cfg.Config
 	out                   *outputs.Output
 	inputs                []inputs.Input
 	collectors            []outputs.ValidationResultCollector
 	clusterDumpCollectors []outputs.ClusterDumpCollector
 	discovery             gke.DiscoveryClient
+	clusterDumps          map[string]*gke.ClusterDump
 	policyDocsFile        string
+	retriers              map[string]*retry.Retrier
 }
 
 func NewPolicyAutomationApp() PolicyAutomation {
+	return NewPolicyAutomationAppWithContext(context.Background())
+}
+
+// NewPolicyAutomationAppWithContext returns the application bound to a given context.
+// Cancelling the context interrupts the cluster review; the results collected so far
+// are still written to the outputs.
+func NewPolicyAutomationAppWithContext(ctx context.Context) PolicyAutomation {
 	out := outputs.NewSilentOutput()
 	return &PolicyAutomationApp{
-		ctx:        context.Background(),
+		ctx:        ctx,
+		outputsCtx: ctx,
 		config:     &cfg.Config{},
 		out:        out,
 		collectors: []outputs.ValidationResultCollector{outputs.NewConsoleResultCollector(out)},
@@ -115,12 +115,33 @@ func (p *PolicyAutomationApp) Close() error {
 			errors = append(errors, err)
 		}
 	}
+	if p.outputsCancel != nil {
+		p.outputsCancel()
+	}
+	if p.cancel != nil {
+		p.cancel()
+	}
 	if len(errors) > 0 {
 		return errors[0]
 	}
 	return nil
 }
 
+// newOutputsContext returns the context for the outputs. It is not cancelled along with a given
+// run context but a grace period later, so the outputs can still write the results collected
+// before the run was interrupted or timed out.
+func newOutputsContext(ctx context.Context, gracePeriod time.Duration) (context.Context, context.CancelFunc) {
+	outputsCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(ctx, func() {
+		log.Warnf("run interrupted: %s, waiting up to %s for the outputs", context.Cause(ctx), gracePeriod)
+		time.AfterFunc(gracePeriod, cancel)
+	})
+	return outputsCtx, func() {
+		stop()
+		cancel()
+	}
+}
+
 func (p *PolicyAutomationApp) Check() error {
 	return p.evaluateClusters([]string{regoPackageBaseBestPractices})
 }
@@ -142,6 +163,41 @@ func (p *PolicyAutomationApp) CheckScalability() error {
 	return p.evaluateClusters([]string{regoPackageBaseScalability})
 }
 
+// CheckTerraformPlan evaluates best practices policies against the clusters planned in
+// a Terraform plan. The error is returned when any of the policies is violated.
+func (p *PolicyAutomationApp) CheckTerraformPlan() error {
+	log.Info("Terraform plan review starting")
+	violations := 0
+	evaluated, err := p.evaluatePolicies([]string{regoPackageBaseBestPractices}, func(result *policy.PolicyEvaluationResult) error {
+		violations += countViolatedPolicies(result)
+		return p.registerResult(result)
+	})
+	if evaluated {
+		if err := p.closeCollectors(); err != nil {
+			return err
+		}
+	}
+	if err != nil {
+		return err
+	}
+	p.finishClusterReview()
+	if violations > 0 {
+		log.Warnf("Terraform plan violates %d policies", violations)
+		return fmt.Errorf("%w: %d violated policies in Terraform plan", errPolicyViolations, violations)
+	}
+	return nil
+}
+
+func countViolatedPolicies(result *policy.PolicyEvaluationResult) int {
+	count := 0
+	for _, pol := range result.Policies {
+		if !pol.Valid && len(pol.ProcessingErrors) == 0 {
+			count++
+		}
+	}
+	return count
+}
+
 func (p *PolicyAutomationApp) ClusterJSONData() error {
 	clusterIds, err := p.getClusters()
 	if err != nil {
@@ -149,26 +205,15 @@ func (p *PolicyAutomationApp) ClusterJSONData() error {
 		log.Errorf("could not get clusters: %s", err)
 	}
 
-	//for dumping JSON data - create gkeInput
-	var gkeInput inputs.Input
-	if p.config.CredentialsFile != "" {
-		gkeInput, err = inputs.NewGKEApiInputWithCredentials(p.ctx, p.config.CredentialsFile)
-		if err != nil {
-			return err
-		}
-	} else {
-		gkeInput, err = inputs.NewGKEApiInput(p.ctx)
-		if err != nil {
-			return err
-		}
-	}
-	p.inputs = append(p.inputs, gkeInput)
-
-	clusterData, errors := inputs.GetAllInputsData(p.inputs, clusterIds)
+	log.Infof("Dumping cluster data from %d inputs", len(p.inputs))
+	clusterData, errors := inputs.GetAllInputsData(p.ctx, p.inputs, clusterIds)
 	if len(errors) > 0 {
 		p.out.ErrorPrint("could not fetch the cluster details", errors[0])
 		log.Errorf("could not fetch cluster details: %s", errors[0])
-		return errors[0]
+		// the data fetched before the interruption is still dumped
+		if p.ctx.Err() == nil {
+			return errors[0]
+		}
 	}
 	val, err := json.MarshalIndent(clusterData, "", "    ")
 	log.Debugf("[DEBUG] cluster: %s", string(val))
@@ -203,7 +248,9 @@ func (p *PolicyAutomationApp) ClusterJSONData() error {
 			return err
 		}
 	}
-
+	if err := p.ctx.Err(); err != nil {
+		return fmt.Errorf("cluster data dump interrupted after fetching data of %d out of %d clusters: %w", len(clusterData), len(clusterIds), context.Cause(p.ctx))
+	}
 	return nil
 }
 
@@ -269,8 +316,12 @@ func (p *PolicyAutomationApp) PolicyGenerateDocumentation() error {
 }
 
 func (p *PolicyAutomationApp) loadPolicyFiles() ([]*policy.PolicyFile, error) {
+	return p.loadPolicyFilesFromSources(p.config.Policies)
+}
+
+func (p *PolicyAutomationApp) loadPolicyFilesFromSources(sources []cfg.ConfigPolicy) ([]*policy.PolicyFile, error) {
 	policyFiles := make([]*policy.PolicyFile, 0)
-	for _, policyConfig := range p.config.Policies {
+	for _, policyConfig := range sources {
 		var policySrc policy.PolicySource
 		if policyConfig.LocalDirectory != "" {
 			policySrc = policy.NewLocalPolicySource(policyConfig.LocalDirectory)
@@ -15,8 +15,13 @@
 package app
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"regexp"
+	"runtime"
+	"sync"
+	"time"
 
 	"github.com/google/gke-policy-automation/internal/config"
 	"github.com/google/gke-policy-automation/internal/gke"
@@ -29,21 +34,36 @@ import (
 // getClusters retrieves lists of a clusters for further processing
 // from the sources that are defined in a configuration.
 func (p *PolicyAutomationApp) getClusters() ([]string, error) {
+	if p.config.Inputs.TerraformPlan != nil && p.config.Inputs.TerraformPlan.Enabled {
+		log.Debugf("using terraform plan cluster discovery client on a file %s", p.config.Inputs.TerraformPlan.PlanFile)
+		dc := gke.NewTerraformPlanDiscoveryClient(p.config.Inputs.TerraformPlan.PlanFile)
+		return dc.GetClustersInOrg("doesn't-matter-for-terraform-plan-discovery")
+	}
+	if p.config.Inputs.ConfigConnector != nil && p.config.Inputs.ConfigConnector.Enabled {
+		log.Debugf("using config connector cluster discovery client on a directory %s", p.config.Inputs.ConfigConnector.Directory)
+		dc := gke.NewConfigConnectorDiscoveryClient(p.config.Inputs.ConfigConnector.Directory)
+		return dc.GetClustersInOrg("doesn't-matter-for-config-connector-discovery")
+	}
 	if p.config.DumpFile != "" {
 		log.Debugf("using local cluster discovery client on a file %s", p.config.DumpFile)
-		dc := gke.NewLocalDiscoveryClient(p.config.DumpFile)
+		dump, err := p.loadClusterDump(p.config.DumpFile)
+		if err != nil {
+			return nil, err
+		}
+		dc := gke.NewLocalDiscoveryClientWithDump(dump)
 		return dc.GetClustersInOrg("doesn't-matter-for-local-discovery")
 	}
+	if p.config.ClusterInventory.File != "" {
+		log.Debugf("using inventory cluster discovery client on a file %s", p.config.ClusterInventory.File)
+		p.out.Printf("%s %s\n",
+			outputs.IconInfo,
+			consoleInfoColorF("Reading clusters from inventory... [%s]", p.config.ClusterInventory.File),
+		)
+		p.discovery = gke.NewInventoryDiscoveryClient(p.config.ClusterInventory.File, p.config.ClusterInventory.Format)
+		return p.discovery.GetClustersInOrg("doesn't-matter-for-inventory-discovery")
+	}
 	if p.config.ClusterDiscovery.Enabled {
-		var dc gke.DiscoveryClient
-		var err error
-		if p.config.CredentialsFile != "" {
-			log.Debugf("instantiating cluster discovery client with a credentials file")
-			dc, err = gke.NewDiscoveryClientWithCredentialsFile(p.ctx, p.config.CredentialsFile)
-		} else {
-			log.Debugf("instantiating cluster discovery client")
-			dc, err = gke.NewDiscoveryClient(p.ctx)
-		}
+		dc, err := p.newDiscoveryClient()
 		if err != nil {
 			return nil, err
 		}
@@ -62,6 +82,64 @@ func (p *PolicyAutomationApp) getClusters() ([]string, error) {
 	return clusters, nil
 }
 
+// newDiscoveryClient creates cluster discovery client for the configured discovery source.
+func (p *PolicyAutomationApp) newDiscoveryClient() (gke.DiscoveryClient, error) {
+	discovery := p.config.ClusterDiscovery
+	filter, err := newClusterFilter(discovery.Filters)
+	if err != nil {
+		return nil, err
+	}
+	if discovery.Source == config.DiscoverySourceGKEAPI {
+		if p.config.CredentialsFile != "" {
+			log.Debugf("instantiating GKE API cluster discovery client with a credentials file")
+			return gke.NewGKEDiscoveryClientWithCredentialsFile(p.ctx, p.config.CredentialsFile, filter, p.getRetrier(config.APIContainer), p.getRetrier(config.APIResourceManager))
+		}
+		log.Debugf("instantiating GKE API cluster discovery client")
+		return gke.NewGKEDiscoveryClient(p.ctx, filter, p.getRetrier(config.APIContainer), p.getRetrier(config.APIResourceManager))
+	}
+	if discovery.Source == config.DiscoverySourceFleet {
+		if p.config.CredentialsFile != "" {
+			log.Debugf("instantiating fleet cluster discovery client with a credentials file")
+			return gke.NewFleetDiscoveryClientWithCredentialsFile(p.ctx, p.config.CredentialsFile, p.getRetrier(config.APIGKEHub))
+		}
+		log.Debugf("instantiating fleet cluster discovery client")
+		return gke.NewFleetDiscoveryClient(p.ctx, p.getRetrier(config.APIGKEHub))
+	}
+	discoveryOpts := []gke.DiscoveryOption{
+		gke.WithClusterFilter(filter),
+		gke.WithSearchPageSize(discovery.PageSize),
+		gke.WithSearchLimit(discovery.MaxResults, discovery.AllowPartialResults),
+	}
+	if p.config.CredentialsFile != "" {
+		log.Debugf("instantiating cluster discovery client with a credentials file")
+		return gke.NewDiscoveryClientWithCredentialsFile(p.ctx, p.config.CredentialsFile, p.getRetrier(config.APICloudAsset), discoveryOpts...)
+	}
+	log.Debugf("instantiating cluster discovery client")
+	return gke.NewDiscoveryClient(p.ctx, p.getRetrier(config.APICloudAsset), discoveryOpts...)
+}
+
+// newClusterFilter creates cluster filter from the cluster discovery filters configuration.
+func newClusterFilter(filters config.ClusterDiscoveryFilters) (*gke.ClusterFilter, error) {
+	filter := &gke.ClusterFilter{
+		IncludeLabels:   filters.IncludeLabels,
+		ExcludeLabels:   filters.ExcludeLabels,
+		Mode:            filters.Mode,
+		ReleaseChannels: filters.ReleaseChannels,
+	}
+	var err error
+	if filters.Location != "" {
+		if filter.Location, err = regexp.Compile(filters.Location); err != nil {
+			return nil, err
+		}
+	}
+	if filters.Name != "" {
+		if filter.Name, err = regexp.Compile(filters.Name); err != nil {
+			return nil, err
+		}
+	}
+	return filter, nil
+}
+
 // discoverClusters discovers clusters according to the cluster discovery configuration.
 func (p *PolicyAutomationApp) discoverClusters() ([]string, error) {
 	if p.config.ClusterDiscovery.Organization != "" {
@@ -103,14 +181,87 @@ func (p *PolicyAutomationApp) discoverClusters() ([]string, error) {
 
 func (p *PolicyAutomationApp) evaluateClusters(regoPackageBases []string) error {
 	log.Info("Cluster review starting")
-	files, err := p.loadPolicyFiles()
+	evaluated, err := p.evaluatePolicies(regoPackageBases, p.registerResult)
+	if evaluated {
+		if closeErr := p.closeCollectors(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
 	if err != nil {
 		return err
 	}
+	p.finishClusterReview()
+	return nil
+}
+
+// registerResult registers the evaluation result of a single cluster with all configured
+// collectors. The streaming collectors are flushed, so they don't keep the result in memory.
+// A failing collector does not stop registering the result with the other collectors,
+// the first error is returned.
+func (p *PolicyAutomationApp) registerResult(result *policy.PolicyEvaluationResult) error {
+	var firstErr error
+	for _, c := range p.collectors {
+		log.Debugf("Collector %s registering the results of cluster %s", c.Name(), result.ClusterID)
+		if err := c.RegisterResult([]*policy.PolicyEvaluationResult{result}); err != nil {
+			p.out.ErrorPrint("failed to register evaluation results", err)
+			log.Errorf("could not register evaluation results: %s", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if sc, ok := c.(outputs.StreamingResultCollector); ok {
+			if err := sc.Flush(); err != nil {
+				p.out.ErrorPrint("failed to write evaluation results", err)
+				log.Errorf("could not write evaluation results of cluster %s: %s", result.ClusterID, err)
+				if firstErr == nil {
+					firstErr = err
+				}
+			}
+		}
+	}
+	return firstErr
+}
+
+// closeCollectors finalizes registering the evaluation results with all configured collectors.
+// All collectors are closed even if some of them fail, the first error is returned.
+func (p *PolicyAutomationApp) closeCollectors() error {
+	var firstErr error
+	for _, c := range p.collectors {
+		log.Infof("Collector %s writing the results", c.Name())
+		p.out.Printf("%s %s\n",
+			outputs.IconInfo,
+			consoleInfoColorF("Writing evaluation results ... [%s]", c.Name()),
+		)
+		if err := c.Close(); err != nil {
+			p.out.ErrorPrint("failed to close results registration", err)
+			log.Errorf("could not finalize registering evaluation results: %s", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		log.Infof("Collector %s processing closed", c.Name())
+	}
+	return firstErr
+}
+
+// evaluatePolicies loads the policies and evaluates the policies from given package bases
+// against the clusters. The clusters are processed in a streaming manner: the data is fetched
+// with bounded concurrency, each cluster is evaluated as soon as its data is available, the results
+// are passed to the resultFn and the cluster data is released. False is returned when there are
+// no clusters to check. When the run is interrupted, true is returned along with the error if any
+// of the clusters was evaluated, so the partial results can be written. The same applies when
+// the resultFn fails, as the result may still be registered with some of the collectors.
+func (p *PolicyAutomationApp) evaluatePolicies(regoPackageBases []string, resultFn func(result *policy.PolicyEvaluationResult) error) (bool, error) {
+	files, err := p.loadPolicyFiles()
+	if err != nil {
+		return false, err
+	}
 	if len(files) == 0 {
 		p.out.Printf("%s\n", consoleWarnColorF("No policies to check against"))
 		log.Errorf("No policies to check against")
-		return errNoPolicies
+		return false, errNoPolicies
 	}
 	// create a PolicyAgent client instance
 	pa := policy.NewPolicyAgent(p.ctx)
@@ -123,80 +274,151 @@ func (p *PolicyAutomationApp) evaluateClusters(regoPackageBases []string) error
 	if err := pa.WithFiles(files, p.config.PolicyExclusions); err != nil {
 		p.out.ErrorPrint("could not parse policy files", err)
 		log.Errorf("could not parse policy files: %s", err)
-		return err
+		return false, err
+	}
+
+	profiles, err := p.loadPolicyProfiles(files)
+	if err != nil {
+		return false, err
 	}
 
 	clusterIds, err := p.getClusters()
 	if err != nil {
 		p.out.ErrorPrint("could not identify clusters", err)
 		log.Errorf("could not identify clusters: %s", err)
-		return err
+		return false, err
 	}
 	if len(clusterIds) < 1 {
 		p.out.Printf("%s\n", consoleWarnColorF("No clusters to check, finishing..."))
-		log.Info("Cluster review finished")
-		p.out.Printf("%s %s\n",
-			outputs.IconInfo,
-			consoleInfoColorF("Cluster review finished"),
-		)
-		return nil
+		return false, nil
 	}
 	p.out.Printf("%s %s\n",
 		outputs.IconInfo,
 		consoleInfoColorF("Fetching data from %d input(s) for %d cluster(s)", len(p.inputs), len(clusterIds)),
 	)
-	clusterData, errors := inputs.GetAllInputsData(p.inputs, clusterIds)
-	if len(errors) > 0 {
-		p.out.ErrorPrint("could not fetch the cluster details", errors[0])
-		log.Errorf("could not fetch cluster details: %s", errors[0])
-		return errors[0]
+	ctx, cancel := context.WithCancel(p.ctx)
+	defer cancel()
+	inputTimeout := time.Duration(p.config.Inputs.TimeoutSeconds) * time.Second
+	clusters := inputs.StreamAllInputsData(ctx, p.inputs, clusterIds, inputTimeout)
+	evaluatedCount := 0
+	for evaluation := range p.evaluateClusterStream(ctx, pa, profiles, clusters, regoPackageBases) {
+		if (evaluation.fetchErr != nil || evaluation.evalErr != nil) && p.ctx.Err() != nil {
+			// errors caused by the interruption are reported below
+			break
+		}
+		if evaluation.fetchErr != nil {
+			p.out.ErrorPrint("could not fetch the cluster details", evaluation.fetchErr)
+			log.Errorf("could not fetch cluster details: %s", evaluation.fetchErr)
+			return false, evaluation.fetchErr
+		}
+		if evaluation.profile != "" {
+			p.out.Printf("%s %s\n",
+				outputs.IconInfo,
+				consoleInfoColorF("Evaluated policies of profile %s against GKE cluster... [%s]", evaluation.profile, evaluation.clusterID),
+			)
+		} else {
+			p.out.Printf("%s %s\n",
+				outputs.IconInfo,
+				consoleInfoColorF("Evaluated policies against GKE cluster... [%s]", evaluation.clusterID),
+			)
+		}
+		if evaluation.evalErr != nil {
+			p.out.ErrorPrint("failed to evaluate policies", evaluation.evalErr)
+			log.Errorf("could not evaluate rego policies on cluster %s: %s", evaluation.clusterID, evaluation.evalErr)
+			return false, evaluation.evalErr
+		}
+		if err := resultFn(evaluation.result); err != nil {
+			return true, err
+		}
+		evaluatedCount++
+	}
+	if err := p.ctx.Err(); err != nil {
+		err = fmt.Errorf("cluster review interrupted after evaluating %d out of %d clusters: %w", evaluatedCount, len(clusterIds), context.Cause(p.ctx))
+		p.out.ErrorPrint("cluster review interrupted", err)
+		log.Errorf("%s", err)
+		return evaluatedCount > 0, err
+	}
+	return true, nil
+}
+
+// clusterEvaluation is the policy evaluation result of a single cluster, or the error
+// of fetching the cluster data or evaluating the policies.
+type clusterEvaluation struct {
+	clusterID string
+	profile   string
+	result    *policy.PolicyEvaluationResult
+	fetchErr  error
+	evalErr   error
+}
+
+// evaluateClusterStream evaluates the clusters from a given channel with the configured number
+// of concurrent workers. The results are sent to the returned channel, which is closed when all
+// clusters are evaluated or the context is done.
+func (p *PolicyAutomationApp) evaluateClusterStream(ctx context.Context, pa policy.PolicyAgent, profiles []*policyProfile, clusters <-chan *inputs.ClusterData, regoPackageBases []string) <-chan *clusterEvaluation {
+	workers := p.config.Evaluation.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
 	}
-	val, _ := json.MarshalIndent(clusterData, "", "    ")
+	log.Debugf("evaluating clusters with %d workers", workers)
+	evaluations := make(chan *clusterEvaluation)
+	go func() {
+		var wg sync.WaitGroup
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for clusterData := range clusters {
+					evaluation := &clusterEvaluation{clusterID: clusterData.Cluster.Name, fetchErr: clusterData.Err}
+					if clusterData.Err == nil {
+						evaluation.profile, evaluation.result, evaluation.evalErr = p.evaluateCluster(pa, profiles, clusterData.Cluster, regoPackageBases)
+					}
+					select {
+					case evaluations <- evaluation:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		close(evaluations)
+	}()
+	return evaluations
+}
+
+// evaluateCluster evaluates the policies from given package bases against a single cluster,
+// using the agent of the matching policy profile if any. The name of the profile is returned
+// along with the results.
+func (p *PolicyAutomationApp) evaluateCluster(pa policy.PolicyAgent, profiles []*policyProfile, cluster *inputs.Cluster, regoPackageBases []string) (string, *policy.PolicyEvaluationResult, error) {
+	val, _ := json.MarshalIndent(cluster, "", "    ")
 	log.Debugf("[DEBUG] cluster: %s", string(val))
 
-	evalResults := &evaluationResults{}
-	for _, cluster := range clusterData {
-		p.out.Printf("%s %s\n",
-			outputs.IconInfo,
-			consoleInfoColorF("Evaluating policies against GKE cluster... [%s]", cluster.Name),
-		)
+	agent := pa
+	profileName := ""
+	if profile := p.selectPolicyProfile(profiles, cluster); profile != nil {
+		agent = profile.agent
+		profileName = profile.name
+		log.Infof("Evaluating policies of profile %s against GKE cluster %s", profile.name, cluster.Name)
+	} else {
 		log.Infof("Evaluating policies against GKE cluster %s", cluster.Name)
-		for _, pkgBase := range regoPackageBases {
-			evalResult, err := pa.Evaluate(cluster, pkgBase)
-			if err != nil {
-				p.out.ErrorPrint("failed to evaluate policies", err)
-				log.Errorf("could not evaluate rego policies on cluster %s: %s", cluster.Name, err)
-				return err
-			}
-			evalResult.ClusterID = cluster.Name
-			evalResults.Add(evalResult)
-		}
 	}
-
-	for _, c := range p.collectors {
-		log.Infof("Collector %s registering the results", c.Name())
-		p.out.Printf("%s %s\n",
-			outputs.IconInfo,
-			consoleInfoColorF("Writing evaluation results ... [%s]", c.Name()),
-		)
-		if err = c.RegisterResult(evalResults.List()); err != nil {
-			p.out.ErrorPrint("failed to register evaluation results", err)
-			log.Errorf("could not register evaluation results: %s", err)
-			return err
-		}
-		if err = c.Close(); err != nil {
-			p.out.ErrorPrint("failed to close results registration", err)
-			log.Errorf("could not finalize registering evaluation results: %s", err)
-			return err
+	result := &policy.PolicyEvaluationResult{ClusterID: cluster.Name}
+	for _, pkgBase := range regoPackageBases {
+		evalResult, err := agent.Evaluate(cluster, pkgBase)
+		if err != nil {
+			return profileName, nil, err
 		}
-		log.Infof("Collector %s processing closed", c.Name())
+		result.Policies = append(result.Policies, evalResult.Policies...)
 	}
+	return profileName, result, nil
+}
+
+func (p *PolicyAutomationApp) finishClusterReview() {
 	log.Info("Cluster review finished")
 	p.out.Printf("%s %s\n",
 		outputs.IconInfo,
 		consoleInfoColorF("Cluster review finished"),
 	)
-	return nil
 }
 
 func getClusterID(c config.ConfigCluster) (string, error) {
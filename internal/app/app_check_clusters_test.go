@@ -16,12 +16,20 @@ package app
 
 import (
 	"context"
+	"errors"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	cfg "github.com/google/gke-policy-automation/internal/config"
 	"github.com/google/gke-policy-automation/internal/gke"
+	"github.com/google/gke-policy-automation/internal/inputs"
 	"github.com/google/gke-policy-automation/internal/outputs"
+	"github.com/google/gke-policy-automation/internal/policy"
 )
 
 func TestGetClusters_config(t *testing.T) {
@@ -145,3 +153,278 @@ func TestDiscoverClusters_projects(t *testing.T) {
 		t.Fatalf("results are %v; want %v", results, allProjectsContent)
 	}
 }
+
+func TestNewClusterFilter(t *testing.T) {
+	filters := cfg.ClusterDiscoveryFilters{
+		IncludeLabels:   map[string]string{"env": "prod"},
+		Location:        "^europe-",
+		Name:            "^prod-",
+		Mode:            gke.ClusterModeStandard,
+		ReleaseChannels: []string{"REGULAR"},
+	}
+	filter, err := newClusterFilter(filters)
+	if err != nil {
+		t.Fatalf("err is not nil; want nil; err = %s", err)
+	}
+	if !reflect.DeepEqual(filter.IncludeLabels, filters.IncludeLabels) {
+		t.Errorf("filter includeLabels = %v; want %v", filter.IncludeLabels, filters.IncludeLabels)
+	}
+	if filter.Location == nil || filter.Location.String() != filters.Location {
+		t.Errorf("filter location = %v; want %v", filter.Location, filters.Location)
+	}
+	if filter.Name == nil || filter.Name.String() != filters.Name {
+		t.Errorf("filter name = %v; want %v", filter.Name, filters.Name)
+	}
+	if filter.Mode != filters.Mode {
+		t.Errorf("filter mode = %v; want %v", filter.Mode, filters.Mode)
+	}
+	if _, err := newClusterFilter(cfg.ClusterDiscoveryFilters{Name: "[prod"}); err == nil {
+		t.Errorf("err is nil; want error")
+	}
+}
+
+type streamingCollectorMock struct {
+	registered []*policy.PolicyEvaluationResult
+	flushed    int
+	closed     bool
+	registerFn func()
+	flushErr   error
+}
+
+func (m *streamingCollectorMock) RegisterResult(results []*policy.PolicyEvaluationResult) error {
+	m.registered = append(m.registered, results...)
+	if m.registerFn != nil {
+		m.registerFn()
+	}
+	return nil
+}
+
+func (m *streamingCollectorMock) Flush() error {
+	m.flushed++
+	return m.flushErr
+}
+
+func (m *streamingCollectorMock) Close() error {
+	m.closed = true
+	return nil
+}
+
+func (m *streamingCollectorMock) Name() string {
+	return "streaming"
+}
+
+func TestRegisterResult(t *testing.T) {
+	collector := &streamingCollectorMock{}
+	pa := PolicyAutomationApp{
+		out:        outputs.NewSilentOutput(),
+		collectors: []outputs.ValidationResultCollector{collector},
+	}
+	for _, clusterID := range []string{"cluster-one", "cluster-two"} {
+		if err := pa.registerResult(&policy.PolicyEvaluationResult{ClusterID: clusterID}); err != nil {
+			t.Fatalf("err = %v; want nil", err)
+		}
+	}
+	if len(collector.registered) != 2 {
+		t.Errorf("registered results = %v; want %v", len(collector.registered), 2)
+	}
+	if collector.flushed != 2 {
+		t.Errorf("flushed = %v; want %v", collector.flushed, 2)
+	}
+	if collector.closed {
+		t.Errorf("collector is closed before closeCollectors")
+	}
+	if err := pa.closeCollectors(); err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	if !collector.closed {
+		t.Errorf("collector is not closed")
+	}
+}
+
+type policyAgentMock struct {
+	evaluateFn func(input interface{}, packageBase string) (*policy.PolicyEvaluationResult, error)
+}
+
+func (m *policyAgentMock) Compile(files []*policy.PolicyFile) error {
+	return nil
+}
+
+func (m *policyAgentMock) WithFiles(files []*policy.PolicyFile, excludes cfg.ConfigPolicyExclusions) error {
+	return nil
+}
+
+func (m *policyAgentMock) WithParameters(parameters map[string]interface{}) error {
+	return nil
+}
+
+func (m *policyAgentMock) Evaluate(input interface{}, packageBase string) (*policy.PolicyEvaluationResult, error) {
+	return m.evaluateFn(input, packageBase)
+}
+
+func (m *policyAgentMock) GetPolicies() []*policy.Policy {
+	return nil
+}
+
+func TestEvaluateClusterStream(t *testing.T) {
+	workers := 3
+	var mutex sync.Mutex
+	running, maxRunning := 0, 0
+	agent := &policyAgentMock{evaluateFn: func(input interface{}, packageBase string) (*policy.PolicyEvaluationResult, error) {
+		mutex.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mutex.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		mutex.Lock()
+		running--
+		mutex.Unlock()
+		if input.(*inputs.Cluster).Name == "cluster-bad" {
+			return nil, errors.New("evaluation error")
+		}
+		return &policy.PolicyEvaluationResult{Policies: []*policy.Policy{{Name: packageBase + ".policy"}}}, nil
+	}}
+	clusterIDs := []string{"cluster-one", "cluster-two", "cluster-three", "cluster-four", "cluster-bad"}
+	clusters := make(chan *inputs.ClusterData, len(clusterIDs)+1)
+	for _, clusterID := range clusterIDs {
+		clusters <- &inputs.ClusterData{Cluster: &inputs.Cluster{Name: clusterID}}
+	}
+	clusters <- &inputs.ClusterData{Cluster: &inputs.Cluster{Name: "cluster-unreachable"}, Err: errors.New("fetch error")}
+	close(clusters)
+
+	pa := PolicyAutomationApp{config: &cfg.Config{Evaluation: cfg.ConfigEvaluation{Workers: workers}}}
+	evaluations := make(map[string]*clusterEvaluation)
+	for evaluation := range pa.evaluateClusterStream(context.Background(), agent, nil, clusters, []string{"gke.policy", "gke.scalability"}) {
+		evaluations[evaluation.clusterID] = evaluation
+	}
+	if len(evaluations) != len(clusterIDs)+1 {
+		t.Fatalf("len(evaluations) = %v; want %v", len(evaluations), len(clusterIDs)+1)
+	}
+	if result := evaluations["cluster-one"].result; result == nil || result.ClusterID != "cluster-one" || len(result.Policies) != 2 {
+		t.Errorf("cluster-one result = %+v; want result with two policies", result)
+	}
+	if evaluations["cluster-bad"].evalErr == nil {
+		t.Errorf("cluster-bad evalErr is nil; want error")
+	}
+	if evaluations["cluster-unreachable"].fetchErr == nil {
+		t.Errorf("cluster-unreachable fetchErr is nil; want error")
+	}
+	if maxRunning > workers {
+		t.Errorf("max concurrent evaluations = %v; want at most %v", maxRunning, workers)
+	}
+}
+
+type inputMock struct {
+	getDataFn func(clusterID string) (interface{}, error)
+}
+
+func (m *inputMock) GetID() string {
+	return "mock"
+}
+
+func (m *inputMock) GetDataSourceName() string {
+	return "gke"
+}
+
+func (m *inputMock) GetDescription() string {
+	return "mock input"
+}
+
+func (m *inputMock) GetData(ctx context.Context, clusterID string) (interface{}, error) {
+	return m.getDataFn(clusterID)
+}
+
+func (m *inputMock) Close() error {
+	return nil
+}
+
+// writeTestPolicy writes a valid test policy to a temporary directory and returns the directory.
+func writeTestPolicy(t *testing.T) string {
+	policyDir := t.TempDir()
+	policyContent := "# METADATA\n" +
+		"# title: Test\n" +
+		"# description: Test policy\n" +
+		"# custom:\n" +
+		"#   group: Test\n" +
+		"#   severity: Low\n" +
+		"#   sccCategory: TEST\n" +
+		"package gke.policy.test\n" +
+		"default valid := true\n"
+	if err := os.WriteFile(filepath.Join(policyDir, "test.rego"), []byte(policyContent), 0644); err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	return policyDir
+}
+
+func TestEvaluateClusters_interrupted(t *testing.T) {
+	policyDir := writeTestPolicy(t)
+	release := make(chan struct{})
+	defer close(release)
+	input := &inputMock{getDataFn: func(clusterID string) (interface{}, error) {
+		if clusterID == "cluster-two" {
+			<-release
+		}
+		return map[string]interface{}{}, nil
+	}}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	collector := &streamingCollectorMock{registerFn: cancel}
+	pa := PolicyAutomationApp{
+		ctx:        ctx,
+		out:        outputs.NewSilentOutput(),
+		inputs:     []inputs.Input{input},
+		collectors: []outputs.ValidationResultCollector{collector},
+		config: &cfg.Config{
+			Policies: []cfg.ConfigPolicy{{LocalDirectory: policyDir}},
+			Clusters: []cfg.ConfigCluster{{ID: "cluster-one"}, {ID: "cluster-two"}},
+		},
+	}
+	err := pa.evaluateClusters([]string{regoPackageBaseBestPractices})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v; want %v", err, context.Canceled)
+	}
+	if len(collector.registered) != 1 || collector.registered[0].ClusterID != "cluster-one" {
+		t.Errorf("registered results = %v; want result of cluster-one", collector.registered)
+	}
+	if !collector.closed {
+		t.Errorf("collector is not closed after interruption")
+	}
+}
+
+func TestEvaluateClusters_flushError(t *testing.T) {
+	policyDir := writeTestPolicy(t)
+	input := &inputMock{getDataFn: func(clusterID string) (interface{}, error) {
+		return map[string]interface{}{}, nil
+	}}
+	flushErr := errors.New("flush error")
+	streamingCollector := &streamingCollectorMock{flushErr: flushErr}
+	fileName := filepath.Join(t.TempDir(), "results.csv")
+	pa := PolicyAutomationApp{
+		ctx:    context.Background(),
+		out:    outputs.NewSilentOutput(),
+		inputs: []inputs.Input{input},
+		collectors: []outputs.ValidationResultCollector{
+			streamingCollector,
+			outputs.NewCSVResultToFileCollector(fileName),
+		},
+		config: &cfg.Config{
+			Policies: []cfg.ConfigPolicy{{LocalDirectory: policyDir}},
+			Clusters: []cfg.ConfigCluster{{ID: "cluster-one"}},
+		},
+	}
+	err := pa.evaluateClusters([]string{regoPackageBaseBestPractices})
+	if !errors.Is(err, flushErr) {
+		t.Fatalf("err = %v; want %v", err, flushErr)
+	}
+	if !streamingCollector.closed {
+		t.Errorf("streaming collector is not closed after flush error")
+	}
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	if !strings.Contains(string(data), "cluster-one") {
+		t.Errorf("file output = %q; want results of cluster-one", data)
+	}
+}
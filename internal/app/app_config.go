@@ -15,17 +15,24 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"time"
 
+	"github.com/fatih/color"
+	bqc "github.com/google/gke-policy-automation/internal/bigquery"
 	cfg "github.com/google/gke-policy-automation/internal/config"
+	"github.com/google/gke-policy-automation/internal/gke"
 	"github.com/google/gke-policy-automation/internal/inputs"
 	"github.com/google/gke-policy-automation/internal/inputs/clients"
 	"github.com/google/gke-policy-automation/internal/log"
 	"github.com/google/gke-policy-automation/internal/outputs"
+	"github.com/google/gke-policy-automation/internal/outputs/issues"
+	lgc "github.com/google/gke-policy-automation/internal/outputs/logging"
 	pbc "github.com/google/gke-policy-automation/internal/outputs/pubsub"
 	"github.com/google/gke-policy-automation/internal/outputs/storage"
+	"github.com/google/gke-policy-automation/internal/retry"
 )
 
 type validateConfig func(config cfg.Config) error
@@ -53,14 +60,48 @@ func (p *PolicyAutomationApp) LoadCliConfig(cliConfig *CliConfig, defaultsFn set
 	return p.LoadConfig(config)
 }
 
+// getRetrier returns the retrier of a given API with the settings of the API clients configuration.
+// The retrier is shared by all clients of the API, so the rate limit applies to the API as a whole.
+func (p *PolicyAutomationApp) getRetrier(api string) *retry.Retrier {
+	if r, ok := p.retriers[api]; ok {
+		return r
+	}
+	apiClients := p.config.APIClients
+	r := retry.NewRetrier(api, retry.Settings{
+		MaxRetries:     apiClients.MaxRetries,
+		InitialBackoff: time.Duration(apiClients.InitialBackoffMillis) * time.Millisecond,
+		MaxBackoff:     time.Duration(apiClients.MaxBackoffMillis) * time.Millisecond,
+		QPS:            apiClients.QPS[api],
+	})
+	if p.retriers == nil {
+		p.retriers = make(map[string]*retry.Retrier)
+	}
+	p.retriers[api] = r
+	return r
+}
+
 func (p *PolicyAutomationApp) LoadConfig(config *cfg.Config) error {
 	p.config = config
+	if config.TimeoutSeconds > 0 {
+		log.Debugf("setting run timeout to %d seconds", config.TimeoutSeconds)
+		p.ctx, p.cancel = context.WithTimeout(p.ctx, time.Duration(config.TimeoutSeconds)*time.Second)
+	}
+	p.outputsCtx, p.outputsCancel = newOutputsContext(p.ctx, outputsShutdownGracePeriod)
+	p.retriers = make(map[string]*retry.Retrier)
+	if config.Console.NoColor {
+		color.NoColor = true
+	}
 	if p.config.JSONOutput {
 		p.collectors = []outputs.ValidationResultCollector{outputs.NewConsoleJSONResultCollector(outputs.NewStdOutOutput())}
 	} else {
 		if !p.config.SilentMode {
 			p.out = outputs.NewStdOutOutput()
-			p.collectors = []outputs.ValidationResultCollector{outputs.NewConsoleResultCollector(p.out)}
+			p.collectors = []outputs.ValidationResultCollector{outputs.NewConsoleResultCollectorWithOptions(p.out, outputs.ConsoleOptions{
+				ViolationsOnly: config.Console.ViolationsOnly,
+				MinSeverity:    config.Console.MinSeverity,
+				GroupByCluster: config.Console.GroupBy == cfg.ConsoleGroupByCluster,
+				Summary:        config.Console.Summary,
+			})}
 			p.clusterDumpCollectors = append(p.clusterDumpCollectors, outputs.NewOutputClusterDumpCollector(p.out))
 		}
 	}
@@ -86,6 +127,18 @@ func (p *PolicyAutomationApp) loadInputsConfig(config *cfg.Config) error {
 	if err := p.loadMetricsAPIInputConfig(config.Inputs.MetricsAPI); err != nil {
 		return err
 	}
+	if err := p.loadTerraformPlanInputConfig(config.Inputs.TerraformPlan); err != nil {
+		return err
+	}
+	if err := p.loadConfigConnectorInputConfig(config.Inputs.ConfigConnector); err != nil {
+		return err
+	}
+	if err := p.loadFleetInputConfig(config.Inputs.Fleet, config.CredentialsFile); err != nil {
+		return err
+	}
+	if err := p.loadLocalInputConfig(config.Inputs.Local); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -96,9 +149,9 @@ func (p *PolicyAutomationApp) loadGKEApiInputConfig(config *cfg.GKEApiInput, cre
 	var input inputs.Input
 	var err error
 	if credentialsFile != "" {
-		input, err = inputs.NewGKEApiInputWithCredentials(p.ctx, p.config.CredentialsFile)
+		input, err = inputs.NewGKEApiInputWithCredentials(p.ctx, p.config.CredentialsFile, p.getRetrier(cfg.APIContainer))
 	} else {
-		input, err = inputs.NewGKEApiInput(p.ctx)
+		input, err = inputs.NewGKEApiInput(p.ctx, p.getRetrier(cfg.APIContainer))
 	}
 	if err != nil {
 		return err
@@ -108,9 +161,76 @@ func (p *PolicyAutomationApp) loadGKEApiInputConfig(config *cfg.GKEApiInput, cre
 }
 
 func (p *PolicyAutomationApp) loadGKELocalInputConfig(config *cfg.GKELocalInput) error {
+	if config == nil || !config.Enabled {
+		return nil
+	}
+	dump, err := p.loadClusterDump(config.DumpFile)
+	if err != nil {
+		return err
+	}
+	p.inputs = append(p.inputs, inputs.NewGKELocalInputWithDump(dump))
+	return nil
+}
+
+func (p *PolicyAutomationApp) loadTerraformPlanInputConfig(config *cfg.TerraformPlanInput) error {
+	if config != nil && config.Enabled {
+		p.inputs = append(p.inputs, inputs.NewTerraformPlanInput(config.PlanFile))
+	}
+	return nil
+}
+
+func (p *PolicyAutomationApp) loadConfigConnectorInputConfig(config *cfg.ConfigConnectorInput) error {
 	if config != nil && config.Enabled {
-		p.inputs = append(p.inputs, inputs.NewGKELocalInput(config.DumpFile))
+		p.inputs = append(p.inputs, inputs.NewConfigConnectorInput(config.Directory))
+	}
+	return nil
+}
+
+func (p *PolicyAutomationApp) loadLocalInputConfig(config *cfg.LocalInput) error {
+	if config == nil || !config.Enabled {
+		return nil
+	}
+	dump, err := p.loadClusterDump(config.DumpFile)
+	if err != nil {
+		return err
 	}
+	p.inputs = append(p.inputs, inputs.NewLocalInputsWithDump(dump, config.DataSources)...)
+	return nil
+}
+
+// loadClusterDump reads and parses a given cluster dump file. Each file is parsed once and
+// shared by the local inputs and the local cluster discovery.
+func (p *PolicyAutomationApp) loadClusterDump(file string) (*gke.ClusterDump, error) {
+	if dump, ok := p.clusterDumps[file]; ok {
+		return dump, nil
+	}
+	log.Debugf("reading cluster dump file %s", file)
+	dump, err := gke.ReadClusterDump(file)
+	if err != nil {
+		return nil, err
+	}
+	if p.clusterDumps == nil {
+		p.clusterDumps = make(map[string]*gke.ClusterDump)
+	}
+	p.clusterDumps[file] = dump
+	return dump, nil
+}
+
+func (p *PolicyAutomationApp) loadFleetInputConfig(config *cfg.FleetInput, credentialsFile string) error {
+	if config == nil || !config.Enabled {
+		return nil
+	}
+	var input inputs.Input
+	var err error
+	if credentialsFile != "" {
+		input, err = inputs.NewFleetInputWithCredentials(p.ctx, credentialsFile, config.Projects, p.getRetrier(cfg.APIGKEHub))
+	} else {
+		input, err = inputs.NewFleetInput(p.ctx, config.Projects, p.getRetrier(cfg.APIGKEHub))
+	}
+	if err != nil {
+		return err
+	}
+	p.inputs = append(p.inputs, input)
 	return nil
 }
 
@@ -118,7 +238,7 @@ func (p *PolicyAutomationApp) loadK8SApiInputConfig(config *cfg.K8SAPIInput) err
 	if config == nil || !config.Enabled {
 		return nil
 	}
-	k8InputBuilder := inputs.NewK8sAPIInputBuilder(p.ctx, config.APIVersions).
+	k8InputBuilder := inputs.NewK8sAPIInputBuilder(p.ctx, config.APIVersions, p.getRetrier(cfg.APIContainer)).
 		WithCredentialsFile(p.config.CredentialsFile)
 	k8Input, err := k8InputBuilder.Build()
 	if err != nil {
@@ -153,7 +273,7 @@ func (p *PolicyAutomationApp) loadMetricsAPIInputConfig(config *cfg.MetricsAPIIn
 
 func (p *PolicyAutomationApp) loadOutputsConfig(config *cfg.Config) error {
 	for _, out := range config.Outputs {
-		if err := p.loadFileOutputConfig(out.FileName); err != nil {
+		if err := p.loadFileOutputConfig(out); err != nil {
 			return nil
 		}
 		if err := p.loadCloudStorageOutputConfig(out.CloudStorage, config.CredentialsFile); err != nil {
@@ -165,15 +285,39 @@ func (p *PolicyAutomationApp) loadOutputsConfig(config *cfg.Config) error {
 		if err := p.loadSccOutputConfig(out.SecurityCommandCenter, config.CredentialsFile); err != nil {
 			return nil
 		}
+		if err := p.loadBigQueryOutputConfig(out.BigQuery, config.CredentialsFile); err != nil {
+			return err
+		}
+		if err := p.loadWebhookOutputConfig(out.Webhook); err != nil {
+			return err
+		}
+		if err := p.loadIssuesOutputConfig(out.Issues); err != nil {
+			return err
+		}
+		if err := p.loadCloudLoggingOutputConfig(out.CloudLogging, config.CredentialsFile); err != nil {
+			return err
+		}
+	}
+	if cfg.IsHistoryEnabled(*config) {
+		log.Infof("Loading history store output")
+		p.collectors = append(p.collectors, outputs.NewHistoryResultCollector(p.newHistoryStore))
 	}
 	return nil
 }
 
-func (p *PolicyAutomationApp) loadFileOutputConfig(fileName string) error {
+func (p *PolicyAutomationApp) loadFileOutputConfig(config cfg.ConfigOutput) error {
+	fileName := config.FileName
 	if fileName != "" {
 		log.Infof("Loading File output")
-		p.collectors = append(p.collectors, outputs.NewJSONResultToFileCollector(fileName))
-		p.clusterDumpCollectors = append(p.clusterDumpCollectors, outputs.NewFileClusterDumpCollector(fileName))
+		switch cfg.GetOutputFileFormat(config) {
+		case cfg.OutputFormatCSV:
+			p.collectors = append(p.collectors, outputs.NewCSVResultToFileCollector(fileName))
+		case cfg.OutputFormatMarkdown:
+			p.collectors = append(p.collectors, outputs.NewMarkdownResultToFileCollector(fileName))
+		default:
+			p.collectors = append(p.collectors, outputs.NewJSONResultToFileCollector(fileName))
+			p.clusterDumpCollectors = append(p.clusterDumpCollectors, outputs.NewFileClusterDumpCollector(fileName))
+		}
 		p.policyDocsFile = fileName
 	}
 	return nil
@@ -187,9 +331,9 @@ func (p *PolicyAutomationApp) loadCloudStorageOutputConfig(config cfg.CloudStora
 	var client *storage.CloudStorageClient
 	var err error
 	if credentialsFile != "" {
-		client, err = storage.NewCloudStorageClientWithCredentialsFile(p.ctx, credentialsFile)
+		client, err = storage.NewCloudStorageClientWithCredentialsFile(p.outputsCtx, credentialsFile, p.getRetrier(cfg.APIStorage))
 	} else {
-		client, err = storage.NewCloudStorageClient(p.ctx)
+		client, err = storage.NewCloudStorageClient(p.outputsCtx, p.getRetrier(cfg.APIStorage))
 	}
 	if err != nil {
 		return err
@@ -215,14 +359,116 @@ func (p *PolicyAutomationApp) loadPubSubOutputConfig(config cfg.PubSubOutput, cr
 	var client outputs.PubSubClient
 	var err error
 	if credentialsFile != "" {
-		client, err = pbc.NewPubSubClientWithCredentialsFile(p.ctx, config.Project, credentialsFile)
+		client, err = pbc.NewPubSubClientWithCredentialsFile(p.outputsCtx, config.Project, credentialsFile, p.getRetrier(cfg.APIPubSub))
+	} else {
+		client, err = pbc.NewPubSubClient(p.outputsCtx, config.Project, p.getRetrier(cfg.APIPubSub))
+	}
+	if err != nil {
+		return err
+	}
+	p.collectors = append(p.collectors, outputs.NewPubSubResultCollectorWithMode(client, config.Project, config.Topic, config.Mode))
+	return nil
+}
+
+func (p *PolicyAutomationApp) loadBigQueryOutputConfig(config cfg.BigQueryOutput, credentialsFile string) error {
+	if config.Dataset == "" {
+		return nil
+	}
+	log.Infof("Loading BigQuery output")
+	var client bqc.BigQueryClient
+	var err error
+	if credentialsFile != "" {
+		client, err = bqc.NewBigQueryClientWithCredentialsFile(p.outputsCtx, config.Project, credentialsFile, p.getRetrier(cfg.APIBigQuery))
+	} else {
+		client, err = bqc.NewBigQueryClient(p.outputsCtx, config.Project, p.getRetrier(cfg.APIBigQuery))
+	}
+	if err != nil {
+		return err
+	}
+	p.collectors = append(p.collectors, outputs.NewBigQueryResultCollector(client, config.Dataset, config.Table))
+	return nil
+}
+
+func (p *PolicyAutomationApp) loadWebhookOutputConfig(config cfg.WebhookOutput) error {
+	if config.URL == "" {
+		return nil
+	}
+	log.Infof("Loading webhook output")
+	tmpl := config.Template
+	if config.TemplateFile != "" {
+		data, err := os.ReadFile(config.TemplateFile)
+		if err != nil {
+			return fmt.Errorf("could not read webhook template file: %w", err)
+		}
+		tmpl = string(data)
+	}
+	collector, err := outputs.NewWebhookResultCollector(p.outputsCtx, config.URL, outputs.WebhookOptions{
+		Template:       tmpl,
+		MinSeverity:    config.MinSeverity,
+		GroupByCluster: config.GroupByCluster,
+		MaxRetries:     config.MaxRetries,
+	})
+	if err != nil {
+		return err
+	}
+	p.collectors = append(p.collectors, collector)
+	return nil
+}
+
+func (p *PolicyAutomationApp) loadCloudLoggingOutputConfig(config cfg.CloudLoggingOutput, credentialsFile string) error {
+	if !config.Enabled {
+		return nil
+	}
+	log.Infof("Loading Cloud Logging output")
+	var client *lgc.CloudLoggingClient
+	var err error
+	if credentialsFile != "" {
+		client, err = lgc.NewCloudLoggingClientWithCredentialsFile(p.outputsCtx, credentialsFile, p.getRetrier(cfg.APILogging))
 	} else {
-		client, err = pbc.NewPubSubClient(p.ctx, config.Project)
+		client, err = lgc.NewCloudLoggingClient(p.outputsCtx, p.getRetrier(cfg.APILogging))
 	}
 	if err != nil {
 		return err
 	}
-	p.collectors = append(p.collectors, outputs.NewPubSubResultCollector(client, config.Project, config.Topic))
+	p.collectors = append(p.collectors, outputs.NewCloudLoggingResultCollector(client, config.Project, config.LogID))
+	return nil
+}
+
+func (p *PolicyAutomationApp) loadIssuesOutputConfig(config cfg.IssuesOutput) error {
+	if config.Tracker == "" {
+		return nil
+	}
+	log.Infof("Loading %s issues output", config.Tracker)
+	tokenEnv := config.TokenEnv
+	var client issues.IssueTrackerClient
+	var err error
+	switch config.Tracker {
+	case cfg.IssueTrackerGitHub:
+		if tokenEnv == "" {
+			tokenEnv = cfg.DefaultGitHubTokenEnv
+		}
+		client, err = issues.NewGitHubClient(p.outputsCtx, config.URL, config.Repository, os.Getenv(tokenEnv), config.Labels)
+	case cfg.IssueTrackerJira:
+		if tokenEnv == "" {
+			tokenEnv = cfg.DefaultJiraTokenEnv
+		}
+		client, err = issues.NewJiraClient(p.outputsCtx, issues.JiraOptions{
+			BaseURL:          config.URL,
+			Project:          config.Project,
+			IssueType:        config.IssueType,
+			User:             config.User,
+			Token:            os.Getenv(tokenEnv),
+			Labels:           config.Labels,
+			CloseTransition:  config.CloseTransition,
+			ReopenTransition: config.ReopenTransition,
+		})
+	default:
+		err = fmt.Errorf("unsupported issue tracker %q", config.Tracker)
+	}
+	if err != nil {
+		return err
+	}
+	p.collectors = append(p.collectors, outputs.NewIssuesResultCollector(client, config.MinSeverity))
 	return nil
 }
 
@@ -231,7 +477,7 @@ func (p *PolicyAutomationApp) loadSccOutputConfig(config cfg.SecurityCommandCent
 		return nil
 	}
 	log.Infof("Loading Security Command Center output")
-	collector, err := outputs.NewSccCollector(p.ctx, config.OrganizationNumber, config.ProvisionSource, credsFile)
+	collector, err := outputs.NewSccCollector(p.outputsCtx, config.OrganizationNumber, config.ProvisionSource, credsFile, p.getRetrier(cfg.APISecurityCenter))
 	if err != nil {
 		return err
 	}
@@ -253,8 +499,11 @@ func newConfigFromCli(cliConfig *CliConfig) *cfg.Config {
 	config.JSONOutput = cliConfig.JSONOutput
 	config.CredentialsFile = cliConfig.CredentialsFile
 	config.DumpFile = cliConfig.DumpFile
+	config.ClusterInventory.File = cliConfig.InventoryFile
+	config.ClusterInventory.Format = cliConfig.InventoryFormat
 	if cliConfig.DiscoveryEnabled {
 		config.ClusterDiscovery.Enabled = true
+		config.ClusterDiscovery.Source = cliConfig.DiscoverySource
 		if cliConfig.ProjectName != "" {
 			config.ClusterDiscovery.Projects = []string{cliConfig.ProjectName}
 		}
@@ -272,6 +521,7 @@ func newConfigFromCli(cliConfig *CliConfig) *cfg.Config {
 	if cliConfig.OutputFile != "" {
 		config.Outputs = append(config.Outputs, cfg.ConfigOutput{
 			FileName: cliConfig.OutputFile,
+			Format:   cliConfig.OutputFormat,
 		})
 	}
 	if cliConfig.LocalDirectory != "" || cliConfig.GitRepository != "" {
@@ -282,5 +532,30 @@ func newConfigFromCli(cliConfig *CliConfig) *cfg.Config {
 			GitDirectory:   cliConfig.GitDirectory,
 		})
 	}
+	if cliConfig.ConfigConnectorDirectory != "" {
+		config.Inputs.ConfigConnector = &cfg.ConfigConnectorInput{
+			Enabled:   true,
+			Directory: cliConfig.ConfigConnectorDirectory,
+		}
+	}
+	if cliConfig.TerraformPlanFile != "" {
+		config.Inputs.TerraformPlan = &cfg.TerraformPlanInput{
+			Enabled:  true,
+			PlanFile: cliConfig.TerraformPlanFile,
+		}
+	}
+	config.Remediation.Format = cliConfig.RemediationFormat
+	config.Remediation.Apply = cliConfig.RemediationApply
+	config.History.SQLite.File = cliConfig.HistoryFile
+	config.Console = cfg.ConfigConsole{
+		ViolationsOnly: cliConfig.ConsoleViolationsOnly,
+		MinSeverity:    cliConfig.ConsoleMinSeverity,
+		GroupBy:        cliConfig.ConsoleGroupBy,
+		Summary:        cliConfig.ConsoleSummary,
+		NoColor:        cliConfig.ConsoleNoColor,
+	}
+	config.Evaluation.Workers = cliConfig.EvaluationWorkers
+	config.TimeoutSeconds = cliConfig.TimeoutSeconds
+	config.Inputs.TimeoutSeconds = cliConfig.InputTimeoutSeconds
 	return config
 }
@@ -25,6 +25,8 @@ import (
 	"time"
 
 	cfg "github.com/google/gke-policy-automation/internal/config"
+	"github.com/google/gke-policy-automation/internal/gke"
+	"github.com/google/gke-policy-automation/internal/outputs"
 	"gopkg.in/yaml.v3"
 )
 
@@ -112,6 +114,45 @@ func TestLoadConfig(t *testing.T) {
 	}
 }
 
+func TestLoadConfig_timeout(t *testing.T) {
+	config := &cfg.Config{
+		CredentialsFile: "./test-fixtures/test_credentials.json",
+		TimeoutSeconds:  60,
+	}
+	pa := PolicyAutomationApp{ctx: context.Background()}
+	if err := pa.LoadConfig(config); err != nil {
+		t.Fatalf("err is not nil; want nil; err = %s", err)
+	}
+	if _, ok := pa.ctx.Deadline(); !ok {
+		t.Errorf("pa.ctx has no deadline")
+	}
+	if _, ok := pa.outputsCtx.Deadline(); ok {
+		t.Errorf("pa.outputsCtx has deadline; want none")
+	}
+	if err := pa.Close(); err != nil {
+		t.Errorf("err on close is not nil; want nil; err = %s", err)
+	}
+	if pa.ctx.Err() == nil {
+		t.Errorf("pa.ctx is not cancelled on close")
+	}
+}
+
+func TestGetRetrier(t *testing.T) {
+	pa := PolicyAutomationApp{config: &cfg.Config{
+		APIClients: cfg.ConfigAPIClients{QPS: map[string]float64{cfg.APIContainer: 10}},
+	}}
+	retrier := pa.getRetrier(cfg.APIContainer)
+	if retrier == nil {
+		t.Fatalf("retrier is nil")
+	}
+	if pa.getRetrier(cfg.APIContainer) != retrier {
+		t.Errorf("retrier of the same API is not shared")
+	}
+	if pa.getRetrier(cfg.APIPubSub) == retrier {
+		t.Errorf("retrier of other API is the same")
+	}
+}
+
 func TestNewConfigFromCli(t *testing.T) {
 	input := &CliConfig{
 		SilentMode:      true,
@@ -164,6 +205,149 @@ func TestNewConfigFromCli(t *testing.T) {
 	}
 }
 
+func TestNewConfigFromCli_discovery(t *testing.T) {
+	input := &CliConfig{
+		DiscoveryEnabled: true,
+		DiscoverySource:  cfg.DiscoverySourceGKEAPI,
+		ProjectName:      "my-project",
+	}
+	config := newConfigFromCli(input)
+	if !config.ClusterDiscovery.Enabled {
+		t.Errorf("clusterDiscovery enabled = %v; want %v", config.ClusterDiscovery.Enabled, true)
+	}
+	if config.ClusterDiscovery.Source != input.DiscoverySource {
+		t.Errorf("clusterDiscovery source = %v; want %v", config.ClusterDiscovery.Source, input.DiscoverySource)
+	}
+	if !reflect.DeepEqual(config.ClusterDiscovery.Projects, []string{input.ProjectName}) {
+		t.Errorf("clusterDiscovery projects = %v; want %v", config.ClusterDiscovery.Projects, []string{input.ProjectName})
+	}
+}
+
+func TestNewConfigFromCli_inventory(t *testing.T) {
+	input := &CliConfig{
+		InventoryFile:   "-",
+		InventoryFormat: gke.InventoryFormatYAML,
+	}
+	config := newConfigFromCli(input)
+	if config.ClusterInventory.File != input.InventoryFile {
+		t.Errorf("clusterInventory file = %v; want %v", config.ClusterInventory.File, input.InventoryFile)
+	}
+	if config.ClusterInventory.Format != input.InventoryFormat {
+		t.Errorf("clusterInventory format = %v; want %v", config.ClusterInventory.Format, input.InventoryFormat)
+	}
+}
+
+func TestNewConfigFromCli_history(t *testing.T) {
+	input := &CliConfig{
+		HistoryFile: "history.db",
+	}
+	config := newConfigFromCli(input)
+	if config.History.SQLite.File != input.HistoryFile {
+		t.Errorf("history sqlite file = %v; want %v", config.History.SQLite.File, input.HistoryFile)
+	}
+}
+
+func TestNewConfigFromCli_outputFormat(t *testing.T) {
+	input := &CliConfig{
+		OutputFile:   "report.txt",
+		OutputFormat: cfg.OutputFormatMarkdown,
+	}
+	config := newConfigFromCli(input)
+	if len(config.Outputs) != 1 {
+		t.Fatalf("len(outputs) = %v; want %v", len(config.Outputs), 1)
+	}
+	if config.Outputs[0].Format != input.OutputFormat {
+		t.Errorf("output format = %v; want %v", config.Outputs[0].Format, input.OutputFormat)
+	}
+}
+
+func TestNewConfigFromCli_console(t *testing.T) {
+	input := &CliConfig{
+		ConsoleViolationsOnly: true,
+		ConsoleMinSeverity:    "high",
+		ConsoleGroupBy:        cfg.ConsoleGroupByCluster,
+		ConsoleSummary:        true,
+		ConsoleNoColor:        true,
+	}
+	expected := cfg.ConfigConsole{
+		ViolationsOnly: true,
+		MinSeverity:    "high",
+		GroupBy:        cfg.ConsoleGroupByCluster,
+		Summary:        true,
+		NoColor:        true,
+	}
+	config := newConfigFromCli(input)
+	if config.Console != expected {
+		t.Errorf("console = %+v; want %+v", config.Console, expected)
+	}
+}
+
+func TestNewConfigFromCli_evaluation(t *testing.T) {
+	input := &CliConfig{EvaluationWorkers: 8}
+	config := newConfigFromCli(input)
+	if config.Evaluation.Workers != input.EvaluationWorkers {
+		t.Errorf("evaluation workers = %v; want %v", config.Evaluation.Workers, input.EvaluationWorkers)
+	}
+}
+
+func TestNewConfigFromCli_timeout(t *testing.T) {
+	input := &CliConfig{TimeoutSeconds: 3600, InputTimeoutSeconds: 60}
+	config := newConfigFromCli(input)
+	if config.TimeoutSeconds != input.TimeoutSeconds {
+		t.Errorf("timeout = %v; want %v", config.TimeoutSeconds, input.TimeoutSeconds)
+	}
+	if config.Inputs.TimeoutSeconds != input.InputTimeoutSeconds {
+		t.Errorf("inputs timeout = %v; want %v", config.Inputs.TimeoutSeconds, input.InputTimeoutSeconds)
+	}
+}
+
+func TestLoadFileOutputConfig(t *testing.T) {
+	pa := PolicyAutomationApp{}
+	for _, output := range []cfg.ConfigOutput{
+		{FileName: "report.json"},
+		{FileName: "report.csv"},
+		{FileName: "report.md"},
+	} {
+		if err := pa.loadFileOutputConfig(output); err != nil {
+			t.Fatalf("err = %v; want nil", err)
+		}
+	}
+	if len(pa.collectors) != 3 {
+		t.Fatalf("len(collectors) = %v; want %v", len(pa.collectors), 3)
+	}
+	if _, ok := pa.collectors[0].(*outputs.JSONResultCollector); !ok {
+		t.Errorf("collector[0] is not *outputs.JSONResultCollector")
+	}
+	if _, ok := pa.collectors[1].(*outputs.CSVResultCollector); !ok {
+		t.Errorf("collector[1] is not *outputs.CSVResultCollector")
+	}
+	if _, ok := pa.collectors[2].(*outputs.MarkdownResultCollector); !ok {
+		t.Errorf("collector[2] is not *outputs.MarkdownResultCollector")
+	}
+	if len(pa.clusterDumpCollectors) != 1 {
+		t.Errorf("len(clusterDumpCollectors) = %v; want %v", len(pa.clusterDumpCollectors), 1)
+	}
+}
+
+func TestNewConfigFromCli_terraformPlan(t *testing.T) {
+	input := &CliConfig{
+		TerraformPlanFile: "/path/to/plan.json",
+	}
+	config := newConfigFromCli(input)
+	if config.Inputs.TerraformPlan == nil {
+		t.Fatalf("terraformPlan input is nil")
+	}
+	if !config.Inputs.TerraformPlan.Enabled {
+		t.Errorf("terraformPlan input enabled = %v; want %v", config.Inputs.TerraformPlan.Enabled, true)
+	}
+	if config.Inputs.TerraformPlan.PlanFile != input.TerraformPlanFile {
+		t.Errorf("terraformPlan input file = %v; want %v", config.Inputs.TerraformPlan.PlanFile, input.TerraformPlanFile)
+	}
+	if len(config.Clusters) != 0 {
+		t.Errorf("len(clusters) = %v; want %v", len(config.Clusters), 0)
+	}
+}
+
 func TestAddDatetimePrefix(t *testing.T) {
 	testDate := time.Date(1994, 7, 20, 5, 20, 0, 0, time.UTC)
 	expectedResult := "19940720_0520_value"
@@ -0,0 +1,150 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"encoding/json"
+	"time"
+
+	bqc "github.com/google/gke-policy-automation/internal/bigquery"
+	cfg "github.com/google/gke-policy-automation/internal/config"
+	"github.com/google/gke-policy-automation/internal/history"
+	"github.com/google/gke-policy-automation/internal/log"
+	"github.com/google/gke-policy-automation/internal/outputs"
+)
+
+// HistoryTrends prints the compliance of the validation runs stored in the history store.
+func (p *PolicyAutomationApp) HistoryTrends(filter history.Filter) error {
+	trend, err := readHistory(p, func(store history.Store) ([]*history.RunSummary, error) {
+		return store.Trend(filter)
+	})
+	if err != nil {
+		return err
+	}
+	if p.config.JSONOutput {
+		return printHistoryJSON(trend)
+	}
+	p.out.InitTabs(0, 2)
+	p.out.TabPrintf("RUN TIME\tRUN ID\tVALID\tVIOLATED\tERRORED\tCOMPLIANCE\n")
+	for _, run := range trend {
+		p.out.TabPrintf("%s\t%s\t%d\t%d\t%d\t%.1f%%\n",
+			run.Time.Format(time.RFC3339), run.RunID, run.Valid, run.Violated, run.Errored, run.Compliance())
+	}
+	return p.out.TabFlush()
+}
+
+// HistoryViolations prints first seen and last seen time of the policy violations
+// stored in the history store.
+func (p *PolicyAutomationApp) HistoryViolations(filter history.Filter) error {
+	violations, err := readHistory(p, func(store history.Store) ([]*history.Violation, error) {
+		return store.Violations(filter)
+	})
+	if err != nil {
+		return err
+	}
+	if p.config.JSONOutput {
+		return printHistoryJSON(violations)
+	}
+	p.out.InitTabs(0, 2)
+	p.out.TabPrintf("CLUSTER\tPOLICY\tFIRST SEEN\tLAST SEEN\tREMEDIATED\n")
+	for _, violation := range violations {
+		remediated := "-"
+		if !violation.IsOpen() {
+			remediated = violation.RemediatedTime.Format(time.RFC3339)
+		}
+		p.out.TabPrintf("%s\t%s\t%s\t%s\t%s\n",
+			violation.ClusterID, violation.PolicyName,
+			violation.FirstSeen.Format(time.RFC3339), violation.LastSeen.Format(time.RFC3339), remediated)
+	}
+	return p.out.TabFlush()
+}
+
+// HistoryRemediation prints the mean time to remediate the violations of each policy.
+func (p *PolicyAutomationApp) HistoryRemediation(filter history.Filter) error {
+	violations, err := readHistory(p, func(store history.Store) ([]*history.Violation, error) {
+		return store.Violations(filter)
+	})
+	if err != nil {
+		return err
+	}
+	remediations := history.MeanTimeToRemediate(violations)
+	if p.config.JSONOutput {
+		return printHistoryJSON(remediations)
+	}
+	p.out.InitTabs(0, 2)
+	p.out.TabPrintf("POLICY\tREMEDIATED\tOPEN\tMEAN TIME TO REMEDIATE\n")
+	for _, remediation := range remediations {
+		mttr := "-"
+		if remediation.Remediated > 0 {
+			mttr = remediation.MeanTimeToRemediate.Round(time.Minute).String()
+		}
+		p.out.TabPrintf("%s\t%d\t%d\t%s\n", remediation.PolicyName, remediation.Remediated, remediation.Open, mttr)
+	}
+	return p.out.TabFlush()
+}
+
+// readHistory opens the history store and reads the results of a given read function,
+// i.e. the records or the trends aggregated by the store.
+func readHistory[T any](p *PolicyAutomationApp, read func(store history.Store) ([]T, error)) ([]T, error) {
+	store, err := p.newHistoryStore()
+	if err != nil {
+		p.out.ErrorPrint("could not open history store", err)
+		log.Errorf("could not open history store: %s", err)
+		return nil, err
+	}
+	defer store.Close()
+	results, err := read(store)
+	if err != nil {
+		p.out.ErrorPrint("could not read history", err)
+		log.Errorf("could not read history: %s", err)
+		return nil, err
+	}
+	log.Infof("Read %d results from history store", len(results))
+	return results, nil
+}
+
+func (p *PolicyAutomationApp) newHistoryStore() (history.Store, error) {
+	config := p.config.History
+	if config.SQLite.File != "" {
+		return history.NewSQLiteStore(config.SQLite.File)
+	}
+	var client bqc.BigQueryClient
+	var err error
+	if config.BigQuery.Endpoint != "" {
+		client, err = bqc.NewBigQueryClientWithEndpoint(p.outputsCtx, config.BigQuery.Project, config.BigQuery.Endpoint, p.getRetrier(cfg.APIBigQuery))
+	} else if p.config.CredentialsFile != "" {
+		client, err = bqc.NewBigQueryClientWithCredentialsFile(p.outputsCtx, config.BigQuery.Project, p.config.CredentialsFile, p.getRetrier(cfg.APIBigQuery))
+	} else {
+		client, err = bqc.NewBigQueryClient(p.outputsCtx, config.BigQuery.Project, p.getRetrier(cfg.APIBigQuery))
+	}
+	if err != nil {
+		return nil, err
+	}
+	store, err := history.NewBigQueryStore(client, config.BigQuery.Project, config.BigQuery.Dataset, config.BigQuery.Table)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+func printHistoryJSON(v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	outputs.NewStdOutOutput().Printf("%s\n", data)
+	return nil
+}
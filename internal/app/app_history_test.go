@@ -0,0 +1,91 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	cfg "github.com/google/gke-policy-automation/internal/config"
+	"github.com/google/gke-policy-automation/internal/history"
+	"github.com/google/gke-policy-automation/internal/outputs"
+)
+
+func TestReadHistory(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "history.db")
+	store, err := history.NewSQLiteStore(file)
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	records := []*history.Record{
+		{RunID: "run-one", Time: now.Add(-48 * time.Hour), ClusterID: "cluster-one", PolicyName: "policy-one", Status: history.StatusViolated},
+		{RunID: "run-two", Time: now, ClusterID: "cluster-one", PolicyName: "policy-one", Status: history.StatusValid},
+		{RunID: "run-two", Time: now, ClusterID: "cluster-two", PolicyName: "policy-one", Status: history.StatusViolated},
+	}
+	if err := store.Append(records); err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	store.Close()
+
+	pa := PolicyAutomationApp{
+		out:    outputs.NewSilentOutput(),
+		config: &cfg.Config{History: cfg.ConfigHistory{SQLite: cfg.HistorySQLite{File: file}}},
+	}
+	readRecords := func(filter history.Filter) ([]*history.Record, error) {
+		return readHistory(&pa, func(store history.Store) ([]*history.Record, error) {
+			return store.Records(filter)
+		})
+	}
+	result, err := readRecords(history.Filter{Since: now.Add(-24 * time.Hour)})
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	if len(result) != 2 {
+		t.Errorf("number of records = %v; want %v", len(result), 2)
+	}
+	result, err = readRecords(history.Filter{ClusterID: "cluster-one"})
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	if len(result) != 2 {
+		t.Errorf("number of records = %v; want %v", len(result), 2)
+	}
+	if err := pa.HistoryRemediation(history.Filter{}); err != nil {
+		t.Errorf("err = %v; want nil", err)
+	}
+}
+
+func TestNewHistoryFilter(t *testing.T) {
+	config := &CliConfig{
+		HistorySince:   time.Hour,
+		HistoryCluster: "cluster-one",
+		HistoryPolicy:  "policy-one",
+	}
+	filter := newHistoryFilter(config)
+	if filter.ClusterID != config.HistoryCluster {
+		t.Errorf("filter clusterID = %v; want %v", filter.ClusterID, config.HistoryCluster)
+	}
+	if filter.PolicyName != config.HistoryPolicy {
+		t.Errorf("filter policyName = %v; want %v", filter.PolicyName, config.HistoryPolicy)
+	}
+	if since := time.Since(filter.Since); since < time.Hour || since > 2*time.Hour {
+		t.Errorf("filter since = %v; want about an hour ago", filter.Since)
+	}
+	if filter := newHistoryFilter(&CliConfig{}); !filter.Since.IsZero() {
+		t.Errorf("filter since = %v; want zero", filter.Since)
+	}
+}
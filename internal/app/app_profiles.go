@@ -0,0 +1,176 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"regexp"
+	"slices"
+
+	cfg "github.com/google/gke-policy-automation/internal/config"
+	"github.com/google/gke-policy-automation/internal/gke"
+	"github.com/google/gke-policy-automation/internal/inputs"
+	"github.com/google/gke-policy-automation/internal/log"
+	"github.com/google/gke-policy-automation/internal/outputs"
+	"github.com/google/gke-policy-automation/internal/policy"
+)
+
+// policyProfile holds the policy agent of the configured policy profile along
+// with the profile cluster selector.
+type policyProfile struct {
+	name     string
+	selector cfg.ConfigClusterSelector
+	idRegexp *regexp.Regexp
+	agent    policy.PolicyAgent
+}
+
+// loadPolicyProfiles creates policy agents for the configured policy profiles. Profiles without
+// policy sources use given default policy files.
+func (p *PolicyAutomationApp) loadPolicyProfiles(defaultFiles []*policy.PolicyFile) ([]*policyProfile, error) {
+	profiles := make([]*policyProfile, 0, len(p.config.Profiles))
+	for _, profileConfig := range p.config.Profiles {
+		p.out.Printf("%s %s\n",
+			outputs.IconInfo,
+			consoleInfoColorF("Loading policy profile... [%s]", profileConfig.Name),
+		)
+		log.Infof("Loading policy profile %s", profileConfig.Name)
+		files := defaultFiles
+		if len(profileConfig.Policies) > 0 {
+			var err error
+			if files, err = p.loadPolicyFilesFromSources(profileConfig.Policies); err != nil {
+				return nil, err
+			}
+		}
+		exclusions := mergePolicyExclusions(p.config.PolicyExclusions, profileConfig.PolicyExclusions)
+		agent := policy.NewPolicyAgent(p.ctx)
+		if err := agent.WithFiles(files, exclusions); err != nil {
+			p.out.ErrorPrint("could not parse policy files", err)
+			log.Errorf("could not parse policy files of profile %s: %s", profileConfig.Name, err)
+			return nil, err
+		}
+		if err := agent.WithParameters(profileConfig.Parameters); err != nil {
+			p.out.ErrorPrint("could not set policy parameters", err)
+			log.Errorf("could not set policy parameters of profile %s: %s", profileConfig.Name, err)
+			return nil, err
+		}
+		profile := &policyProfile{
+			name:     profileConfig.Name,
+			selector: profileConfig.Selector,
+			agent:    agent,
+		}
+		if profileConfig.Selector.ID != "" {
+			var err error
+			if profile.idRegexp, err = regexp.Compile(profileConfig.Selector.ID); err != nil {
+				return nil, err
+			}
+		}
+		profiles = append(profiles, profile)
+	}
+	return profiles, nil
+}
+
+// selectPolicyProfile returns policy profile named in the cluster inventory or first policy profile
+// with a selector matching a given cluster. Returns nil if there is no such profile.
+func (p *PolicyAutomationApp) selectPolicyProfile(profiles []*policyProfile, cluster *inputs.Cluster) *policyProfile {
+	if inventoryCluster := p.getInventoryCluster(cluster.Name); inventoryCluster != nil && inventoryCluster.Profile != "" {
+		for _, profile := range profiles {
+			if profile.name == inventoryCluster.Profile {
+				return profile
+			}
+		}
+		log.Warnf("policy profile %s of cluster %s is not defined", inventoryCluster.Profile, cluster.Name)
+	}
+	for _, profile := range profiles {
+		if p.matchClusterSelector(profile, cluster) {
+			return profile
+		}
+	}
+	return nil
+}
+
+// matchClusterSelector checks if a given cluster matches all criteria of the profile selector.
+func (p *PolicyAutomationApp) matchClusterSelector(profile *policyProfile, cluster *inputs.Cluster) bool {
+	if profile.idRegexp != nil && !profile.idRegexp.MatchString(cluster.Name) {
+		return false
+	}
+	if len(profile.selector.Projects) > 0 {
+		project, _, _, err := gke.SliceAndValidateClusterID(cluster.Name)
+		if err != nil || !slices.Contains(profile.selector.Projects, project) {
+			return false
+		}
+	}
+	if len(profile.selector.Labels) > 0 {
+		labels := p.getClusterLabels(cluster)
+		for k, v := range profile.selector.Labels {
+			if value, ok := labels[k]; !ok || value != v {
+				return false
+			}
+		}
+	}
+	if len(profile.selector.Folders) > 0 {
+		resolver, ok := p.discovery.(gke.ClusterFoldersResolver)
+		if !ok {
+			log.Debugf("cluster folders are not known, skipping folder selector of profile %s", profile.name)
+			return false
+		}
+		folders := resolver.GetClusterFolders(cluster.Name)
+		if !slices.ContainsFunc(profile.selector.Folders, func(folder string) bool {
+			return slices.Contains(folders, folder)
+		}) {
+			return false
+		}
+	}
+	return true
+}
+
+// getClusterLabels returns labels of a given cluster, along with the labels
+// from the cluster inventory.
+func (p *PolicyAutomationApp) getClusterLabels(cluster *inputs.Cluster) map[string]string {
+	labels := make(map[string]string)
+	if cluster.Normalized != nil {
+		for k, v := range cluster.Normalized.Labels {
+			labels[k] = v
+		}
+	}
+	if inventoryCluster := p.getInventoryCluster(cluster.Name); inventoryCluster != nil {
+		for k, v := range inventoryCluster.Labels {
+			labels[k] = v
+		}
+	}
+	return labels
+}
+
+// getInventoryCluster returns cluster inventory entry of a given cluster or nil if
+// clusters were not read from the inventory.
+func (p *PolicyAutomationApp) getInventoryCluster(clusterID string) *gke.InventoryCluster {
+	resolver, ok := p.discovery.(gke.ClusterInventoryResolver)
+	if !ok {
+		return nil
+	}
+	inventoryCluster, ok := resolver.GetInventoryCluster(clusterID)
+	if !ok {
+		return nil
+	}
+	return inventoryCluster
+}
+
+// mergePolicyExclusions returns policy exclusions combined from the given ones.
+func mergePolicyExclusions(exclusions ...cfg.ConfigPolicyExclusions) cfg.ConfigPolicyExclusions {
+	result := cfg.ConfigPolicyExclusions{}
+	for _, e := range exclusions {
+		result.Policies = append(result.Policies, e.Policies...)
+		result.PolicyGroups = append(result.PolicyGroups, e.PolicyGroups...)
+	}
+	return result
+}
@@ -0,0 +1,137 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	cfg "github.com/google/gke-policy-automation/internal/config"
+	"github.com/google/gke-policy-automation/internal/gke"
+	"github.com/google/gke-policy-automation/internal/inputs"
+	"github.com/google/gke-policy-automation/internal/inputs/schema"
+)
+
+type foldersDiscoveryClientMock struct {
+	DiscoveryClientMock
+	folders map[string][]string
+}
+
+func (m foldersDiscoveryClientMock) GetClusterFolders(clusterID string) []string {
+	return m.folders[clusterID]
+}
+
+type inventoryDiscoveryClientMock struct {
+	DiscoveryClientMock
+	clusters map[string]*gke.InventoryCluster
+}
+
+func (m inventoryDiscoveryClientMock) GetInventoryCluster(clusterID string) (*gke.InventoryCluster, bool) {
+	cluster, ok := m.clusters[clusterID]
+	return cluster, ok
+}
+
+func TestSelectPolicyProfile(t *testing.T) {
+	prodCluster := &inputs.Cluster{
+		Name:       "projects/prod-project/locations/europe-west2/clusters/prod",
+		Normalized: &schema.Cluster{Labels: map[string]string{"env": "prod"}},
+	}
+	sandboxCluster := &inputs.Cluster{
+		Name: "projects/sandbox-project/locations/europe-west2/clusters/sandbox",
+	}
+	otherCluster := &inputs.Cluster{
+		Name: "projects/other-project/locations/europe-west2/clusters/other",
+	}
+	profiles := []*policyProfile{
+		{
+			name:     "production",
+			selector: cfg.ConfigClusterSelector{Labels: map[string]string{"env": "prod"}, Projects: []string{"prod-project"}},
+		},
+		{
+			name:     "sandbox",
+			selector: cfg.ConfigClusterSelector{Folders: []string{"123"}, ID: "/clusters/sandbox$"},
+			idRegexp: regexp.MustCompile("/clusters/sandbox$"),
+		},
+	}
+	pa := PolicyAutomationApp{
+		discovery: foldersDiscoveryClientMock{
+			folders: map[string][]string{sandboxCluster.Name: {"123", "456"}},
+		},
+	}
+	if profile := pa.selectPolicyProfile(profiles, prodCluster); profile == nil || profile.name != "production" {
+		t.Errorf("profile of cluster %s = %v; want %v", prodCluster.Name, profile, "production")
+	}
+	if profile := pa.selectPolicyProfile(profiles, sandboxCluster); profile == nil || profile.name != "sandbox" {
+		t.Errorf("profile of cluster %s = %v; want %v", sandboxCluster.Name, profile, "sandbox")
+	}
+	if profile := pa.selectPolicyProfile(profiles, otherCluster); profile != nil {
+		t.Errorf("profile of cluster %s = %v; want nil", otherCluster.Name, profile.name)
+	}
+}
+
+func TestSelectPolicyProfile_noFolders(t *testing.T) {
+	cluster := &inputs.Cluster{Name: "projects/project/locations/europe-west2/clusters/cluster"}
+	profiles := []*policyProfile{{name: "folder", selector: cfg.ConfigClusterSelector{Folders: []string{"123"}}}}
+	pa := PolicyAutomationApp{}
+	if profile := pa.selectPolicyProfile(profiles, cluster); profile != nil {
+		t.Errorf("profile = %v; want nil", profile.name)
+	}
+}
+
+func TestSelectPolicyProfile_inventory(t *testing.T) {
+	labeledCluster := &inputs.Cluster{Name: "projects/project/locations/europe-west2/clusters/labeled"}
+	namedCluster := &inputs.Cluster{
+		Name:       "projects/project/locations/europe-west2/clusters/named",
+		Normalized: &schema.Cluster{Labels: map[string]string{"env": "prod"}},
+	}
+	unknownCluster := &inputs.Cluster{Name: "projects/project/locations/europe-west2/clusters/unknown"}
+	profiles := []*policyProfile{
+		{name: "production", selector: cfg.ConfigClusterSelector{Labels: map[string]string{"env": "prod"}}},
+		{name: "sandbox", selector: cfg.ConfigClusterSelector{Labels: map[string]string{"env": "sandbox"}}},
+	}
+	pa := PolicyAutomationApp{
+		discovery: inventoryDiscoveryClientMock{
+			clusters: map[string]*gke.InventoryCluster{
+				labeledCluster.Name: {ID: labeledCluster.Name, Labels: map[string]string{"env": "sandbox"}},
+				namedCluster.Name:   {ID: namedCluster.Name, Profile: "sandbox"},
+				unknownCluster.Name: {ID: unknownCluster.Name, Profile: "unknown"},
+			},
+		},
+	}
+	if profile := pa.selectPolicyProfile(profiles, labeledCluster); profile == nil || profile.name != "sandbox" {
+		t.Errorf("profile of cluster %s = %v; want %v", labeledCluster.Name, profile, "sandbox")
+	}
+	if profile := pa.selectPolicyProfile(profiles, namedCluster); profile == nil || profile.name != "sandbox" {
+		t.Errorf("profile of cluster %s = %v; want %v", namedCluster.Name, profile, "sandbox")
+	}
+	if profile := pa.selectPolicyProfile(profiles, unknownCluster); profile != nil {
+		t.Errorf("profile of cluster %s = %v; want nil", unknownCluster.Name, profile.name)
+	}
+}
+
+func TestMergePolicyExclusions(t *testing.T) {
+	result := mergePolicyExclusions(
+		cfg.ConfigPolicyExclusions{Policies: []string{"gke.policy.one"}},
+		cfg.ConfigPolicyExclusions{Policies: []string{"gke.policy.two"}, PolicyGroups: []string{"Security"}},
+	)
+	expected := cfg.ConfigPolicyExclusions{
+		Policies:     []string{"gke.policy.one", "gke.policy.two"},
+		PolicyGroups: []string{"Security"},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("exclusions = %v; want %v", result, expected)
+	}
+}
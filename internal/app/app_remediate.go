@@ -0,0 +1,108 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/google/gke-policy-automation/internal/log"
+	"github.com/google/gke-policy-automation/internal/outputs"
+	"github.com/google/gke-policy-automation/internal/policy"
+	"github.com/google/gke-policy-automation/internal/remediation"
+)
+
+// Remediate evaluates best practices policies and produces remediation plan for
+// the violated ones. The plan is applied only when configured so.
+func (p *PolicyAutomationApp) Remediate() error {
+	log.Info("Cluster remediation starting")
+	planner, err := remediation.NewPlanner(p.config.Remediation.Format)
+	if err != nil {
+		p.out.ErrorPrint("could not create remediation planner", err)
+		log.Errorf("could not create remediation planner: %s", err)
+		return err
+	}
+	results := make([]*policy.PolicyEvaluationResult, 0)
+	evaluated, err := p.evaluatePolicies([]string{regoPackageBaseBestPractices}, func(result *policy.PolicyEvaluationResult) error {
+		results = append(results, result)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if !evaluated {
+		log.Info("Cluster remediation finished")
+		return nil
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].ClusterID < results[j].ClusterID
+	})
+	for _, result := range results {
+		plan, err := planner.Plan(result)
+		if err != nil {
+			p.out.ErrorPrint("could not create remediation plan", err)
+			log.Errorf("could not create remediation plan for cluster %s: %s", result.ClusterID, err)
+			return err
+		}
+		p.printRemediationPlan(plan)
+		if !p.config.Remediation.Apply {
+			continue
+		}
+		p.out.Printf("%s %s\n",
+			outputs.IconInfo,
+			consoleInfoColorF("Applying remediation plan... [%s]", plan.ClusterID),
+		)
+		if err := plan.Apply(remediation.RunCommand); err != nil {
+			p.out.ErrorPrint("failed to apply remediation plan", err)
+			log.Errorf("could not apply remediation plan for cluster %s: %s", plan.ClusterID, err)
+			return err
+		}
+	}
+	if !p.config.Remediation.Apply {
+		p.out.Printf("%s %s\n",
+			outputs.IconInfo,
+			consoleWarnColorF("Dry run: remediation plan was not applied"),
+		)
+	}
+	log.Info("Cluster remediation finished")
+	return nil
+}
+
+func (p *PolicyAutomationApp) printRemediationPlan(plan *remediation.Plan) {
+	titleF := color.New(color.Bold, color.FgHiWhite).Sprintf
+	commentF := color.New(color.FgCyan).Sprintf
+	p.out.Printf("\n%s %s\n", outputs.IconMagnifier, titleF("Remediation plan for %s", plan.ClusterID))
+	if len(plan.Steps) == 0 {
+		p.out.Printf("   %s\n", commentF("# no remediation steps"))
+	}
+	for _, step := range plan.Steps {
+		comment := "# " + step.PolicyName
+		if step.PolicyTitle != "" {
+			comment += ": " + step.PolicyTitle
+		}
+		if step.Resource != "" {
+			comment += " [" + step.Resource + "]"
+		}
+		p.out.Printf("   %s\n", commentF("%s", comment))
+		for _, line := range strings.Split(step.Change, "\n") {
+			p.out.Printf("   %s\n", line)
+		}
+	}
+	for _, name := range plan.Unmapped {
+		p.out.Printf("   %s\n", commentF("# %s: no automated remediation available", name))
+	}
+	log.Infof("Remediation plan for cluster %s has %d steps", plan.ClusterID, len(plan.Steps))
+}
@@ -17,6 +17,7 @@ package app
 import (
 	"errors"
 
+	cfg "github.com/google/gke-policy-automation/internal/config"
 	"github.com/google/gke-policy-automation/internal/log"
 	"github.com/google/gke-policy-automation/internal/outputs"
 	"github.com/google/gke-policy-automation/internal/outputs/scc"
@@ -26,7 +27,7 @@ func (p *PolicyAutomationApp) ConfigureSCC(orgNumber string) error {
 	if orgNumber == "" {
 		return errors.New("organization number is not set")
 	}
-	cli, err := scc.NewSecurityCommandCenterClient(p.ctx, orgNumber)
+	cli, err := scc.NewSecurityCommandCenterClient(p.ctx, orgNumber, p.getRetrier(cfg.APISecurityCenter))
 	if err != nil {
 		return err
 	}
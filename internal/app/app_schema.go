@@ -0,0 +1,92 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/google/gke-policy-automation/internal/inputs/schema"
+	"github.com/google/gke-policy-automation/internal/log"
+	"github.com/google/gke-policy-automation/internal/outputs"
+)
+
+// clusterDumpEntry is an entry of the cluster dump file with the normalized cluster data.
+type clusterDumpEntry struct {
+	Name    string          `json:"name"`
+	Cluster json.RawMessage `json:"cluster"`
+}
+
+func (p *PolicyAutomationApp) GenerateInputSchema() error {
+	fileName := ""
+	for _, out := range p.config.Outputs {
+		if out.FileName != "" {
+			fileName = out.FileName
+		}
+	}
+	if fileName == "" {
+		p.out.Printf("%s\n", schema.JSONSchema())
+		return nil
+	}
+	p.out.Printf("%s %s\n",
+		outputs.IconInfo,
+		consoleInfoColorF("Writing normalized cluster input schema ... [%s]", fileName),
+	)
+	log.Infof("Writing normalized cluster input schema to file %s", fileName)
+	if err := os.WriteFile(fileName, schema.JSONSchema(), 0644); err != nil {
+		p.out.ErrorPrint("could not write input schema file", err)
+		log.Errorf("could not write input schema file: %s", err)
+		return err
+	}
+	return nil
+}
+
+func (p *PolicyAutomationApp) ValidateClusterDump() error {
+	data, err := os.ReadFile(p.config.DumpFile)
+	if err != nil {
+		p.out.ErrorPrint("could not read cluster dump file", err)
+		log.Errorf("could not read cluster dump file: %s", err)
+		return err
+	}
+	p.out.Printf("%s %s\n",
+		outputs.IconInfo,
+		consoleInfoColorF("Validating cluster dump against input schema %s ... [%s]", schema.Version, p.config.DumpFile),
+	)
+	if err := validateClusterDump(data); err != nil {
+		p.out.ErrorPrint("cluster dump is not valid", err)
+		log.Errorf("cluster dump is not valid: %s", err)
+		return err
+	}
+	p.out.Printf("%s %s\n", outputs.IconInfo, consoleInfoColorF("Cluster dump validated correctly"))
+	log.Info("Cluster dump validated correctly")
+	return nil
+}
+
+func validateClusterDump(data []byte) error {
+	var entries []clusterDumpEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("could not parse cluster dump: %w", err)
+	}
+	for _, entry := range entries {
+		if len(entry.Cluster) == 0 {
+			return fmt.Errorf("cluster %s: normalized cluster data not found", entry.Name)
+		}
+		if err := schema.Validate(entry.Cluster); err != nil {
+			return fmt.Errorf("cluster %s: %w", entry.Name, err)
+		}
+	}
+	return nil
+}
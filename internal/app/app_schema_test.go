@@ -0,0 +1,52 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"encoding/json"
+	"testing"
+
+	"cloud.google.com/go/container/apiv1/containerpb"
+	"github.com/google/gke-policy-automation/internal/inputs/schema"
+)
+
+func TestValidateClusterDump(t *testing.T) {
+	clusterID := "projects/test/locations/europe-west2/clusters/cluster"
+	cluster := schema.NormalizeCluster(clusterID, &containerpb.Cluster{Name: "cluster", Location: "europe-west2"})
+	clusterData, err := json.Marshal(cluster)
+	if err != nil {
+		t.Fatalf("err is not nil; want nil; err = %s", err)
+	}
+	dump, err := json.Marshal([]clusterDumpEntry{{Name: clusterID, Cluster: clusterData}})
+	if err != nil {
+		t.Fatalf("err is not nil; want nil; err = %s", err)
+	}
+	if err := validateClusterDump(dump); err != nil {
+		t.Errorf("err is not nil; want nil; err = %s", err)
+	}
+}
+
+func TestValidateClusterDump_negative(t *testing.T) {
+	dumps := []string{
+		`{"name": "cluster"}`,
+		`[{"name": "cluster"}]`,
+		`[{"name": "cluster", "cluster": {"schemaVersion": "v0"}}]`,
+	}
+	for _, dump := range dumps {
+		if err := validateClusterDump([]byte(dump)); err == nil {
+			t.Errorf("err is nil for dump %s; want error", dump)
+		}
+	}
+}
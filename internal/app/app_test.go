@@ -15,11 +15,14 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	cfg "github.com/google/gke-policy-automation/internal/config"
 	"github.com/google/gke-policy-automation/internal/outputs"
+	"github.com/google/gke-policy-automation/internal/policy"
 )
 
 type DiscoveryClientMock struct {
@@ -65,6 +68,34 @@ func TestNewPolicyAutomationApp(t *testing.T) {
 	}
 }
 
+func TestNewPolicyAutomationAppWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pa := NewPolicyAutomationAppWithContext(ctx)
+	paApp, ok := pa.(*PolicyAutomationApp)
+	if !ok {
+		t.Fatalf("Result of NewPolicyAutomationAppWithContext is not *PolicyAutomationApp")
+	}
+	if paApp.ctx != ctx {
+		t.Errorf("policyAutomationApp ctx = %v; want %v", paApp.ctx, ctx)
+	}
+}
+
+func TestNewOutputsContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	outputsCtx, outputsCancel := newOutputsContext(ctx, 20*time.Millisecond)
+	defer outputsCancel()
+	cancel()
+	if err := outputsCtx.Err(); err != nil {
+		t.Fatalf("outputs context err = %v; want nil within grace period", err)
+	}
+	select {
+	case <-outputsCtx.Done():
+	case <-time.After(time.Second):
+		t.Fatalf("outputs context is not done after grace period")
+	}
+}
+
 func TestClusterReviewWithNoPolicies(t *testing.T) {
 	pa := PolicyAutomationApp{
 		out: outputs.NewSilentOutput(),
@@ -97,6 +128,21 @@ func TestPolicyAutomationAppClose_negative(t *testing.T) {
 	}
 }
 
+func TestCountViolatedPolicies(t *testing.T) {
+	result := &policy.PolicyEvaluationResult{
+		ClusterID: "cluster-one",
+		Policies: []*policy.Policy{
+			{Name: "valid", Valid: true},
+			{Name: "violated", Valid: false, Violations: []string{"violation"}},
+			{Name: "errored", Valid: false, ProcessingErrors: []error{fmt.Errorf("error")}},
+			{Name: "violated-two", Valid: false},
+		},
+	}
+	if count := countViolatedPolicies(result); count != 2 {
+		t.Errorf("countViolatedPolicies() = %v; want %v", count, 2)
+	}
+}
+
 type MockDocumentation struct {
 	content string
 }
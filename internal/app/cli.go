@@ -15,27 +15,50 @@
 package app
 
 import (
+	"time"
+
 	cfg "github.com/google/gke-policy-automation/internal/config"
+	"github.com/google/gke-policy-automation/internal/history"
 	cli "github.com/urfave/cli/v2"
 )
 
 type CliConfig struct {
-	ConfigFile          string
-	SilentMode          bool
-	JSONOutput          bool
-	CredentialsFile     string
-	DumpFile            string
-	ClusterName         string
-	ClusterLocation     string
-	ProjectName         string
-	GitRepository       string
-	GitBranch           string
-	GitDirectory        string
-	LocalDirectory      string
-	OutputFile          string
-	DocumentationOutput string
-	DiscoveryEnabled    bool
-	SccOrgNumber        string
+	ConfigFile               string
+	SilentMode               bool
+	JSONOutput               bool
+	CredentialsFile          string
+	DumpFile                 string
+	ClusterName              string
+	ClusterLocation          string
+	ProjectName              string
+	GitRepository            string
+	GitBranch                string
+	GitDirectory             string
+	LocalDirectory           string
+	OutputFile               string
+	OutputFormat             string
+	DocumentationOutput      string
+	DiscoveryEnabled         bool
+	DiscoverySource          string
+	InventoryFile            string
+	InventoryFormat          string
+	SccOrgNumber             string
+	TerraformPlanFile        string
+	ConfigConnectorDirectory string
+	RemediationFormat        string
+	RemediationApply         bool
+	HistoryFile              string
+	HistorySince             time.Duration
+	HistoryCluster           string
+	HistoryPolicy            string
+	ConsoleViolationsOnly    bool
+	ConsoleMinSeverity       string
+	ConsoleGroupBy           string
+	ConsoleSummary           bool
+	ConsoleNoColor           bool
+	EvaluationWorkers        int
+	TimeoutSeconds           int
+	InputTimeoutSeconds      int
 }
 
 func NewPolicyAutomationCli(p PolicyAutomation) *cli.App {
@@ -48,6 +71,8 @@ func NewPolicyAutomationCli(p PolicyAutomation) *cli.App {
 			createConfigureCommand(p),
 			createVersionCommand(p),
 			createGenerateCommand(p),
+			createRemediateCommand(p),
+			createHistoryCommand(p),
 		},
 	}
 	return app
@@ -94,6 +119,19 @@ func createCheckCommand(p PolicyAutomation) *cli.Command {
 					return p.CheckScalability()
 				},
 			},
+			{
+				Name:  "plan",
+				Usage: "Check GKE clusters from Terraform plan against best practices",
+				Flags: getTerraformPlanFlags(config),
+				Action: func(c *cli.Context) error {
+					defer p.Close()
+					if err := p.LoadCliConfig(config, cfg.SetPolicyConfigDefaults, cfg.ValidateTerraformPlanCheckConfig); err != nil {
+						cli.ShowSubcommandHelp(c)
+						return err
+					}
+					return p.CheckTerraformPlan()
+				},
+			},
 			{
 				Name:  "policies",
 				Usage: "Validates policy files from the defined source",
@@ -123,13 +161,26 @@ func createDumpCommand(p PolicyAutomation) *cli.Command {
 				Flags: getDumpFlags(config),
 				Action: func(c *cli.Context) error {
 					defer p.Close()
-					if err := p.LoadCliConfig(config, cfg.SetCheckConfigDefaults, cfg.ValidateClusterDumpConfig); err != nil {
+					if err := p.LoadCliConfig(config, cfg.SetClusterDumpConfigDefaults, cfg.ValidateClusterDumpConfig); err != nil {
 						cli.ShowSubcommandHelp(c)
 						return err
 					}
 					return p.ClusterJSONData()
 				},
 			},
+			{
+				Name:  "validate",
+				Usage: "Validate cluster dump against the normalized cluster input schema",
+				Flags: getDumpValidateFlags(config),
+				Action: func(c *cli.Context) error {
+					defer p.Close()
+					if err := p.LoadCliConfig(config, nil, cfg.ValidateClusterDumpFileConfig); err != nil {
+						cli.ShowSubcommandHelp(c)
+						return err
+					}
+					return p.ValidateClusterDump()
+				},
+			},
 		},
 	}
 }
@@ -183,10 +234,100 @@ func createGenerateCommand(p PolicyAutomation) *cli.Command {
 					return p.PolicyGenerateDocumentation()
 				},
 			},
+			{
+				Name:  "input-schema",
+				Usage: "Generate JSON Schema of the normalized cluster input",
+				Flags: getInputSchemaFlags(config),
+				Action: func(c *cli.Context) error {
+					defer p.Close()
+					if err := p.LoadCliConfig(config, nil, nil); err != nil {
+						cli.ShowSubcommandHelp(c)
+						return err
+					}
+					return p.GenerateInputSchema()
+				},
+			},
+		},
+	}
+}
+
+func createRemediateCommand(p PolicyAutomation) *cli.Command {
+	config := &CliConfig{}
+	return &cli.Command{
+		Name:  "remediate",
+		Usage: "Create remediation plan for GKE clusters violating best practices",
+		Flags: getRemediateFlags(config),
+		Action: func(c *cli.Context) error {
+			defer p.Close()
+			if err := p.LoadCliConfig(config, cfg.SetRemediationConfigDefaults, cfg.ValidateRemediationConfig); err != nil {
+				cli.ShowSubcommandHelp(c)
+				return err
+			}
+			return p.Remediate()
 		},
 	}
 }
 
+func createHistoryCommand(p PolicyAutomation) *cli.Command {
+	config := &CliConfig{}
+	return &cli.Command{
+		Name:  "history",
+		Usage: "Query historical validation results",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "trends",
+				Usage: "Show compliance of the validation runs over time",
+				Flags: getHistoryFlags(config),
+				Action: func(c *cli.Context) error {
+					defer p.Close()
+					if err := p.LoadCliConfig(config, nil, cfg.ValidateHistoryConfig); err != nil {
+						cli.ShowSubcommandHelp(c)
+						return err
+					}
+					return p.HistoryTrends(newHistoryFilter(config))
+				},
+			},
+			{
+				Name:  "violations",
+				Usage: "Show first seen and last seen time of the policy violations",
+				Flags: getHistoryFlags(config),
+				Action: func(c *cli.Context) error {
+					defer p.Close()
+					if err := p.LoadCliConfig(config, nil, cfg.ValidateHistoryConfig); err != nil {
+						cli.ShowSubcommandHelp(c)
+						return err
+					}
+					return p.HistoryViolations(newHistoryFilter(config))
+				},
+			},
+			{
+				Name:  "remediation",
+				Usage: "Show mean time to remediate the violations of each policy",
+				Flags: getHistoryFlags(config),
+				Action: func(c *cli.Context) error {
+					defer p.Close()
+					if err := p.LoadCliConfig(config, nil, cfg.ValidateHistoryConfig); err != nil {
+						cli.ShowSubcommandHelp(c)
+						return err
+					}
+					return p.HistoryRemediation(newHistoryFilter(config))
+				},
+			},
+		},
+	}
+}
+
+func newHistoryFilter(config *CliConfig) history.Filter {
+	filter := history.Filter{
+		ClusterID:  config.HistoryCluster,
+		PolicyName: config.HistoryPolicy,
+	}
+	if config.HistorySince > 0 {
+		filter.Since = time.Now().Add(-config.HistorySince)
+	}
+	return filter
+}
+
 func createVersionCommand(p PolicyAutomation) *cli.Command {
 	return &cli.Command{
 		Name:  "version",
@@ -231,12 +372,32 @@ func getClusterSourceFlags(config *CliConfig) []cli.Flag {
 			Usage:       "Enables cluster discovery on a given project",
 			Destination: &config.DiscoveryEnabled,
 		},
+		&cli.StringFlag{
+			Name:        "discovery-source",
+			Usage:       "Cluster discovery source: assetInventory (default), gkeAPI or fleet",
+			Destination: &config.DiscoverySource,
+		},
 		&cli.StringFlag{
 			Name:        "dump",
 			Aliases:     []string{"d"},
 			Usage:       "Path to the JSON file with cluster data dump for local checks",
 			Destination: &config.DumpFile,
 		},
+		&cli.StringFlag{
+			Name:        "inventory",
+			Usage:       "Path to the CSV or YAML cluster inventory file, use - to read from standard input",
+			Destination: &config.InventoryFile,
+		},
+		&cli.StringFlag{
+			Name:        "inventory-format",
+			Usage:       "Cluster inventory format: csv or yaml (default: detected from file extension, csv for standard input)",
+			Destination: &config.InventoryFormat,
+		},
+		&cli.StringFlag{
+			Name:        "krm-dir",
+			Usage:       "Path to the directory with Config Connector cluster manifests for local checks",
+			Destination: &config.ConfigConnectorDirectory,
+		},
 		&cli.StringFlag{
 			Name:        "project",
 			Aliases:     []string{"p"},
@@ -266,6 +427,36 @@ func getOutputFlags(config *CliConfig) []cli.Flag {
 			Usage:       "Path to the file for storing results",
 			Destination: &config.OutputFile,
 		},
+		&cli.StringFlag{
+			Name:        "out-format",
+			Usage:       "Format of the results file: json, csv or markdown (default: detected from file extension)",
+			Destination: &config.OutputFormat,
+		},
+		&cli.BoolFlag{
+			Name:        "violations-only",
+			Usage:       "Shows only violated and errored policies in the console output",
+			Destination: &config.ConsoleViolationsOnly,
+		},
+		&cli.StringFlag{
+			Name:        "min-severity",
+			Usage:       "Shows only policies with a given or higher severity in the console output: critical, high, medium or low",
+			Destination: &config.ConsoleMinSeverity,
+		},
+		&cli.StringFlag{
+			Name:        "group-by",
+			Usage:       "Groups the console output by policy or cluster",
+			Destination: &config.ConsoleGroupBy,
+		},
+		&cli.BoolFlag{
+			Name:        "summary",
+			Usage:       "Shows only the summary table in the console output",
+			Destination: &config.ConsoleSummary,
+		},
+		&cli.BoolFlag{
+			Name:        "no-color",
+			Usage:       "Disables colors in the console output, also honored via NO_COLOR environment variable",
+			Destination: &config.ConsoleNoColor,
+		},
 		&cli.BoolFlag{
 			Name:        "json",
 			Usage:       "Outputs results to standard console in JSON format",
@@ -306,17 +497,138 @@ func getPolicyDocumentationFlags(config *CliConfig) []cli.Flag {
 	return flags
 }
 
+func getEvaluationFlags(config *CliConfig) []cli.Flag {
+	return []cli.Flag{
+		&cli.IntFlag{
+			Name:        "eval-workers",
+			Usage:       "Number of clusters evaluated concurrently (default: number of CPUs)",
+			Destination: &config.EvaluationWorkers,
+		},
+	}
+}
+
+func getRunTimeoutFlag(config *CliConfig) cli.Flag {
+	return &cli.IntFlag{
+		Name:        "timeout",
+		Usage:       "Overall run timeout in seconds, results collected so far are written on timeout (default: no timeout)",
+		Destination: &config.TimeoutSeconds,
+	}
+}
+
+func getTimeoutFlags(config *CliConfig) []cli.Flag {
+	return []cli.Flag{
+		getRunTimeoutFlag(config),
+		&cli.IntFlag{
+			Name:        "input-timeout",
+			Usage:       "Timeout in seconds for fetching the data of a single cluster from a single input (default: no timeout)",
+			Destination: &config.InputTimeoutSeconds,
+		},
+	}
+}
+
 func getCheckFlags(config *CliConfig) []cli.Flag {
 	flags := getCommonFlags(config)
 	flags = append(flags, getClusterSourceFlags(config)...)
 	flags = append(flags, getPolicySourceFlags(config)...)
+	flags = append(flags, getEvaluationFlags(config)...)
+	flags = append(flags, getTimeoutFlags(config)...)
+	flags = append(flags, getOutputFlags(config)...)
+	return flags
+}
+
+func getTerraformPlanFlags(config *CliConfig) []cli.Flag {
+	flags := getCommonFlags(config)
+	flags = append(flags, getPolicySourceFlags(config)...)
+	flags = append(flags, getEvaluationFlags(config)...)
+	flags = append(flags, getTimeoutFlags(config)...)
 	flags = append(flags, getOutputFlags(config)...)
+	flags = append(flags, &cli.StringFlag{
+		Name:        "file",
+		Usage:       "Path to the Terraform plan in JSON format (terraform show -json)",
+		Destination: &config.TerraformPlanFile,
+	})
 	return flags
 }
 
 func getDumpFlags(config *CliConfig) []cli.Flag {
 	flags := getCommonFlags(config)
 	flags = append(flags, getClusterSourceFlags(config)...)
+	flags = append(flags, getRunTimeoutFlag(config))
 	flags = append(flags, getOutputFlags(config)...)
 	return flags
 }
+
+func getDumpValidateFlags(config *CliConfig) []cli.Flag {
+	flags := getCommonFlags(config)
+	flags = append(flags, &cli.StringFlag{
+		Name:        "dump",
+		Aliases:     []string{"d"},
+		Usage:       "Path to the cluster dump file",
+		Destination: &config.DumpFile,
+	})
+	return flags
+}
+
+func getInputSchemaFlags(config *CliConfig) []cli.Flag {
+	flags := getCommonFlags(config)
+	flags = append(flags, &cli.StringFlag{
+		Name:        "out-file",
+		Aliases:     []string{"f"},
+		Usage:       "Path to the file for storing the schema",
+		Destination: &config.OutputFile,
+	})
+	return flags
+}
+
+func getRemediateFlags(config *CliConfig) []cli.Flag {
+	flags := getCommonFlags(config)
+	flags = append(flags, getClusterSourceFlags(config)...)
+	flags = append(flags, getPolicySourceFlags(config)...)
+	flags = append(flags, getEvaluationFlags(config)...)
+	flags = append(flags, getTimeoutFlags(config)...)
+	flags = append(flags,
+		&cli.StringFlag{
+			Name:        "format",
+			Usage:       "Format of the remediation plan: gcloud or terraform",
+			Destination: &config.RemediationFormat,
+		},
+		&cli.BoolFlag{
+			Name:        "apply",
+			Usage:       "Applies the remediation plan instead of a dry run (gcloud format only)",
+			Destination: &config.RemediationApply,
+		},
+	)
+	return flags
+}
+
+func getHistoryFlags(config *CliConfig) []cli.Flag {
+	flags := getCommonFlags(config)
+	flags = append(flags,
+		&cli.StringFlag{
+			Name:        "sqlite",
+			Usage:       "Path to the SQLite history store file",
+			Destination: &config.HistoryFile,
+		},
+		&cli.DurationFlag{
+			Name:        "since",
+			Usage:       "Includes only results stored within a given duration, i.e. 720h",
+			Destination: &config.HistorySince,
+		},
+		&cli.StringFlag{
+			Name:        "cluster",
+			Usage:       "Includes only results of a cluster with a given identifier",
+			Destination: &config.HistoryCluster,
+		},
+		&cli.StringFlag{
+			Name:        "policy",
+			Usage:       "Includes only results of a policy with a given name",
+			Destination: &config.HistoryPolicy,
+		},
+		&cli.BoolFlag{
+			Name:        "json",
+			Usage:       "Outputs results to standard console in JSON format",
+			Destination: &config.JSONOutput,
+		},
+	)
+	return flags
+}
@@ -23,19 +23,19 @@ import (
 func TestNewPolicyAutomationCli(t *testing.T) {
 	app := NewPolicyAutomationApp()
 	cmd := NewPolicyAutomationCli(app)
-	validateCommandsExist(t, cmd.Commands, []string{"check", "dump", "configure", "generate", "version"})
+	validateCommandsExist(t, cmd.Commands, []string{"check", "dump", "configure", "generate", "version", "remediate", "history"})
 }
 
 func TestCheckCommand(t *testing.T) {
 	app := NewPolicyAutomationApp()
 	cmd := createCheckCommand(app)
-	validateCommandsExist(t, cmd.Subcommands, []string{"best-practices", "scalability", "policies"})
+	validateCommandsExist(t, cmd.Subcommands, []string{"best-practices", "scalability", "plan", "policies"})
 }
 
 func TestDumpCommand(t *testing.T) {
 	app := NewPolicyAutomationApp()
 	cmd := createDumpCommand(app)
-	validateCommandsExist(t, cmd.Subcommands, []string{"cluster"})
+	validateCommandsExist(t, cmd.Subcommands, []string{"cluster", "validate"})
 }
 
 func TestConfigureCommand(t *testing.T) {
@@ -47,7 +47,13 @@ func TestConfigureCommand(t *testing.T) {
 func TestCreatePolicyGenerateDocsCommand(t *testing.T) {
 	app := NewPolicyAutomationApp()
 	cmd := createGenerateCommand(app)
-	validateCommandsExist(t, cmd.Subcommands, []string{"policy-docs"})
+	validateCommandsExist(t, cmd.Subcommands, []string{"policy-docs", "input-schema"})
+}
+
+func TestHistoryCommand(t *testing.T) {
+	app := NewPolicyAutomationApp()
+	cmd := createHistoryCommand(app)
+	validateCommandsExist(t, cmd.Subcommands, []string{"trends", "violations", "remediation"})
 }
 
 func validateCommandsExist(t *testing.T, commands []*cli.Command, expected []string) {
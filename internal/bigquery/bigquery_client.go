@@ -0,0 +1,296 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bigquery implements Google BigQuery client
+package bigquery
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/gke-policy-automation/internal/log"
+	"github.com/google/gke-policy-automation/internal/retry"
+	"github.com/google/gke-policy-automation/internal/version"
+	"github.com/google/uuid"
+	bq "google.golang.org/api/bigquery/v2"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+)
+
+const (
+	maxInsertRows  = 500
+	queryTimeoutMs = 60000
+
+	newTableRetryDelay = 5 * time.Second
+	newTableTimeout    = 2 * time.Minute
+)
+
+// BigQueryClient creates BigQuery tables, streams rows into them and runs the queries.
+type BigQueryClient interface {
+	EnsureTable(dataset, table string, schema *bq.TableSchema, partitionField string) error
+	InsertRows(dataset, table string, rows []*Row) error
+	Query(query string, params []*bq.QueryParameter) ([]map[string]interface{}, error)
+	Close() error
+}
+
+// Row is a table row with the insert ID, that is used by BigQuery to deduplicate rows
+// inserted more than once, i.e. on retries. Rows without the insert ID get a random one.
+type Row struct {
+	InsertID string
+	Values   map[string]bq.JsonValue
+}
+
+type bigQueryClient struct {
+	ctx                context.Context
+	svc                *bq.Service
+	retrier            *retry.Retrier
+	project            string
+	createdTables      map[string]bool
+	newTableRetryDelay time.Duration
+	newTableTimeout    time.Duration
+}
+
+// NewBigQueryClient returns BigQuery client for a given project, that is used
+// for running the queries and as a default table project.
+func NewBigQueryClient(ctx context.Context, project string, retrier *retry.Retrier) (BigQueryClient, error) {
+	return newBigQueryClient(ctx, project, retrier)
+}
+
+func NewBigQueryClientWithCredentialsFile(ctx context.Context, project string, credentialsFile string, retrier *retry.Retrier) (BigQueryClient, error) {
+	return newBigQueryClient(ctx, project, retrier, option.WithCredentialsFile(credentialsFile))
+}
+
+// NewBigQueryClientWithEndpoint returns BigQuery client for a given API endpoint without
+// authentication, i.e. for a BigQuery emulator.
+func NewBigQueryClientWithEndpoint(ctx context.Context, project string, endpoint string, retrier *retry.Retrier) (BigQueryClient, error) {
+	return newBigQueryClient(ctx, project, retrier, option.WithEndpoint(endpoint), option.WithoutAuthentication())
+}
+
+func newBigQueryClient(ctx context.Context, project string, retrier *retry.Retrier, opts ...option.ClientOption) (*bigQueryClient, error) {
+	opts = append(opts, option.WithUserAgent(version.UserAgent))
+	svc, err := bq.NewService(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &bigQueryClient{
+		ctx:                ctx,
+		svc:                svc,
+		retrier:            retrier,
+		project:            project,
+		createdTables:      make(map[string]bool),
+		newTableRetryDelay: newTableRetryDelay,
+		newTableTimeout:    newTableTimeout,
+	}, nil
+}
+
+// EnsureTable creates a table with a given schema, partitioned by days of a given timestamp
+// field, if the table does not exist. The rows inserted shortly after the table creation
+// are retried, as the new table may not be available for the streaming inserts yet.
+func (c *bigQueryClient) EnsureTable(dataset, table string, schema *bq.TableSchema, partitionField string) error {
+	err := c.retrier.Do(c.ctx, "tables.get", func(ctx context.Context) error {
+		_, err := c.svc.Tables.Get(c.project, dataset, table).Context(ctx).Do()
+		return err
+	})
+	if err == nil {
+		return nil
+	}
+	if !isNotFound(err) {
+		return err
+	}
+	err = c.retrier.Do(c.ctx, "tables.insert", func(ctx context.Context) error {
+		_, err := c.svc.Tables.Insert(c.project, dataset, &bq.Table{
+			TableReference: &bq.TableReference{
+				ProjectId: c.project,
+				DatasetId: dataset,
+				TableId:   table,
+			},
+			Schema: schema,
+			TimePartitioning: &bq.TimePartitioning{
+				Type:  "DAY",
+				Field: partitionField,
+			},
+		}).Context(ctx).Do()
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	c.createdTables[dataset+"."+table] = true
+	return nil
+}
+
+// InsertRows streams given rows into a table.
+func (c *bigQueryClient) InsertRows(dataset, table string, rows []*Row) error {
+	for start := 0; start < len(rows); start += maxInsertRows {
+		end := min(start+maxInsertRows, len(rows))
+		request := &bq.TableDataInsertAllRequest{
+			Rows: make([]*bq.TableDataInsertAllRequestRows, 0, end-start),
+		}
+		for _, row := range rows[start:end] {
+			insertID := row.InsertID
+			if insertID == "" {
+				insertID = uuid.NewString()
+			}
+			request.Rows = append(request.Rows, &bq.TableDataInsertAllRequestRows{InsertId: insertID, Json: row.Values})
+		}
+		resp, err := c.insertAll(dataset, table, request)
+		if err != nil {
+			return err
+		}
+		if len(resp.InsertErrors) > 0 && len(resp.InsertErrors[0].Errors) > 0 {
+			return fmt.Errorf("failed to insert %d rows: %s", len(resp.InsertErrors), resp.InsertErrors[0].Errors[0].Message)
+		}
+	}
+	return nil
+}
+
+// insertAll streams the rows of a given request with retries. The requests to the tables
+// created by the client are also retried when the table is not found, until the new table
+// becomes available for the streaming inserts.
+func (c *bigQueryClient) insertAll(dataset, table string, request *bq.TableDataInsertAllRequest) (*bq.TableDataInsertAllResponse, error) {
+	deadline := time.Now().Add(c.newTableTimeout)
+	for {
+		var resp *bq.TableDataInsertAllResponse
+		err := c.retrier.Do(c.ctx, "tabledata.insertAll", func(ctx context.Context) error {
+			var err error
+			resp, err = c.svc.Tabledata.InsertAll(c.project, dataset, table, request).Context(ctx).Do()
+			return err
+		})
+		if !c.createdTables[dataset+"."+table] || !isNotFound(err) || time.Now().After(deadline) {
+			return resp, err
+		}
+		log.Debugf("new table %s.%s is not available for inserts yet, retrying in %s", dataset, table, c.newTableRetryDelay)
+		timer := time.NewTimer(c.newTableRetryDelay)
+		select {
+		case <-timer.C:
+		case <-c.ctx.Done():
+			timer.Stop()
+			return nil, err
+		}
+	}
+}
+
+// Query runs a given standard SQL query and returns the result rows as maps of
+// column names to values.
+func (c *bigQueryClient) Query(query string, params []*bq.QueryParameter) ([]map[string]interface{}, error) {
+	useLegacySQL := false
+	var resp *bq.QueryResponse
+	err := c.retrier.Do(c.ctx, "jobs.query", func(ctx context.Context) error {
+		var err error
+		resp, err = c.svc.Jobs.Query(c.project, &bq.QueryRequest{
+			Query:           query,
+			UseLegacySql:    &useLegacySQL,
+			ParameterMode:   "NAMED",
+			QueryParameters: params,
+			TimeoutMs:       queryTimeoutMs,
+		}).Context(ctx).Do()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Errors) > 0 {
+		return nil, fmt.Errorf("query failed: %s", resp.Errors[0].Message)
+	}
+	schema, rows, pageToken := resp.Schema, resp.Rows, resp.PageToken
+	results := make([]map[string]interface{}, 0, len(rows))
+	complete := resp.JobComplete
+	for {
+		if complete {
+			results = append(results, mapRows(schema, rows)...)
+			if pageToken == "" {
+				return results, nil
+			}
+		}
+		if resp.JobReference == nil {
+			return nil, fmt.Errorf("query job reference is not set")
+		}
+		call := c.svc.Jobs.GetQueryResults(c.project, resp.JobReference.JobId).
+			Location(resp.JobReference.Location).
+			TimeoutMs(queryTimeoutMs)
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+		var page *bq.GetQueryResultsResponse
+		err := c.retrier.Do(c.ctx, "jobs.getQueryResults", func(ctx context.Context) error {
+			var err error
+			page, err = call.Context(ctx).Do()
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(page.Errors) > 0 {
+			return nil, fmt.Errorf("query failed: %s", page.Errors[0].Message)
+		}
+		schema, rows, pageToken, complete = page.Schema, page.Rows, page.PageToken, page.JobComplete
+	}
+}
+
+func (c *bigQueryClient) Close() error {
+	return nil
+}
+
+// InsertID returns the insert ID of a row identified by given values, i.e. the run, cluster
+// and policy of a validation result, so the same row has the same insert ID on every insert.
+func InsertID(values ...string) string {
+	hash := sha256.Sum256([]byte(strings.Join(values, "\x00")))
+	return hex.EncodeToString(hash[:])
+}
+
+func isNotFound(err error) bool {
+	var apiErr *googleapi.Error
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound
+}
+
+// StringParameter returns named query parameter of a string type.
+func StringParameter(name, value string) *bq.QueryParameter {
+	return &bq.QueryParameter{
+		Name:           name,
+		ParameterType:  &bq.QueryParameterType{Type: "STRING"},
+		ParameterValue: &bq.QueryParameterValue{Value: value},
+	}
+}
+
+// Int64Parameter returns named query parameter of an integer type.
+func Int64Parameter(name string, value int64) *bq.QueryParameter {
+	return &bq.QueryParameter{
+		Name:           name,
+		ParameterType:  &bq.QueryParameterType{Type: "INT64"},
+		ParameterValue: &bq.QueryParameterValue{Value: fmt.Sprintf("%d", value)},
+	}
+}
+
+func mapRows(schema *bq.TableSchema, rows []*bq.TableRow) []map[string]interface{} {
+	results := make([]map[string]interface{}, 0, len(rows))
+	if schema == nil {
+		return results
+	}
+	for _, row := range rows {
+		result := make(map[string]interface{}, len(schema.Fields))
+		for i, field := range schema.Fields {
+			if i < len(row.F) {
+				result[field.Name] = row.F[i].V
+			}
+		}
+		results = append(results, result)
+	}
+	return results
+}
@@ -0,0 +1,186 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	bq "google.golang.org/api/bigquery/v2"
+	"google.golang.org/api/option"
+)
+
+// newBigQueryEmulator returns local HTTP server emulating BigQuery API endpoints used by the client.
+// The inserts to the tables with unavailable count fail with not found error given number of times.
+func newBigQueryEmulator(t *testing.T, tables map[string]bool, unavailable map[string]int, inserted *[]*bq.TableDataInsertAllRequestRows) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /projects/project/datasets/dataset/tables/{table}", func(w http.ResponseWriter, r *http.Request) {
+		if !tables[r.PathValue("table")] {
+			http.Error(w, `{"error": {"code": 404, "message": "not found"}}`, http.StatusNotFound)
+			return
+		}
+		writeJSON(t, w, &bq.Table{})
+	})
+	mux.HandleFunc("POST /projects/project/datasets/dataset/tables", func(w http.ResponseWriter, r *http.Request) {
+		var table bq.Table
+		if err := json.NewDecoder(r.Body).Decode(&table); err != nil {
+			t.Errorf("could not decode table: %s", err)
+		}
+		if table.TimePartitioning == nil || table.TimePartitioning.Field != "time" {
+			t.Errorf("table time partitioning = %v; want partitioning by time", table.TimePartitioning)
+		}
+		tables[table.TableReference.TableId] = true
+		writeJSON(t, w, &table)
+	})
+	mux.HandleFunc("POST /projects/project/datasets/dataset/tables/{table}/insertAll", func(w http.ResponseWriter, r *http.Request) {
+		if unavailable[r.PathValue("table")] > 0 {
+			unavailable[r.PathValue("table")]--
+			http.Error(w, `{"error": {"code": 404, "message": "not found"}}`, http.StatusNotFound)
+			return
+		}
+		var request bq.TableDataInsertAllRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("could not decode insert request: %s", err)
+		}
+		*inserted = append(*inserted, request.Rows...)
+		writeJSON(t, w, &bq.TableDataInsertAllResponse{})
+	})
+	schema := &bq.TableSchema{Fields: []*bq.TableFieldSchema{{Name: "name"}, {Name: "count"}}}
+	mux.HandleFunc("POST /projects/project/queries", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, &bq.QueryResponse{
+			JobComplete:  true,
+			JobReference: &bq.JobReference{JobId: "job"},
+			Schema:       schema,
+			Rows:         []*bq.TableRow{{F: []*bq.TableCell{{V: "one"}, {V: "1"}}}},
+			PageToken:    "next",
+		})
+	})
+	mux.HandleFunc("GET /projects/project/queries/job", func(w http.ResponseWriter, r *http.Request) {
+		if token := r.URL.Query().Get("pageToken"); token != "next" {
+			t.Errorf("page token = %v; want %v", token, "next")
+		}
+		writeJSON(t, w, &bq.GetQueryResultsResponse{
+			JobComplete: true,
+			Schema:      schema,
+			Rows:        []*bq.TableRow{{F: []*bq.TableCell{{V: "two"}, {V: nil}}}},
+		})
+	})
+	return httptest.NewServer(mux)
+}
+
+func writeJSON(t *testing.T, w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		t.Errorf("could not encode response: %s", err)
+	}
+}
+
+func TestBigQueryClient(t *testing.T) {
+	tables := map[string]bool{"existing": true}
+	var inserted []*bq.TableDataInsertAllRequestRows
+	server := newBigQueryEmulator(t, tables, nil, &inserted)
+	defer server.Close()
+
+	client, err := NewBigQueryClientWithEndpoint(context.Background(), "project", server.URL+"/", nil)
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	defer client.Close()
+	schema := &bq.TableSchema{Fields: []*bq.TableFieldSchema{{Name: "time", Type: "TIMESTAMP"}}}
+	if err := client.EnsureTable("dataset", "existing", schema, "time"); err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	if err := client.EnsureTable("dataset", "results", schema, "time"); err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	if !tables["results"] {
+		t.Errorf("table results was not created")
+	}
+
+	rows := make([]*Row, maxInsertRows+1)
+	for i := range rows {
+		rows[i] = &Row{Values: map[string]bq.JsonValue{"time": "2024-01-01T00:00:00Z"}}
+	}
+	rows[0].InsertID = "first"
+	if err := client.InsertRows("dataset", "results", rows); err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	if len(inserted) != len(rows) {
+		t.Fatalf("number of inserted rows = %v; want %v", len(inserted), len(rows))
+	}
+	if inserted[0].InsertId != "first" {
+		t.Errorf("insert ID = %v; want %v", inserted[0].InsertId, "first")
+	}
+	if inserted[1].InsertId == "" || inserted[1].InsertId == inserted[2].InsertId {
+		t.Errorf("insert IDs = %v, %v; want unique IDs", inserted[1].InsertId, inserted[2].InsertId)
+	}
+
+	results, err := client.Query("SELECT name, count FROM results", []*bq.QueryParameter{StringParameter("name", "one")})
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	expected := []map[string]interface{}{
+		{"name": "one", "count": "1"},
+		{"name": "two", "count": nil},
+	}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("results = %v; want %v", results, expected)
+	}
+}
+
+func TestBigQueryClientInsertRows_newTable(t *testing.T) {
+	tables := map[string]bool{"existing": true}
+	unavailable := map[string]int{"existing": 1, "results": 2}
+	var inserted []*bq.TableDataInsertAllRequestRows
+	server := newBigQueryEmulator(t, tables, unavailable, &inserted)
+	defer server.Close()
+
+	client, err := newBigQueryClient(context.Background(), "project", nil, option.WithEndpoint(server.URL+"/"), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	client.newTableRetryDelay = time.Millisecond
+	schema := &bq.TableSchema{Fields: []*bq.TableFieldSchema{{Name: "time", Type: "TIMESTAMP"}}}
+	if err := client.EnsureTable("dataset", "results", schema, "time"); err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	rows := []*Row{{Values: map[string]bq.JsonValue{"time": "2024-01-01T00:00:00Z"}}}
+	if err := client.InsertRows("dataset", "results", rows); err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	if len(inserted) != 1 {
+		t.Errorf("number of inserted rows = %v; want %v", len(inserted), 1)
+	}
+	if err := client.InsertRows("dataset", "existing", rows); err == nil {
+		t.Errorf("err = nil; want not found error for table not created by the client")
+	}
+}
+
+func TestInsertID(t *testing.T) {
+	if InsertID("run", "cluster", "policy") != InsertID("run", "cluster", "policy") {
+		t.Errorf("insert IDs of same values are not equal")
+	}
+	if InsertID("run", "cluster", "policy") == InsertID("run", "cluster", "other") {
+		t.Errorf("insert IDs of different values are equal")
+	}
+	if id := InsertID("run", "projects/p/locations/l/clusters/c", "gke.policy.name"); len(id) > 128 {
+		t.Errorf("insert ID length = %v; want at most %v", len(id), 128)
+	}
+}
@@ -19,14 +19,27 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
 
+	"github.com/google/gke-policy-automation/internal/gke"
 	"github.com/google/gke-policy-automation/internal/log"
 	"gopkg.in/yaml.v3"
 )
 
 var (
 	DefaultK8SApiVersions = []string{"v1", "autoscaling/v1"}
+
+	// APIs are the names of the GCP APIs with the retry and rate limit settings.
+	APIs = []string{APIContainer, APICloudAsset, APISecurityCenter, APIPubSub, APIStorage, APILogging, APIResourceManager, APIBigQuery, APIGKEHub}
+
+	releaseChannels = []string{"RAPID", "REGULAR", "STABLE", "EXTENDED", "UNSPECIFIED"}
+	severities      = []string{"critical", "high", "medium", "low"}
+
+	// cloudLoggingLogIDRegexp matches the allowed characters and length of Cloud Logging log IDs.
+	cloudLoggingLogIDRegexp = regexp.MustCompile(`^[A-Za-z0-9/_.-]{1,512}$`)
 )
 
 const (
@@ -34,8 +47,48 @@ const (
 	DefaultGitBranch     = "main"
 	DefaultGitPolicyDir  = "gke-policies-v2"
 	DefaultK8SClientQPS  = 50
+	MaxDiscoveryPageSize = 500
+
+	RemediationFormatGcloud    = "gcloud"
+	RemediationFormatTerraform = "terraform"
+
+	DiscoverySourceAssetInventory = "assetInventory"
+	DiscoverySourceGKEAPI         = "gkeAPI"
+	DiscoverySourceFleet          = "fleet"
+
+	IssueTrackerGitHub = "github"
+	IssueTrackerJira   = "jira"
+
+	DefaultGitHubTokenEnv = "GITHUB_TOKEN"
+	DefaultJiraTokenEnv   = "JIRA_API_TOKEN"
+
+	ConsoleGroupByPolicy  = "policy"
+	ConsoleGroupByCluster = "cluster"
+
+	OutputFormatJSON     = "json"
+	OutputFormatCSV      = "csv"
+	OutputFormatMarkdown = "markdown"
+
+	PubSubModeReport    = "report"
+	PubSubModeCluster   = "cluster"
+	PubSubModeViolation = "violation"
+
+	APIContainer       = "container"
+	APICloudAsset      = "cloudasset"
+	APISecurityCenter  = "securitycenter"
+	APIPubSub          = "pubsub"
+	APIStorage         = "storage"
+	APILogging         = "logging"
+	APIResourceManager = "cloudresourcemanager"
+	APIBigQuery        = "bigquery"
+	APIGKEHub          = "gkehub"
 )
 
+type ConfigRemediation struct {
+	Format string `yaml:"format"`
+	Apply  bool   `yaml:"apply"`
+}
+
 type ReadFileFn func(string) ([]byte, error)
 
 type Config struct {
@@ -48,9 +101,58 @@ type Config struct {
 	Inputs           ConfigInput            `yaml:"inputs"`
 	Outputs          []ConfigOutput         `yaml:"outputs"`
 	ClusterDiscovery ClusterDiscovery       `yaml:"clusterDiscovery"`
+	ClusterInventory ClusterInventory       `yaml:"clusterInventory"`
 	PolicyExclusions ConfigPolicyExclusions `yaml:"policyExclusions"`
 	Metrics          []ConfigMetric         `yaml:"metrics"`
 	K8SApiConfig     K8SApiConfig           `yaml:"kubernetesAPIClient"`
+	Remediation      ConfigRemediation      `yaml:"remediation"`
+	Profiles         []ConfigPolicyProfile  `yaml:"profiles"`
+	History          ConfigHistory          `yaml:"history"`
+	Console          ConfigConsole          `yaml:"console"`
+	Evaluation       ConfigEvaluation       `yaml:"evaluation"`
+	TimeoutSeconds   int                    `yaml:"timeoutSeconds"`
+	APIClients       ConfigAPIClients       `yaml:"apiClients"`
+}
+
+// ConfigAPIClients defines the retries on quota and availability errors and the client-side
+// rate limits of the GCP API calls. The QPS limits are set per API name.
+type ConfigAPIClients struct {
+	MaxRetries           int                `yaml:"maxRetries"`
+	InitialBackoffMillis int                `yaml:"initialBackoffMillis"`
+	MaxBackoffMillis     int                `yaml:"maxBackoffMillis"`
+	QPS                  map[string]float64 `yaml:"qps"`
+}
+
+// ConfigEvaluation defines the policy evaluation settings. The number of workers evaluating
+// the clusters concurrently defaults to the number of CPUs.
+type ConfigEvaluation struct {
+	Workers int `yaml:"workers"`
+}
+
+// ConfigConsole defines filtering and layout of the validation results in the console output.
+type ConfigConsole struct {
+	ViolationsOnly bool   `yaml:"violationsOnly"`
+	MinSeverity    string `yaml:"minSeverity"`
+	GroupBy        string `yaml:"groupBy"`
+	Summary        bool   `yaml:"summary"`
+	NoColor        bool   `yaml:"noColor"`
+}
+
+// ConfigPolicyProfile binds policy sources, exclusions and parameters to the clusters
+// matching the profile selector.
+type ConfigPolicyProfile struct {
+	Name             string                 `yaml:"name"`
+	Selector         ConfigClusterSelector  `yaml:"selector"`
+	Policies         []ConfigPolicy         `yaml:"policies"`
+	PolicyExclusions ConfigPolicyExclusions `yaml:"policyExclusions"`
+	Parameters       map[string]interface{} `yaml:"parameters"`
+}
+
+type ConfigClusterSelector struct {
+	Labels   map[string]string `yaml:"labels"`
+	Folders  []string          `yaml:"folders"`
+	Projects []string          `yaml:"projects"`
+	ID       string            `yaml:"id"`
 }
 
 type ConfigPolicy struct {
@@ -68,11 +170,16 @@ type ConfigCluster struct {
 }
 
 type ConfigInput struct {
-	GKEApi        *GKEApiInput     `yaml:"gkeAPI"`
-	GKELocalInput *GKELocalInput   `yaml:"gkeLocal"`
-	K8sAPI        *K8SAPIInput     `yaml:"k8sAPI"`
-	MetricsAPI    *MetricsAPIInput `yaml:"metricsAPI"`
-	Rest          *RestInput       `yaml:"rest"`
+	GKEApi          *GKEApiInput          `yaml:"gkeAPI"`
+	GKELocalInput   *GKELocalInput        `yaml:"gkeLocal"`
+	K8sAPI          *K8SAPIInput          `yaml:"k8sAPI"`
+	MetricsAPI      *MetricsAPIInput      `yaml:"metricsAPI"`
+	Rest            *RestInput            `yaml:"rest"`
+	TerraformPlan   *TerraformPlanInput   `yaml:"terraformPlan"`
+	ConfigConnector *ConfigConnectorInput `yaml:"configConnector"`
+	Fleet           *FleetInput           `yaml:"fleet"`
+	Local           *LocalInput           `yaml:"local"`
+	TimeoutSeconds  int                   `yaml:"timeoutSeconds"`
 }
 
 type GKEApiInput struct {
@@ -84,6 +191,27 @@ type GKELocalInput struct {
 	DumpFile string `yaml:"file"`
 }
 
+type LocalInput struct {
+	Enabled     bool     `yaml:"enabled"`
+	DumpFile    string   `yaml:"file"`
+	DataSources []string `yaml:"dataSources"`
+}
+
+type FleetInput struct {
+	Enabled  bool     `yaml:"enabled"`
+	Projects []string `yaml:"projects"`
+}
+
+type TerraformPlanInput struct {
+	Enabled  bool   `yaml:"enabled"`
+	PlanFile string `yaml:"file"`
+}
+
+type ConfigConnectorInput struct {
+	Enabled   bool   `yaml:"enabled"`
+	Directory string `yaml:"directory"`
+}
+
 type K8SAPIInput struct {
 	Enabled     bool     `yaml:"enabled"`
 	APIVersions []string `yaml:"resourceAPIVersions"`
@@ -105,9 +233,14 @@ type RestInput struct {
 
 type ConfigOutput struct {
 	FileName              string                      `yaml:"file"`
+	Format                string                      `yaml:"format"`
 	PubSub                PubSubOutput                `yaml:"pubsub"`
 	CloudStorage          CloudStorageOutput          `yaml:"cloudStorage"`
 	SecurityCommandCenter SecurityCommandCenterOutput `yaml:"securityCommandCenter"`
+	BigQuery              BigQueryOutput              `yaml:"bigquery"`
+	Webhook               WebhookOutput               `yaml:"webhook"`
+	Issues                IssuesOutput                `yaml:"issues"`
+	CloudLogging          CloudLoggingOutput          `yaml:"cloudLogging"`
 }
 
 type ConfigMetric struct {
@@ -118,6 +251,7 @@ type ConfigMetric struct {
 type PubSubOutput struct {
 	Project string `yaml:"project"`
 	Topic   string `yaml:"topic"`
+	Mode    string `yaml:"mode"`
 }
 
 type CloudStorageOutput struct {
@@ -126,16 +260,86 @@ type CloudStorageOutput struct {
 	SkipDatePrefix bool   `yaml:"skipDatePrefix"`
 }
 
+type BigQueryOutput struct {
+	Project string `yaml:"project"`
+	Dataset string `yaml:"dataset"`
+	Table   string `yaml:"table"`
+}
+
+type WebhookOutput struct {
+	URL            string `yaml:"url"`
+	Template       string `yaml:"template"`
+	TemplateFile   string `yaml:"templateFile"`
+	MinSeverity    string `yaml:"minSeverity"`
+	GroupByCluster bool   `yaml:"groupByCluster"`
+	MaxRetries     int    `yaml:"maxRetries"`
+}
+
+type CloudLoggingOutput struct {
+	Enabled bool   `yaml:"enabled"`
+	Project string `yaml:"project"`
+	LogID   string `yaml:"logID"`
+}
+
+type IssuesOutput struct {
+	Tracker          string   `yaml:"tracker"`
+	URL              string   `yaml:"url"`
+	Repository       string   `yaml:"repository"`
+	Project          string   `yaml:"project"`
+	IssueType        string   `yaml:"issueType"`
+	User             string   `yaml:"user"`
+	TokenEnv         string   `yaml:"tokenEnv"`
+	Labels           []string `yaml:"labels"`
+	MinSeverity      string   `yaml:"minSeverity"`
+	CloseTransition  string   `yaml:"closeTransition"`
+	ReopenTransition string   `yaml:"reopenTransition"`
+}
+
 type SecurityCommandCenterOutput struct {
 	OrganizationNumber string `yaml:"organization"`
 	ProvisionSource    bool   `yaml:"provisionSource"`
 }
 
+type ConfigHistory struct {
+	SQLite   HistorySQLite   `yaml:"sqlite"`
+	BigQuery HistoryBigQuery `yaml:"bigquery"`
+}
+
+type HistorySQLite struct {
+	File string `yaml:"file"`
+}
+
+type HistoryBigQuery struct {
+	Project  string `yaml:"project"`
+	Dataset  string `yaml:"dataset"`
+	Table    string `yaml:"table"`
+	Endpoint string `yaml:"endpoint"`
+}
+
 type ClusterDiscovery struct {
-	Enabled      bool     `yaml:"enabled"`
-	Organization string   `yaml:"organization"`
-	Folders      []string `yaml:"folders"`
-	Projects     []string `yaml:"projects"`
+	Enabled             bool                    `yaml:"enabled"`
+	Source              string                  `yaml:"source"`
+	Organization        string                  `yaml:"organization"`
+	Folders             []string                `yaml:"folders"`
+	Projects            []string                `yaml:"projects"`
+	Filters             ClusterDiscoveryFilters `yaml:"filters"`
+	PageSize            int                     `yaml:"pageSize"`
+	MaxResults          int                     `yaml:"maxResults"`
+	AllowPartialResults bool                    `yaml:"allowPartialResults"`
+}
+
+type ClusterInventory struct {
+	File   string `yaml:"file"`
+	Format string `yaml:"format"`
+}
+
+type ClusterDiscoveryFilters struct {
+	IncludeLabels   map[string]string `yaml:"includeLabels"`
+	ExcludeLabels   map[string]string `yaml:"excludeLabels"`
+	Location        string            `yaml:"location"`
+	Name            string            `yaml:"name"`
+	Mode            string            `yaml:"mode"`
+	ReleaseChannels []string          `yaml:"releaseChannels"`
 }
 
 type ConfigPolicyExclusions struct {
@@ -167,6 +371,8 @@ func ReadConfig(path string, readFn ReadFileFn) (*Config, error) {
 func ValidateClusterDumpConfig(config Config) error {
 	var errors = make([]error, 0)
 	errors = append(errors, validateClustersConfig(config)...)
+	errors = append(errors, validateTimeoutConfig(config)...)
+	errors = append(errors, validateAPIClientsConfig(config.APIClients)...)
 	if len(errors) > 0 {
 		for _, err := range errors {
 			log.Warnf("configuration validation error: %s", err)
@@ -176,23 +382,71 @@ func ValidateClusterDumpConfig(config Config) error {
 	return nil
 }
 
+func ValidateClusterDumpFileConfig(config Config) error {
+	if config.DumpFile == "" {
+		err := fmt.Errorf("cluster dump file is not set")
+		log.Warnf("configuration validation error: %s", err)
+		return err
+	}
+	return nil
+}
+
 func ValidateClusterCheckConfig(config Config) error {
 	var errors = make([]error, 0)
 	errors = append(errors, validateClustersConfig(config)...)
 	errors = append(errors, validatePolicySourceConfig(config.Policies)...)
 	errors = append(errors, validateOutputConfig(config.Outputs)...)
-	if config.Inputs.GKEApi == nil && config.Inputs.GKELocalInput == nil {
-		errors = append(errors, fmt.Errorf("either gkeAPI input or gkeLocalInput has to be declared"))
+	errors = append(errors, validateConsoleConfig(config.Console)...)
+	errors = append(errors, validateEvaluationConfig(config.Evaluation)...)
+	errors = append(errors, validateTimeoutConfig(config)...)
+	errors = append(errors, validateAPIClientsConfig(config.APIClients)...)
+	errors = append(errors, validateConfigConnectorInputConfig(config.Inputs.ConfigConnector)...)
+	errors = append(errors, validateProfilesConfig(config.Profiles)...)
+	errors = append(errors, validateFleetInputConfig(config.Inputs.Fleet)...)
+	errors = append(errors, validateLocalInputConfig(config.Inputs.Local)...)
+	if IsHistoryEnabled(config) {
+		errors = append(errors, validateHistoryConfig(config.History)...)
 	}
-	if config.Inputs.GKEApi != nil && !config.Inputs.GKEApi.Enabled {
-		if config.Inputs.GKELocalInput == nil || !config.Inputs.GKELocalInput.Enabled {
-			errors = append(errors, fmt.Errorf("either gkeAPI input or gkeLocalInput has to be enabled"))
+	if !isConfigConnectorInputEnabled(config) && !isLocalInputEnabled(config) {
+		if config.Inputs.GKEApi == nil && config.Inputs.GKELocalInput == nil {
+			errors = append(errors, fmt.Errorf("either gkeAPI input or gkeLocalInput has to be declared"))
+		}
+		if config.Inputs.GKEApi != nil && !config.Inputs.GKEApi.Enabled {
+			if config.Inputs.GKELocalInput == nil || !config.Inputs.GKELocalInput.Enabled {
+				errors = append(errors, fmt.Errorf("either gkeAPI input or gkeLocalInput has to be enabled"))
+			}
+		}
+		if config.Inputs.GKELocalInput != nil && !config.Inputs.GKELocalInput.Enabled {
+			if config.Inputs.GKEApi == nil || !config.Inputs.GKEApi.Enabled {
+				errors = append(errors, fmt.Errorf("either gkeAPI input or gkeLocalInput has to be enabled"))
+			}
 		}
 	}
-	if config.Inputs.GKELocalInput != nil && !config.Inputs.GKELocalInput.Enabled {
-		if config.Inputs.GKEApi == nil || !config.Inputs.GKEApi.Enabled {
-			errors = append(errors, fmt.Errorf("either gkeAPI input or gkeLocalInput has to be enabled"))
+	if len(errors) > 0 {
+		for _, err := range errors {
+			log.Warnf("configuration validation error: %s", err)
 		}
+		return errors[0]
+	}
+	return nil
+}
+
+func ValidateTerraformPlanCheckConfig(config Config) error {
+	var errors = make([]error, 0)
+	errors = append(errors, validatePolicySourceConfig(config.Policies)...)
+	errors = append(errors, validateOutputConfig(config.Outputs)...)
+	errors = append(errors, validateConsoleConfig(config.Console)...)
+	errors = append(errors, validateEvaluationConfig(config.Evaluation)...)
+	errors = append(errors, validateTimeoutConfig(config)...)
+	errors = append(errors, validateAPIClientsConfig(config.APIClients)...)
+	errors = append(errors, validateProfilesConfig(config.Profiles)...)
+	if config.Inputs.TerraformPlan == nil || !config.Inputs.TerraformPlan.Enabled {
+		errors = append(errors, fmt.Errorf("terraformPlan input has to be enabled"))
+	} else if config.Inputs.TerraformPlan.PlanFile == "" {
+		errors = append(errors, fmt.Errorf("terraformPlan input file is not set"))
+	}
+	if config.ClusterDiscovery.Enabled || config.DumpFile != "" || config.ClusterInventory.File != "" || len(config.Clusters) > 0 {
+		errors = append(errors, fmt.Errorf("clusters can't be defined when checking Terraform plan"))
 	}
 	if len(errors) > 0 {
 		for _, err := range errors {
@@ -234,11 +488,19 @@ func ValidateScalabilityCheckConfig(config Config) error {
 	errors = append(errors, validateClustersConfig(config)...)
 	errors = append(errors, validatePolicySourceConfig(config.Policies)...)
 	errors = append(errors, validateOutputConfig(config.Outputs)...)
-	if config.Inputs.MetricsAPI == nil || !config.Inputs.MetricsAPI.Enabled {
-		errors = append(errors, fmt.Errorf("metricsAPI input has to be enabled"))
-	}
-	if config.Inputs.GKEApi == nil || !config.Inputs.GKEApi.Enabled {
-		errors = append(errors, fmt.Errorf("gkeAPI input has to be enabled"))
+	errors = append(errors, validateConsoleConfig(config.Console)...)
+	errors = append(errors, validateEvaluationConfig(config.Evaluation)...)
+	errors = append(errors, validateTimeoutConfig(config)...)
+	errors = append(errors, validateAPIClientsConfig(config.APIClients)...)
+	errors = append(errors, validateProfilesConfig(config.Profiles)...)
+	errors = append(errors, validateLocalInputConfig(config.Inputs.Local)...)
+	if !isLocalInputEnabled(config) {
+		if config.Inputs.MetricsAPI == nil || !config.Inputs.MetricsAPI.Enabled {
+			errors = append(errors, fmt.Errorf("metricsAPI input has to be enabled"))
+		}
+		if config.Inputs.GKEApi == nil || !config.Inputs.GKEApi.Enabled {
+			errors = append(errors, fmt.Errorf("gkeAPI input has to be enabled"))
+		}
 	}
 	if len(errors) > 0 {
 		for _, err := range errors {
@@ -246,7 +508,7 @@ func ValidateScalabilityCheckConfig(config Config) error {
 		}
 		return errors[0]
 	}
-	if config.Inputs.MetricsAPI.Enabled {
+	if config.Inputs.MetricsAPI != nil && config.Inputs.MetricsAPI.Enabled {
 		if (config.Inputs.MetricsAPI.Username != "" && config.Inputs.MetricsAPI.Password == "") ||
 			(config.Inputs.MetricsAPI.Password != "" && config.Inputs.MetricsAPI.Username == "") {
 			return fmt.Errorf("can't set username without password or password without the username")
@@ -258,7 +520,118 @@ func ValidateScalabilityCheckConfig(config Config) error {
 	return nil
 }
 
+func ValidateRemediationConfig(config Config) error {
+	if err := ValidateClusterCheckConfig(config); err != nil {
+		return err
+	}
+	var errors = make([]error, 0)
+	format := config.Remediation.Format
+	if format != RemediationFormatGcloud && format != RemediationFormatTerraform {
+		errors = append(errors, fmt.Errorf("invalid remediation format %q - should be %s or %s",
+			format, RemediationFormatGcloud, RemediationFormatTerraform))
+	}
+	if config.Remediation.Apply && format != RemediationFormatGcloud {
+		errors = append(errors, fmt.Errorf("remediation can be applied only with %s format", RemediationFormatGcloud))
+	}
+	if len(errors) > 0 {
+		for _, err := range errors {
+			log.Warnf("configuration validation error: %s", err)
+		}
+		return errors[0]
+	}
+	return nil
+}
+
+func ValidateHistoryConfig(config Config) error {
+	errors := validateHistoryConfig(config.History)
+	if len(errors) > 0 {
+		for _, err := range errors {
+			log.Warnf("configuration validation error: %s", err)
+		}
+		return errors[0]
+	}
+	return nil
+}
+
+func validateHistoryConfig(config ConfigHistory) []error {
+	sqlite := config.SQLite.File != ""
+	bigQuery := config.BigQuery.Project != "" || config.BigQuery.Dataset != ""
+	if !sqlite && !bigQuery {
+		return []error{fmt.Errorf("history store is not set - either sqlite or bigquery has to be configured")}
+	}
+	if sqlite && bigQuery {
+		return []error{fmt.Errorf("only one history store can be configured - sqlite or bigquery")}
+	}
+	var errors = make([]error, 0)
+	if bigQuery && config.BigQuery.Project == "" {
+		errors = append(errors, fmt.Errorf("history bigquery project is not set for the dataset [%s]", config.BigQuery.Dataset))
+	}
+	if bigQuery && config.BigQuery.Dataset == "" {
+		errors = append(errors, fmt.Errorf("history bigquery dataset is not set for the project [%s]", config.BigQuery.Project))
+	}
+	return errors
+}
+
+func validateConfigConnectorInputConfig(config *ConfigConnectorInput) []error {
+	if config == nil || !config.Enabled {
+		return nil
+	}
+	if config.Directory == "" {
+		return []error{fmt.Errorf("configConnector input directory is not set")}
+	}
+	return nil
+}
+
+func validateFleetInputConfig(config *FleetInput) []error {
+	if config == nil || !config.Enabled {
+		return nil
+	}
+	if len(config.Projects) < 1 {
+		return []error{fmt.Errorf("fleet input projects are not set")}
+	}
+	return nil
+}
+
+func validateLocalInputConfig(config *LocalInput) []error {
+	if config == nil || !config.Enabled {
+		return nil
+	}
+	if config.DumpFile == "" {
+		return []error{fmt.Errorf("local input file is not set")}
+	}
+	return nil
+}
+
+func isLocalInputEnabled(config Config) bool {
+	return config.Inputs.Local != nil && config.Inputs.Local.Enabled
+}
+
+// IsHistoryEnabled returns true if any history store backend is configured.
+func IsHistoryEnabled(config Config) bool {
+	return config.History.SQLite.File != "" || config.History.BigQuery.Project != "" || config.History.BigQuery.Dataset != ""
+}
+
+func isConfigConnectorInputEnabled(config Config) bool {
+	return config.Inputs.ConfigConnector != nil && config.Inputs.ConfigConnector.Enabled
+}
+
 func validateClustersConfig(config Config) []error {
+	if isConfigConnectorInputEnabled(config) {
+		if config.ClusterDiscovery.Enabled || config.DumpFile != "" || config.ClusterInventory.File != "" || len(config.Clusters) > 0 {
+			return []error{fmt.Errorf("clusters can't be defined when configConnector input is enabled")}
+		}
+		return nil
+	}
+	if config.ClusterInventory.File != "" {
+		if config.ClusterDiscovery.Enabled || config.DumpFile != "" || len(config.Clusters) > 0 {
+			return []error{fmt.Errorf("cluster inventory is defined along with cluster discovery, a dump file or a cluster list")}
+		}
+		format := strings.ToLower(config.ClusterInventory.Format)
+		if format != "" && format != gke.InventoryFormatCSV && format != gke.InventoryFormatYAML {
+			return []error{fmt.Errorf("cluster inventory format %q is not one of %s, %s", config.ClusterInventory.Format, gke.InventoryFormatCSV, gke.InventoryFormatYAML)}
+		}
+		return nil
+	}
 	if config.ClusterDiscovery.Enabled {
 		discovery := config.ClusterDiscovery
 		if config.DumpFile != "" {
@@ -270,6 +643,20 @@ func validateClustersConfig(config Config) []error {
 		if len(discovery.Folders) < 1 && len(discovery.Projects) < 1 && discovery.Organization == "" {
 			return []error{fmt.Errorf("cluster discovery is enabled but none of organization, folder list or project list are defined")}
 		}
+		if discovery.Source != "" && discovery.Source != DiscoverySourceAssetInventory &&
+			discovery.Source != DiscoverySourceGKEAPI && discovery.Source != DiscoverySourceFleet {
+			return []error{fmt.Errorf("cluster discovery source %q is not one of %s, %s, %s",
+				discovery.Source, DiscoverySourceAssetInventory, DiscoverySourceGKEAPI, DiscoverySourceFleet)}
+		}
+		if discovery.Source == DiscoverySourceFleet && (len(discovery.Folders) > 0 || discovery.Organization != "") {
+			return []error{fmt.Errorf("fleet cluster discovery supports fleet host projects only")}
+		}
+		if discovery.PageSize < 0 || discovery.PageSize > MaxDiscoveryPageSize {
+			return []error{fmt.Errorf("cluster discovery page size has to be between 0 and %d", MaxDiscoveryPageSize)}
+		}
+		if discovery.MaxResults < 0 {
+			return []error{fmt.Errorf("cluster discovery max results can't be negative")}
+		}
 	} else {
 		if config.DumpFile == "" && len(config.Clusters) < 1 {
 			return []error{fmt.Errorf("cluster discovery is disabled and there are no clusters defined")}
@@ -294,7 +681,52 @@ func validateClustersConfig(config Config) []error {
 		}
 		return errors
 	}
-	return nil
+	return validateClusterDiscoveryFilters(config.ClusterDiscovery.Filters)
+}
+
+func validateClusterDiscoveryFilters(filters ClusterDiscoveryFilters) []error {
+	var errors []error
+	if _, err := regexp.Compile(filters.Location); err != nil {
+		errors = append(errors, fmt.Errorf("cluster discovery location filter is not a valid regular expression: %w", err))
+	}
+	if _, err := regexp.Compile(filters.Name); err != nil {
+		errors = append(errors, fmt.Errorf("cluster discovery name filter is not a valid regular expression: %w", err))
+	}
+	if filters.Mode != "" && filters.Mode != gke.ClusterModeAutopilot && filters.Mode != gke.ClusterModeStandard {
+		errors = append(errors, fmt.Errorf("cluster discovery mode filter %q is not one of %s, %s", filters.Mode, gke.ClusterModeAutopilot, gke.ClusterModeStandard))
+	}
+	for _, channel := range filters.ReleaseChannels {
+		if !slices.Contains(releaseChannels, strings.ToUpper(channel)) {
+			errors = append(errors, fmt.Errorf("cluster discovery release channel filter %q is not one of %s", channel, strings.Join(releaseChannels, ", ")))
+		}
+	}
+	return errors
+}
+
+func validateProfilesConfig(profiles []ConfigPolicyProfile) []error {
+	var errors = make([]error, 0)
+	names := make(map[string]bool)
+	for i, profile := range profiles {
+		if profile.Name == "" {
+			errors = append(errors, fmt.Errorf("profile [%v]: name is not set", i))
+		} else if names[profile.Name] {
+			errors = append(errors, fmt.Errorf("profile [%v]: name %q is not unique", i, profile.Name))
+		}
+		names[profile.Name] = true
+		selector := profile.Selector
+		if len(selector.Labels) == 0 && len(selector.Folders) == 0 && len(selector.Projects) == 0 && selector.ID == "" {
+			errors = append(errors, fmt.Errorf("profile [%v]: selector is not set", i))
+		}
+		if _, err := regexp.Compile(selector.ID); err != nil {
+			errors = append(errors, fmt.Errorf("profile [%v]: selector id is not a valid regular expression: %w", i, err))
+		}
+		if len(profile.Policies) > 0 {
+			for _, err := range validatePolicySourceConfig(profile.Policies) {
+				errors = append(errors, fmt.Errorf("profile [%v]: %w", i, err))
+			}
+		}
+	}
+	return errors
 }
 
 func validatePolicySourceConfig(policies []ConfigPolicy) []error {
@@ -325,8 +757,12 @@ func validatePolicySourceConfig(policies []ConfigPolicy) []error {
 func validateOutputConfig(outputs []ConfigOutput) []error {
 	var errors = make([]error, 0)
 	for _, output := range outputs {
-		if output.FileName != "" && !strings.HasSuffix(output.FileName, ".json") {
-			errors = append(errors, fmt.Errorf("invalid output - filename should end with .json"))
+		if output.FileName != "" && GetOutputFileFormat(output) == "" {
+			errors = append(errors, fmt.Errorf("invalid output - filename should end with .json, .csv or .md, or format should be one of %s, %s, %s",
+				OutputFormatJSON, OutputFormatCSV, OutputFormatMarkdown))
+		}
+		if output.FileName == "" && output.Format != "" {
+			errors = append(errors, fmt.Errorf("invalid output - filename empty for format: %s", output.Format))
 		}
 		if output.CloudStorage.Bucket == "" && output.CloudStorage.Path != "" {
 			errors = append(errors, fmt.Errorf("invalid output - bucket empty for path: %s", output.CloudStorage.Path))
@@ -335,10 +771,89 @@ func validateOutputConfig(outputs []ConfigOutput) []error {
 			errors = append(errors, fmt.Errorf("invalid output - path empty for bucket: %s", output.CloudStorage.Bucket))
 		}
 		errors = append(errors, validatePubSubConfig(output.PubSub)...)
+		errors = append(errors, validateBigQueryOutputConfig(output.BigQuery)...)
+		errors = append(errors, validateWebhookOutputConfig(output.Webhook)...)
+		errors = append(errors, validateCloudLoggingOutputConfig(output.CloudLogging)...)
+		errors = append(errors, validateIssuesOutputConfig(output.Issues)...)
+	}
+	return errors
+}
+
+func validateConsoleConfig(console ConfigConsole) []error {
+	var errors = make([]error, 0)
+	if console.MinSeverity != "" && !slices.Contains(severities, strings.ToLower(console.MinSeverity)) {
+		errors = append(errors, fmt.Errorf("invalid console minSeverity %q - should be one of %s",
+			console.MinSeverity, strings.Join(severities, ", ")))
+	}
+	if console.GroupBy != "" && console.GroupBy != ConsoleGroupByPolicy && console.GroupBy != ConsoleGroupByCluster {
+		errors = append(errors, fmt.Errorf("invalid console groupBy %q - should be one of %s, %s",
+			console.GroupBy, ConsoleGroupByPolicy, ConsoleGroupByCluster))
+	}
+	return errors
+}
+
+func validateEvaluationConfig(evaluation ConfigEvaluation) []error {
+	var errors = make([]error, 0)
+	if evaluation.Workers < 0 {
+		errors = append(errors, fmt.Errorf("evaluation workers can't be negative"))
+	}
+	return errors
+}
+
+func validateTimeoutConfig(config Config) []error {
+	var errors = make([]error, 0)
+	if config.TimeoutSeconds < 0 {
+		errors = append(errors, fmt.Errorf("timeout can't be negative"))
+	}
+	if config.Inputs.TimeoutSeconds < 0 {
+		errors = append(errors, fmt.Errorf("inputs timeout can't be negative"))
+	}
+	return errors
+}
+
+func validateAPIClientsConfig(apiClients ConfigAPIClients) []error {
+	var errors = make([]error, 0)
+	if apiClients.MaxRetries < 0 {
+		errors = append(errors, fmt.Errorf("API clients max retries can't be negative"))
+	}
+	if apiClients.InitialBackoffMillis < 0 || apiClients.MaxBackoffMillis < 0 {
+		errors = append(errors, fmt.Errorf("API clients backoff can't be negative"))
+	}
+	if apiClients.InitialBackoffMillis > 0 && apiClients.MaxBackoffMillis > 0 && apiClients.InitialBackoffMillis > apiClients.MaxBackoffMillis {
+		errors = append(errors, fmt.Errorf("API clients initial backoff can't be greater than max backoff"))
+	}
+	for api, qps := range apiClients.QPS {
+		if !slices.Contains(APIs, api) {
+			errors = append(errors, fmt.Errorf("invalid API %q in API clients QPS - should be one of %s", api, strings.Join(APIs, ", ")))
+		}
+		if qps < 0 {
+			errors = append(errors, fmt.Errorf("API clients QPS of %s can't be negative", api))
+		}
 	}
 	return errors
 }
 
+// GetOutputFileFormat returns the format of the output file, set explicitly or determined by
+// the file extension. Empty string is returned when the format is not supported.
+func GetOutputFileFormat(output ConfigOutput) string {
+	if output.Format != "" {
+		format := strings.ToLower(output.Format)
+		if format == OutputFormatJSON || format == OutputFormatCSV || format == OutputFormatMarkdown {
+			return format
+		}
+		return ""
+	}
+	switch strings.ToLower(filepath.Ext(output.FileName)) {
+	case ".json":
+		return OutputFormatJSON
+	case ".csv":
+		return OutputFormatCSV
+	case ".md", ".markdown":
+		return OutputFormatMarkdown
+	}
+	return ""
+}
+
 func validatePubSubConfig(pubsub PubSubOutput) []error {
 	var errors = make([]error, 0)
 	if pubsub.Project != "" && pubsub.Topic == "" {
@@ -347,36 +862,142 @@ func validatePubSubConfig(pubsub PubSubOutput) []error {
 	if pubsub.Topic != "" && pubsub.Project == "" {
 		errors = append(errors, fmt.Errorf("PubSub Project name is not set for the topic [%s]", pubsub.Topic))
 	}
+	if pubsub.Mode != "" && pubsub.Mode != PubSubModeReport && pubsub.Mode != PubSubModeCluster && pubsub.Mode != PubSubModeViolation {
+		errors = append(errors, fmt.Errorf("invalid PubSub mode %q - should be %s, %s or %s",
+			pubsub.Mode, PubSubModeReport, PubSubModeCluster, PubSubModeViolation))
+	}
+	return errors
+}
+
+func validateBigQueryOutputConfig(bigQuery BigQueryOutput) []error {
+	var errors = make([]error, 0)
+	if bigQuery.Project != "" && bigQuery.Dataset == "" {
+		errors = append(errors, fmt.Errorf("BigQuery dataset is not set for the project [%s]", bigQuery.Project))
+	}
+	if bigQuery.Dataset != "" && bigQuery.Project == "" {
+		errors = append(errors, fmt.Errorf("BigQuery project is not set for the dataset [%s]", bigQuery.Dataset))
+	}
+	if bigQuery.Table != "" && bigQuery.Dataset == "" {
+		errors = append(errors, fmt.Errorf("BigQuery dataset is not set for the table [%s]", bigQuery.Table))
+	}
+	return errors
+}
+
+func validateCloudLoggingOutputConfig(cloudLogging CloudLoggingOutput) []error {
+	var errors = make([]error, 0)
+	if cloudLogging.LogID != "" && !cloudLoggingLogIDRegexp.MatchString(cloudLogging.LogID) {
+		errors = append(errors, fmt.Errorf("invalid Cloud Logging logID %q - should have up to 512 letters, digits, "+
+			"forward slashes, underscores, hyphens and periods", cloudLogging.LogID))
+	}
+	return errors
+}
+
+func validateWebhookOutputConfig(webhook WebhookOutput) []error {
+	var errors = make([]error, 0)
+	if webhook.URL == "" {
+		if webhook.Template != "" || webhook.TemplateFile != "" || webhook.MinSeverity != "" {
+			errors = append(errors, fmt.Errorf("webhook URL is not set"))
+		}
+		return errors
+	}
+	if !strings.HasPrefix(webhook.URL, "https://") && !strings.HasPrefix(webhook.URL, "http://") {
+		errors = append(errors, fmt.Errorf("invalid webhook URL - should start with http:// or https://"))
+	}
+	if webhook.Template != "" && webhook.TemplateFile != "" {
+		errors = append(errors, fmt.Errorf("webhook template and templateFile can't be set together"))
+	}
+	if webhook.MinSeverity != "" && !slices.Contains(severities, strings.ToLower(webhook.MinSeverity)) {
+		errors = append(errors, fmt.Errorf("invalid webhook minSeverity %q - should be one of %s",
+			webhook.MinSeverity, strings.Join(severities, ", ")))
+	}
+	if webhook.MaxRetries < 0 {
+		errors = append(errors, fmt.Errorf("webhook maxRetries can't be negative"))
+	}
+	return errors
+}
+
+func validateIssuesOutputConfig(issues IssuesOutput) []error {
+	var errors = make([]error, 0)
+	switch issues.Tracker {
+	case "":
+		if issues.Repository != "" || issues.Project != "" {
+			errors = append(errors, fmt.Errorf("issues output tracker is not set"))
+		}
+		return errors
+	case IssueTrackerGitHub:
+		if strings.Count(issues.Repository, "/") != 1 {
+			errors = append(errors, fmt.Errorf("invalid issues output GitHub repository %q - should be in owner/name format", issues.Repository))
+		}
+	case IssueTrackerJira:
+		if issues.URL == "" {
+			errors = append(errors, fmt.Errorf("issues output Jira URL is not set"))
+		}
+		if issues.Project == "" {
+			errors = append(errors, fmt.Errorf("issues output Jira project is not set"))
+		}
+	default:
+		errors = append(errors, fmt.Errorf("invalid issues output tracker %q - should be %s or %s",
+			issues.Tracker, IssueTrackerGitHub, IssueTrackerJira))
+	}
+	if issues.MinSeverity != "" && !slices.Contains(severities, strings.ToLower(issues.MinSeverity)) {
+		errors = append(errors, fmt.Errorf("invalid issues output minSeverity %q - should be one of %s",
+			issues.MinSeverity, strings.Join(severities, ", ")))
+	}
 	return errors
 }
 
 func SetCheckConfigDefaults(config *Config) {
 	SetPolicyConfigDefaults(config)
-	if config.Inputs.GKEApi == nil {
+	setLocalInputDefaults(config)
+	if config.Inputs.GKEApi == nil && !isConfigConnectorInputEnabled(*config) && !isLocalInputEnabled(*config) {
 		log.Debugf("Configuring GKEApi input defaults")
 		config.Inputs.GKEApi = &GKEApiInput{
 			Enabled: true,
 		}
 	}
+	if config.Inputs.Fleet == nil && config.ClusterDiscovery.Enabled && config.ClusterDiscovery.Source == DiscoverySourceFleet {
+		log.Debugf("Configuring Fleet input defaults")
+		config.Inputs.Fleet = &FleetInput{
+			Enabled:  true,
+			Projects: config.ClusterDiscovery.Projects,
+		}
+	}
+}
+
+// SetClusterDumpConfigDefaults sets cluster check defaults, along with the k8sAPI input defaults
+// and the scalability metrics for the enabled metricsAPI input without metrics, so that the dump
+// can be used for offline scalability checks.
+func SetClusterDumpConfigDefaults(config *Config) {
+	SetCheckConfigDefaults(config)
+	setK8SAPIInputDefaults(config)
+	if config.Inputs.MetricsAPI != nil && config.Inputs.MetricsAPI.Enabled && len(config.Inputs.MetricsAPI.Metrics) == 0 {
+		log.Debugf("configuring MetricsApi input scalability metrics")
+		config.Inputs.MetricsAPI.Metrics = getScalabilityMetricsDefaults()
+	}
 }
 
 func SetScalabilityConfigDefaults(config *Config) {
 	SetPolicyConfigDefaults(config)
-	if config.Inputs.MetricsAPI == nil {
+	setLocalInputDefaults(config)
+	if config.Inputs.MetricsAPI == nil && !isLocalInputEnabled(*config) {
 		log.Debugf("configuring MetricsApi input defaults")
 		config.Inputs.MetricsAPI = &MetricsAPIInput{
 			Enabled: true,
 			Metrics: getScalabilityMetricsDefaults(),
 		}
-	} else {
+	} else if config.Inputs.MetricsAPI != nil {
 		config.Inputs.MetricsAPI.Metrics = append(config.Inputs.MetricsAPI.Metrics, getScalabilityMetricsDefaults()...)
 	}
-	if config.Inputs.GKEApi == nil {
+	if config.Inputs.GKEApi == nil && !isLocalInputEnabled(*config) {
 		log.Debugf("configuring GKEApi input defaults")
 		config.Inputs.GKEApi = &GKEApiInput{
 			Enabled: true,
 		}
 	}
+	setK8SAPIInputDefaults(config)
+}
+
+func setK8SAPIInputDefaults(config *Config) {
 	if config.Inputs.K8sAPI != nil {
 		log.Debugf("Configuring K8SApiConfig input defaults")
 		if config.Inputs.K8sAPI.MaxQPS == 0 {
@@ -388,6 +1009,29 @@ func SetScalabilityConfigDefaults(config *Config) {
 	}
 }
 
+// setLocalInputDefaults enables local input for the configured cluster dump file, when none of
+// GKE data inputs is configured. Sets the dump file of the enabled local input to the configured
+// cluster dump file, when not set.
+func setLocalInputDefaults(config *Config) {
+	if config.DumpFile != "" && config.Inputs.Local == nil && config.Inputs.GKEApi == nil &&
+		config.Inputs.GKELocalInput == nil && config.Inputs.MetricsAPI == nil && !isConfigConnectorInputEnabled(*config) {
+		log.Debugf("Configuring local input defaults")
+		config.Inputs.Local = &LocalInput{Enabled: true}
+	}
+	if isLocalInputEnabled(*config) && config.Inputs.Local.DumpFile == "" {
+		log.Debugf("configuring local input dump file %s", config.DumpFile)
+		config.Inputs.Local.DumpFile = config.DumpFile
+	}
+}
+
+func SetRemediationConfigDefaults(config *Config) {
+	SetCheckConfigDefaults(config)
+	if config.Remediation.Format == "" {
+		log.Debugf("Configuring remediation format defaults")
+		config.Remediation.Format = RemediationFormatGcloud
+	}
+}
+
 func SetPolicyConfigDefaults(config *Config) {
 	if len(config.Policies) < 1 {
 		log.Debugf("no policies defined, using default GIT policy source: repo %s, branch %s, directory %s",
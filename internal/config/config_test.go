@@ -16,8 +16,10 @@ package config
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
+	"github.com/google/gke-policy-automation/internal/gke"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -126,6 +128,15 @@ func TestValidateClusterDumpConfig(t *testing.T) {
 
 }
 
+func TestValidateClusterDumpFileConfig(t *testing.T) {
+	if err := ValidateClusterDumpFileConfig(Config{DumpFile: "dump.json"}); err != nil {
+		t.Errorf("err is not nil; want nil; err = %s", err)
+	}
+	if err := ValidateClusterDumpFileConfig(Config{}); err == nil {
+		t.Errorf("err is nil; want error")
+	}
+}
+
 func TestValidateCheckConfig(t *testing.T) {
 	config := Config{
 		Clusters: []ConfigCluster{
@@ -177,6 +188,38 @@ func TestValidateClusterReviewConfig_negative(t *testing.T) {
 	}
 }
 
+func TestValidateClusterCheckConfig_configConnector(t *testing.T) {
+	config := Config{
+		Policies: []ConfigPolicy{{LocalDirectory: "./directory"}},
+		Inputs: ConfigInput{
+			ConfigConnector: &ConfigConnectorInput{Enabled: true, Directory: "./manifests"},
+		},
+	}
+	if err := ValidateClusterCheckConfig(config); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+}
+
+func TestValidateClusterCheckConfig_configConnector_negative(t *testing.T) {
+	policies := []ConfigPolicy{{LocalDirectory: "./directory"}}
+	badConfigs := []Config{
+		{
+			Policies: policies,
+			Inputs:   ConfigInput{ConfigConnector: &ConfigConnectorInput{Enabled: true}},
+		},
+		{
+			Policies: policies,
+			Inputs:   ConfigInput{ConfigConnector: &ConfigConnectorInput{Enabled: true, Directory: "./manifests"}},
+			Clusters: []ConfigCluster{{ID: "projects/p/locations/l/clusters/c"}},
+		},
+	}
+	for i, config := range badConfigs {
+		if err := ValidateClusterCheckConfig(config); err == nil {
+			t.Errorf("expected error on invalid config connector config [%d]", i)
+		}
+	}
+}
+
 func TestValidateClustersConfig_discovery(t *testing.T) {
 	config := Config{
 		ClusterDiscovery: ClusterDiscovery{
@@ -200,6 +243,117 @@ func TestValidateClustersConfig_discovery_negative(t *testing.T) {
 	}
 }
 
+func TestValidateClustersConfig_discoveryLimits(t *testing.T) {
+	config := Config{
+		ClusterDiscovery: ClusterDiscovery{
+			Enabled:             true,
+			Organization:        "12345",
+			Source:              DiscoverySourceGKEAPI,
+			PageSize:            MaxDiscoveryPageSize,
+			MaxResults:          50000,
+			AllowPartialResults: true,
+		},
+	}
+	if err := validateClustersConfig(config); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+}
+
+func TestValidateClustersConfig_discoveryLimits_negative(t *testing.T) {
+	badDiscoveries := []ClusterDiscovery{
+		{Enabled: true, Organization: "12345", PageSize: -1},
+		{Enabled: true, Organization: "12345", PageSize: MaxDiscoveryPageSize + 1},
+		{Enabled: true, Organization: "12345", MaxResults: -1},
+		{Enabled: true, Organization: "12345", Source: "resourceManager"},
+		{Enabled: true, Organization: "12345", Source: DiscoverySourceFleet},
+		{Enabled: true, Projects: []string{"host"}, Folders: []string{"123"}, Source: DiscoverySourceFleet},
+	}
+	for i, discovery := range badDiscoveries {
+		if err := validateClustersConfig(Config{ClusterDiscovery: discovery}); err == nil {
+			t.Errorf("discovery [%d]: expected error, got no error", i)
+		}
+	}
+}
+
+func TestValidateClustersConfig_inventory(t *testing.T) {
+	config := Config{ClusterInventory: ClusterInventory{File: "-", Format: "YAML"}}
+	if err := validateClustersConfig(config); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+	badConfigs := []Config{
+		{ClusterInventory: ClusterInventory{File: "inventory.json", Format: "json"}},
+		{ClusterInventory: ClusterInventory{File: "inventory.csv"}, DumpFile: "dump.json"},
+		{ClusterInventory: ClusterInventory{File: "inventory.csv"}, ClusterDiscovery: ClusterDiscovery{Enabled: true, Organization: "123"}},
+	}
+	for i, config := range badConfigs {
+		if err := validateClustersConfig(config); err == nil {
+			t.Errorf("config [%d]: expected error, got no error", i)
+		}
+	}
+}
+
+func TestValidateClusterCheckConfig_fleet(t *testing.T) {
+	config := Config{
+		Policies: []ConfigPolicy{{LocalDirectory: "./directory"}},
+		ClusterDiscovery: ClusterDiscovery{
+			Enabled:  true,
+			Source:   DiscoverySourceFleet,
+			Projects: []string{"fleet-host"},
+		},
+		Inputs: ConfigInput{
+			GKEApi: &GKEApiInput{Enabled: true},
+			Fleet:  &FleetInput{Enabled: true, Projects: []string{"fleet-host"}},
+		},
+	}
+	if err := ValidateClusterCheckConfig(config); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+	config.Inputs.Fleet.Projects = nil
+	if err := ValidateClusterCheckConfig(config); err == nil {
+		t.Errorf("expected error on fleet input without projects, got no error")
+	}
+}
+
+func TestValidateClustersConfig_discoveryFilters(t *testing.T) {
+	config := Config{
+		ClusterDiscovery: ClusterDiscovery{
+			Enabled:      true,
+			Organization: "12345",
+			Filters: ClusterDiscoveryFilters{
+				IncludeLabels:   map[string]string{"env": "prod"},
+				Location:        "^europe-",
+				Name:            "^prod-",
+				Mode:            gke.ClusterModeAutopilot,
+				ReleaseChannels: []string{"regular", "STABLE"},
+			},
+		},
+	}
+	if err := validateClustersConfig(config); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+}
+
+func TestValidateClustersConfig_discoveryFilters_negative(t *testing.T) {
+	badFilters := []ClusterDiscoveryFilters{
+		{Location: "europe-("},
+		{Name: "[prod"},
+		{Mode: "serverless"},
+		{ReleaseChannels: []string{"beta"}},
+	}
+	for i, filters := range badFilters {
+		config := Config{
+			ClusterDiscovery: ClusterDiscovery{
+				Enabled:      true,
+				Organization: "12345",
+				Filters:      filters,
+			},
+		}
+		if err := validateClustersConfig(config); err == nil {
+			t.Errorf("filters [%d]: expected error, got no error", i)
+		}
+	}
+}
+
 func TestValidateClustersConfig_both(t *testing.T) {
 	config := Config{
 		ClusterDiscovery: ClusterDiscovery{
@@ -215,6 +369,43 @@ func TestValidateClustersConfig_both(t *testing.T) {
 	}
 }
 
+func TestValidateProfilesConfig(t *testing.T) {
+	profiles := []ConfigPolicyProfile{
+		{
+			Name:     "production",
+			Selector: ConfigClusterSelector{Labels: map[string]string{"env": "prod"}},
+			Policies: []ConfigPolicy{{LocalDirectory: "./prod-policies"}},
+		},
+		{
+			Name:             "sandbox",
+			Selector:         ConfigClusterSelector{Folders: []string{"123456"}, ID: "/clusters/sandbox-"},
+			PolicyExclusions: ConfigPolicyExclusions{PolicyGroups: []string{"Management"}},
+			Parameters:       map[string]interface{}{"max_nodes": 10},
+		},
+	}
+	if errors := validateProfilesConfig(profiles); len(errors) > 0 {
+		t.Errorf("expected no error, got: %v", errors)
+	}
+}
+
+func TestValidateProfilesConfig_negative(t *testing.T) {
+	badProfiles := [][]ConfigPolicyProfile{
+		{{Selector: ConfigClusterSelector{Projects: []string{"project"}}}},
+		{{Name: "profile"}},
+		{{Name: "profile", Selector: ConfigClusterSelector{ID: "[cluster"}}},
+		{{Name: "profile", Selector: ConfigClusterSelector{ID: "cluster"}, Policies: []ConfigPolicy{{GitRepository: "repo"}}}},
+		{
+			{Name: "profile", Selector: ConfigClusterSelector{ID: "one"}},
+			{Name: "profile", Selector: ConfigClusterSelector{ID: "two"}},
+		},
+	}
+	for i, profiles := range badProfiles {
+		if errors := validateProfilesConfig(profiles); len(errors) == 0 {
+			t.Errorf("profiles [%d]: expected error, got no error", i)
+		}
+	}
+}
+
 func TestValidatePolicyCheckConfig(t *testing.T) {
 	config := Config{
 		Policies: []ConfigPolicy{
@@ -248,11 +439,43 @@ func TestValidatePolicyCheckConfig_negative(t *testing.T) {
 	}
 }
 
+func TestValidateTerraformPlanCheckConfig(t *testing.T) {
+	config := Config{
+		Policies: []ConfigPolicy{{LocalDirectory: "./directory"}},
+		Inputs: ConfigInput{
+			TerraformPlan: &TerraformPlanInput{Enabled: true, PlanFile: "plan.json"},
+		},
+	}
+	if err := ValidateTerraformPlanCheckConfig(config); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+}
+
+func TestValidateTerraformPlanCheckConfig_negative(t *testing.T) {
+	policies := []ConfigPolicy{{LocalDirectory: "./directory"}}
+	badConfigs := []Config{
+		{Policies: policies},
+		{Policies: policies, Inputs: ConfigInput{TerraformPlan: &TerraformPlanInput{Enabled: true}}},
+		{
+			Policies: policies,
+			Inputs:   ConfigInput{TerraformPlan: &TerraformPlanInput{Enabled: true, PlanFile: "plan.json"}},
+			Clusters: []ConfigCluster{{ID: "projects/p/locations/l/clusters/c"}},
+		},
+	}
+	for i, config := range badConfigs {
+		if err := ValidateTerraformPlanCheckConfig(config); err == nil {
+			t.Errorf("expected error on invalid terraform plan config [%d]", i)
+		}
+	}
+}
+
 func TestValidateOutputConfig(t *testing.T) {
 	config := []ConfigOutput{
 		{FileName: "out.json"},
 		{PubSub: PubSubOutput{Project: "test", Topic: "test"}},
+		{PubSub: PubSubOutput{Project: "test", Topic: "test", Mode: PubSubModeViolation}},
 		{CloudStorage: CloudStorageOutput{Bucket: "bucket", Path: "path"}},
+		{BigQuery: BigQueryOutput{Project: "project", Dataset: "dataset"}},
 	}
 
 	if err := validateOutputConfig(config); len(err) > 0 {
@@ -270,6 +493,190 @@ func TestValidateOutputConfig_negative(t *testing.T) {
 	}
 }
 
+func TestValidateOutputConfig_fileFormat(t *testing.T) {
+	goodConfigs := []ConfigOutput{
+		{FileName: "out.csv"},
+		{FileName: "out.md"},
+		{FileName: "out.txt", Format: OutputFormatMarkdown},
+	}
+	if err := validateOutputConfig(goodConfigs); len(err) > 0 {
+		t.Errorf("expected no error, got: %v", err)
+	}
+	badConfigs := []ConfigOutput{
+		{FileName: "out.txt"},
+		{FileName: "out.json", Format: "xml"},
+		{Format: OutputFormatCSV},
+	}
+	for i, badConfig := range badConfigs {
+		if err := validateOutputConfig([]ConfigOutput{badConfig}); len(err) == 0 {
+			t.Errorf("expected error on invalid file output config [%d]", i)
+		}
+	}
+}
+
+func TestValidateConsoleConfig(t *testing.T) {
+	if err := validateConsoleConfig(ConfigConsole{MinSeverity: "High", GroupBy: ConsoleGroupByCluster}); len(err) > 0 {
+		t.Errorf("expected no error, got: %v", err)
+	}
+	badConfigs := []ConfigConsole{
+		{MinSeverity: "bogus"},
+		{GroupBy: "bogus"},
+	}
+	for i, badConfig := range badConfigs {
+		if err := validateConsoleConfig(badConfig); len(err) == 0 {
+			t.Errorf("expected error on invalid console config [%d]", i)
+		}
+	}
+}
+
+func TestValidateEvaluationConfig(t *testing.T) {
+	if err := validateEvaluationConfig(ConfigEvaluation{Workers: 4}); len(err) > 0 {
+		t.Errorf("expected no error, got: %v", err)
+	}
+	if err := validateEvaluationConfig(ConfigEvaluation{Workers: -1}); len(err) == 0 {
+		t.Errorf("expected error on negative evaluation workers")
+	}
+}
+
+func TestValidateTimeoutConfig(t *testing.T) {
+	if err := validateTimeoutConfig(Config{TimeoutSeconds: 3600, Inputs: ConfigInput{TimeoutSeconds: 60}}); len(err) > 0 {
+		t.Errorf("expected no error, got: %v", err)
+	}
+	if err := validateTimeoutConfig(Config{TimeoutSeconds: -1}); len(err) != 1 {
+		t.Errorf("expected error on negative timeout")
+	}
+	if err := validateTimeoutConfig(Config{Inputs: ConfigInput{TimeoutSeconds: -1}}); len(err) != 1 {
+		t.Errorf("expected error on negative inputs timeout")
+	}
+}
+
+func TestValidateAPIClientsConfig(t *testing.T) {
+	input := []ConfigAPIClients{
+		{},
+		{MaxRetries: 3, InitialBackoffMillis: 500, MaxBackoffMillis: 10000, QPS: map[string]float64{"container": 10, "securitycenter": 2.5}},
+		{MaxRetries: -1},
+		{InitialBackoffMillis: -1},
+		{InitialBackoffMillis: 2000, MaxBackoffMillis: 1000},
+		{QPS: map[string]float64{"compute": 10}},
+		{QPS: map[string]float64{"pubsub": -1}},
+	}
+	expected := []int{0, 0, 1, 1, 1, 1, 1}
+	for i := range input {
+		if errs := validateAPIClientsConfig(input[i]); len(errs) != expected[i] {
+			t.Errorf("errors [%d] = %v; want %v errors", i, errs, expected[i])
+		}
+	}
+}
+
+func TestGetOutputFileFormat(t *testing.T) {
+	input := []ConfigOutput{
+		{FileName: "out.json"},
+		{FileName: "out.CSV"},
+		{FileName: "out.md"},
+		{FileName: "out.markdown"},
+		{FileName: "out.txt"},
+		{FileName: "out.json", Format: "CSV"},
+		{FileName: "out.json", Format: "xml"},
+	}
+	expected := []string{OutputFormatJSON, OutputFormatCSV, OutputFormatMarkdown, OutputFormatMarkdown, "", OutputFormatCSV, ""}
+	for i := range input {
+		if format := GetOutputFileFormat(input[i]); format != expected[i] {
+			t.Errorf("format [%d] = %v; want %v", i, format, expected[i])
+		}
+	}
+}
+
+func TestValidatePubSubConfig_mode(t *testing.T) {
+	if err := validatePubSubConfig(PubSubOutput{Project: "test", Topic: "test", Mode: "bogus"}); len(err) == 0 {
+		t.Errorf("expected error on invalid PubSub mode")
+	}
+	for _, mode := range []string{"", PubSubModeReport, PubSubModeCluster, PubSubModeViolation} {
+		if err := validatePubSubConfig(PubSubOutput{Project: "test", Topic: "test", Mode: mode}); len(err) > 0 {
+			t.Errorf("expected no error on PubSub mode %q, got: %v", mode, err)
+		}
+	}
+}
+
+func TestValidateOutputConfig_bigQuery(t *testing.T) {
+	badConfigs := []BigQueryOutput{
+		{Project: "project"},
+		{Dataset: "dataset"},
+		{Project: "project", Table: "table"},
+	}
+	for i, badConfig := range badConfigs {
+		if err := validateOutputConfig([]ConfigOutput{{BigQuery: badConfig}}); len(err) == 0 {
+			t.Errorf("expected error on invalid BigQuery output config [%d]", i)
+		}
+	}
+}
+
+func TestValidateCloudLoggingOutputConfig(t *testing.T) {
+	badConfigs := []CloudLoggingOutput{
+		{Enabled: true, LogID: "my log"},
+		{Enabled: true, LogID: "log:id"},
+		{Enabled: true, LogID: strings.Repeat("a", 513)},
+	}
+	for i, badConfig := range badConfigs {
+		if err := validateCloudLoggingOutputConfig(badConfig); len(err) == 0 {
+			t.Errorf("expected error on invalid Cloud Logging output config [%d]", i)
+		}
+	}
+	goodConfigs := []CloudLoggingOutput{
+		{Enabled: true},
+		{Enabled: true, Project: "project", LogID: "gke-policy_automation/results.v1"},
+	}
+	for i, goodConfig := range goodConfigs {
+		if err := validateCloudLoggingOutputConfig(goodConfig); len(err) > 0 {
+			t.Errorf("expected no error on Cloud Logging output config [%d], got: %v", i, err)
+		}
+	}
+}
+
+func TestValidateWebhookOutputConfig(t *testing.T) {
+	badConfigs := []WebhookOutput{
+		{Template: "template"},
+		{URL: "ftp://example.com"},
+		{URL: "https://example.com", Template: "template", TemplateFile: "template.tmpl"},
+		{URL: "https://example.com", MinSeverity: "bogus"},
+		{URL: "https://example.com", MaxRetries: -1},
+	}
+	for i, badConfig := range badConfigs {
+		if err := validateWebhookOutputConfig(badConfig); len(err) == 0 {
+			t.Errorf("expected error on invalid webhook output config [%d]", i)
+		}
+	}
+	goodConfig := WebhookOutput{URL: "https://example.com", MinSeverity: "High", GroupByCluster: true}
+	if err := validateWebhookOutputConfig(goodConfig); len(err) > 0 {
+		t.Errorf("expected no error, got: %v", err)
+	}
+}
+
+func TestValidateIssuesOutputConfig(t *testing.T) {
+	badConfigs := []IssuesOutput{
+		{Repository: "owner/repo"},
+		{Tracker: "bogus"},
+		{Tracker: IssueTrackerGitHub, Repository: "repo"},
+		{Tracker: IssueTrackerJira, Project: "GKE"},
+		{Tracker: IssueTrackerJira, URL: "https://example.atlassian.net"},
+		{Tracker: IssueTrackerGitHub, Repository: "owner/repo", MinSeverity: "bogus"},
+	}
+	for i, badConfig := range badConfigs {
+		if err := validateIssuesOutputConfig(badConfig); len(err) == 0 {
+			t.Errorf("expected error on invalid issues output config [%d]", i)
+		}
+	}
+	goodConfigs := []IssuesOutput{
+		{},
+		{Tracker: IssueTrackerGitHub, Repository: "owner/repo", MinSeverity: "Critical"},
+		{Tracker: IssueTrackerJira, URL: "https://example.atlassian.net", Project: "GKE"},
+	}
+	for i, goodConfig := range goodConfigs {
+		if err := validateIssuesOutputConfig(goodConfig); len(err) > 0 {
+			t.Errorf("expected no error on valid issues output config [%d], got: %v", i, err)
+		}
+	}
+}
+
 func TestSetPolicyConfigDefaults(t *testing.T) {
 	config := &Config{}
 	SetPolicyConfigDefaults(config)
@@ -285,6 +692,33 @@ func TestSetCheckConfigDefaults(t *testing.T) {
 	}
 }
 
+func TestSetCheckConfigDefaults_fleet(t *testing.T) {
+	config := &Config{
+		ClusterDiscovery: ClusterDiscovery{
+			Enabled:  true,
+			Source:   DiscoverySourceFleet,
+			Projects: []string{"fleet-host"},
+		},
+	}
+	SetCheckConfigDefaults(config)
+	if config.Inputs.Fleet == nil || !config.Inputs.Fleet.Enabled {
+		t.Fatalf("Fleet input is not enabled")
+	}
+	assert.Equal(t, config.ClusterDiscovery.Projects, config.Inputs.Fleet.Projects, "Fleet input projects match discovery projects")
+}
+
+func TestSetCheckConfigDefaults_configConnector(t *testing.T) {
+	config := &Config{
+		Inputs: ConfigInput{
+			ConfigConnector: &ConfigConnectorInput{Enabled: true, Directory: "./manifests"},
+		},
+	}
+	SetCheckConfigDefaults(config)
+	if config.Inputs.GKEApi != nil {
+		t.Errorf("GKEApi = %v; want nil", config.Inputs.GKEApi)
+	}
+}
+
 func TestSetScalabilityConfigDefaults(t *testing.T) {
 	config := &Config{}
 	config.Inputs.K8sAPI = &K8SAPIInput{
@@ -301,6 +735,71 @@ func TestSetScalabilityConfigDefaults(t *testing.T) {
 	assert.ElementsMatch(t, config.Inputs.K8sAPI.APIVersions, DefaultK8SApiVersions, "K8sApi.ApiVersions matches defaults")
 }
 
+func TestSetScalabilityConfigDefaults_local(t *testing.T) {
+	config := &Config{
+		DumpFile: "dump.json",
+		Inputs:   ConfigInput{Local: &LocalInput{Enabled: true}},
+	}
+	SetScalabilityConfigDefaults(config)
+	if config.Inputs.MetricsAPI != nil {
+		t.Errorf("MetricsAPI = %v; want nil", config.Inputs.MetricsAPI)
+	}
+	if config.Inputs.GKEApi != nil {
+		t.Errorf("GKEApi = %v; want nil", config.Inputs.GKEApi)
+	}
+	if config.Inputs.Local.DumpFile != config.DumpFile {
+		t.Errorf("Local.DumpFile = %v; want %v", config.Inputs.Local.DumpFile, config.DumpFile)
+	}
+	config.Policies = []ConfigPolicy{{LocalDirectory: "./directory"}}
+	if err := ValidateScalabilityCheckConfig(*config); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+}
+
+func TestSetCheckConfigDefaults_dumpFile(t *testing.T) {
+	config := &Config{DumpFile: "dump.json"}
+	SetCheckConfigDefaults(config)
+	if config.Inputs.GKEApi != nil {
+		t.Errorf("GKEApi = %v; want nil", config.Inputs.GKEApi)
+	}
+	if config.Inputs.Local == nil || !config.Inputs.Local.Enabled {
+		t.Fatalf("Local input is not enabled")
+	}
+	if config.Inputs.Local.DumpFile != config.DumpFile {
+		t.Errorf("Local.DumpFile = %v; want %v", config.Inputs.Local.DumpFile, config.DumpFile)
+	}
+}
+
+func TestSetClusterDumpConfigDefaults(t *testing.T) {
+	config := &Config{
+		Inputs: ConfigInput{
+			MetricsAPI: &MetricsAPIInput{Enabled: true},
+			K8sAPI:     &K8SAPIInput{Enabled: true},
+		},
+	}
+	SetClusterDumpConfigDefaults(config)
+	if !config.Inputs.GKEApi.Enabled {
+		t.Errorf("GKEApi.Enabled = %v; want %v", config.Inputs.GKEApi.Enabled, true)
+	}
+	assert.ElementsMatch(t, config.Inputs.MetricsAPI.Metrics, getScalabilityMetricsDefaults(), "MetricsApi.Metrics matches scalability defaults")
+	assert.ElementsMatch(t, config.Inputs.K8sAPI.APIVersions, DefaultK8SApiVersions, "K8sApi.ApiVersions matches defaults")
+}
+
+func TestValidateClusterCheckConfig_local(t *testing.T) {
+	config := Config{
+		Policies: []ConfigPolicy{{LocalDirectory: "./directory"}},
+		DumpFile: "dump.json",
+		Inputs:   ConfigInput{Local: &LocalInput{Enabled: true, DumpFile: "dump.json"}},
+	}
+	if err := ValidateClusterCheckConfig(config); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+	config.Inputs.Local.DumpFile = ""
+	if err := ValidateClusterCheckConfig(config); err == nil {
+		t.Errorf("expected error on local input without file, got no error")
+	}
+}
+
 func assertPolicyConfigDefaults(t *testing.T, config *Config) {
 	if len(config.Policies) < 1 {
 		t.Fatalf("len of policy sources is %d; want %d", len(config.Policies), 1)
@@ -316,3 +815,60 @@ func assertPolicyConfigDefaults(t *testing.T, config *Config) {
 		t.Errorf("policy gitDirectory = %v; want %v", policySrc.GitDirectory, DefaultGitPolicyDir)
 	}
 }
+
+func TestSetRemediationConfigDefaults(t *testing.T) {
+	config := &Config{}
+	SetRemediationConfigDefaults(config)
+	assertPolicyConfigDefaults(t, config)
+	if config.Remediation.Format != RemediationFormatGcloud {
+		t.Errorf("Remediation.Format = %v; want %v", config.Remediation.Format, RemediationFormatGcloud)
+	}
+}
+
+func TestValidateRemediationConfig(t *testing.T) {
+	config := Config{
+		Clusters: []ConfigCluster{{ID: "some/cluster/id"}},
+		Policies: []ConfigPolicy{{LocalDirectory: "./directory"}},
+		Inputs: ConfigInput{
+			GKEApi: &GKEApiInput{Enabled: true},
+		},
+	}
+	badRemediations := []ConfigRemediation{
+		{},
+		{Format: "bogus"},
+		{Format: RemediationFormatTerraform, Apply: true},
+	}
+	for i, remediation := range badRemediations {
+		config.Remediation = remediation
+		if err := ValidateRemediationConfig(config); err == nil {
+			t.Errorf("expected error on invalid remediation config [%d]", i)
+		}
+	}
+	config.Remediation = ConfigRemediation{Format: RemediationFormatGcloud, Apply: true}
+	if err := ValidateRemediationConfig(config); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+}
+
+func TestValidateHistoryConfig(t *testing.T) {
+	badHistories := []ConfigHistory{
+		{},
+		{SQLite: HistorySQLite{File: "history.db"}, BigQuery: HistoryBigQuery{Project: "project", Dataset: "dataset"}},
+		{BigQuery: HistoryBigQuery{Project: "project"}},
+		{BigQuery: HistoryBigQuery{Dataset: "dataset"}},
+	}
+	for i, history := range badHistories {
+		if err := ValidateHistoryConfig(Config{History: history}); err == nil {
+			t.Errorf("expected error on invalid history config [%d]", i)
+		}
+	}
+	goodHistories := []ConfigHistory{
+		{SQLite: HistorySQLite{File: "history.db"}},
+		{BigQuery: HistoryBigQuery{Project: "project", Dataset: "dataset"}},
+	}
+	for i, history := range goodHistories {
+		if err := ValidateHistoryConfig(Config{History: history}); err != nil {
+			t.Errorf("expected no error on valid history config [%d], got: %v", i, err)
+		}
+	}
+}
@@ -18,21 +18,28 @@ import (
 	"context"
 	"fmt"
 	"regexp"
+	"strings"
 
 	asset "cloud.google.com/go/asset/apiv1"
 	"cloud.google.com/go/asset/apiv1/assetpb"
 	"github.com/google/gke-policy-automation/internal/log"
+	"github.com/google/gke-policy-automation/internal/retry"
 	"github.com/google/gke-policy-automation/internal/version"
 	gax "github.com/googleapis/gax-go/v2"
 	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 )
 
 const (
-	clusterAssetType   = "container.googleapis.com/Cluster"
-	defaultSearchLimit = 10000
+	clusterAssetType = "container.googleapis.com/Cluster"
+	// defaultSearchLimit of zero means that the number of search results is not limited
+	defaultSearchLimit = 0
 )
 
+// searchResourceDataReadMask is a read mask for Asset Inventory search including full resource data.
+var searchResourceDataReadMask = []string{"name", "assetType", "displayName", "folders", "location", "labels", "versionedResources"}
+
 type AssetInventoryClient interface {
 	SearchAllResources(ctx context.Context, req *assetpb.SearchAllResourcesRequest, opts ...gax.CallOption) *asset.ResourceSearchResultIterator
 	Close() error
@@ -49,27 +56,70 @@ type DiscoveryClient interface {
 	Close() error
 }
 
+// ClusterFoldersResolver is implemented by the discovery clients that are aware of
+// the folders the discovered clusters belong to.
+type ClusterFoldersResolver interface {
+	GetClusterFolders(clusterID string) []string
+}
+
 type AssetInventoryDiscoveryClient struct {
-	cli         AssetInventoryClient
-	ctx         context.Context
-	searchLimit int
+	cli            AssetInventoryClient
+	ctx            context.Context
+	retrier        *retry.Retrier
+	searchLimit    int
+	allowPartial   bool
+	pageSize       int32
+	filter         *ClusterFilter
+	clusterFolders map[string][]string
+}
+
+// DiscoveryOption configures the Asset Inventory discovery client.
+type DiscoveryOption func(c *AssetInventoryDiscoveryClient)
+
+// WithClusterFilter configures discovery client to return only clusters matching a given filter.
+func WithClusterFilter(filter *ClusterFilter) DiscoveryOption {
+	return func(c *AssetInventoryDiscoveryClient) {
+		c.filter = filter
+	}
 }
 
-func NewDiscoveryClient(ctx context.Context) (DiscoveryClient, error) {
-	return newAssetInventoryDiscoveryClient(ctx)
+// WithSearchPageSize configures the number of the search results fetched in a single page.
+// Page size of zero means the API default page size.
+func WithSearchPageSize(pageSize int) DiscoveryOption {
+	return func(c *AssetInventoryDiscoveryClient) {
+		c.pageSize = int32(pageSize)
+	}
 }
 
-func NewDiscoveryClientWithCredentialsFile(ctx context.Context, credentialsFile string) (DiscoveryClient, error) {
-	return newAssetInventoryDiscoveryClient(ctx, option.WithCredentialsFile(credentialsFile))
+// WithSearchLimit configures hard cap on the number of the search results. Reaching the cap
+// results in an error, unless partial results are allowed. Limit of zero means no limit.
+func WithSearchLimit(limit int, allowPartial bool) DiscoveryOption {
+	return func(c *AssetInventoryDiscoveryClient) {
+		c.searchLimit = limit
+		c.allowPartial = allowPartial
+	}
 }
 
-func newAssetInventoryDiscoveryClient(ctx context.Context, opts ...option.ClientOption) (*AssetInventoryDiscoveryClient, error) {
+func NewDiscoveryClient(ctx context.Context, retrier *retry.Retrier, discoveryOpts ...DiscoveryOption) (DiscoveryClient, error) {
+	return newAssetInventoryDiscoveryClient(ctx, retrier, discoveryOpts)
+}
+
+func NewDiscoveryClientWithCredentialsFile(ctx context.Context, credentialsFile string, retrier *retry.Retrier, discoveryOpts ...DiscoveryOption) (DiscoveryClient, error) {
+	return newAssetInventoryDiscoveryClient(ctx, retrier, discoveryOpts, option.WithCredentialsFile(credentialsFile))
+}
+
+func newAssetInventoryDiscoveryClient(ctx context.Context, retrier *retry.Retrier, discoveryOpts []DiscoveryOption, opts ...option.ClientOption) (*AssetInventoryDiscoveryClient, error) {
 	opts = append(opts, option.WithUserAgent(version.UserAgent))
+	opts = append(opts, retrier.ClientOptions()...)
 	client, err := asset.NewClient(ctx, opts...)
 	if err != nil {
 		return nil, err
 	}
-	return &AssetInventoryDiscoveryClient{ctx: ctx, cli: client, searchLimit: defaultSearchLimit}, nil
+	c := &AssetInventoryDiscoveryClient{ctx: ctx, cli: client, retrier: retrier, searchLimit: defaultSearchLimit}
+	for _, opt := range discoveryOpts {
+		opt(c)
+	}
+	return c, nil
 }
 
 // GetClustersInProject finds GKE clusters in a given GCP project (identified by name)
@@ -93,6 +143,11 @@ func (c *AssetInventoryDiscoveryClient) GetClustersInOrg(number string) ([]strin
 	return c.getClustersForScope(scope)
 }
 
+// GetClusterFolders returns numbers of all the folders a given discovered cluster belongs to.
+func (c *AssetInventoryDiscoveryClient) GetClusterFolders(clusterID string) []string {
+	return c.clusterFolders[clusterID]
+}
+
 // Close closes the client and underlying connections to other services.
 func (c *AssetInventoryDiscoveryClient) Close() error {
 	return c.cli.Close()
@@ -101,29 +156,59 @@ func (c *AssetInventoryDiscoveryClient) Close() error {
 // getClustersForScope searches for a GKE clusters in a given Asset Inventory scope
 // and returns slice with cluster identifiers.
 func (c *AssetInventoryDiscoveryClient) getClustersForScope(scope string) ([]string, error) {
+	results, err := c.clusterSearch(c.newClusterSearchRequest(scope))
+	if err != nil {
+		return nil, err
+	}
+	c.recordClusterFolders(results)
+	return filterMapSeachResults(results, c.filter), nil
+}
+
+// newClusterSearchRequest returns Asset Inventory search request for GKE clusters in a given scope.
+func (c *AssetInventoryDiscoveryClient) newClusterSearchRequest(scope string) *assetpb.SearchAllResourcesRequest {
 	req := &assetpb.SearchAllResourcesRequest{
 		Scope:      scope,
-		AssetTypes: []string{clusterAssetType}}
+		AssetTypes: []string{clusterAssetType},
+		PageSize:   c.pageSize}
+	if c.filter != nil {
+		req.Query = c.filter.searchQuery()
+		if c.filter.requiresResourceData() {
+			req.ReadMask = &fieldmaskpb.FieldMask{Paths: searchResourceDataReadMask}
+		}
+	}
+	return req
+}
 
-	results, err := c.clusterSearch(req)
-	if err != nil {
-		return nil, err
+// recordClusterFolders records the folders of the clusters from the search results.
+func (c *AssetInventoryDiscoveryClient) recordClusterFolders(results []*assetpb.ResourceSearchResult) {
+	if c.clusterFolders == nil {
+		c.clusterFolders = make(map[string][]string)
+	}
+	for _, result := range results {
+		id, err := getIDFromName(result.Name)
+		if err != nil {
+			continue
+		}
+		folders := make([]string, 0, len(result.Folders))
+		for _, folder := range result.Folders {
+			folders = append(folders, strings.TrimPrefix(folder, "folders/"))
+		}
+		c.clusterFolders[id] = folders
 	}
-	return filterMapSeachResults(results), nil
 }
 
 // clusterSearch runs asset inventory searchAllResults with a given request and iterates
 // through the results, returning them as a slice.
 func (c *AssetInventoryDiscoveryClient) clusterSearch(req *assetpb.SearchAllResourcesRequest) ([]*assetpb.ResourceSearchResult, error) {
 	log.Debugf("cluster search with request: %s", req)
-	return c.collectResourceSearchResults(c.cli.SearchAllResources(c.ctx, req))
+	return c.collectResourceSearchResults(c.cli.SearchAllResources(c.ctx, req, c.retrier.CallOptions()...))
 }
 
 // collectResourceSearchResults collects ResourceSearchResult with a given iterator.
+// The iterator fetches the consecutive result pages using the page tokens.
 func (c *AssetInventoryDiscoveryClient) collectResourceSearchResults(it AssetInventorySearchResultIterator) ([]*assetpb.ResourceSearchResult, error) {
 	results := make([]*assetpb.ResourceSearchResult, 0)
-	i := 0
-	for ; i < c.searchLimit; i++ {
+	for {
 		result, err := it.Next()
 		if err == iterator.Done {
 			log.Debugf("search iterator done")
@@ -132,24 +217,32 @@ func (c *AssetInventoryDiscoveryClient) collectResourceSearchResults(it AssetInv
 		if err != nil {
 			return nil, err
 		}
+		if c.searchLimit > 0 && len(results) == c.searchLimit {
+			if !c.allowPartial {
+				return nil, fmt.Errorf("search limit of %d was reached, discovery results are not complete", c.searchLimit)
+			}
+			log.Warnf("search limit of %d was reached, discovery results are not complete", c.searchLimit)
+			break
+		}
 		log.Debugf("search iterator result: %s", result)
 		results = append(results, result)
 	}
-	if i == c.searchLimit {
-		log.Warnf("search limit of %d was reached", c.searchLimit)
-	}
 	return results, nil
 }
 
-// filterMapSeachResults filters search results to GKE clusters, maps to
-// the cluster identifiers and returns as a slice.
-func filterMapSeachResults(results []*assetpb.ResourceSearchResult) []string {
+// filterMapSeachResults filters search results to GKE clusters matching a given
+// cluster filter, maps to the cluster identifiers and returns as a slice.
+func filterMapSeachResults(results []*assetpb.ResourceSearchResult, filter *ClusterFilter) []string {
 	identifiers := make([]string, 0, len(results))
 	for _, result := range results {
 		if result.AssetType != clusterAssetType {
 			log.Debugf("skipping search result as it is not a cluster asset type of %q", clusterAssetType)
 			continue
 		}
+		if filter != nil && !filter.Match(getSearchResultAttributes(result)) {
+			log.Debugf("skipping cluster search result %s as it does not match the cluster filter", result.Name)
+			continue
+		}
 		id, err := getIDFromName(result.Name)
 		if err != nil {
 			log.Warnf("skipping cluster asset search result due to invalid name: %s", err)
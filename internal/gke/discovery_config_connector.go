@@ -0,0 +1,160 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	ConfigConnectorClusterKind  = "ContainerCluster"
+	ConfigConnectorNodePoolKind = "ContainerNodePool"
+
+	configConnectorAPIGroup          = "container.cnrm.cloud.google.com/"
+	configConnectorProjectAnnotation = "cnrm.cloud.google.com/project-id"
+)
+
+// ConfigConnectorResource is a Config Connector KRM resource.
+type ConfigConnectorResource struct {
+	APIVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
+	Metadata   struct {
+		Name        string            `yaml:"name"`
+		Namespace   string            `yaml:"namespace"`
+		Annotations map[string]string `yaml:"annotations"`
+	} `yaml:"metadata"`
+	Spec map[string]interface{} `yaml:"spec"`
+}
+
+type configConnectorDiscoveryClient struct {
+	readDirFunc func(dir string) ([]*ConfigConnectorResource, error)
+	directory   string
+}
+
+// NewConfigConnectorDiscoveryClient returns discovery client that finds GKE clusters
+// defined by Config Connector manifests in a given directory.
+func NewConfigConnectorDiscoveryClient(directory string) DiscoveryClient {
+	return &configConnectorDiscoveryClient{
+		readDirFunc: ReadConfigConnectorResources,
+		directory:   directory,
+	}
+}
+
+func (c *configConnectorDiscoveryClient) GetClustersInProject(name string) ([]string, error) {
+	return c.getClusters()
+}
+
+func (c *configConnectorDiscoveryClient) GetClustersInFolder(number string) ([]string, error) {
+	return c.getClusters()
+}
+
+func (c *configConnectorDiscoveryClient) GetClustersInOrg(number string) ([]string, error) {
+	return c.getClusters()
+}
+
+func (c *configConnectorDiscoveryClient) Close() error {
+	return nil
+}
+
+func (c *configConnectorDiscoveryClient) getClusters() ([]string, error) {
+	resources, err := c.readDirFunc(c.directory)
+	if err != nil {
+		return nil, err
+	}
+	clusters := make([]string, 0)
+	for _, r := range resources {
+		if r.Kind == ConfigConnectorClusterKind {
+			clusters = append(clusters, r.GetClusterID())
+		}
+	}
+	return clusters, nil
+}
+
+// ReadConfigConnectorResources reads GKE Config Connector resources from YAML files
+// in a given directory and its subdirectories. Other resources are skipped.
+func ReadConfigConnectorResources(directory string) ([]*ConfigConnectorResource, error) {
+	resources := make([]*ConfigConnectorResource, 0)
+	err := filepath.WalkDir(directory, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		ext := filepath.Ext(path)
+		if d.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		fileResources, err := parseConfigConnectorResources(data)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		resources = append(resources, fileResources...)
+		return nil
+	})
+	return resources, err
+}
+
+func parseConfigConnectorResources(data []byte) ([]*ConfigConnectorResource, error) {
+	resources := make([]*ConfigConnectorResource, 0)
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var r ConfigConnectorResource
+		err := decoder.Decode(&r)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(r.APIVersion, configConnectorAPIGroup) {
+			continue
+		}
+		if r.Kind == ConfigConnectorClusterKind || r.Kind == ConfigConnectorNodePoolKind {
+			resources = append(resources, &r)
+		}
+	}
+	return resources, nil
+}
+
+// GetClusterID returns the identifier of a cluster defined by a ContainerCluster resource.
+// The project is taken from the project-id annotation or, if not set, from the namespace.
+func (r *ConfigConnectorResource) GetClusterID() string {
+	project := r.Metadata.Annotations[configConnectorProjectAnnotation]
+	if project == "" {
+		project = r.Metadata.Namespace
+	}
+	location, _ := r.Spec["location"].(string)
+	return GetOfflineClusterID(project, location, r.GetResourceName())
+}
+
+// GetResourceName returns the name of a GCP resource defined by a given resource. The name
+// is taken from the resourceID field or, if not set, from the resource metadata.
+func (r *ConfigConnectorResource) GetResourceName() string {
+	if name, ok := r.Spec["resourceID"].(string); ok && name != "" {
+		return name
+	}
+	return r.Metadata.Name
+}
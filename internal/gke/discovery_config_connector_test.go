@@ -0,0 +1,52 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"reflect"
+	"testing"
+)
+
+const configConnectorTestDir = "test-fixtures/config-connector"
+
+func TestReadConfigConnectorResources(t *testing.T) {
+	resources, err := ReadConfigConnectorResources(configConnectorTestDir)
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	kinds := make([]string, 0, len(resources))
+	for _, r := range resources {
+		kinds = append(kinds, r.Kind)
+	}
+	expected := []string{"ContainerCluster", "ContainerNodePool", "ContainerCluster"}
+	if !reflect.DeepEqual(kinds, expected) {
+		t.Errorf("resource kinds = %v; want %v", kinds, expected)
+	}
+}
+
+func TestConfigConnectorDiscoveryClientGetClusters(t *testing.T) {
+	client := NewConfigConnectorDiscoveryClient(configConnectorTestDir)
+	clusters, err := client.GetClustersInOrg("any")
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	expected := []string{
+		"projects/my-project/locations/europe-west2/clusters/cluster-one",
+		"projects/other-project/locations/us-central1/clusters/cluster-two",
+	}
+	if !reflect.DeepEqual(clusters, expected) {
+		t.Errorf("clusters = %v; want %v", clusters, expected)
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"cloud.google.com/go/asset/apiv1/assetpb"
+)
+
+const (
+	ClusterModeAutopilot = "autopilot"
+	ClusterModeStandard  = "standard"
+
+	releaseChannelUnspecified = "UNSPECIFIED"
+)
+
+// ClusterFilter defines criteria for filtering of the discovered clusters.
+// Empty criteria match all clusters.
+type ClusterFilter struct {
+	IncludeLabels   map[string]string
+	ExcludeLabels   map[string]string
+	Location        *regexp.Regexp
+	Name            *regexp.Regexp
+	Mode            string
+	ReleaseChannels []string
+}
+
+// ClusterAttributes are cluster attributes the cluster filter is applied on.
+type ClusterAttributes struct {
+	Name           string
+	Location       string
+	Labels         map[string]string
+	Autopilot      bool
+	ReleaseChannel string
+}
+
+// Match checks if cluster with given attributes matches all filter criteria.
+func (f *ClusterFilter) Match(attrs *ClusterAttributes) bool {
+	for k, v := range f.IncludeLabels {
+		if value, ok := attrs.Labels[k]; !ok || value != v {
+			return false
+		}
+	}
+	for k, v := range f.ExcludeLabels {
+		if value, ok := attrs.Labels[k]; ok && value == v {
+			return false
+		}
+	}
+	if f.Location != nil && !f.Location.MatchString(attrs.Location) {
+		return false
+	}
+	if f.Name != nil && !f.Name.MatchString(attrs.Name) {
+		return false
+	}
+	switch f.Mode {
+	case ClusterModeAutopilot:
+		if !attrs.Autopilot {
+			return false
+		}
+	case ClusterModeStandard:
+		if attrs.Autopilot {
+			return false
+		}
+	}
+	if len(f.ReleaseChannels) > 0 {
+		releaseChannel := attrs.ReleaseChannel
+		if releaseChannel == "" {
+			releaseChannel = releaseChannelUnspecified
+		}
+		found := false
+		for _, channel := range f.ReleaseChannels {
+			if strings.EqualFold(channel, releaseChannel) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// requiresResourceData checks if filter criteria require full cluster resource data
+// that is not returned by Asset Inventory search by default.
+func (f *ClusterFilter) requiresResourceData() bool {
+	return f.Mode != "" || len(f.ReleaseChannels) > 0
+}
+
+// searchQuery returns Asset Inventory search query for the filter criteria that
+// are supported by the search, i.e. included labels.
+func (f *ClusterFilter) searchQuery() string {
+	keys := make([]string, 0, len(f.IncludeLabels))
+	for k := range f.IncludeLabels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	terms := make([]string, 0, len(keys))
+	for _, k := range keys {
+		terms = append(terms, fmt.Sprintf("labels.%s=%q", k, f.IncludeLabels[k]))
+	}
+	return strings.Join(terms, " AND ")
+}
+
+// getSearchResultAttributes returns cluster attributes from the Asset Inventory search result.
+func getSearchResultAttributes(result *assetpb.ResourceSearchResult) *ClusterAttributes {
+	attrs := &ClusterAttributes{
+		Name:     result.DisplayName,
+		Location: result.Location,
+		Labels:   result.Labels,
+	}
+	if attrs.Name == "" {
+		attrs.Name = result.Name[strings.LastIndex(result.Name, "/")+1:]
+	}
+	for _, vr := range result.VersionedResources {
+		resource := vr.GetResource().GetFields()
+		if resource == nil {
+			continue
+		}
+		if autopilot := resource["autopilot"].GetStructValue().GetFields(); autopilot != nil {
+			attrs.Autopilot = autopilot["enabled"].GetBoolValue()
+		}
+		if channel := resource["releaseChannel"].GetStructValue().GetFields(); channel != nil {
+			attrs.ReleaseChannel = channel["channel"].GetStringValue()
+		}
+	}
+	return attrs
+}
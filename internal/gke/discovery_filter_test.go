@@ -0,0 +1,128 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"cloud.google.com/go/asset/apiv1/assetpb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestClusterFilterMatch(t *testing.T) {
+	attrs := &ClusterAttributes{
+		Name:           "prod-cluster",
+		Location:       "europe-west2",
+		Labels:         map[string]string{"env": "prod", "team": "core"},
+		Autopilot:      true,
+		ReleaseChannel: "REGULAR",
+	}
+	filters := map[string]struct {
+		filter *ClusterFilter
+		want   bool
+	}{
+		"empty":              {&ClusterFilter{}, true},
+		"include labels":     {&ClusterFilter{IncludeLabels: map[string]string{"env": "prod"}}, true},
+		"include labels no":  {&ClusterFilter{IncludeLabels: map[string]string{"env": "dev"}}, false},
+		"exclude labels":     {&ClusterFilter{ExcludeLabels: map[string]string{"env": "dev"}}, true},
+		"exclude labels no":  {&ClusterFilter{ExcludeLabels: map[string]string{"team": "core"}}, false},
+		"location":           {&ClusterFilter{Location: regexp.MustCompile("^europe-")}, true},
+		"location no":        {&ClusterFilter{Location: regexp.MustCompile("^us-")}, false},
+		"name":               {&ClusterFilter{Name: regexp.MustCompile("^prod-")}, true},
+		"name no":            {&ClusterFilter{Name: regexp.MustCompile("^dev-")}, false},
+		"autopilot":          {&ClusterFilter{Mode: ClusterModeAutopilot}, true},
+		"standard":           {&ClusterFilter{Mode: ClusterModeStandard}, false},
+		"release channel":    {&ClusterFilter{ReleaseChannels: []string{"stable", "regular"}}, true},
+		"release channel no": {&ClusterFilter{ReleaseChannels: []string{"RAPID"}}, false},
+	}
+	for name, f := range filters {
+		if got := f.filter.Match(attrs); got != f.want {
+			t.Errorf("filter %q match = %v; want %v", name, got, f.want)
+		}
+	}
+}
+
+func TestClusterFilterMatch_unspecifiedReleaseChannel(t *testing.T) {
+	filter := &ClusterFilter{ReleaseChannels: []string{"UNSPECIFIED"}}
+	if !filter.Match(&ClusterAttributes{}) {
+		t.Errorf("filter match = false; want true")
+	}
+}
+
+func TestClusterFilterSearchQuery(t *testing.T) {
+	filter := &ClusterFilter{IncludeLabels: map[string]string{"team": "core", "env": "prod"}}
+	expected := `labels.env="prod" AND labels.team="core"`
+	if query := filter.searchQuery(); query != expected {
+		t.Errorf("search query = %v; want %v", query, expected)
+	}
+}
+
+func TestGetSearchResultAttributes(t *testing.T) {
+	resource, err := structpb.NewStruct(map[string]interface{}{
+		"autopilot":      map[string]interface{}{"enabled": true},
+		"releaseChannel": map[string]interface{}{"channel": "STABLE"},
+	})
+	if err != nil {
+		t.Fatalf("err is not nil; want nil; err = %s", err)
+	}
+	result := &assetpb.ResourceSearchResult{
+		Name:               "//container.googleapis.com/projects/my-project/locations/europe-west2/clusters/my-cluster",
+		Location:           "europe-west2",
+		Labels:             map[string]string{"env": "prod"},
+		VersionedResources: []*assetpb.VersionedResource{{Version: "v1", Resource: resource}},
+	}
+	attrs := getSearchResultAttributes(result)
+	if attrs.Name != "my-cluster" {
+		t.Errorf("name = %v; want %v", attrs.Name, "my-cluster")
+	}
+	if attrs.Location != "europe-west2" {
+		t.Errorf("location = %v; want %v", attrs.Location, "europe-west2")
+	}
+	if !attrs.Autopilot {
+		t.Errorf("autopilot = %v; want %v", attrs.Autopilot, true)
+	}
+	if attrs.ReleaseChannel != "STABLE" {
+		t.Errorf("release channel = %v; want %v", attrs.ReleaseChannel, "STABLE")
+	}
+}
+
+func TestNewClusterSearchRequest_filter(t *testing.T) {
+	filter := &ClusterFilter{IncludeLabels: map[string]string{"env": "prod"}, Mode: ClusterModeStandard}
+	client := AssetInventoryDiscoveryClient{filter: filter}
+	req := client.newClusterSearchRequest("projects/my-project")
+	if req.Query != filter.searchQuery() {
+		t.Fatalf("query in request = %v; want %v", req.Query, filter.searchQuery())
+	}
+	if req.ReadMask == nil {
+		t.Fatalf("read mask in request is nil; want read mask")
+	}
+}
+
+func TestFilterMapSeachResults_filter(t *testing.T) {
+	id := "projects/my-project/locations/europe-west2/clusters/prod-cluster"
+	results := []*assetpb.ResourceSearchResult{
+		{Name: fmt.Sprintf("//container.googleapis.com/%s", id), AssetType: clusterAssetType, Labels: map[string]string{"env": "prod"}},
+		{Name: "//container.googleapis.com/projects/my-project/locations/europe-west2/clusters/dev-cluster", AssetType: clusterAssetType, Labels: map[string]string{"env": "dev"}},
+	}
+	ids := filterMapSeachResults(results, &ClusterFilter{IncludeLabels: map[string]string{"env": "prod"}})
+	if len(ids) != 1 {
+		t.Fatalf("number of cluster identifiers = %v; want %v", len(ids), 1)
+	}
+	if ids[0] != id {
+		t.Errorf("cluster identifier [0] = %v; want %v", ids[0], id)
+	}
+}
@@ -0,0 +1,245 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	container "cloud.google.com/go/container/apiv1"
+	"cloud.google.com/go/container/apiv1/containerpb"
+	"github.com/google/gke-policy-automation/internal/log"
+	"github.com/google/gke-policy-automation/internal/retry"
+	"github.com/google/gke-policy-automation/internal/version"
+	gax "github.com/googleapis/gax-go/v2"
+	"google.golang.org/api/cloudresourcemanager/v3"
+	"google.golang.org/api/option"
+)
+
+const activeResourceState = "ACTIVE"
+
+type ClusterManagerClient interface {
+	ListClusters(ctx context.Context, req *containerpb.ListClustersRequest, opts ...gax.CallOption) (*containerpb.ListClustersResponse, error)
+	Close() error
+}
+
+// ResourceManagerClient lists active projects and folders under a given parent resource.
+type ResourceManagerClient interface {
+	ListProjects(ctx context.Context, parent string) ([]string, error)
+	ListFolders(ctx context.Context, parent string) ([]string, error)
+}
+
+// GKEDiscoveryClient discovers clusters with the GKE API ListClusters calls. Projects in
+// folders and organizations are found with the Resource Manager API.
+type GKEDiscoveryClient struct {
+	ctx            context.Context
+	cli            ClusterManagerClient
+	retrier        *retry.Retrier
+	rm             ResourceManagerClient
+	filter         *ClusterFilter
+	clusterFolders map[string][]string
+}
+
+// NewGKEDiscoveryClient returns the GKE API discovery client. The retriers of the GKE and
+// the Resource Manager APIs are applied to the calls of the respective APIs.
+func NewGKEDiscoveryClient(ctx context.Context, filter *ClusterFilter, gkeRetrier, rmRetrier *retry.Retrier) (DiscoveryClient, error) {
+	return newGKEDiscoveryClient(ctx, filter, gkeRetrier, rmRetrier)
+}
+
+func NewGKEDiscoveryClientWithCredentialsFile(ctx context.Context, credentialsFile string, filter *ClusterFilter, gkeRetrier, rmRetrier *retry.Retrier) (DiscoveryClient, error) {
+	return newGKEDiscoveryClient(ctx, filter, gkeRetrier, rmRetrier, option.WithCredentialsFile(credentialsFile))
+}
+
+func newGKEDiscoveryClient(ctx context.Context, filter *ClusterFilter, gkeRetrier, rmRetrier *retry.Retrier, opts ...option.ClientOption) (*GKEDiscoveryClient, error) {
+	opts = append(opts, option.WithUserAgent(version.UserAgent))
+	cli, err := container.NewClusterManagerClient(ctx, append(opts, gkeRetrier.ClientOptions()...)...)
+	if err != nil {
+		return nil, err
+	}
+	rm, err := newResourceManagerClient(ctx, rmRetrier, opts...)
+	if err != nil {
+		cli.Close()
+		return nil, err
+	}
+	return &GKEDiscoveryClient{ctx: ctx, cli: cli, retrier: gkeRetrier, rm: rm, filter: filter}, nil
+}
+
+// GetClustersInProject finds GKE clusters in a given GCP project (identified by name)
+// and returns slice with their identifiers.
+func (c *GKEDiscoveryClient) GetClustersInProject(name string) ([]string, error) {
+	return c.getClustersInProject(name, nil)
+}
+
+// GetClustersInFolder finds GKE clusters in all projects in a given GCP folder (identified by number)
+// and its subfolders and returns slice with their identifiers.
+func (c *GKEDiscoveryClient) GetClustersInFolder(number string) ([]string, error) {
+	return c.getClustersInParent(fmt.Sprintf("folders/%s", number), []string{number})
+}
+
+// GetClustersInOrg finds GKE clusters in all projects in a given GCP organization (identified by number)
+// and its folders and returns slice with their identifiers.
+func (c *GKEDiscoveryClient) GetClustersInOrg(number string) ([]string, error) {
+	return c.getClustersInParent(fmt.Sprintf("organizations/%s", number), nil)
+}
+
+// GetClusterFolders returns numbers of all the folders a given discovered cluster belongs to.
+func (c *GKEDiscoveryClient) GetClusterFolders(clusterID string) []string {
+	return c.clusterFolders[clusterID]
+}
+
+// Close closes the client and underlying connections to other services.
+func (c *GKEDiscoveryClient) Close() error {
+	return c.cli.Close()
+}
+
+// getClustersInParent finds GKE clusters in the projects of a given parent resource
+// and, recursively, in the projects of its folders. Projects where the clusters could
+// not be listed are skipped, so a single project does not fail the whole discovery.
+func (c *GKEDiscoveryClient) getClustersInParent(parent string, folders []string) ([]string, error) {
+	projects, err := c.rm.ListProjects(c.ctx, parent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projects in %s: %w", parent, err)
+	}
+	clusters := make([]string, 0)
+	for _, project := range projects {
+		results, err := c.getClustersInProject(project, folders)
+		if err != nil {
+			log.Warnf("skipping project %s in %s: %s", project, parent, err)
+			continue
+		}
+		clusters = append(clusters, results...)
+	}
+	subfolders, err := c.rm.ListFolders(c.ctx, parent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list folders in %s: %w", parent, err)
+	}
+	for _, folder := range subfolders {
+		number := strings.TrimPrefix(folder, "folders/")
+		results, err := c.getClustersInParent(folder, append(folders[:len(folders):len(folders)], number))
+		if err != nil {
+			return nil, err
+		}
+		clusters = append(clusters, results...)
+	}
+	return clusters, nil
+}
+
+// getClustersInProject lists GKE clusters in all locations of a given project and returns
+// identifiers of the clusters matching the cluster filter.
+func (c *GKEDiscoveryClient) getClustersInProject(project string, folders []string) ([]string, error) {
+	req := &containerpb.ListClustersRequest{
+		Parent: fmt.Sprintf("projects/%s/locations/-", project)}
+	log.Debugf("listing clusters with request: %s", req)
+	resp, err := c.cli.ListClusters(c.ctx, req, c.retrier.CallOptions()...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list clusters in project %s: %w", project, err)
+	}
+	if len(resp.MissingZones) > 0 {
+		log.Warnf("clusters in project %s could not be listed in zones: %s", project, strings.Join(resp.MissingZones, ", "))
+	}
+	if c.clusterFolders == nil {
+		c.clusterFolders = make(map[string][]string)
+	}
+	clusters := make([]string, 0, len(resp.Clusters))
+	for _, cluster := range resp.Clusters {
+		if c.filter != nil && !c.filter.Match(getClusterAttributes(cluster)) {
+			log.Debugf("skipping cluster %s as it does not match the cluster filter", cluster.Name)
+			continue
+		}
+		id := GetClusterID(project, cluster.Location, cluster.Name)
+		c.clusterFolders[id] = folders
+		clusters = append(clusters, id)
+	}
+	return clusters, nil
+}
+
+// getClusterAttributes returns cluster attributes from the GKE API cluster.
+func getClusterAttributes(cluster *containerpb.Cluster) *ClusterAttributes {
+	attrs := &ClusterAttributes{
+		Name:      cluster.Name,
+		Location:  cluster.Location,
+		Labels:    cluster.ResourceLabels,
+		Autopilot: cluster.GetAutopilot().GetEnabled(),
+	}
+	if channel := cluster.GetReleaseChannel().GetChannel(); channel != containerpb.ReleaseChannel_UNSPECIFIED {
+		attrs.ReleaseChannel = channel.String()
+	}
+	return attrs
+}
+
+// resourceManagerClient implements ResourceManagerClient with the Resource Manager v3 API.
+type resourceManagerClient struct {
+	svc     *cloudresourcemanager.Service
+	retrier *retry.Retrier
+}
+
+func newResourceManagerClient(ctx context.Context, retrier *retry.Retrier, opts ...option.ClientOption) (ResourceManagerClient, error) {
+	svc, err := cloudresourcemanager.NewService(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &resourceManagerClient{svc: svc, retrier: retrier}, nil
+}
+
+// ListProjects returns identifiers of the active projects that are direct children of a given parent.
+func (c *resourceManagerClient) ListProjects(ctx context.Context, parent string) ([]string, error) {
+	projects := make([]string, 0)
+	pageToken := ""
+	for {
+		var resp *cloudresourcemanager.ListProjectsResponse
+		err := c.retrier.Do(ctx, "projects.list", func(ctx context.Context) error {
+			var err error
+			resp, err = c.svc.Projects.List().Parent(parent).PageToken(pageToken).Context(ctx).Do()
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, project := range resp.Projects {
+			if project.State == activeResourceState {
+				projects = append(projects, project.ProjectId)
+			}
+		}
+		if pageToken = resp.NextPageToken; pageToken == "" {
+			return projects, nil
+		}
+	}
+}
+
+// ListFolders returns names of the active folders that are direct children of a given parent.
+func (c *resourceManagerClient) ListFolders(ctx context.Context, parent string) ([]string, error) {
+	folders := make([]string, 0)
+	pageToken := ""
+	for {
+		var resp *cloudresourcemanager.ListFoldersResponse
+		err := c.retrier.Do(ctx, "folders.list", func(ctx context.Context) error {
+			var err error
+			resp, err = c.svc.Folders.List().Parent(parent).PageToken(pageToken).Context(ctx).Do()
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, folder := range resp.Folders {
+			if folder.State == activeResourceState {
+				folders = append(folders, folder.Name)
+			}
+		}
+		if pageToken = resp.NextPageToken; pageToken == "" {
+			return folders, nil
+		}
+	}
+}
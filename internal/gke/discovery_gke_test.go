@@ -0,0 +1,207 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/container/apiv1/containerpb"
+	gax "github.com/googleapis/gax-go/v2"
+)
+
+type clusterManagerClientMock struct {
+	listClustersFn func(ctx context.Context, req *containerpb.ListClustersRequest, opts ...gax.CallOption) (*containerpb.ListClustersResponse, error)
+}
+
+func (m clusterManagerClientMock) ListClusters(ctx context.Context, req *containerpb.ListClustersRequest, opts ...gax.CallOption) (*containerpb.ListClustersResponse, error) {
+	return m.listClustersFn(ctx, req, opts...)
+}
+
+func (m clusterManagerClientMock) Close() error {
+	return nil
+}
+
+type resourceManagerClientMock struct {
+	projects map[string][]string
+	folders  map[string][]string
+}
+
+func (m resourceManagerClientMock) ListProjects(ctx context.Context, parent string) ([]string, error) {
+	return m.projects[parent], nil
+}
+
+func (m resourceManagerClientMock) ListFolders(ctx context.Context, parent string) ([]string, error) {
+	return m.folders[parent], nil
+}
+
+func newClusterManagerClientMock(clusters map[string][]*containerpb.Cluster) clusterManagerClientMock {
+	return clusterManagerClientMock{
+		listClustersFn: func(ctx context.Context, req *containerpb.ListClustersRequest, opts ...gax.CallOption) (*containerpb.ListClustersResponse, error) {
+			project := strings.Split(req.Parent, "/")[1]
+			if !strings.HasSuffix(req.Parent, "/locations/-") {
+				return nil, fmt.Errorf("unexpected parent %s", req.Parent)
+			}
+			return &containerpb.ListClustersResponse{Clusters: clusters[project]}, nil
+		},
+	}
+}
+
+func TestGKEDiscoveryClientGetClustersInProject(t *testing.T) {
+	client := GKEDiscoveryClient{
+		ctx: context.Background(),
+		cli: newClusterManagerClientMock(map[string][]*containerpb.Cluster{
+			"project-one": {
+				{Name: "prod", Location: "europe-west2", ResourceLabels: map[string]string{"env": "prod"}},
+				{Name: "dev", Location: "europe-west2-a", ResourceLabels: map[string]string{"env": "dev"}},
+			},
+		}),
+		filter: &ClusterFilter{ExcludeLabels: map[string]string{"env": "dev"}},
+	}
+	clusters, err := client.GetClustersInProject("project-one")
+	if err != nil {
+		t.Fatalf("err is not nil; want nil; err = %s", err)
+	}
+	expected := []string{"projects/project-one/locations/europe-west2/clusters/prod"}
+	if !reflect.DeepEqual(clusters, expected) {
+		t.Errorf("clusters = %v; want %v", clusters, expected)
+	}
+}
+
+func TestGKEDiscoveryClientGetClustersInFolder(t *testing.T) {
+	client := GKEDiscoveryClient{
+		ctx: context.Background(),
+		cli: newClusterManagerClientMock(map[string][]*containerpb.Cluster{
+			"project-one": {{Name: "one", Location: "europe-west2"}},
+			"project-two": {{Name: "two", Location: "us-central1"}},
+		}),
+		rm: resourceManagerClientMock{
+			projects: map[string][]string{
+				"folders/123": {"project-one"},
+				"folders/456": {"project-two"},
+			},
+			folders: map[string][]string{
+				"folders/123": {"folders/456"},
+			},
+		},
+	}
+	clusters, err := client.GetClustersInFolder("123")
+	if err != nil {
+		t.Fatalf("err is not nil; want nil; err = %s", err)
+	}
+	expected := []string{
+		"projects/project-one/locations/europe-west2/clusters/one",
+		"projects/project-two/locations/us-central1/clusters/two",
+	}
+	if !reflect.DeepEqual(clusters, expected) {
+		t.Errorf("clusters = %v; want %v", clusters, expected)
+	}
+	if folders := client.GetClusterFolders(expected[1]); !reflect.DeepEqual(folders, []string{"123", "456"}) {
+		t.Errorf("cluster folders = %v; want %v", folders, []string{"123", "456"})
+	}
+}
+
+func TestGKEDiscoveryClientGetClustersInOrg(t *testing.T) {
+	client := GKEDiscoveryClient{
+		ctx: context.Background(),
+		cli: newClusterManagerClientMock(map[string][]*containerpb.Cluster{
+			"project-one": {{Name: "one", Location: "europe-west2"}},
+		}),
+		rm: resourceManagerClientMock{
+			projects: map[string][]string{"organizations/789": {"project-one"}},
+		},
+	}
+	clusters, err := client.GetClustersInOrg("789")
+	if err != nil {
+		t.Fatalf("err is not nil; want nil; err = %s", err)
+	}
+	if len(clusters) != 1 {
+		t.Errorf("number of clusters = %v; want %v", len(clusters), 1)
+	}
+}
+
+func TestGKEDiscoveryClientGetClustersInProject_negative(t *testing.T) {
+	client := GKEDiscoveryClient{
+		ctx: context.Background(),
+		cli: clusterManagerClientMock{
+			listClustersFn: func(ctx context.Context, req *containerpb.ListClustersRequest, opts ...gax.CallOption) (*containerpb.ListClustersResponse, error) {
+				return nil, fmt.Errorf("permission denied")
+			},
+		},
+	}
+	if _, err := client.GetClustersInProject("project-one"); err == nil {
+		t.Errorf("err is nil; want error")
+	}
+}
+
+func TestGKEDiscoveryClientGetClustersInFolder_failingProject(t *testing.T) {
+	clusters := newClusterManagerClientMock(map[string][]*containerpb.Cluster{
+		"project-one":   {{Name: "one", Location: "europe-west2"}},
+		"project-three": {{Name: "three", Location: "us-central1"}},
+	})
+	client := GKEDiscoveryClient{
+		ctx: context.Background(),
+		cli: clusterManagerClientMock{
+			listClustersFn: func(ctx context.Context, req *containerpb.ListClustersRequest, opts ...gax.CallOption) (*containerpb.ListClustersResponse, error) {
+				if req.Parent == "projects/project-two/locations/-" {
+					return nil, fmt.Errorf("permission denied")
+				}
+				return clusters.ListClusters(ctx, req, opts...)
+			},
+		},
+		rm: resourceManagerClientMock{
+			projects: map[string][]string{
+				"folders/123": {"project-one", "project-two", "project-three"},
+			},
+		},
+	}
+	result, err := client.GetClustersInFolder("123")
+	if err != nil {
+		t.Fatalf("err is not nil; want nil; err = %s", err)
+	}
+	expected := []string{
+		"projects/project-one/locations/europe-west2/clusters/one",
+		"projects/project-three/locations/us-central1/clusters/three",
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("clusters = %v; want %v", result, expected)
+	}
+}
+
+func TestGetClusterAttributes(t *testing.T) {
+	cluster := &containerpb.Cluster{
+		Name:           "cluster",
+		Location:       "europe-west2",
+		ResourceLabels: map[string]string{"env": "prod"},
+		Autopilot:      &containerpb.Autopilot{Enabled: true},
+		ReleaseChannel: &containerpb.ReleaseChannel{Channel: containerpb.ReleaseChannel_STABLE},
+	}
+	expected := &ClusterAttributes{
+		Name:           "cluster",
+		Location:       "europe-west2",
+		Labels:         map[string]string{"env": "prod"},
+		Autopilot:      true,
+		ReleaseChannel: "STABLE",
+	}
+	if attrs := getClusterAttributes(cluster); !reflect.DeepEqual(attrs, expected) {
+		t.Errorf("attributes = %v; want %v", attrs, expected)
+	}
+	if attrs := getClusterAttributes(&containerpb.Cluster{}); attrs.ReleaseChannel != "" {
+		t.Errorf("release channel = %v; want empty", attrs.ReleaseChannel)
+	}
+}
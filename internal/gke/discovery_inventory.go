@@ -0,0 +1,299 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	InventoryFormatCSV  = "csv"
+	InventoryFormatYAML = "yaml"
+
+	// InventoryStdin is the inventory file name for reading the inventory from the standard input.
+	InventoryStdin = "-"
+)
+
+// InventoryCluster is a cluster entry of the cluster inventory.
+type InventoryCluster struct {
+	ID      string
+	Labels  map[string]string
+	Profile string
+}
+
+// ClusterInventoryResolver is implemented by the discovery clients that have inventory
+// data of the discovered clusters, like labels or policy profile names.
+type ClusterInventoryResolver interface {
+	GetInventoryCluster(clusterID string) (*InventoryCluster, bool)
+}
+
+// inventoryYAMLCluster is a cluster entry of a YAML inventory. The selfLink and resourceLabels
+// fields allow to use the output of gcloud container clusters list with a YAML format.
+type inventoryYAMLCluster struct {
+	ID             string            `yaml:"id"`
+	SelfLink       string            `yaml:"selfLink"`
+	Labels         map[string]string `yaml:"labels"`
+	ResourceLabels map[string]string `yaml:"resourceLabels"`
+	Profile        string            `yaml:"profile"`
+}
+
+type inventoryDiscoveryClient struct {
+	readFileFunc func(name string) ([]byte, error)
+	stdin        io.Reader
+	filename     string
+	format       string
+	clusters     map[string]*InventoryCluster
+}
+
+// NewInventoryDiscoveryClient returns discovery client that reads clusters from a given
+// CSV or YAML inventory file or from the standard input when the file name is "-".
+// The format is detected from the file extension when not given.
+func NewInventoryDiscoveryClient(filename string, format string) DiscoveryClient {
+	return &inventoryDiscoveryClient{
+		readFileFunc: os.ReadFile,
+		stdin:        os.Stdin,
+		filename:     filename,
+		format:       format,
+	}
+}
+
+func (c *inventoryDiscoveryClient) Close() error {
+	return nil
+}
+
+func (c *inventoryDiscoveryClient) GetClustersInFolder(number string) ([]string, error) {
+	return c.getClusters()
+}
+
+func (c *inventoryDiscoveryClient) GetClustersInOrg(number string) ([]string, error) {
+	return c.getClusters()
+}
+
+func (c *inventoryDiscoveryClient) GetClustersInProject(name string) ([]string, error) {
+	return c.getClusters()
+}
+
+func (c *inventoryDiscoveryClient) GetInventoryCluster(clusterID string) (*InventoryCluster, bool) {
+	cluster, ok := c.clusters[clusterID]
+	return cluster, ok
+}
+
+func (c *inventoryDiscoveryClient) getClusters() ([]string, error) {
+	data, err := c.readInventory()
+	if err != nil {
+		return nil, err
+	}
+	var clusters []*InventoryCluster
+	switch c.getFormat() {
+	case InventoryFormatCSV:
+		clusters, err = parseCSVInventory(data)
+	case InventoryFormatYAML:
+		clusters, err = parseYAMLInventory(data)
+	default:
+		err = fmt.Errorf("unsupported inventory format %q", c.format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.clusters = make(map[string]*InventoryCluster, len(clusters))
+	ids := make([]string, 0, len(clusters))
+	for _, cluster := range clusters {
+		if _, ok := c.clusters[cluster.ID]; ok {
+			continue
+		}
+		c.clusters[cluster.ID] = cluster
+		ids = append(ids, cluster.ID)
+	}
+	return ids, nil
+}
+
+func (c *inventoryDiscoveryClient) readInventory() ([]byte, error) {
+	if c.filename == InventoryStdin {
+		return io.ReadAll(c.stdin)
+	}
+	return c.readFileFunc(c.filename)
+}
+
+// getFormat returns configured inventory format or the one matching inventory file extension.
+// The CSV format is used by default.
+func (c *inventoryDiscoveryClient) getFormat() string {
+	if c.format != "" {
+		return strings.ToLower(c.format)
+	}
+	switch strings.ToLower(filepath.Ext(c.filename)) {
+	case ".yaml", ".yml":
+		return InventoryFormatYAML
+	default:
+		return InventoryFormatCSV
+	}
+}
+
+// parseCSVInventory parses CSV inventory with the id, labels and profile columns. The header
+// row is optional, without it the columns are taken in that order. Labels are given as
+// semicolon separated key=value pairs, the same way as gcloud CSV format prints them.
+func parseCSVInventory(data []byte) ([]*InventoryCluster, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	idCol, labelsCol, profileCol := 0, 1, 2
+	if len(records) > 0 && isCSVInventoryHeader(records[0]) {
+		idCol, labelsCol, profileCol = -1, -1, -1
+		for i, name := range records[0] {
+			switch strings.ToLower(strings.TrimSpace(name)) {
+			case "id", "selflink":
+				idCol = i
+			case "labels", "resourcelabels":
+				labelsCol = i
+			case "profile":
+				profileCol = i
+			}
+		}
+		records = records[1:]
+	}
+	clusters := make([]*InventoryCluster, 0, len(records))
+	for i, record := range records {
+		if len(record) == 0 || (len(record) == 1 && strings.TrimSpace(record[0]) == "") {
+			continue
+		}
+		id, err := normalizeInventoryClusterID(getCSVField(record, idCol))
+		if err != nil {
+			return nil, fmt.Errorf("inventory record [%d]: %w", i, err)
+		}
+		labels, err := parseInventoryLabels(getCSVField(record, labelsCol))
+		if err != nil {
+			return nil, fmt.Errorf("inventory record [%d]: %w", i, err)
+		}
+		clusters = append(clusters, &InventoryCluster{
+			ID:      id,
+			Labels:  labels,
+			Profile: getCSVField(record, profileCol),
+		})
+	}
+	return clusters, nil
+}
+
+// parseYAMLInventory parses YAML inventory with a list of clusters, or a stream of YAML
+// documents with a cluster each.
+func parseYAMLInventory(data []byte) ([]*InventoryCluster, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	clusters := make([]*InventoryCluster, 0)
+	for {
+		var node yaml.Node
+		if err := decoder.Decode(&node); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		var entries []inventoryYAMLCluster
+		if len(node.Content) > 0 && node.Content[0].Kind == yaml.SequenceNode {
+			if err := node.Decode(&entries); err != nil {
+				return nil, err
+			}
+		} else {
+			var entry inventoryYAMLCluster
+			if err := node.Decode(&entry); err != nil {
+				return nil, err
+			}
+			entries = append(entries, entry)
+		}
+		for _, entry := range entries {
+			rawID := entry.ID
+			if rawID == "" {
+				rawID = entry.SelfLink
+			}
+			id, err := normalizeInventoryClusterID(rawID)
+			if err != nil {
+				return nil, fmt.Errorf("inventory cluster [%d]: %w", len(clusters), err)
+			}
+			labels := entry.Labels
+			if labels == nil {
+				labels = entry.ResourceLabels
+			}
+			clusters = append(clusters, &InventoryCluster{
+				ID:      id,
+				Labels:  labels,
+				Profile: entry.Profile,
+			})
+		}
+	}
+	return clusters, nil
+}
+
+func isCSVInventoryHeader(record []string) bool {
+	for _, field := range record {
+		switch strings.ToLower(strings.TrimSpace(field)) {
+		case "id", "selflink":
+			return true
+		}
+	}
+	return false
+}
+
+func getCSVField(record []string, i int) string {
+	if i < 0 || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+// parseInventoryLabels parses labels in a form of semicolon separated key=value pairs.
+func parseInventoryLabels(value string) (map[string]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+	labels := make(map[string]string)
+	for _, pair := range strings.Split(value, ";") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("label %q is not in a key=value form", pair)
+		}
+		labels[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return labels, nil
+}
+
+// normalizeInventoryClusterID returns cluster ID from a given cluster ID, self link
+// or asset name. Fleet membership names are accepted as well.
+func normalizeInventoryClusterID(value string) (string, error) {
+	id := value
+	if i := strings.Index(id, "projects/"); i > 0 {
+		id = id[i:]
+	}
+	if IsFleetMembershipName(id) {
+		return id, nil
+	}
+	if _, _, _, err := SliceAndValidateClusterID(id); err != nil || strings.Count(id, "/") != 5 {
+		return "", fmt.Errorf("%q is not a valid cluster ID", value)
+	}
+	return id, nil
+}
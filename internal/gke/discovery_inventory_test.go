@@ -0,0 +1,192 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewInventoryDiscoveryClient(t *testing.T) {
+	client := NewInventoryDiscoveryClient("inventory.csv", InventoryFormatCSV)
+	inventoryClient, ok := client.(*inventoryDiscoveryClient)
+	if !ok {
+		t.Fatalf("client type is not *inventoryDiscoveryClient")
+	}
+	if inventoryClient.filename != "inventory.csv" {
+		t.Errorf("client filename = %v; want %v", inventoryClient.filename, "inventory.csv")
+	}
+	if inventoryClient.format != InventoryFormatCSV {
+		t.Errorf("client format = %v; want %v", inventoryClient.format, InventoryFormatCSV)
+	}
+}
+
+func TestInventoryDiscoveryClientGetFormat(t *testing.T) {
+	tests := []struct {
+		filename string
+		format   string
+		expected string
+	}{
+		{"inventory.csv", "", InventoryFormatCSV},
+		{"inventory.yaml", "", InventoryFormatYAML},
+		{"inventory.YML", "", InventoryFormatYAML},
+		{InventoryStdin, "", InventoryFormatCSV},
+		{InventoryStdin, "YAML", InventoryFormatYAML},
+	}
+	for _, tt := range tests {
+		client := &inventoryDiscoveryClient{filename: tt.filename, format: tt.format}
+		if format := client.getFormat(); format != tt.expected {
+			t.Errorf("format for %q, %q = %v; want %v", tt.filename, tt.format, format, tt.expected)
+		}
+	}
+}
+
+func TestInventoryDiscoveryClientGetClusters_csv(t *testing.T) {
+	data := `id,labels,profile
+projects/p1/locations/europe-west2/clusters/c1,env=prod;team=a,production
+https://container.googleapis.com/v1/projects/p2/zones/europe-west2-a/clusters/c2,,
+projects/p1/locations/europe-west2/clusters/c1,env=dev,
+`
+	client := &inventoryDiscoveryClient{
+		filename: "inventory.csv",
+		readFileFunc: func(name string) ([]byte, error) {
+			return []byte(data), nil
+		},
+	}
+	clusters, err := client.GetClustersInOrg("")
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	expected := []string{
+		"projects/p1/locations/europe-west2/clusters/c1",
+		"projects/p2/zones/europe-west2-a/clusters/c2",
+	}
+	if !reflect.DeepEqual(clusters, expected) {
+		t.Fatalf("clusters = %v; want %v", clusters, expected)
+	}
+	cluster, ok := client.GetInventoryCluster(expected[0])
+	if !ok {
+		t.Fatalf("inventory cluster %s not found", expected[0])
+	}
+	expectedCluster := &InventoryCluster{
+		ID:      expected[0],
+		Labels:  map[string]string{"env": "prod", "team": "a"},
+		Profile: "production",
+	}
+	if !reflect.DeepEqual(cluster, expectedCluster) {
+		t.Errorf("inventory cluster = %+v; want %+v", cluster, expectedCluster)
+	}
+}
+
+func TestInventoryDiscoveryClientGetClusters_stdin(t *testing.T) {
+	data := `https://container.googleapis.com/v1/projects/p1/locations/europe-west2/clusters/c1,env=prod
+https://container.googleapis.com/v1/projects/p1/locations/us-central1/clusters/c2,env=dev
+`
+	client := &inventoryDiscoveryClient{
+		filename: InventoryStdin,
+		stdin:    strings.NewReader(data),
+	}
+	clusters, err := client.GetClustersInProject("")
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	expected := []string{
+		"projects/p1/locations/europe-west2/clusters/c1",
+		"projects/p1/locations/us-central1/clusters/c2",
+	}
+	if !reflect.DeepEqual(clusters, expected) {
+		t.Fatalf("clusters = %v; want %v", clusters, expected)
+	}
+	if cluster, _ := client.GetInventoryCluster(expected[1]); cluster.Labels["env"] != "dev" {
+		t.Errorf("cluster label env = %v; want %v", cluster.Labels["env"], "dev")
+	}
+}
+
+func TestInventoryDiscoveryClientGetClusters_yaml(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{
+			name: "list",
+			data: `- id: projects/p1/locations/europe-west2/clusters/c1
+  labels:
+    env: prod
+  profile: production
+- id: projects/p1/locations/europe-west2/clusters/c2
+`,
+		},
+		{
+			name: "gcloud",
+			data: `---
+name: c1
+resourceLabels:
+  env: prod
+selfLink: https://container.googleapis.com/v1/projects/p1/locations/europe-west2/clusters/c1
+---
+name: c2
+selfLink: https://container.googleapis.com/v1/projects/p1/locations/europe-west2/clusters/c2
+`,
+		},
+	}
+	expected := []string{
+		"projects/p1/locations/europe-west2/clusters/c1",
+		"projects/p1/locations/europe-west2/clusters/c2",
+	}
+	for _, tt := range tests {
+		data := tt.data
+		client := &inventoryDiscoveryClient{
+			filename: "inventory.yaml",
+			readFileFunc: func(name string) ([]byte, error) {
+				return []byte(data), nil
+			},
+		}
+		clusters, err := client.GetClustersInFolder("")
+		if err != nil {
+			t.Fatalf("%s: err = %v; want nil", tt.name, err)
+		}
+		if !reflect.DeepEqual(clusters, expected) {
+			t.Fatalf("%s: clusters = %v; want %v", tt.name, clusters, expected)
+		}
+		if cluster, _ := client.GetInventoryCluster(expected[0]); cluster.Labels["env"] != "prod" {
+			t.Errorf("%s: cluster label env = %v; want %v", tt.name, cluster.Labels["env"], "prod")
+		}
+	}
+}
+
+func TestInventoryDiscoveryClientGetClusters_negative(t *testing.T) {
+	tests := []struct {
+		filename string
+		data     string
+	}{
+		{"inventory.csv", "my-cluster,env=prod\n"},
+		{"inventory.csv", "projects/p/locations/l/clusters/c,env\n"},
+		{"inventory.yaml", "- id: projects/p/locations/l\n"},
+		{"inventory.yaml", "- id: [\n"},
+	}
+	for _, tt := range tests {
+		data := tt.data
+		client := &inventoryDiscoveryClient{
+			filename: tt.filename,
+			readFileFunc: func(name string) ([]byte, error) {
+				return []byte(data), nil
+			},
+		}
+		if _, err := client.getClusters(); err == nil {
+			t.Errorf("inventory %q: err is nil; want error", tt.data)
+		}
+	}
+}
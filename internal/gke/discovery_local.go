@@ -15,17 +15,13 @@
 package gke
 
 import (
-	"encoding/json"
 	"os"
 )
 
-type localCluster struct {
-	Name string `json:"name"`
-}
-
 type localDiscoveryClient struct {
 	readFileFunc func(name string) ([]byte, error)
 	filename     string
+	dump         *ClusterDump
 }
 
 func (c *localDiscoveryClient) Close() error {
@@ -52,19 +48,26 @@ func NewLocalDiscoveryClient(filename string) DiscoveryClient {
 	}
 }
 
-func (c *localDiscoveryClient) getClusters() ([]string, error) {
-	data, err := c.readFileFunc(c.filename)
-	if err != nil {
-		return nil, err
+// NewLocalDiscoveryClientWithDump returns discovery client for the clusters of a given,
+// already parsed cluster data dump.
+func NewLocalDiscoveryClientWithDump(dump *ClusterDump) DiscoveryClient {
+	return &localDiscoveryClient{
+		dump: dump,
 	}
+}
 
-	var clusters []*localCluster
-	if err = json.Unmarshal(data, &clusters); err != nil {
-		return nil, err
+func (c *localDiscoveryClient) getClusters() ([]string, error) {
+	if c.dump == nil {
+		dump, err := readClusterDump(c.readFileFunc, c.filename)
+		if err != nil {
+			return nil, err
+		}
+		c.dump = dump
 	}
-	names := make([]string, len(clusters))
+	clusters := c.dump.Clusters()
+	ids := make([]string, len(clusters))
 	for i := range clusters {
-		names[i] = clusters[i].Name
+		ids[i] = clusters[i].ID
 	}
-	return names, nil
+	return ids, nil
 }
@@ -0,0 +1,70 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"os"
+
+	"github.com/google/gke-policy-automation/internal/terraform"
+)
+
+type terraformPlanDiscoveryClient struct {
+	readFileFunc func(name string) ([]byte, error)
+	filename     string
+}
+
+// NewTerraformPlanDiscoveryClient returns discovery client that finds GKE clusters
+// planned in a Terraform plan file in a JSON format.
+func NewTerraformPlanDiscoveryClient(filename string) DiscoveryClient {
+	return &terraformPlanDiscoveryClient{
+		readFileFunc: os.ReadFile,
+		filename:     filename,
+	}
+}
+
+func (c *terraformPlanDiscoveryClient) Close() error {
+	return nil
+}
+
+func (c *terraformPlanDiscoveryClient) GetClustersInFolder(number string) ([]string, error) {
+	return c.getClusters()
+}
+
+func (c *terraformPlanDiscoveryClient) GetClustersInOrg(number string) ([]string, error) {
+	return c.getClusters()
+}
+
+func (c *terraformPlanDiscoveryClient) GetClustersInProject(name string) ([]string, error) {
+	return c.getClusters()
+}
+
+func (c *terraformPlanDiscoveryClient) getClusters() ([]string, error) {
+	data, err := c.readFileFunc(c.filename)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := terraform.ParsePlan(data)
+	if err != nil {
+		return nil, err
+	}
+	clusters := make([]string, 0)
+	for _, r := range plan.ManagedResources(terraform.ClusterResourceType) {
+		project, _ := r.Values["project"].(string)
+		location, _ := r.Values["location"].(string)
+		name, _ := r.Values["name"].(string)
+		clusters = append(clusters, GetOfflineClusterID(project, location, name))
+	}
+	return clusters, nil
+}
@@ -0,0 +1,51 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestNewTerraformPlanDiscoveryClient(t *testing.T) {
+	filename := "plan.json"
+	client := NewTerraformPlanDiscoveryClient(filename)
+	planClient, ok := client.(*terraformPlanDiscoveryClient)
+	if !ok {
+		t.Fatalf("client type is not *terraformPlanDiscoveryClient")
+	}
+	if planClient.filename != filename {
+		t.Errorf("client filename = %v; want %v", planClient.filename, filename)
+	}
+}
+
+func TestTerraformPlanDiscoveryClientGetClusters(t *testing.T) {
+	client := &terraformPlanDiscoveryClient{
+		filename:     "../terraform/test-fixtures/terraform_plan.json",
+		readFileFunc: os.ReadFile,
+	}
+	clusters, err := client.GetClustersInOrg("any")
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	expected := []string{
+		"projects/my-project/locations/europe-west2/clusters/cluster-one",
+		"projects/-/locations/us-central1/clusters/cluster-two",
+	}
+	if !reflect.DeepEqual(clusters, expected) {
+		t.Fatalf("clusters = %v; want %v", clusters, expected)
+	}
+}
@@ -22,22 +22,9 @@ import (
 
 	asset "cloud.google.com/go/asset/apiv1"
 	"cloud.google.com/go/asset/apiv1/assetpb"
-	gax "github.com/googleapis/gax-go/v2"
 	"google.golang.org/api/iterator"
 )
 
-type assetInventoryClientMock struct {
-	searchAllResourcesFn func(ctx context.Context, req *assetpb.SearchAllResourcesRequest, opts ...gax.CallOption) *asset.ResourceSearchResultIterator
-}
-
-func (m assetInventoryClientMock) SearchAllResources(ctx context.Context, req *assetpb.SearchAllResourcesRequest, opts ...gax.CallOption) *asset.ResourceSearchResultIterator {
-	return m.searchAllResourcesFn(ctx, req, opts...)
-}
-
-func (m assetInventoryClientMock) Close() error {
-	return nil
-}
-
 type assetInventorySearchResultIteratorMock struct {
 	nextFn func() (*assetpb.ResourceSearchResult, error)
 }
@@ -48,7 +35,7 @@ func (m assetInventorySearchResultIteratorMock) Next() (*assetpb.ResourceSearchR
 
 func TestNewAssetInventoryDiscoveryClient(t *testing.T) {
 	testCredsFile := "test-fixtures/test_credentials.json"
-	client, err := NewDiscoveryClientWithCredentialsFile(context.Background(), testCredsFile)
+	client, err := NewDiscoveryClientWithCredentialsFile(context.Background(), testCredsFile, nil)
 	if err != nil {
 		t.Fatalf("err is not nil; want nil; err = %s", err)
 	}
@@ -64,22 +51,21 @@ func TestNewAssetInventoryDiscoveryClient(t *testing.T) {
 	}
 }
 
-func TestGetClustersForScope(t *testing.T) {
+func TestNewClusterSearchRequest(t *testing.T) {
 	scope := "projects/myProject"
-	searchFn := func(ctx context.Context, req *assetpb.SearchAllResourcesRequest, opts ...gax.CallOption) *asset.ResourceSearchResultIterator {
-		if req.Scope != scope {
-			t.Fatalf("scope in request = %v; want %v", req.Scope, scope)
-		}
-		if !reflect.DeepEqual(req.AssetTypes, []string{clusterAssetType}) {
-			t.Fatalf("asset types in request = %v; want %v", req.AssetTypes, []string{clusterAssetType})
-		}
-		return &asset.ResourceSearchResultIterator{}
+	client := AssetInventoryDiscoveryClient{pageSize: 200}
+	req := client.newClusterSearchRequest(scope)
+	if req.Scope != scope {
+		t.Fatalf("scope in request = %v; want %v", req.Scope, scope)
 	}
-	client := AssetInventoryDiscoveryClient{
-		cli: assetInventoryClientMock{searchAllResourcesFn: searchFn},
+	if !reflect.DeepEqual(req.AssetTypes, []string{clusterAssetType}) {
+		t.Fatalf("asset types in request = %v; want %v", req.AssetTypes, []string{clusterAssetType})
 	}
-	if _, err := client.getClustersForScope(scope); err != nil {
-		t.Fatalf("err is not nil; want nil; err = %s", err)
+	if req.PageSize != 200 {
+		t.Errorf("page size in request = %v; want %v", req.PageSize, 200)
+	}
+	if req.Query != "" {
+		t.Errorf("query in request = %v; want empty", req.Query)
 	}
 }
 
@@ -137,7 +123,7 @@ func TestCollectResourceSearchResults_limit(t *testing.T) {
 	}
 
 	iteratorMock := assetInventorySearchResultIteratorMock{nextFn}
-	client := AssetInventoryDiscoveryClient{searchLimit: searchLimit}
+	client := AssetInventoryDiscoveryClient{searchLimit: searchLimit, allowPartial: true}
 	results, err := client.collectResourceSearchResults(iteratorMock)
 	if err != nil {
 		t.Fatalf("err is not nil; want nil; err = %s", err)
@@ -147,6 +133,64 @@ func TestCollectResourceSearchResults_limit(t *testing.T) {
 	}
 }
 
+func TestCollectResourceSearchResults_limitNotAllowed(t *testing.T) {
+	expected := []*assetpb.ResourceSearchResult{
+		{Name: "testNameOne", AssetType: "testAssetType"},
+		{Name: "testNameTwo", AssetType: "testAssetType"},
+		nil,
+	}
+	errors := []error{nil, nil, iterator.Done}
+	i := 0
+	nextFn := func() (res *assetpb.ResourceSearchResult, err error) {
+		res, err = expected[i], errors[i]
+		i++
+		return
+	}
+	client := AssetInventoryDiscoveryClient{searchLimit: 1}
+	if _, err := client.collectResourceSearchResults(assetInventorySearchResultIteratorMock{nextFn}); err == nil {
+		t.Errorf("error is nil; want error")
+	}
+}
+
+func TestCollectResourceSearchResults_limitExact(t *testing.T) {
+	expected := []*assetpb.ResourceSearchResult{
+		{Name: "testNameOne", AssetType: "testAssetType"},
+		{Name: "testNameTwo", AssetType: "testAssetType"},
+		nil,
+	}
+	errors := []error{nil, nil, iterator.Done}
+	i := 0
+	nextFn := func() (res *assetpb.ResourceSearchResult, err error) {
+		res, err = expected[i], errors[i]
+		i++
+		return
+	}
+	client := AssetInventoryDiscoveryClient{searchLimit: 2}
+	results, err := client.collectResourceSearchResults(assetInventorySearchResultIteratorMock{nextFn})
+	if err != nil {
+		t.Fatalf("err is not nil; want nil; err = %s", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("number of results = %v; want %v", len(results), 2)
+	}
+}
+
+func TestDiscoveryOptions(t *testing.T) {
+	client := &AssetInventoryDiscoveryClient{}
+	for _, opt := range []DiscoveryOption{WithSearchPageSize(200), WithSearchLimit(5000, true)} {
+		opt(client)
+	}
+	if client.pageSize != 200 {
+		t.Errorf("pageSize = %v; want %v", client.pageSize, 200)
+	}
+	if client.searchLimit != 5000 {
+		t.Errorf("searchLimit = %v; want %v", client.searchLimit, 5000)
+	}
+	if !client.allowPartial {
+		t.Errorf("allowPartial = %v; want %v", client.allowPartial, true)
+	}
+}
+
 func TestCollectResourceSearchResults_negative(t *testing.T) {
 	expected := []*assetpb.ResourceSearchResult{{}}
 	errors := []error{fmt.Errorf("some strange error")}
@@ -170,7 +214,7 @@ func TestFilterMapSeachResults(t *testing.T) {
 		{Name: "testName", AssetType: "testAssetType"},
 		{Name: "invalidName", AssetType: clusterAssetType},
 	}
-	ids := filterMapSeachResults(results)
+	ids := filterMapSeachResults(results, nil)
 	if len(ids) != 1 {
 		t.Fatalf("number of cluster identifiers = %v; want %v", len(ids), 1)
 	}
@@ -207,3 +251,20 @@ func TestGetIDFromName_negative(t *testing.T) {
 		}
 	}
 }
+
+func TestRecordClusterFolders(t *testing.T) {
+	id := "projects/my-project/locations/europe-west2/clusters/my-cluster"
+	results := []*assetpb.ResourceSearchResult{
+		{Name: fmt.Sprintf("//container.googleapis.com/%s", id), Folders: []string{"folders/123", "folders/456"}},
+		{Name: "invalidName", Folders: []string{"folders/789"}},
+	}
+	client := AssetInventoryDiscoveryClient{}
+	client.recordClusterFolders(results)
+	expected := []string{"123", "456"}
+	if folders := client.GetClusterFolders(id); !reflect.DeepEqual(folders, expected) {
+		t.Errorf("cluster folders = %v; want %v", folders, expected)
+	}
+	if len(client.clusterFolders) != 1 {
+		t.Errorf("number of clusters with folders = %v; want %v", len(client.clusterFolders), 1)
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"cloud.google.com/go/container/apiv1/containerpb"
+)
+
+const dumpGKEDataSourceName = "gke"
+
+// DumpCluster is a cluster of a cluster data dump.
+type DumpCluster struct {
+	ID      string
+	Cluster *containerpb.Cluster
+	Data    map[string]json.RawMessage
+}
+
+// ClusterDump is a cluster data dump indexed by cluster IDs and short cluster names.
+type ClusterDump struct {
+	clusters []*DumpCluster
+	byID     map[string]*DumpCluster
+	byName   map[string][]*DumpCluster
+}
+
+// dumpEntry is a cluster entry of a dump produced by the dump cluster command. Dumps with
+// GKE API cluster data only do not have the data field.
+type dumpEntry struct {
+	Name string                     `json:"name"`
+	Data map[string]json.RawMessage `json:"data"`
+}
+
+// ReadClusterDump reads and indexes a given cluster data dump file.
+func ReadClusterDump(filename string) (*ClusterDump, error) {
+	return readClusterDump(os.ReadFile, filename)
+}
+
+func readClusterDump(readFileFunc func(name string) ([]byte, error), filename string) (*ClusterDump, error) {
+	data, err := readFileFunc(filename)
+	if err != nil {
+		return nil, err
+	}
+	dump, err := ParseClusterDump(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse cluster dump %s: %w", filename, err)
+	}
+	return dump, nil
+}
+
+// ParseClusterDump parses cluster data dump. The dump is either a JSON array or JSON lines of
+// clusters produced by the dump cluster command, or of GKE API clusters. Gzip compressed
+// dumps are supported as well.
+func ParseClusterDump(data []byte) (*ClusterDump, error) {
+	data, err := decompressDump(data)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := splitDumpEntries(data)
+	if err != nil {
+		return nil, err
+	}
+	dump := &ClusterDump{
+		clusters: make([]*DumpCluster, 0, len(entries)),
+		byID:     make(map[string]*DumpCluster, len(entries)),
+		byName:   make(map[string][]*DumpCluster, len(entries)),
+	}
+	for i, entry := range entries {
+		cluster, err := parseDumpEntry(entry)
+		if err != nil {
+			return nil, fmt.Errorf("cluster [%d]: %w", i, err)
+		}
+		if _, ok := dump.byID[cluster.ID]; ok {
+			return nil, fmt.Errorf("cluster [%d]: duplicated cluster %s", i, cluster.ID)
+		}
+		dump.clusters = append(dump.clusters, cluster)
+		dump.byID[cluster.ID] = cluster
+		name := getDumpClusterName(cluster)
+		dump.byName[name] = append(dump.byName[name], cluster)
+	}
+	return dump, nil
+}
+
+// Clusters returns clusters of the dump in their original order.
+func (d *ClusterDump) Clusters() []*DumpCluster {
+	return d.clusters
+}
+
+// GetCluster returns the dump cluster with a given ID or with a given short name,
+// when it is unique in the dump.
+func (d *ClusterDump) GetCluster(clusterID string) (*DumpCluster, error) {
+	if cluster, ok := d.byID[clusterID]; ok {
+		return cluster, nil
+	}
+	clusters := d.byName[clusterID]
+	switch len(clusters) {
+	case 0:
+		return nil, fmt.Errorf("cluster %s not found in a dump file", clusterID)
+	case 1:
+		return clusters[0], nil
+	default:
+		return nil, fmt.Errorf("cluster name %s is ambiguous in a dump file, use cluster ID instead", clusterID)
+	}
+}
+
+func decompressDump(data []byte) ([]byte, error) {
+	if len(data) < 2 || data[0] != 0x1f || data[1] != 0x8b {
+		return data, nil
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// splitDumpEntries returns raw cluster entries of a JSON array or JSON lines dump.
+func splitDumpEntries(data []byte) ([]json.RawMessage, error) {
+	data = bytes.TrimSpace(data)
+	var entries []json.RawMessage
+	if len(data) > 0 && data[0] == '[' {
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return nil, err
+		}
+		return entries, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var entry json.RawMessage
+		if err := decoder.Decode(&entry); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func parseDumpEntry(data json.RawMessage) (*DumpCluster, error) {
+	var entry dumpEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, err
+	}
+	cluster := &DumpCluster{ID: entry.Name, Data: entry.Data}
+	if entry.Data == nil {
+		cluster.Data = map[string]json.RawMessage{dumpGKEDataSourceName: data}
+	}
+	if gkeData, ok := cluster.Data[dumpGKEDataSourceName]; ok {
+		cluster.Cluster = &containerpb.Cluster{}
+		if err := json.Unmarshal(gkeData, cluster.Cluster); err != nil {
+			return nil, err
+		}
+		if entry.Data == nil {
+			cluster.ID = getDumpClusterID(cluster.Cluster)
+		}
+	}
+	if cluster.ID == "" {
+		return nil, fmt.Errorf("cluster name is not set")
+	}
+	return cluster, nil
+}
+
+// getDumpClusterID returns cluster ID from the self link of a GKE API cluster or
+// its name when the self link is not set.
+func getDumpClusterID(cluster *containerpb.Cluster) string {
+	if i := strings.Index(cluster.SelfLink, "projects/"); i >= 0 {
+		if id := cluster.SelfLink[i:]; strings.Count(id, "/") == 5 {
+			return id
+		}
+	}
+	return cluster.Name
+}
+
+func getDumpClusterName(cluster *DumpCluster) string {
+	if cluster.Cluster != nil && cluster.Cluster.Name != "" {
+		return cluster.Cluster.Name
+	}
+	return cluster.ID[strings.LastIndex(cluster.ID, "/")+1:]
+}
@@ -0,0 +1,132 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"bytes"
+	"compress/gzip"
+	"reflect"
+	"testing"
+)
+
+const (
+	gkeClustersDump = `[
+	{"name": "one", "self_link": "https://container.googleapis.com/v1/projects/p1/locations/europe-west2/clusters/one"},
+	{"name": "two"}
+]`
+	wrappedClustersDump = `[
+	{"name": "projects/p1/locations/europe-west2/clusters/one", "data": {"gke": {"name": "one"}, "k8s": []}},
+	{"name": "projects/p2/locations/europe-west2/clusters/one", "data": {"gke": {"name": "one"}}},
+	{"name": "projects/p1/locations/global/memberships/attached", "data": {"fleet": {}}}
+]`
+	jsonLinesClustersDump = `{"name": "projects/p1/locations/europe-west2/clusters/one", "data": {"gke": {"name": "one"}}}
+{"name": "projects/p1/locations/europe-west2/clusters/two", "data": {"gke": {"name": "two"}}}
+`
+)
+
+func TestParseClusterDump(t *testing.T) {
+	var gzipped bytes.Buffer
+	writer := gzip.NewWriter(&gzipped)
+	if _, err := writer.Write([]byte(jsonLinesClustersDump)); err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	tests := []struct {
+		name     string
+		data     []byte
+		expected []string
+	}{
+		{
+			name:     "gke",
+			data:     []byte(gkeClustersDump),
+			expected: []string{"projects/p1/locations/europe-west2/clusters/one", "two"},
+		},
+		{
+			name: "wrapped",
+			data: []byte(wrappedClustersDump),
+			expected: []string{
+				"projects/p1/locations/europe-west2/clusters/one",
+				"projects/p2/locations/europe-west2/clusters/one",
+				"projects/p1/locations/global/memberships/attached",
+			},
+		},
+		{
+			name:     "jsonLines",
+			data:     []byte(jsonLinesClustersDump),
+			expected: []string{"projects/p1/locations/europe-west2/clusters/one", "projects/p1/locations/europe-west2/clusters/two"},
+		},
+		{
+			name:     "gzip",
+			data:     gzipped.Bytes(),
+			expected: []string{"projects/p1/locations/europe-west2/clusters/one", "projects/p1/locations/europe-west2/clusters/two"},
+		},
+	}
+	for _, tt := range tests {
+		dump, err := ParseClusterDump(tt.data)
+		if err != nil {
+			t.Fatalf("%s: err = %v; want nil", tt.name, err)
+		}
+		ids := make([]string, 0, len(dump.Clusters()))
+		for _, cluster := range dump.Clusters() {
+			ids = append(ids, cluster.ID)
+		}
+		if !reflect.DeepEqual(ids, tt.expected) {
+			t.Errorf("%s: cluster ids = %v; want %v", tt.name, ids, tt.expected)
+		}
+	}
+}
+
+func TestParseClusterDump_negative(t *testing.T) {
+	badDumps := []string{
+		`[{"name": "one"}`,
+		`[{"data": {"k8s": []}}]`,
+		`[{"name": "one"}, {"name": "one"}]`,
+		`{"name": "one", "data": {"gke": {"name": 1}}}`,
+	}
+	for i, data := range badDumps {
+		if _, err := ParseClusterDump([]byte(data)); err == nil {
+			t.Errorf("dump [%d]: err is nil; want error", i)
+		}
+	}
+}
+
+func TestClusterDumpGetCluster(t *testing.T) {
+	dump, err := ParseClusterDump([]byte(wrappedClustersDump))
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	cluster, err := dump.GetCluster("projects/p2/locations/europe-west2/clusters/one")
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	if cluster.Cluster == nil || cluster.Cluster.Name != "one" {
+		t.Errorf("cluster = %v; want GKE cluster one", cluster.Cluster)
+	}
+	cluster, err = dump.GetCluster("attached")
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	if cluster.Cluster != nil {
+		t.Errorf("cluster = %v; want nil", cluster.Cluster)
+	}
+	if _, err := dump.GetCluster("one"); err == nil {
+		t.Errorf("err is nil for ambiguous cluster name; want error")
+	}
+	if _, err := dump.GetCluster("three"); err == nil {
+		t.Errorf("err is nil for missing cluster; want error")
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/gke-policy-automation/internal/log"
+	"github.com/google/gke-policy-automation/internal/retry"
+	"github.com/google/gke-policy-automation/internal/version"
+	"google.golang.org/api/gkehub/v1"
+	"google.golang.org/api/option"
+)
+
+// FleetClient lists Fleet (GKE Hub) memberships and features of a Fleet host project.
+type FleetClient interface {
+	ListMemberships(ctx context.Context, project string) ([]*gkehub.Membership, error)
+	ListFeatures(ctx context.Context, project string) ([]*gkehub.Feature, error)
+}
+
+type fleetClient struct {
+	svc     *gkehub.Service
+	retrier *retry.Retrier
+}
+
+func NewFleetClient(ctx context.Context, retrier *retry.Retrier) (FleetClient, error) {
+	return newFleetClient(ctx, retrier)
+}
+
+func NewFleetClientWithCredentialsFile(ctx context.Context, credentialsFile string, retrier *retry.Retrier) (FleetClient, error) {
+	return newFleetClient(ctx, retrier, option.WithCredentialsFile(credentialsFile))
+}
+
+func newFleetClient(ctx context.Context, retrier *retry.Retrier, opts ...option.ClientOption) (FleetClient, error) {
+	opts = append(opts, option.WithUserAgent(version.UserAgent))
+	svc, err := gkehub.NewService(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &fleetClient{svc: svc, retrier: retrier}, nil
+}
+
+// ListMemberships returns memberships in all locations of a given Fleet host project.
+func (c *fleetClient) ListMemberships(ctx context.Context, project string) ([]*gkehub.Membership, error) {
+	memberships := make([]*gkehub.Membership, 0)
+	parent := fmt.Sprintf("projects/%s/locations/-", project)
+	pageToken := ""
+	for {
+		var resp *gkehub.ListMembershipsResponse
+		err := c.retrier.Do(ctx, "memberships.list", func(ctx context.Context) error {
+			var err error
+			resp, err = c.svc.Projects.Locations.Memberships.List(parent).PageToken(pageToken).Context(ctx).Do()
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		memberships = append(memberships, resp.Resources...)
+		if pageToken = resp.NextPageToken; pageToken == "" {
+			return memberships, nil
+		}
+	}
+}
+
+// ListFeatures returns features in all locations of a given Fleet host project.
+func (c *fleetClient) ListFeatures(ctx context.Context, project string) ([]*gkehub.Feature, error) {
+	features := make([]*gkehub.Feature, 0)
+	parent := fmt.Sprintf("projects/%s/locations/-", project)
+	pageToken := ""
+	for {
+		var resp *gkehub.ListFeaturesResponse
+		err := c.retrier.Do(ctx, "features.list", func(ctx context.Context) error {
+			var err error
+			resp, err = c.svc.Projects.Locations.Features.List(parent).PageToken(pageToken).Context(ctx).Do()
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		features = append(features, resp.Resources...)
+		if pageToken = resp.NextPageToken; pageToken == "" {
+			return features, nil
+		}
+	}
+}
+
+// GetFleetMembershipClusterID returns identifier of a cluster registered with a given membership.
+// For GKE clusters, it is the GKE cluster identifier. For other clusters, i.e. attached or multi-cloud
+// clusters, it is the membership name.
+func GetFleetMembershipClusterID(membership *gkehub.Membership) string {
+	if membership.Endpoint != nil && membership.Endpoint.GkeCluster != nil {
+		if id, err := getIDFromName(membership.Endpoint.GkeCluster.ResourceLink); err == nil {
+			return id
+		}
+	}
+	return membership.Name
+}
+
+// IsFleetMembershipName checks if a given cluster identifier is a Fleet membership name.
+func IsFleetMembershipName(id string) bool {
+	parts := strings.Split(id, "/")
+	return len(parts) == 6 && parts[0] == "projects" && parts[2] == "locations" && parts[4] == "memberships"
+}
+
+// FleetDiscoveryClient discovers clusters registered in Fleet host projects, including
+// attached and multi-cloud clusters.
+type FleetDiscoveryClient struct {
+	ctx context.Context
+	cli FleetClient
+}
+
+func NewFleetDiscoveryClient(ctx context.Context, retrier *retry.Retrier) (DiscoveryClient, error) {
+	cli, err := NewFleetClient(ctx, retrier)
+	if err != nil {
+		return nil, err
+	}
+	return &FleetDiscoveryClient{ctx: ctx, cli: cli}, nil
+}
+
+func NewFleetDiscoveryClientWithCredentialsFile(ctx context.Context, credentialsFile string, retrier *retry.Retrier) (DiscoveryClient, error) {
+	cli, err := NewFleetClientWithCredentialsFile(ctx, credentialsFile, retrier)
+	if err != nil {
+		return nil, err
+	}
+	return &FleetDiscoveryClient{ctx: ctx, cli: cli}, nil
+}
+
+// GetClustersInProject finds clusters registered in a given Fleet host project (identified by name)
+// and returns slice with their identifiers.
+func (c *FleetDiscoveryClient) GetClustersInProject(name string) ([]string, error) {
+	memberships, err := c.cli.ListMemberships(c.ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list fleet memberships in project %s: %w", name, err)
+	}
+	clusters := make([]string, 0, len(memberships))
+	for _, membership := range memberships {
+		id := GetFleetMembershipClusterID(membership)
+		log.Debugf("fleet membership %s maps to cluster %s", membership.Name, id)
+		clusters = append(clusters, id)
+	}
+	return clusters, nil
+}
+
+func (c *FleetDiscoveryClient) GetClustersInFolder(number string) ([]string, error) {
+	return nil, fmt.Errorf("fleet cluster discovery is not supported on folders")
+}
+
+func (c *FleetDiscoveryClient) GetClustersInOrg(number string) ([]string, error) {
+	return nil, fmt.Errorf("fleet cluster discovery is not supported on organizations")
+}
+
+func (c *FleetDiscoveryClient) Close() error {
+	return nil
+}
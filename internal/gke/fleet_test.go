@@ -0,0 +1,142 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gke
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/gke-policy-automation/internal/retry"
+	"google.golang.org/api/gkehub/v1"
+	"google.golang.org/api/option"
+)
+
+type fleetClientMock struct {
+	memberships map[string][]*gkehub.Membership
+}
+
+func (m fleetClientMock) ListMemberships(ctx context.Context, project string) ([]*gkehub.Membership, error) {
+	return m.memberships[project], nil
+}
+
+func (m fleetClientMock) ListFeatures(ctx context.Context, project string) ([]*gkehub.Feature, error) {
+	return nil, nil
+}
+
+func TestGetFleetMembershipClusterID(t *testing.T) {
+	gkeMembership := &gkehub.Membership{
+		Name: "projects/host/locations/europe-west2/memberships/gke",
+		Endpoint: &gkehub.MembershipEndpoint{
+			GkeCluster: &gkehub.GkeCluster{ResourceLink: "//container.googleapis.com/projects/my-project/locations/europe-west2/clusters/gke"},
+		},
+	}
+	attachedMembership := &gkehub.Membership{
+		Name:     "projects/host/locations/global/memberships/attached",
+		Endpoint: &gkehub.MembershipEndpoint{MultiCloudCluster: &gkehub.MultiCloudCluster{}},
+	}
+	if id := GetFleetMembershipClusterID(gkeMembership); id != "projects/my-project/locations/europe-west2/clusters/gke" {
+		t.Errorf("cluster id = %v; want %v", id, "projects/my-project/locations/europe-west2/clusters/gke")
+	}
+	if id := GetFleetMembershipClusterID(attachedMembership); id != attachedMembership.Name {
+		t.Errorf("cluster id = %v; want %v", id, attachedMembership.Name)
+	}
+}
+
+func TestIsFleetMembershipName(t *testing.T) {
+	if !IsFleetMembershipName("projects/host/locations/global/memberships/attached") {
+		t.Errorf("IsFleetMembershipName() = false; want true")
+	}
+	if IsFleetMembershipName("projects/my-project/locations/europe-west2/clusters/gke") {
+		t.Errorf("IsFleetMembershipName() = true; want false")
+	}
+}
+
+func TestFleetDiscoveryClient(t *testing.T) {
+	client := FleetDiscoveryClient{
+		ctx: context.Background(),
+		cli: fleetClientMock{
+			memberships: map[string][]*gkehub.Membership{
+				"host": {
+					{Name: "projects/host/locations/global/memberships/attached"},
+					{
+						Name: "projects/host/locations/europe-west2/memberships/gke",
+						Endpoint: &gkehub.MembershipEndpoint{
+							GkeCluster: &gkehub.GkeCluster{ResourceLink: "//container.googleapis.com/projects/my-project/locations/europe-west2/clusters/gke"},
+						},
+					},
+				},
+			},
+		},
+	}
+	clusters, err := client.GetClustersInProject("host")
+	if err != nil {
+		t.Fatalf("err is not nil; want nil; err = %s", err)
+	}
+	expected := []string{
+		"projects/host/locations/global/memberships/attached",
+		"projects/my-project/locations/europe-west2/clusters/gke",
+	}
+	if !reflect.DeepEqual(clusters, expected) {
+		t.Errorf("clusters = %v; want %v", clusters, expected)
+	}
+	if _, err := client.GetClustersInFolder("123"); err == nil {
+		t.Errorf("err is nil; want error")
+	}
+	if _, err := client.GetClustersInOrg("123"); err == nil {
+		t.Errorf("err is nil; want error")
+	}
+}
+
+func TestFleetClientListMemberships(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch {
+		case requests == 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case r.URL.Query().Get("pageToken") == "":
+			fmt.Fprint(w, `{"resources": [{"name": "projects/p/locations/global/memberships/one"}], "nextPageToken": "next"}`)
+		default:
+			fmt.Fprint(w, `{"resources": [{"name": "projects/p/locations/global/memberships/two"}]}`)
+		}
+	}))
+	defer server.Close()
+
+	retrier := retry.NewRetrier("gkehub", retry.Settings{InitialBackoff: time.Millisecond})
+	client, err := newFleetClient(context.Background(), retrier, option.WithEndpoint(server.URL), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	memberships, err := client.ListMemberships(context.Background(), "p")
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	if requests != 3 {
+		t.Errorf("number of requests = %v; want %v", requests, 3)
+	}
+	names := make([]string, 0, len(memberships))
+	for _, membership := range memberships {
+		names = append(names, membership.Name)
+	}
+	expected := []string{"projects/p/locations/global/memberships/one", "projects/p/locations/global/memberships/two"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("memberships = %v; want %v", names, expected)
+	}
+}
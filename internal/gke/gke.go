@@ -20,10 +20,24 @@ import (
 	"regexp"
 )
 
+const offlineUnknownValue = "-"
+
 func GetClusterID(project string, location string, name string) string {
 	return fmt.Sprintf("projects/%s/locations/%s/clusters/%s", project, location, name)
 }
 
+// GetOfflineClusterID returns cluster identifier for a cluster defined offline, i.e. in a Terraform
+// plan or in Config Connector manifests. The unknown project and location are replaced with "-".
+func GetOfflineClusterID(project string, location string, name string) string {
+	if project == "" {
+		project = offlineUnknownValue
+	}
+	if location == "" {
+		location = offlineUnknownValue
+	}
+	return GetClusterID(project, location, name)
+}
+
 func MustSliceClusterID(id string) (string, string, string) {
 	p, l, c, err := SliceAndValidateClusterID(id)
 	if err != nil {
@@ -74,3 +74,9 @@ func TestMustSliceClusterID(t *testing.T) {
 	input := "projects/demo-project-123/locations/europe-central2/clusters/cluster-waw"
 	MustSliceClusterID(input)
 }
+
+func TestGetOfflineClusterID(t *testing.T) {
+	if id := GetOfflineClusterID("", "", "cluster"); id != "projects/-/locations/-/clusters/cluster" {
+		t.Errorf("id = %v; want %v", id, "projects/-/locations/-/clusters/cluster")
+	}
+}
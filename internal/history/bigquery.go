@@ -0,0 +1,251 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package history
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/gke-policy-automation/internal/bigquery"
+	bq "google.golang.org/api/bigquery/v2"
+)
+
+const (
+	DefaultBigQueryTable = "validation_history"
+
+	bigQueryPartitionField = "time"
+
+	bigQueryTrendQuery = `SELECT run_id, UNIX_MICROS(MIN(time)) AS time_us,
+	COUNTIF(status = 'valid') AS valid, COUNTIF(status = 'violated') AS violated, COUNTIF(status = 'errored') AS errored
+FROM %s%s
+GROUP BY run_id
+ORDER BY time_us, run_id`
+
+	// bigQueryViolationsQuery numbers the violation periods of each cluster and policy. A period
+	// starts with a violated evaluation that is first or follows a valid one. Errored evaluations
+	// are skipped, as they neither open nor remediate the violations.
+	bigQueryViolationsQuery = `WITH evaluations AS (
+	SELECT cluster_id, policy_name, time, status,
+		LAG(status) OVER (PARTITION BY cluster_id, policy_name ORDER BY time) AS previous_status
+	FROM %s%s
+), periods AS (
+	SELECT cluster_id, policy_name, time, status,
+		COUNTIF(status = 'violated' AND (previous_status IS NULL OR previous_status = 'valid'))
+			OVER (PARTITION BY cluster_id, policy_name ORDER BY time ROWS UNBOUNDED PRECEDING) AS period
+	FROM evaluations
+)
+SELECT cluster_id, policy_name,
+	UNIX_MICROS(MIN(IF(status = 'violated', time, NULL))) AS first_seen_us,
+	UNIX_MICROS(MAX(IF(status = 'violated', time, NULL))) AS last_seen_us,
+	UNIX_MICROS(MIN(IF(status = 'valid', time, NULL))) AS remediated_us
+FROM periods
+WHERE period > 0
+GROUP BY cluster_id, policy_name, period
+ORDER BY cluster_id, policy_name, first_seen_us`
+)
+
+type bigQueryStore struct {
+	client  bigquery.BigQueryClient
+	project string
+	dataset string
+	table   string
+}
+
+// NewBigQueryStore returns store backed by a given BigQuery table. The table, partitioned
+// by the record time, is created when missing.
+func NewBigQueryStore(client bigquery.BigQueryClient, project, dataset, table string) (Store, error) {
+	if table == "" {
+		table = DefaultBigQueryTable
+	}
+	if err := client.EnsureTable(dataset, table, bigQuerySchema(), bigQueryPartitionField); err != nil {
+		return nil, err
+	}
+	return &bigQueryStore{
+		client:  client,
+		project: project,
+		dataset: dataset,
+		table:   table,
+	}, nil
+}
+
+func (s *bigQueryStore) Append(records []*Record) error {
+	rows := make([]*bigquery.Row, 0, len(records))
+	for _, r := range records {
+		rows = append(rows, &bigquery.Row{InsertID: bigquery.InsertID(r.RunID, r.ClusterID, r.PolicyName), Values: map[string]bq.JsonValue{
+			"run_id":       r.RunID,
+			"time":         r.Time.UTC().Format(time.RFC3339Nano),
+			"cluster_id":   r.ClusterID,
+			"policy_name":  r.PolicyName,
+			"policy_group": r.PolicyGroup,
+			"severity":     r.Severity,
+			"status":       r.Status,
+		}})
+	}
+	return s.client.InsertRows(s.dataset, s.table, rows)
+}
+
+func (s *bigQueryStore) Records(filter Filter) ([]*Record, error) {
+	conditions, params := getBigQueryConditions(filter)
+	query := fmt.Sprintf("SELECT run_id, UNIX_MICROS(time) AS time_us, cluster_id, policy_name, policy_group, severity, status FROM %s%s ORDER BY time",
+		s.tableName(), getWhereClause(conditions))
+	rows, err := s.client.Query(query, params)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]*Record, 0, len(rows))
+	for _, row := range rows {
+		recordTime, err := getTimeValue(row, "time_us")
+		if err != nil {
+			return nil, fmt.Errorf("invalid record time: %w", err)
+		}
+		records = append(records, &Record{
+			RunID:       getStringValue(row, "run_id"),
+			Time:        recordTime,
+			ClusterID:   getStringValue(row, "cluster_id"),
+			PolicyName:  getStringValue(row, "policy_name"),
+			PolicyGroup: getStringValue(row, "policy_group"),
+			Severity:    getStringValue(row, "severity"),
+			Status:      getStringValue(row, "status"),
+		})
+	}
+	return records, nil
+}
+
+// Trend returns summaries of the runs aggregated by BigQuery, so the records are not read.
+func (s *bigQueryStore) Trend(filter Filter) ([]*RunSummary, error) {
+	conditions, params := getBigQueryConditions(filter)
+	rows, err := s.client.Query(fmt.Sprintf(bigQueryTrendQuery, s.tableName(), getWhereClause(conditions)), params)
+	if err != nil {
+		return nil, err
+	}
+	summaries := make([]*RunSummary, 0, len(rows))
+	for _, row := range rows {
+		runTime, err := getTimeValue(row, "time_us")
+		if err != nil {
+			return nil, fmt.Errorf("invalid run time: %w", err)
+		}
+		summary := &RunSummary{RunID: getStringValue(row, "run_id"), Time: runTime}
+		for name, count := range map[string]*int{"valid": &summary.Valid, "violated": &summary.Violated, "errored": &summary.Errored} {
+			if *count, err = strconv.Atoi(getStringValue(row, name)); err != nil {
+				return nil, fmt.Errorf("invalid %s count: %w", name, err)
+			}
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries, nil
+}
+
+// Violations returns violations found by BigQuery, so the records are not read. The violation
+// periods are found with the window functions, in the same way as the Violations function does.
+func (s *bigQueryStore) Violations(filter Filter) ([]*Violation, error) {
+	conditions, params := getBigQueryConditions(filter)
+	conditions = append([]string{"status != '" + StatusErrored + "'"}, conditions...)
+	rows, err := s.client.Query(fmt.Sprintf(bigQueryViolationsQuery, s.tableName(), getWhereClause(conditions)), params)
+	if err != nil {
+		return nil, err
+	}
+	violations := make([]*Violation, 0, len(rows))
+	for _, row := range rows {
+		firstSeen, err := getTimeValue(row, "first_seen_us")
+		if err != nil {
+			return nil, fmt.Errorf("invalid first seen time: %w", err)
+		}
+		lastSeen, err := getTimeValue(row, "last_seen_us")
+		if err != nil {
+			return nil, fmt.Errorf("invalid last seen time: %w", err)
+		}
+		violation := &Violation{
+			ClusterID:  getStringValue(row, "cluster_id"),
+			PolicyName: getStringValue(row, "policy_name"),
+			FirstSeen:  firstSeen,
+			LastSeen:   lastSeen,
+		}
+		if getStringValue(row, "remediated_us") != "" {
+			remediatedTime, err := getTimeValue(row, "remediated_us")
+			if err != nil {
+				return nil, fmt.Errorf("invalid remediated time: %w", err)
+			}
+			violation.RemediatedTime = &remediatedTime
+		}
+		violations = append(violations, violation)
+	}
+	return violations, nil
+}
+
+func (s *bigQueryStore) Close() error {
+	return s.client.Close()
+}
+
+func (s *bigQueryStore) tableName() string {
+	return fmt.Sprintf("`%s.%s.%s`", s.project, s.dataset, s.table)
+}
+
+// getBigQueryConditions returns the query conditions and parameters of a given filter.
+func getBigQueryConditions(filter Filter) ([]string, []*bq.QueryParameter) {
+	var conditions []string
+	var params []*bq.QueryParameter
+	if !filter.Since.IsZero() {
+		conditions = append(conditions, "time >= TIMESTAMP_MICROS(@since)")
+		params = append(params, bigquery.Int64Parameter("since", filter.Since.UnixMicro()))
+	}
+	if filter.ClusterID != "" {
+		conditions = append(conditions, "cluster_id = @cluster")
+		params = append(params, bigquery.StringParameter("cluster", filter.ClusterID))
+	}
+	if filter.PolicyName != "" {
+		conditions = append(conditions, "policy_name = @policy")
+		params = append(params, bigquery.StringParameter("policy", filter.PolicyName))
+	}
+	return conditions, params
+}
+
+func getWhereClause(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conditions, " AND ")
+}
+
+func bigQuerySchema() *bq.TableSchema {
+	return &bq.TableSchema{
+		Fields: []*bq.TableFieldSchema{
+			{Name: "run_id", Type: "STRING", Mode: "REQUIRED"},
+			{Name: "time", Type: "TIMESTAMP", Mode: "REQUIRED"},
+			{Name: "cluster_id", Type: "STRING", Mode: "REQUIRED"},
+			{Name: "policy_name", Type: "STRING", Mode: "REQUIRED"},
+			{Name: "policy_group", Type: "STRING"},
+			{Name: "severity", Type: "STRING"},
+			{Name: "status", Type: "STRING", Mode: "REQUIRED"},
+		},
+	}
+}
+
+func getStringValue(row map[string]interface{}, name string) string {
+	if value, ok := row[name].(string); ok {
+		return value
+	}
+	return ""
+}
+
+// getTimeValue returns the time of a given column with the UNIX time in microseconds.
+func getTimeValue(row map[string]interface{}, name string) (time.Time, error) {
+	micros, err := strconv.ParseInt(getStringValue(row, name), 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.UnixMicro(micros).UTC(), nil
+}
@@ -0,0 +1,169 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package history
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/gke-policy-automation/internal/bigquery"
+	bq "google.golang.org/api/bigquery/v2"
+)
+
+type bigQueryClientMock struct {
+	ensureTableFn func(dataset, table string, schema *bq.TableSchema, partitionField string) error
+	insertRowsFn  func(dataset, table string, rows []*bigquery.Row) error
+	queryFn       func(query string, params []*bq.QueryParameter) ([]map[string]interface{}, error)
+}
+
+func (m bigQueryClientMock) EnsureTable(dataset, table string, schema *bq.TableSchema, partitionField string) error {
+	return m.ensureTableFn(dataset, table, schema, partitionField)
+}
+
+func (m bigQueryClientMock) InsertRows(dataset, table string, rows []*bigquery.Row) error {
+	return m.insertRowsFn(dataset, table, rows)
+}
+
+func (m bigQueryClientMock) Query(query string, params []*bq.QueryParameter) ([]map[string]interface{}, error) {
+	return m.queryFn(query, params)
+}
+
+func (m bigQueryClientMock) Close() error {
+	return nil
+}
+
+func TestBigQueryStore(t *testing.T) {
+	var inserted []map[string]bq.JsonValue
+	client := bigQueryClientMock{
+		ensureTableFn: func(dataset, table string, schema *bq.TableSchema, partitionField string) error {
+			if dataset != "dataset" || table != DefaultBigQueryTable {
+				t.Errorf("table = %s.%s; want %s.%s", dataset, table, "dataset", DefaultBigQueryTable)
+			}
+			if partitionField != bigQueryPartitionField {
+				t.Errorf("partition field = %v; want %v", partitionField, bigQueryPartitionField)
+			}
+			return nil
+		},
+		insertRowsFn: func(dataset, table string, rows []*bigquery.Row) error {
+			for _, row := range rows {
+				inserted = append(inserted, row.Values)
+			}
+			return nil
+		},
+		queryFn: func(query string, params []*bq.QueryParameter) ([]map[string]interface{}, error) {
+			if !strings.Contains(query, "`project.dataset."+DefaultBigQueryTable+"`") {
+				t.Errorf("query %q does not select from the store table", query)
+			}
+			if len(params) != 2 || params[0].Name != "since" || params[1].Name != "cluster" {
+				t.Errorf("query params = %v; want since and cluster", params)
+			}
+			rows := make([]map[string]interface{}, 0, len(inserted))
+			for _, row := range inserted {
+				result := make(map[string]interface{})
+				for k, v := range row {
+					result[k] = v
+				}
+				result["time_us"] = fmt.Sprintf("%d", runOneTime.UnixMicro())
+				rows = append(rows, result)
+			}
+			return rows, nil
+		},
+	}
+	store, err := NewBigQueryStore(client, "project", "dataset", "")
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	records := getTestRecords()[:3]
+	if err := store.Append(records); err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	if len(inserted) != len(records) {
+		t.Fatalf("number of inserted rows = %v; want %v", len(inserted), len(records))
+	}
+	results, err := store.Records(Filter{Since: runOneTime, ClusterID: "c1"})
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	if !reflect.DeepEqual(results, records) {
+		t.Errorf("records = %v; want %v", results, records)
+	}
+}
+
+func TestBigQueryStoreTrend(t *testing.T) {
+	client := bigQueryClientMock{
+		ensureTableFn: func(dataset, table string, schema *bq.TableSchema, partitionField string) error { return nil },
+		queryFn: func(query string, params []*bq.QueryParameter) ([]map[string]interface{}, error) {
+			if !strings.Contains(query, "GROUP BY run_id") {
+				t.Errorf("query %q does not aggregate the runs", query)
+			}
+			if len(params) != 1 || params[0].Name != "policy" {
+				t.Errorf("query params = %v; want policy", params)
+			}
+			return []map[string]interface{}{
+				{"run_id": "one", "time_us": fmt.Sprintf("%d", runOneTime.UnixMicro()), "valid": "1", "violated": "2", "errored": "0"},
+				{"run_id": "two", "time_us": fmt.Sprintf("%d", runTwoTime.UnixMicro()), "valid": "2", "violated": "0", "errored": "1"},
+			}, nil
+		},
+	}
+	store, err := NewBigQueryStore(client, "project", "dataset", "table")
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	trend, err := store.Trend(Filter{PolicyName: "p1"})
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	expected := []*RunSummary{
+		{RunID: "one", Time: runOneTime, Valid: 1, Violated: 2},
+		{RunID: "two", Time: runTwoTime, Valid: 2, Errored: 1},
+	}
+	if !reflect.DeepEqual(trend, expected) {
+		t.Errorf("trend = %v; want %v", trend, expected)
+	}
+}
+
+func TestBigQueryStoreViolations(t *testing.T) {
+	client := bigQueryClientMock{
+		ensureTableFn: func(dataset, table string, schema *bq.TableSchema, partitionField string) error { return nil },
+		queryFn: func(query string, params []*bq.QueryParameter) ([]map[string]interface{}, error) {
+			if !strings.Contains(query, "WHERE status != 'errored' AND cluster_id = @cluster") {
+				t.Errorf("query %q does not skip errored evaluations of a cluster", query)
+			}
+			return []map[string]interface{}{
+				{"cluster_id": "c1", "policy_name": "p1", "first_seen_us": fmt.Sprintf("%d", runOneTime.UnixMicro()),
+					"last_seen_us": fmt.Sprintf("%d", runOneTime.UnixMicro()), "remediated_us": fmt.Sprintf("%d", runThreeTime.UnixMicro())},
+				{"cluster_id": "c1", "policy_name": "p2", "first_seen_us": fmt.Sprintf("%d", runThreeTime.UnixMicro()),
+					"last_seen_us": fmt.Sprintf("%d", runThreeTime.UnixMicro()), "remediated_us": nil},
+			}, nil
+		},
+	}
+	store, err := NewBigQueryStore(client, "project", "dataset", "table")
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	violations, err := store.Violations(Filter{ClusterID: "c1"})
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	expected := []*Violation{
+		{ClusterID: "c1", PolicyName: "p1", FirstSeen: runOneTime, LastSeen: runOneTime, RemediatedTime: &runThreeTime},
+		{ClusterID: "c1", PolicyName: "p2", FirstSeen: runThreeTime, LastSeen: runThreeTime},
+	}
+	if !reflect.DeepEqual(violations, expected) {
+		t.Errorf("violations = %v; want %v", violations, expected)
+	}
+}
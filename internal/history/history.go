@@ -0,0 +1,55 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package history implements the store of historical validation results
+// along with the compliance trend queries
+package history
+
+import (
+	"time"
+)
+
+const (
+	StatusValid    = "valid"
+	StatusViolated = "violated"
+	StatusErrored  = "errored"
+)
+
+// Record is a result of a single policy evaluation on a cluster in a given run.
+type Record struct {
+	RunID       string    `json:"runID"`
+	Time        time.Time `json:"time"`
+	ClusterID   string    `json:"cluster"`
+	PolicyName  string    `json:"policy"`
+	PolicyGroup string    `json:"group,omitempty"`
+	Severity    string    `json:"severity,omitempty"`
+	Status      string    `json:"status"`
+}
+
+// Filter narrows down the records read from the store. Zero values match all records.
+type Filter struct {
+	Since      time.Time
+	ClusterID  string
+	PolicyName string
+}
+
+// Store appends and reads validation result records, and aggregates them into
+// the compliance trends.
+type Store interface {
+	Append(records []*Record) error
+	Records(filter Filter) ([]*Record, error)
+	Trend(filter Filter) ([]*RunSummary, error)
+	Violations(filter Filter) ([]*Violation, error)
+	Close() error
+}
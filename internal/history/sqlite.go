@@ -0,0 +1,147 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package history
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite" // registers sqlite database driver
+)
+
+const (
+	sqliteDriverName = "sqlite"
+
+	sqliteCreateTableStatement = `CREATE TABLE IF NOT EXISTS validation_results (
+	run_id TEXT NOT NULL,
+	time INTEGER NOT NULL,
+	cluster_id TEXT NOT NULL,
+	policy_name TEXT NOT NULL,
+	policy_group TEXT,
+	severity TEXT,
+	status TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS validation_results_time ON validation_results (time);
+CREATE INDEX IF NOT EXISTS validation_results_cluster_policy ON validation_results (cluster_id, policy_name);`
+
+	sqliteInsertStatement = `INSERT INTO validation_results
+	(run_id, time, cluster_id, policy_name, policy_group, severity, status) VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	sqliteSelectStatement = `SELECT run_id, time, cluster_id, policy_name, policy_group, severity, status
+	FROM validation_results`
+)
+
+type sqliteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore returns store backed by a given SQLite database file. The database
+// and the table are created when missing.
+func NewSQLiteStore(file string) (Store, error) {
+	db, err := sql.Open(sqliteDriverName, file)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(sqliteCreateTableStatement); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &sqliteStore{db: db}, nil
+}
+
+func (s *sqliteStore) Append(records []*Record) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	stmt, err := tx.Prepare(sqliteInsertStatement)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+	for _, r := range records {
+		if _, err := stmt.Exec(r.RunID, r.Time.UnixMicro(), r.ClusterID, r.PolicyName, r.PolicyGroup, r.Severity, r.Status); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *sqliteStore) Records(filter Filter) ([]*Record, error) {
+	var conditions []string
+	var args []interface{}
+	if !filter.Since.IsZero() {
+		conditions = append(conditions, "time >= ?")
+		args = append(args, filter.Since.UnixMicro())
+	}
+	if filter.ClusterID != "" {
+		conditions = append(conditions, "cluster_id = ?")
+		args = append(args, filter.ClusterID)
+	}
+	if filter.PolicyName != "" {
+		conditions = append(conditions, "policy_name = ?")
+		args = append(args, filter.PolicyName)
+	}
+	query := sqliteSelectStatement
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	rows, err := s.db.Query(query+" ORDER BY time", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	records := make([]*Record, 0)
+	for rows.Next() {
+		r := &Record{}
+		var timeMicros int64
+		var group, severity sql.NullString
+		if err := rows.Scan(&r.RunID, &timeMicros, &r.ClusterID, &r.PolicyName, &group, &severity, &r.Status); err != nil {
+			return nil, err
+		}
+		r.Time = time.UnixMicro(timeMicros).UTC()
+		r.PolicyGroup = group.String
+		r.Severity = severity.String
+		records = append(records, r)
+	}
+	return records, rows.Err()
+}
+
+// Trend returns summaries of the runs of the records matching a given filter. The local
+// database records are aggregated in memory.
+func (s *sqliteStore) Trend(filter Filter) ([]*RunSummary, error) {
+	records, err := s.Records(filter)
+	if err != nil {
+		return nil, err
+	}
+	return Trend(records), nil
+}
+
+// Violations returns violations found in the records matching a given filter. The local
+// database records are aggregated in memory.
+func (s *sqliteStore) Violations(filter Filter) ([]*Violation, error) {
+	records, err := s.Records(filter)
+	if err != nil {
+		return nil, err
+	}
+	return Violations(records), nil
+}
+
+func (s *sqliteStore) Close() error {
+	return s.db.Close()
+}
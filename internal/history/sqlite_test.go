@@ -0,0 +1,71 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package history
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSQLiteStore(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "history.db")
+	store, err := NewSQLiteStore(file)
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	records := getTestRecords()
+	if err := store.Append(records); err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+
+	store, err = NewSQLiteStore(file)
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	defer store.Close()
+	results, err := store.Records(Filter{})
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	if len(results) != len(records) {
+		t.Fatalf("number of records = %v; want %v", len(results), len(records))
+	}
+	results, err = store.Records(Filter{Since: runTwoTime, ClusterID: "c1", PolicyName: "p1"})
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	expected := []*Record{records[3], records[6]}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("records = %v; want %v", results, expected)
+	}
+	trend, err := store.Trend(Filter{})
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	if !reflect.DeepEqual(trend, Trend(records)) {
+		t.Errorf("trend = %v; want %v", trend, Trend(records))
+	}
+	violations, err := store.Violations(Filter{ClusterID: "c2"})
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	if len(violations) != 1 || violations[0].ClusterID != "c2" {
+		t.Errorf("violations = %v; want single violation of c2", violations)
+	}
+}
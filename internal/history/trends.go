@@ -0,0 +1,163 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package history
+
+import (
+	"sort"
+	"time"
+)
+
+// RunSummary is a number of policy evaluations with a given status in a single run.
+type RunSummary struct {
+	RunID    string    `json:"runID"`
+	Time     time.Time `json:"time"`
+	Valid    int       `json:"valid"`
+	Violated int       `json:"violated"`
+	Errored  int       `json:"errored"`
+}
+
+// Compliance returns percentage of valid evaluations among valid and violated ones.
+func (s *RunSummary) Compliance() float64 {
+	if s.Valid+s.Violated == 0 {
+		return 100
+	}
+	return float64(s.Valid) * 100 / float64(s.Valid+s.Violated)
+}
+
+// Violation is a period of time when a policy was violated on a cluster. The violation
+// is remediated when the policy was valid in a run following the last violated one.
+type Violation struct {
+	ClusterID      string     `json:"cluster"`
+	PolicyName     string     `json:"policy"`
+	FirstSeen      time.Time  `json:"firstSeen"`
+	LastSeen       time.Time  `json:"lastSeen"`
+	RemediatedTime *time.Time `json:"remediated,omitempty"`
+}
+
+// IsOpen checks if the violation was not remediated yet.
+func (v *Violation) IsOpen() bool {
+	return v.RemediatedTime == nil
+}
+
+// PolicyRemediation is the mean time to remediate the violations of a policy.
+type PolicyRemediation struct {
+	PolicyName          string        `json:"policy"`
+	Remediated          int           `json:"remediated"`
+	Open                int           `json:"open"`
+	MeanTimeToRemediate time.Duration `json:"meanTimeToRemediate"`
+}
+
+// Trend returns summaries of the runs of given records, ordered by the run time.
+func Trend(records []*Record) []*RunSummary {
+	runs := make(map[string]*RunSummary)
+	for _, record := range records {
+		run, ok := runs[record.RunID]
+		if !ok {
+			run = &RunSummary{RunID: record.RunID, Time: record.Time}
+			runs[record.RunID] = run
+		}
+		switch record.Status {
+		case StatusValid:
+			run.Valid++
+		case StatusViolated:
+			run.Violated++
+		case StatusErrored:
+			run.Errored++
+		}
+	}
+	summaries := make([]*RunSummary, 0, len(runs))
+	for _, run := range runs {
+		summaries = append(summaries, run)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Time.Equal(summaries[j].Time) {
+			return summaries[i].RunID < summaries[j].RunID
+		}
+		return summaries[i].Time.Before(summaries[j].Time)
+	})
+	return summaries
+}
+
+// Violations returns violations found in given records, ordered by the cluster, policy and
+// the first seen time. Errored evaluations neither open nor remediate the violations.
+func Violations(records []*Record) []*Violation {
+	sorted := make([]*Record, len(records))
+	copy(sorted, records)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Time.Before(sorted[j].Time)
+	})
+	open := make(map[[2]string]*Violation)
+	violations := make([]*Violation, 0)
+	for _, record := range sorted {
+		key := [2]string{record.ClusterID, record.PolicyName}
+		violation, ok := open[key]
+		switch record.Status {
+		case StatusViolated:
+			if !ok {
+				violation = &Violation{ClusterID: record.ClusterID, PolicyName: record.PolicyName, FirstSeen: record.Time}
+				open[key] = violation
+				violations = append(violations, violation)
+			}
+			violation.LastSeen = record.Time
+		case StatusValid:
+			if ok {
+				remediatedTime := record.Time
+				violation.RemediatedTime = &remediatedTime
+				delete(open, key)
+			}
+		}
+	}
+	sort.SliceStable(violations, func(i, j int) bool {
+		if violations[i].ClusterID != violations[j].ClusterID {
+			return violations[i].ClusterID < violations[j].ClusterID
+		}
+		if violations[i].PolicyName != violations[j].PolicyName {
+			return violations[i].PolicyName < violations[j].PolicyName
+		}
+		return violations[i].FirstSeen.Before(violations[j].FirstSeen)
+	})
+	return violations
+}
+
+// MeanTimeToRemediate returns mean time to remediate the violations of each policy,
+// ordered by the policy name.
+func MeanTimeToRemediate(violations []*Violation) []*PolicyRemediation {
+	policies := make(map[string]*PolicyRemediation)
+	totals := make(map[string]time.Duration)
+	for _, violation := range violations {
+		policy, ok := policies[violation.PolicyName]
+		if !ok {
+			policy = &PolicyRemediation{PolicyName: violation.PolicyName}
+			policies[violation.PolicyName] = policy
+		}
+		if violation.IsOpen() {
+			policy.Open++
+			continue
+		}
+		policy.Remediated++
+		totals[violation.PolicyName] += violation.RemediatedTime.Sub(violation.FirstSeen)
+	}
+	results := make([]*PolicyRemediation, 0, len(policies))
+	for name, policy := range policies {
+		if policy.Remediated > 0 {
+			policy.MeanTimeToRemediate = totals[name] / time.Duration(policy.Remediated)
+		}
+		results = append(results, policy)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].PolicyName < results[j].PolicyName
+	})
+	return results
+}
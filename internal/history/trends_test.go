@@ -0,0 +1,85 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package history
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+var (
+	runOneTime   = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	runTwoTime   = runOneTime.Add(24 * time.Hour)
+	runThreeTime = runTwoTime.Add(24 * time.Hour)
+)
+
+func getTestRecords() []*Record {
+	return []*Record{
+		{RunID: "one", Time: runOneTime, ClusterID: "c1", PolicyName: "p1", Status: StatusViolated},
+		{RunID: "one", Time: runOneTime, ClusterID: "c1", PolicyName: "p2", Status: StatusValid},
+		{RunID: "one", Time: runOneTime, ClusterID: "c2", PolicyName: "p1", Status: StatusViolated},
+		{RunID: "two", Time: runTwoTime, ClusterID: "c1", PolicyName: "p1", Status: StatusErrored},
+		{RunID: "two", Time: runTwoTime, ClusterID: "c1", PolicyName: "p2", Status: StatusValid},
+		{RunID: "two", Time: runTwoTime, ClusterID: "c2", PolicyName: "p1", Status: StatusValid},
+		{RunID: "three", Time: runThreeTime, ClusterID: "c1", PolicyName: "p1", Status: StatusValid},
+		{RunID: "three", Time: runThreeTime, ClusterID: "c1", PolicyName: "p2", Status: StatusViolated},
+		{RunID: "three", Time: runThreeTime, ClusterID: "c2", PolicyName: "p1", Status: StatusValid},
+	}
+}
+
+func TestTrend(t *testing.T) {
+	trend := Trend(getTestRecords())
+	expected := []*RunSummary{
+		{RunID: "one", Time: runOneTime, Valid: 1, Violated: 2},
+		{RunID: "two", Time: runTwoTime, Valid: 2, Errored: 1},
+		{RunID: "three", Time: runThreeTime, Valid: 2, Violated: 1},
+	}
+	if !reflect.DeepEqual(trend, expected) {
+		t.Fatalf("trend = %v; want %v", trend, expected)
+	}
+	if compliance := trend[1].Compliance(); compliance != 100 {
+		t.Errorf("run two compliance = %v; want %v", compliance, 100)
+	}
+	if compliance := (&RunSummary{}).Compliance(); compliance != 100 {
+		t.Errorf("empty run compliance = %v; want %v", compliance, 100)
+	}
+}
+
+func TestViolations(t *testing.T) {
+	violations := Violations(getTestRecords())
+	expected := []*Violation{
+		{ClusterID: "c1", PolicyName: "p1", FirstSeen: runOneTime, LastSeen: runOneTime, RemediatedTime: &runThreeTime},
+		{ClusterID: "c1", PolicyName: "p2", FirstSeen: runThreeTime, LastSeen: runThreeTime},
+		{ClusterID: "c2", PolicyName: "p1", FirstSeen: runOneTime, LastSeen: runOneTime, RemediatedTime: &runTwoTime},
+	}
+	if !reflect.DeepEqual(violations, expected) {
+		t.Fatalf("violations = %v; want %v", violations, expected)
+	}
+	if !violations[1].IsOpen() {
+		t.Errorf("violation %v is not open; want open", violations[1])
+	}
+}
+
+func TestMeanTimeToRemediate(t *testing.T) {
+	remediations := MeanTimeToRemediate(Violations(getTestRecords()))
+	expected := []*PolicyRemediation{
+		{PolicyName: "p1", Remediated: 2, MeanTimeToRemediate: 36 * time.Hour},
+		{PolicyName: "p2", Open: 1},
+	}
+	if !reflect.DeepEqual(remediations, expected) {
+		t.Errorf("remediations = %v; want %v", remediations, expected)
+	}
+}
@@ -231,6 +231,12 @@ func (c *kubernetesClient) GetResources(toBeFetched []*ResourceType, namespaces
 
 func (c *kubernetesClient) getNamespaceResourcesByResourceTypeAsync(wg *sync.WaitGroup, toBeFetched []*ResourceType, namespaces <-chan string, results chan<- []*Resource, errors chan<- error) {
 	for namespace := range namespaces {
+		if err := c.ctx.Err(); err != nil {
+			log.Debugf("fetchNamespace goroutine for namespace: %s stopped: %s", namespace, err)
+			errors <- err
+			wg.Done()
+			return
+		}
 		namespaceResources := make([]*Resource, 0)
 
 		for rt := range toBeFetched {
@@ -343,6 +343,24 @@ func TestGetResourcesWithMultipleGoroutines(t *testing.T) {
 	}
 }
 
+func TestGetResources_canceled(t *testing.T) {
+	resourceType := ResourceType{Group: "apps", Version: "v1", Name: "deployments", Namespaced: true}
+	namespace := "my-namespace"
+	dynCliMock := &kubeDynamicClientMock{
+		ResourceFn: func(resource schema.GroupVersionResource) dynamic.NamespaceableResourceInterface {
+			return createKubeNamespaceResourceMock(namespace, "some-object")
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	client := &kubernetesClient{ctx: ctx, client: dynCliMock, maxGoroutines: 10}
+
+	_, err := client.GetResources([]*ResourceType{&resourceType}, []string{namespace})
+	if err != context.Canceled {
+		t.Errorf("err = %v; want %v", err, context.Canceled)
+	}
+}
+
 func TestGetResourcesWithSync(t *testing.T) {
 
 	resourceOneName := "some-object-one"
@@ -164,7 +164,7 @@ func (m *metricsClient) GetMetric(metricQuery MetricQuery, clusterID string) (*M
 
 	result, warnings, err := m.api.Query(m.ctx, query, time.Now())
 	if err != nil {
-		log.Fatalf("failed to query metrics client: %v", err)
+		log.Errorf("failed to query metrics client: %v", err)
 		return nil, err
 	}
 	if warnings != nil {
@@ -174,7 +174,7 @@ func (m *metricsClient) GetMetric(metricQuery MetricQuery, clusterID string) (*M
 	data, ok := result.(pmodel.Vector)
 	if !ok {
 		badType := reflect.TypeOf(result)
-		log.Fatalf("unsupported result format: %s", badType)
+		log.Errorf("unsupported result format: %s", badType)
 		return nil, fmt.Errorf("unsupported result format: %s", badType)
 	}
 
@@ -208,14 +208,20 @@ func (m *metricsClient) GetMetric(metricQuery MetricQuery, clusterID string) (*M
 func (m *metricsClient) GetMetricsForCluster(queries []MetricQuery, clusterID string) (map[string]Metric, error) {
 
 	metricsResult := make(map[string]Metric)
+	ctx, cancel := context.WithCancel(m.ctx)
+	defer cancel()
 
 	queryChannel := make(chan MetricQuery, m.maxGoRoutines)
 
 	go func() {
+		defer close(queryChannel)
 		for _, q := range queries {
-			queryChannel <- q
+			select {
+			case queryChannel <- q:
+			case <-ctx.Done():
+				return
+			}
 		}
-		close(queryChannel)
 	}()
 
 	resultsChannel := make(chan *Metric, m.maxGoRoutines)
@@ -228,17 +234,28 @@ func (m *metricsClient) GetMetricsForCluster(queries []MetricQuery, clusterID st
 		for gr := 0; gr < m.maxGoRoutines; gr++ {
 			log.Debugf("Starting getMetrics goroutine")
 			go func() {
+				defer wg.Done()
 				for q := range queryChannel {
+					if ctx.Err() != nil {
+						return
+					}
 					log.Debugf("getMetric for cluster %s, query %q", clusterID, q)
 					metric, err := m.GetMetric(q, clusterID)
 					if err != nil {
 						log.Debugf("unable to get metric for cluster: %s, query: %s, reason: %s", clusterID, q, err)
-						errorChannel <- err
+						select {
+						case errorChannel <- err:
+						case <-ctx.Done():
+							return
+						}
 					} else {
-						resultsChannel <- metric
+						select {
+						case resultsChannel <- metric:
+						case <-ctx.Done():
+							return
+						}
 					}
 				}
-				wg.Done()
 			}()
 		}
 		wg.Wait()
@@ -247,16 +264,29 @@ func (m *metricsClient) GetMetricsForCluster(queries []MetricQuery, clusterID st
 
 	}()
 
-	for err := range errorChannel {
-		switch err.(type) {
-		case *emptyResultError:
-			log.Warnf("metric fetch error: %s", err)
-		default:
-			return nil, err
+	for resultsChannel != nil || errorChannel != nil {
+		select {
+		case result, ok := <-resultsChannel:
+			if !ok {
+				resultsChannel = nil
+				continue
+			}
+			metricsResult[result.Name] = *result
+		case err, ok := <-errorChannel:
+			if !ok {
+				errorChannel = nil
+				continue
+			}
+			switch err.(type) {
+			case *emptyResultError:
+				log.Warnf("metric fetch error: %s", err)
+			default:
+				return nil, err
+			}
 		}
 	}
-	for result := range resultsChannel {
-		metricsResult[result.Name] = *result
+	if err := m.ctx.Err(); err != nil {
+		return nil, err
 	}
 	return metricsResult, nil
 }
@@ -279,6 +279,43 @@ func TestGetMetricsForCluster(t *testing.T) {
 	}
 }
 
+func TestGetMetricsForCluster_errorBeforeResults(t *testing.T) {
+	queries := make([]MetricQuery, 0)
+	for i := 0; i < 10; i++ {
+		queries = append(queries, MetricQuery{Query: fmt.Sprintf("query-%d", i), Name: fmt.Sprintf("metric-%d", i)})
+	}
+	v1ApiMock := &metricsAPIClientMock{
+		QueryFn: func(ctx context.Context, query string, ts time.Time, opts ...v1.Option) (pmodel.Value, v1.Warnings, error) {
+			if query == "query-9" {
+				return nil, nil, fmt.Errorf("query error")
+			}
+			return pmodel.Vector{&pmodel.Sample{Value: 1, Timestamp: pmodel.Now()}}, nil, nil
+		},
+	}
+	client := &metricsClient{ctx: context.TODO(), client: nil, api: v1ApiMock, maxGoRoutines: 1}
+
+	_, err := client.GetMetricsForCluster(queries, "sample-cluster")
+	if err == nil {
+		t.Fatalf("err is nil; want error")
+	}
+}
+
+func TestGetMetricsForCluster_canceled(t *testing.T) {
+	v1ApiMock := &metricsAPIClientMock{
+		QueryFn: func(ctx context.Context, query string, ts time.Time, opts ...v1.Option) (pmodel.Value, v1.Warnings, error) {
+			return pmodel.Vector{&pmodel.Sample{Value: 1, Timestamp: pmodel.Now()}}, nil, nil
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	client := &metricsClient{ctx: ctx, client: nil, api: v1ApiMock, maxGoRoutines: defaultMaxGoroutines}
+
+	_, err := client.GetMetricsForCluster([]MetricQuery{{Query: "test-query", Name: "test-metric"}}, "sample-cluster")
+	if err != context.Canceled {
+		t.Errorf("err = %v; want %v", err, context.Canceled)
+	}
+}
+
 func TestReplaceAllWildcards(t *testing.T) {
 	query := "sum by (node) (kube_pod_info{cluster=$CLUSTER_NAME,location=$CLUSTER_LOCATION,project_id=$CLUSTER_PROJECT})"
 	clusterProjectID := "demo-project-123"
@@ -0,0 +1,210 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inputs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+
+	"cloud.google.com/go/container/apiv1/containerpb"
+	"github.com/google/gke-policy-automation/internal/gke"
+)
+
+const (
+	configConnectorInputID          = "configConnector"
+	configConnectorDataSourceName   = "gke"
+	configConnectorInputDescription = "GKE cluster data from Config Connector manifests"
+)
+
+// configConnectorFieldAliases maps Config Connector fields, converted to the snake case,
+// that are named differently in the Terraform provider.
+var configConnectorFieldAliases = map[string]string{
+	"boot_disk_kms_crypto_key": "boot_disk_kms_key",
+	"identity_namespace":       "workload_pool",
+	"node_count":               "initial_node_count",
+}
+
+type configConnectorInput struct {
+	readDirFunc func(dir string) ([]*gke.ConfigConnectorResource, error)
+	directory   string
+	once        sync.Once
+	clusters    map[string]*containerpb.Cluster
+	err         error
+}
+
+// NewConfigConnectorInput returns an input that maps Config Connector ContainerCluster
+// and ContainerNodePool resources from YAML manifests in a given directory.
+func NewConfigConnectorInput(directory string) Input {
+	return &configConnectorInput{
+		readDirFunc: gke.ReadConfigConnectorResources,
+		directory:   directory,
+	}
+}
+
+func (i *configConnectorInput) GetID() string {
+	return configConnectorInputID
+}
+
+func (i *configConnectorInput) GetDescription() string {
+	return configConnectorInputDescription
+}
+
+func (i *configConnectorInput) GetDataSourceName() string {
+	return configConnectorDataSourceName
+}
+
+func (i *configConnectorInput) GetData(ctx context.Context, clusterID string) (interface{}, error) {
+	i.once.Do(func() {
+		var resources []*gke.ConfigConnectorResource
+		if resources, i.err = i.readDirFunc(i.directory); i.err == nil {
+			i.clusters = mapConfigConnectorClusters(resources)
+		}
+	})
+	if i.err != nil {
+		return nil, i.err
+	}
+	cluster, ok := i.clusters[clusterID]
+	if !ok {
+		return nil, fmt.Errorf("cluster %s not found in Config Connector manifests", clusterID)
+	}
+	return cluster, nil
+}
+
+func (i *configConnectorInput) Close() error {
+	return nil
+}
+
+// mapConfigConnectorClusters maps Config Connector resources to the GKE API cluster representation.
+// Resource specs are normalized to the Terraform provider values, as Config Connector resources
+// are generated from the Terraform provider.
+func mapConfigConnectorClusters(resources []*gke.ConfigConnectorResource) map[string]*containerpb.Cluster {
+	clusters := make(map[string]*containerpb.Cluster)
+	clustersByRef := make(map[string]*containerpb.Cluster)
+	for _, r := range resources {
+		if r.Kind != gke.ConfigConnectorClusterKind {
+			continue
+		}
+		values := normalizeConfigConnectorSpec(r.Spec)
+		values["name"] = r.GetResourceName()
+		cluster := mapTerraformCluster(values)
+		clusterID := r.GetClusterID()
+		clusters[clusterID] = cluster
+		clustersByRef[clusterID] = cluster
+		clustersByRef[r.Metadata.Namespace+"/"+r.Metadata.Name] = cluster
+	}
+	for _, r := range resources {
+		if r.Kind != gke.ConfigConnectorNodePoolKind {
+			continue
+		}
+		cluster := findConfigConnectorNodePoolCluster(r, clustersByRef)
+		if cluster == nil {
+			continue
+		}
+		values := normalizeConfigConnectorSpec(r.Spec)
+		values["name"] = r.GetResourceName()
+		cluster.NodePools = append(cluster.NodePools, mapTerraformNodePool(values))
+	}
+	return clusters
+}
+
+func findConfigConnectorNodePoolCluster(r *gke.ConfigConnectorResource, clustersByRef map[string]*containerpb.Cluster) *containerpb.Cluster {
+	ref, _ := r.Spec["clusterRef"].(map[string]interface{})
+	if external, ok := ref["external"].(string); ok && external != "" {
+		if cluster, ok := clustersByRef[external]; ok {
+			return cluster
+		}
+		for _, cluster := range clustersByRef {
+			if cluster.Name == external {
+				return cluster
+			}
+		}
+		return nil
+	}
+	name, _ := ref["name"].(string)
+	namespace, _ := ref["namespace"].(string)
+	if namespace == "" {
+		namespace = r.Metadata.Namespace
+	}
+	return clustersByRef[namespace+"/"+name]
+}
+
+// normalizeConfigConnectorSpec converts Config Connector resource spec to the form of
+// Terraform planned values: keys are converted to the snake case, nested objects are
+// wrapped into single element lists and resource references are replaced with their values.
+func normalizeConfigConnectorSpec(spec map[string]interface{}) map[string]interface{} {
+	values := make(map[string]interface{}, len(spec))
+	for k, v := range spec {
+		if strings.HasSuffix(k, "Ref") {
+			if ref, ok := v.(map[string]interface{}); ok {
+				k = strings.TrimSuffix(k, "Ref")
+				v = getConfigConnectorRefValue(ref)
+			}
+		}
+		key := camelToSnakeCase(k)
+		if alias, ok := configConnectorFieldAliases[key]; ok {
+			key = alias
+		}
+		values[key] = normalizeConfigConnectorValue(v)
+	}
+	return values
+}
+
+func normalizeConfigConnectorValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		return []interface{}{normalizeConfigConnectorSpec(value)}
+	case []interface{}:
+		list := make([]interface{}, len(value))
+		for i := range value {
+			if m, ok := value[i].(map[string]interface{}); ok {
+				list[i] = normalizeConfigConnectorSpec(m)
+			} else {
+				list[i] = normalizeConfigConnectorValue(value[i])
+			}
+		}
+		return list
+	case int:
+		return float64(value)
+	default:
+		return v
+	}
+}
+
+func getConfigConnectorRefValue(ref map[string]interface{}) string {
+	if external, ok := ref["external"].(string); ok && external != "" {
+		return external
+	}
+	name, _ := ref["name"].(string)
+	return name
+}
+
+func camelToSnakeCase(s string) string {
+	runes := []rune(s)
+	var sb strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				sb.WriteRune('_')
+			}
+		}
+		sb.WriteRune(unicode.ToLower(r))
+	}
+	return sb.String()
+}
@@ -0,0 +1,118 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inputs
+
+import (
+	"context"
+	"testing"
+
+	"cloud.google.com/go/container/apiv1/containerpb"
+)
+
+const configConnectorTestDir = "../gke/test-fixtures/config-connector"
+
+func TestNewConfigConnectorInput(t *testing.T) {
+	input := NewConfigConnectorInput(configConnectorTestDir)
+	ccInput, ok := input.(*configConnectorInput)
+	if !ok {
+		t.Fatalf("input type is not *configConnectorInput")
+	}
+	if ccInput.directory != configConnectorTestDir {
+		t.Errorf("input directory = %v; want %v", ccInput.directory, configConnectorTestDir)
+	}
+	if ccInput.GetID() != configConnectorInputID {
+		t.Errorf("id = %v; want %v", ccInput.GetID(), configConnectorInputID)
+	}
+	if ccInput.GetDataSourceName() != gkeLocalDataSourceName {
+		t.Errorf("data source name = %v; want %v", ccInput.GetDataSourceName(), gkeLocalDataSourceName)
+	}
+}
+
+func TestConfigConnectorGetData(t *testing.T) {
+	input := NewConfigConnectorInput(configConnectorTestDir)
+	data, err := input.GetData(context.Background(), "projects/my-project/locations/europe-west2/clusters/cluster-one")
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	cluster, ok := data.(*containerpb.Cluster)
+	if !ok {
+		t.Fatalf("data is not *containerpb.Cluster")
+	}
+	if cluster.Name != "cluster-one" {
+		t.Errorf("name = %v; want %v", cluster.Name, "cluster-one")
+	}
+	if cluster.Network != "my-network" {
+		t.Errorf("network = %v; want %v", cluster.Network, "my-network")
+	}
+	if cluster.ReleaseChannel.GetChannel() != containerpb.ReleaseChannel_REGULAR {
+		t.Errorf("release channel = %v; want %v", cluster.ReleaseChannel.GetChannel(), containerpb.ReleaseChannel_REGULAR)
+	}
+	if !cluster.NetworkConfig.GetEnableL4IlbSubsetting() {
+		t.Errorf("L4 ILB subsetting = false; want true")
+	}
+	if cluster.WorkloadIdentityConfig.GetWorkloadPool() != "my-project.svc.id.goog" {
+		t.Errorf("workload pool = %v; want %v", cluster.WorkloadIdentityConfig.GetWorkloadPool(), "my-project.svc.id.goog")
+	}
+	if len(cluster.MasterAuthorizedNetworksConfig.GetCidrBlocks()) != 1 {
+		t.Errorf("number of cidr blocks = %v; want %v", len(cluster.MasterAuthorizedNetworksConfig.GetCidrBlocks()), 1)
+	}
+	if cluster.NotificationConfig.GetPubsub().GetTopic() != "projects/my-project/topics/cluster-updates" {
+		t.Errorf("notification topic = %v; want %v", cluster.NotificationConfig.GetPubsub().GetTopic(), "projects/my-project/topics/cluster-updates")
+	}
+	if len(cluster.NodePools) != 1 {
+		t.Fatalf("number of node pools = %v; want %v", len(cluster.NodePools), 1)
+	}
+	nodePool := cluster.NodePools[0]
+	if nodePool.Name != "pool-one" || nodePool.InitialNodeCount != 2 {
+		t.Errorf("node pool = %v/%v; want %v/%v", nodePool.Name, nodePool.InitialNodeCount, "pool-one", 2)
+	}
+	if nodePool.Autoscaling.GetMaxNodeCount() != 5 {
+		t.Errorf("node pool max node count = %v; want %v", nodePool.Autoscaling.GetMaxNodeCount(), 5)
+	}
+	if nodePool.Config.GetServiceAccount() != "nodes@my-project.iam.gserviceaccount.com" {
+		t.Errorf("node pool service account = %v; want %v", nodePool.Config.GetServiceAccount(), "nodes@my-project.iam.gserviceaccount.com")
+	}
+	if nodePool.Config.GetBootDiskKmsKey() == "" {
+		t.Errorf("node pool boot disk kms key is empty")
+	}
+	if !nodePool.Config.GetShieldedInstanceConfig().GetEnableIntegrityMonitoring() {
+		t.Errorf("node pool integrity monitoring = false; want true")
+	}
+}
+
+func TestConfigConnectorGetData_negative(t *testing.T) {
+	input := NewConfigConnectorInput(configConnectorTestDir)
+	if _, err := input.GetData(context.Background(), "projects/p/locations/l/clusters/missing"); err == nil {
+		t.Errorf("err = nil; want error for missing cluster")
+	}
+	input = NewConfigConnectorInput("../gke/test-fixtures/does-not-exist")
+	if _, err := input.GetData(context.Background(), "projects/p/locations/l/clusters/c"); err == nil {
+		t.Errorf("err = nil; want error for missing directory")
+	}
+}
+
+func TestCamelToSnakeCase(t *testing.T) {
+	testCases := map[string]string{
+		"location":                "location",
+		"enableL4IlbSubsetting":   "enable_l4_ilb_subsetting",
+		"bootDiskKMSCryptoKeyRef": "boot_disk_kms_crypto_key_ref",
+		"podIpv4CidrBlock":        "pod_ipv4_cidr_block",
+	}
+	for input, expected := range testCases {
+		if result := camelToSnakeCase(input); result != expected {
+			t.Errorf("camelToSnakeCase(%q) = %v; want %v", input, result, expected)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inputs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/google/gke-policy-automation/internal/gke"
+	"github.com/google/gke-policy-automation/internal/log"
+	"github.com/google/gke-policy-automation/internal/retry"
+	"google.golang.org/api/gkehub/v1"
+)
+
+const (
+	fleetInputID          = "fleet"
+	fleetDataSourceName   = "fleet"
+	fleetInputDescription = "Fleet membership and feature data from GKE Hub API"
+)
+
+// FleetMembership is the Fleet data of a cluster: its membership and the states
+// and specs of the features (i.e. configmanagement, policycontroller, servicemesh)
+// for that membership.
+type FleetMembership struct {
+	Membership *gkehub.Membership                 `json:"membership"`
+	Features   map[string]*FleetMembershipFeature `json:"features"`
+}
+
+type FleetMembershipFeature struct {
+	State *gkehub.MembershipFeatureState `json:"state"`
+	Spec  *gkehub.MembershipFeatureSpec  `json:"spec"`
+}
+
+type fleetInput struct {
+	ctx      context.Context
+	client   gke.FleetClient
+	projects []string
+	once     sync.Once
+	members  map[string]*FleetMembership
+	err      error
+}
+
+func NewFleetInput(ctx context.Context, projects []string, retrier *retry.Retrier) (Input, error) {
+	client, err := gke.NewFleetClient(ctx, retrier)
+	if err != nil {
+		return nil, err
+	}
+	return &fleetInput{ctx: ctx, client: client, projects: projects}, nil
+}
+
+func NewFleetInputWithCredentials(ctx context.Context, credentialsFile string, projects []string, retrier *retry.Retrier) (Input, error) {
+	client, err := gke.NewFleetClientWithCredentialsFile(ctx, credentialsFile, retrier)
+	if err != nil {
+		return nil, err
+	}
+	return &fleetInput{ctx: ctx, client: client, projects: projects}, nil
+}
+
+func (i *fleetInput) GetID() string {
+	return fleetInputID
+}
+
+func (i *fleetInput) GetDescription() string {
+	return fleetInputDescription
+}
+
+func (i *fleetInput) GetDataSourceName() string {
+	return fleetDataSourceName
+}
+
+// GetData returns Fleet data of a given cluster. Clusters that are not registered
+// in any of the Fleet host projects have no Fleet data.
+func (i *fleetInput) GetData(ctx context.Context, clusterID string) (interface{}, error) {
+	i.once.Do(func() {
+		i.members, i.err = i.loadMemberships()
+	})
+	if i.err != nil {
+		return nil, i.err
+	}
+	member, ok := i.members[clusterID]
+	if !ok {
+		return nil, fmt.Errorf("cluster %s is not a fleet member: %w", clusterID, ErrDataNotApplicable)
+	}
+	return member, nil
+}
+
+func (i *fleetInput) Close() error {
+	return nil
+}
+
+// loadMemberships fetches memberships and features of all the Fleet host projects
+// and maps them by the cluster identifiers.
+func (i *fleetInput) loadMemberships() (map[string]*FleetMembership, error) {
+	members := make(map[string]*FleetMembership)
+	for _, project := range i.projects {
+		log.Debugf("fetching fleet memberships and features in project %s", project)
+		memberships, err := i.client.ListMemberships(i.ctx, project)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list fleet memberships in project %s: %w", project, err)
+		}
+		features, err := i.client.ListFeatures(i.ctx, project)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list fleet features in project %s: %w", project, err)
+		}
+		for _, membership := range memberships {
+			members[gke.GetFleetMembershipClusterID(membership)] = &FleetMembership{
+				Membership: membership,
+				Features:   getMembershipFeatures(membership.Name, features),
+			}
+		}
+	}
+	return members, nil
+}
+
+// getMembershipFeatures returns the states and specs of the features for a given membership.
+// Memberships are matched by the location and membership ID, as features may reference
+// the host project by its number.
+func getMembershipFeatures(membershipName string, features []*gkehub.Feature) map[string]*FleetMembershipFeature {
+	result := make(map[string]*FleetMembershipFeature)
+	suffix := getMembershipNameSuffix(membershipName)
+	for _, feature := range features {
+		featureName := feature.Name[strings.LastIndex(feature.Name, "/")+1:]
+		for name, state := range feature.MembershipStates {
+			if getMembershipNameSuffix(name) == suffix {
+				state := state
+				getFleetMembershipFeature(result, featureName).State = &state
+			}
+		}
+		for name, spec := range feature.MembershipSpecs {
+			if getMembershipNameSuffix(name) == suffix {
+				spec := spec
+				getFleetMembershipFeature(result, featureName).Spec = &spec
+			}
+		}
+	}
+	return result
+}
+
+func getFleetMembershipFeature(features map[string]*FleetMembershipFeature, name string) *FleetMembershipFeature {
+	if _, ok := features[name]; !ok {
+		features[name] = &FleetMembershipFeature{}
+	}
+	return features[name]
+}
+
+// getMembershipNameSuffix returns membership name without the project part.
+func getMembershipNameSuffix(name string) string {
+	if i := strings.Index(name, "/locations/"); i >= 0 {
+		return name[i:]
+	}
+	return name
+}
@@ -0,0 +1,115 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inputs
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/api/gkehub/v1"
+)
+
+type fleetClientMock struct {
+	memberships map[string][]*gkehub.Membership
+	features    map[string][]*gkehub.Feature
+}
+
+func (m fleetClientMock) ListMemberships(ctx context.Context, project string) ([]*gkehub.Membership, error) {
+	return m.memberships[project], nil
+}
+
+func (m fleetClientMock) ListFeatures(ctx context.Context, project string) ([]*gkehub.Feature, error) {
+	return m.features[project], nil
+}
+
+func TestFleetInput(t *testing.T) {
+	input := fleetInput{}
+	if id := input.GetID(); id != fleetInputID {
+		t.Errorf("id = %v; want %v", id, fleetInputID)
+	}
+	if desc := input.GetDescription(); desc != fleetInputDescription {
+		t.Errorf("description = %v; want %v", desc, fleetInputDescription)
+	}
+	if dsName := input.GetDataSourceName(); dsName != fleetDataSourceName {
+		t.Errorf("data source name = %v; want %v", dsName, fleetDataSourceName)
+	}
+}
+
+func TestFleetInputGetData(t *testing.T) {
+	gkeClusterID := "projects/my-project/locations/europe-west2/clusters/my-cluster"
+	attachedMembership := "projects/host-project/locations/global/memberships/attached"
+	client := fleetClientMock{
+		memberships: map[string][]*gkehub.Membership{
+			"host-project": {
+				{
+					Name: "projects/host-project/locations/europe-west2/memberships/my-cluster",
+					Endpoint: &gkehub.MembershipEndpoint{
+						GkeCluster: &gkehub.GkeCluster{ResourceLink: "//container.googleapis.com/" + gkeClusterID},
+					},
+				},
+				{
+					Name:     attachedMembership,
+					Endpoint: &gkehub.MembershipEndpoint{MultiCloudCluster: &gkehub.MultiCloudCluster{}},
+				},
+			},
+		},
+		features: map[string][]*gkehub.Feature{
+			"host-project": {
+				{
+					Name: "projects/host-project/locations/global/features/policycontroller",
+					MembershipStates: map[string]gkehub.MembershipFeatureState{
+						"projects/123456/locations/europe-west2/memberships/my-cluster": {State: &gkehub.FeatureState{Code: "OK"}},
+					},
+					MembershipSpecs: map[string]gkehub.MembershipFeatureSpec{
+						"projects/123456/locations/europe-west2/memberships/my-cluster": {Origin: &gkehub.Origin{Type: "FLEET"}},
+					},
+				},
+			},
+		},
+	}
+	input := fleetInput{ctx: context.Background(), client: client, projects: []string{"host-project"}}
+
+	data, err := input.GetData(context.Background(), gkeClusterID)
+	if err != nil {
+		t.Fatalf("err is not nil; want nil; err = %s", err)
+	}
+	member, ok := data.(*FleetMembership)
+	if !ok {
+		t.Fatalf("data is not *FleetMembership")
+	}
+	feature, ok := member.Features["policycontroller"]
+	if !ok {
+		t.Fatalf("policycontroller feature not found")
+	}
+	if feature.State == nil || feature.State.State.Code != "OK" {
+		t.Errorf("policycontroller state = %v; want %v", feature.State, "OK")
+	}
+	if feature.Spec == nil || feature.Spec.Origin.Type != "FLEET" {
+		t.Errorf("policycontroller spec = %v; want origin %v", feature.Spec, "FLEET")
+	}
+
+	data, err = input.GetData(context.Background(), attachedMembership)
+	if err != nil {
+		t.Fatalf("err is not nil; want nil; err = %s", err)
+	}
+	if member := data.(*FleetMembership); len(member.Features) != 0 {
+		t.Errorf("number of features = %v; want %v", len(member.Features), 0)
+	}
+
+	if _, err = input.GetData(context.Background(), "projects/other/locations/europe-west2/clusters/other"); !errors.Is(err, ErrDataNotApplicable) {
+		t.Errorf("err = %v; want %v", err, ErrDataNotApplicable)
+	}
+}
@@ -16,10 +16,13 @@ package inputs
 
 import (
 	"context"
+	"fmt"
 
 	container "cloud.google.com/go/container/apiv1"
 	"cloud.google.com/go/container/apiv1/containerpb"
+	"github.com/google/gke-policy-automation/internal/gke"
 	"github.com/google/gke-policy-automation/internal/log"
+	"github.com/google/gke-policy-automation/internal/retry"
 	"github.com/google/gke-policy-automation/internal/version"
 	gax "github.com/googleapis/gax-go/v2"
 	"google.golang.org/api/option"
@@ -32,8 +35,9 @@ const (
 )
 
 type gkeAPIInput struct {
-	ctx    context.Context
-	client clusterManagerClient
+	ctx     context.Context
+	client  clusterManagerClient
+	retrier *retry.Retrier
 }
 
 type clusterManagerClient interface {
@@ -41,24 +45,26 @@ type clusterManagerClient interface {
 	Close() error
 }
 
-func NewGKEApiInput(ctx context.Context) (Input, error) {
-	return newGKEApiInput(ctx, nil)
+func NewGKEApiInput(ctx context.Context, retrier *retry.Retrier) (Input, error) {
+	return newGKEApiInput(ctx, retrier, nil)
 }
 
-func NewGKEApiInputWithCredentials(ctx context.Context, credentialsFile string) (Input, error) {
+func NewGKEApiInputWithCredentials(ctx context.Context, credentialsFile string, retrier *retry.Retrier) (Input, error) {
 	opts := []option.ClientOption{option.WithCredentialsFile(credentialsFile)}
-	return newGKEApiInput(ctx, opts)
+	return newGKEApiInput(ctx, retrier, opts)
 }
 
-func newGKEApiInput(ctx context.Context, opts []option.ClientOption) (Input, error) {
+func newGKEApiInput(ctx context.Context, retrier *retry.Retrier, opts []option.ClientOption) (Input, error) {
 	opts = append(opts, option.WithUserAgent(version.UserAgent))
+	opts = append(opts, retrier.ClientOptions()...)
 	cli, err := container.NewClusterManagerClient(ctx, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return &gkeAPIInput{
-		ctx:    ctx,
-		client: cli,
+		ctx:     ctx,
+		client:  cli,
+		retrier: retrier,
 	}, nil
 }
 
@@ -74,11 +80,14 @@ func (i *gkeAPIInput) GetDataSourceName() string {
 	return gkeDataSourceName
 }
 
-func (i *gkeAPIInput) GetData(clusterID string) (interface{}, error) {
+func (i *gkeAPIInput) GetData(ctx context.Context, clusterID string) (interface{}, error) {
+	if gke.IsFleetMembershipName(clusterID) {
+		return nil, fmt.Errorf("cluster %s is not a GKE cluster: %w", clusterID, ErrDataNotApplicable)
+	}
 	req := &containerpb.GetClusterRequest{
 		Name: clusterID}
 	log.Debugf("Fetching cluster data with request %v", req)
-	cluster, err := i.client.GetCluster(i.ctx, req)
+	cluster, err := i.client.GetCluster(ctx, req, i.retrier.CallOptions()...)
 	if err != nil {
 		return nil, err
 	}
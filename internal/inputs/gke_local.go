@@ -15,11 +15,12 @@
 package inputs
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"os"
+	"sync"
 
-	"cloud.google.com/go/container/apiv1/containerpb"
+	"github.com/google/gke-policy-automation/internal/gke"
 )
 
 const (
@@ -31,6 +32,9 @@ const (
 type gkeLocalInput struct {
 	readFileFunc func(name string) ([]byte, error)
 	dumpFile     string
+	once         sync.Once
+	dump         *gke.ClusterDump
+	err          error
 }
 
 func NewGKELocalInput(dumpFile string) Input {
@@ -40,6 +44,11 @@ func NewGKELocalInput(dumpFile string) Input {
 	}
 }
 
+// NewGKELocalInputWithDump returns GKE local input for a given, already parsed cluster data dump.
+func NewGKELocalInputWithDump(dump *gke.ClusterDump) Input {
+	return &gkeLocalInput{dump: dump}
+}
+
 func (i *gkeLocalInput) GetID() string {
 	return gkeLocalInputID
 }
@@ -52,21 +61,34 @@ func (i *gkeLocalInput) GetDataSourceName() string {
 	return gkeLocalDataSourceName
 }
 
-func (i *gkeLocalInput) GetData(clusterID string) (interface{}, error) {
-	var clusters []*containerpb.Cluster
-	data, err := i.readFileFunc(i.dumpFile)
+func (i *gkeLocalInput) GetData(ctx context.Context, clusterID string) (interface{}, error) {
+	dump, err := i.getDump()
 	if err != nil {
 		return nil, err
 	}
-	if err = json.Unmarshal(data, &clusters); err != nil {
+	cluster, err := dump.GetCluster(clusterID)
+	if err != nil {
 		return nil, err
 	}
-	for _, cluster := range clusters {
-		if cluster.Name == clusterID {
-			return cluster, nil
-		}
+	if cluster.Cluster == nil {
+		return nil, fmt.Errorf("cluster %s has no GKE data in a dump file: %w", clusterID, ErrDataNotApplicable)
 	}
-	return nil, fmt.Errorf("cluster %s not found in a dump file", clusterID)
+	return cluster.Cluster, nil
+}
+
+// getDump returns cluster data dump, the dump file is read and parsed only once.
+func (i *gkeLocalInput) getDump() (*gke.ClusterDump, error) {
+	i.once.Do(func() {
+		if i.dump != nil {
+			return
+		}
+		var data []byte
+		if data, i.err = i.readFileFunc(i.dumpFile); i.err != nil {
+			return
+		}
+		i.dump, i.err = gke.ParseClusterDump(data)
+	})
+	return i.dump, i.err
 }
 
 func (i *gkeLocalInput) Close() error {
@@ -15,6 +15,7 @@
 package inputs
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
@@ -74,7 +75,7 @@ func TestGKEGetData(t *testing.T) {
 		},
 		dumpFile: fileName,
 	}
-	data, err := input.GetData(clusterName)
+	data, err := input.GetData(context.Background(), clusterName)
 	if err != nil {
 		t.Fatalf("err = %v; want nil", err)
 	}
@@ -90,6 +91,34 @@ func TestGKEGetData(t *testing.T) {
 	}
 }
 
+func TestGKELocalGetData_clusterDump(t *testing.T) {
+	clusterID := "projects/my-project/locations/europe-west2/clusters/cluster-test-01"
+	clusterJSON := fmt.Sprintf(`[{"name": %q, "data": {"gke": {"name": "cluster-test-01", "network": "default"}}}]`, clusterID)
+	reads := 0
+	input := gkeLocalInput{
+		readFileFunc: func(name string) ([]byte, error) {
+			reads++
+			return []byte(clusterJSON), nil
+		},
+		dumpFile: "dump.json",
+	}
+	for _, id := range []string{clusterID, "cluster-test-01"} {
+		data, err := input.GetData(context.Background(), id)
+		if err != nil {
+			t.Fatalf("err = %v; want nil", err)
+		}
+		if cluster := data.(*containerpb.Cluster); cluster.Network != "default" {
+			t.Errorf("network = %v; want %v", cluster.Network, "default")
+		}
+	}
+	if reads != 1 {
+		t.Errorf("number of dump file reads = %v; want %v", reads, 1)
+	}
+	if _, err := input.GetData(context.Background(), "cluster-test-02"); err == nil {
+		t.Errorf("err is nil; want error")
+	}
+}
+
 func TestGKELocalClose(t *testing.T) {
 	input := gkeLocalInput{}
 	if err := input.Close(); err != nil {
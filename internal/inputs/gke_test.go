@@ -22,6 +22,7 @@ import (
 
 	"cloud.google.com/go/container/apiv1/containerpb"
 	"github.com/google/gke-policy-automation/internal/gke"
+	"github.com/google/gke-policy-automation/internal/retry"
 	gax "github.com/googleapis/gax-go/v2"
 )
 
@@ -51,7 +52,7 @@ func (mockClusterManagerClient) Close() error {
 
 func TestNewGKEApiInputWithCredentials(t *testing.T) {
 	testCredsFile := "test-fixtures/test_credentials.json"
-	input, err := NewGKEApiInputWithCredentials(context.Background(), testCredsFile)
+	input, err := NewGKEApiInputWithCredentials(context.Background(), testCredsFile, retry.NewRetrier("container", retry.DefaultSettings()))
 	if err != nil {
 		t.Fatalf("err = %v; want nil", err)
 	}
@@ -77,13 +78,14 @@ func TestGetDescription(t *testing.T) {
 
 func TestGetCluster(t *testing.T) {
 	input := gkeAPIInput{
-		ctx:    context.Background(),
-		client: &mockClusterManagerClient{},
+		ctx:     context.Background(),
+		client:  &mockClusterManagerClient{},
+		retrier: retry.NewRetrier("container", retry.DefaultSettings()),
 	}
 	projectID := "test-project"
 	clusterLocation := "europe-central2"
 	clusterName := "warsaw"
-	data, err := input.GetData(gke.GetClusterID(projectID, clusterLocation, clusterName))
+	data, err := input.GetData(context.Background(), gke.GetClusterID(projectID, clusterLocation, clusterName))
 	if err != nil {
 		t.Fatalf("error when fetching cluster: %v", err)
 	}
@@ -16,9 +16,14 @@
 package inputs
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"sync"
+	"time"
 
+	"cloud.google.com/go/container/apiv1/containerpb"
+	"github.com/google/gke-policy-automation/internal/inputs/schema"
 	"github.com/google/gke-policy-automation/internal/log"
 )
 
@@ -26,17 +31,31 @@ const (
 	defaultMaxDataGetCoroutines = 20
 )
 
+// ErrDataNotApplicable is returned by the inputs that have no data for a given cluster,
+// i.e. GKE API input for an attached cluster. Such cluster is processed without the input data.
+var ErrDataNotApplicable = errors.New("input data is not applicable for the cluster")
+
 type Input interface {
 	GetID() string
 	GetDataSourceName() string
 	GetDescription() string
-	GetData(clusterID string) (interface{}, error)
+	GetData(ctx context.Context, clusterID string) (interface{}, error)
 	Close() error
 }
 
+// Cluster is the data of a single cluster keyed by the data source names. The normalized
+// cluster is set only from the data of the gke data source, and is nil otherwise.
 type Cluster struct {
-	Name string                 `json:"name"`
-	Data map[string]interface{} `json:"data"`
+	Name       string                 `json:"name"`
+	Data       map[string]interface{} `json:"data"`
+	Normalized *schema.Cluster        `json:"cluster,omitempty"`
+}
+
+// ClusterData is the data of a single cluster fetched from all inputs, or the error
+// when the data could not be fetched.
+type ClusterData struct {
+	Cluster *Cluster
+	Err     error
 }
 
 type getDataTask struct {
@@ -53,13 +72,15 @@ type getDataTaskResult struct {
 }
 
 // GetAllInputsData fetches data from given inputs for all given clusters in a concurrent manner
-func GetAllInputsData(inputs []Input, clusterIDs []string) (map[string]*Cluster, []error) {
-	return GetAllInputsDataWithMaxGoRoutines(inputs, clusterIDs, defaultMaxDataGetCoroutines)
+func GetAllInputsData(ctx context.Context, inputs []Input, clusterIDs []string) (map[string]*Cluster, []error) {
+	return GetAllInputsDataWithMaxGoRoutines(ctx, inputs, clusterIDs, defaultMaxDataGetCoroutines)
 }
 
 // GetAllInputsDataWithMaxGoRoutines fetches data from given inputs for all given clusters
-// in a concurrent manner. The maxGoRoutines parameter determines concurrency level
-func GetAllInputsDataWithMaxGoRoutines(inputs []Input, clusterIDs []string, maxGoRoutines int) (map[string]*Cluster, []error) {
+// in a concurrent manner. The maxGoRoutines parameter determines concurrency level.
+// No new fetches are started when the context is done, and the context error is returned
+// along with the data fetched so far.
+func GetAllInputsDataWithMaxGoRoutines(ctx context.Context, inputs []Input, clusterIDs []string, maxGoRoutines int) (map[string]*Cluster, []error) {
 	log.Infof("Fetching data from %d inputs for %d clusters", len(inputs), len(clusterIDs))
 	log.Debugf("using %d maxGoRoutines", maxGoRoutines)
 	tasksChan := make(chan *getDataTask, maxGoRoutines)
@@ -68,12 +89,16 @@ func GetAllInputsDataWithMaxGoRoutines(inputs []Input, clusterIDs []string, maxG
 
 	log.Debugf("starting tasks producing goroutine")
 	go func() {
+		defer close(tasksChan)
 		for _, input := range inputs {
 			for _, clusterID := range clusterIDs {
-				tasksChan <- &getDataTask{input: input, clusterID: clusterID}
+				select {
+				case tasksChan <- &getDataTask{input: input, clusterID: clusterID}:
+				case <-ctx.Done():
+					return
+				}
 			}
 		}
-		close(tasksChan)
 	}()
 
 	log.Debugf("starting tasks consuming goroutine")
@@ -81,23 +106,38 @@ func GetAllInputsDataWithMaxGoRoutines(inputs []Input, clusterIDs []string, maxG
 		var wg sync.WaitGroup
 		for i := 0; i < maxGoRoutines; i++ {
 			wg.Add(1)
-			go getInputData(i, &wg, tasksChan, resultsChan, errorsChan)
+			go getInputData(ctx, i, &wg, tasksChan, resultsChan, errorsChan)
 		}
 		wg.Wait()
 		close(resultsChan)
 		close(errorsChan)
 	}()
 	log.Debugf("processing results and errors")
-	results := processResults(resultsChan)
-	errors := processErrors(errorsChan)
+	var results map[string]*Cluster
+	var errors []error
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		errors = processErrors(errorsChan)
+	}()
+	results = processResults(resultsChan)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		errors = append(errors, fmt.Errorf("fetching data interrupted: %w", err))
+	}
 	return results, errors
 }
 
-func getInputData(i int, wg *sync.WaitGroup, tasks chan *getDataTask, results chan *getDataTaskResult, errors chan *getDataTaskResult) {
+func getInputData(ctx context.Context, i int, wg *sync.WaitGroup, tasks chan *getDataTask, results chan *getDataTaskResult, errors chan *getDataTaskResult) {
 	defer wg.Done()
 	for task := range tasks {
 		log.Debugf("goroutine %d fetching input %s for cluster %s", i, task.input.GetID(), task.clusterID)
-		result, err := task.input.GetData(task.clusterID)
+		result, err := task.input.GetData(ctx, task.clusterID)
+		if isDataNotApplicable(err) {
+			log.Debugf("goroutine %d skipping input %s for cluster %s: %s", i, task.input.GetID(), task.clusterID, err)
+			continue
+		}
 		if err != nil {
 			log.Debugf("goroutine %d fetch error %s", i, err)
 			errors <- &getDataTaskResult{clusterID: task.clusterID, inputID: task.input.GetID(), err: err}
@@ -109,6 +149,10 @@ func getInputData(i int, wg *sync.WaitGroup, tasks chan *getDataTask, results ch
 	log.Debugf("goroutine %d done", i)
 }
 
+func isDataNotApplicable(err error) bool {
+	return errors.Is(err, ErrDataNotApplicable)
+}
+
 func processResults(resultsChan chan *getDataTaskResult) map[string]*Cluster {
 	results := make(map[string]*Cluster)
 	for result := range resultsChan {
@@ -119,9 +163,19 @@ func processResults(resultsChan chan *getDataTaskResult) map[string]*Cluster {
 		data.Data[result.dataSourceName] = result.result
 		results[result.clusterID] = data
 	}
+	for _, cluster := range results {
+		normalizeClusterData(cluster)
+	}
 	return results
 }
 
+// normalizeClusterData sets the normalized view of a cluster from its GKE data source.
+func normalizeClusterData(cluster *Cluster) {
+	if gkeData, ok := cluster.Data[gkeDataSourceName].(*containerpb.Cluster); ok {
+		cluster.Normalized = schema.NormalizeCluster(cluster.Name, gkeData)
+	}
+}
+
 func processErrors(errorsChan chan *getDataTaskResult) []error {
 	errors := make([]error, 0, len(errorsChan))
 	for err := range errorsChan {
@@ -131,3 +185,109 @@ func processErrors(errorsChan chan *getDataTaskResult) []error {
 	}
 	return errors
 }
+
+// StreamAllInputsData fetches data from given inputs for all given clusters in a streaming manner.
+// The inputTimeout limits the time of fetching the data of a single cluster from a single input,
+// zero value means no limit.
+func StreamAllInputsData(ctx context.Context, inputs []Input, clusterIDs []string, inputTimeout time.Duration) <-chan *ClusterData {
+	return StreamAllInputsDataWithMaxGoRoutines(ctx, inputs, clusterIDs, inputTimeout, defaultMaxDataGetCoroutines)
+}
+
+// StreamAllInputsDataWithMaxGoRoutines fetches data from given inputs for all given clusters,
+// with at most maxGoRoutines clusters fetched concurrently. The data of each cluster is sent to
+// the returned channel as soon as it is fetched from all inputs, so only a bounded number of clusters
+// is kept in memory. The channel is closed when all clusters are processed or the context is done.
+func StreamAllInputsDataWithMaxGoRoutines(ctx context.Context, inputs []Input, clusterIDs []string, inputTimeout time.Duration, maxGoRoutines int) <-chan *ClusterData {
+	log.Infof("Streaming data from %d inputs for %d clusters", len(inputs), len(clusterIDs))
+	log.Debugf("using %d maxGoRoutines", maxGoRoutines)
+	clusterIDsChan := make(chan string)
+	resultsChan := make(chan *ClusterData)
+
+	go func() {
+		defer close(clusterIDsChan)
+		for _, clusterID := range clusterIDs {
+			select {
+			case clusterIDsChan <- clusterID:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		var wg sync.WaitGroup
+		for i := 0; i < maxGoRoutines; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for clusterID := range clusterIDsChan {
+					log.Debugf("goroutine %d fetching inputs for cluster %s", i, clusterID)
+					select {
+					case resultsChan <- getClusterData(ctx, inputs, clusterID, inputTimeout):
+					case <-ctx.Done():
+						return
+					}
+				}
+				log.Debugf("goroutine %d done", i)
+			}(i)
+		}
+		wg.Wait()
+		close(resultsChan)
+	}()
+	return resultsChan
+}
+
+// getClusterData fetches data of a given cluster from all inputs. Inputs that are not applicable
+// for the cluster are skipped.
+func getClusterData(ctx context.Context, inputs []Input, clusterID string, inputTimeout time.Duration) *ClusterData {
+	cluster := &Cluster{Name: clusterID, Data: make(map[string]interface{})}
+	for _, input := range inputs {
+		result, err := getInputDataWithTimeout(ctx, input, clusterID, inputTimeout)
+		if isDataNotApplicable(err) {
+			log.Debugf("skipping input %s for cluster %s: %s", input.GetID(), clusterID, err)
+			continue
+		}
+		if err != nil {
+			return &ClusterData{
+				Cluster: cluster,
+				Err:     fmt.Errorf("failed to fetch data for cluster %s, input %s: %w", clusterID, input.GetID(), err),
+			}
+		}
+		cluster.Data[input.GetDataSourceName()] = result
+	}
+	normalizeClusterData(cluster)
+	return &ClusterData{Cluster: cluster}
+}
+
+// getInputDataWithTimeout fetches data of a given cluster from the input with a context that is
+// done when the timeout elapses or a given context is done, so the API calls of the input are
+// canceled. Inputs that don't observe the context are not waited for, and their result is discarded.
+func getInputDataWithTimeout(ctx context.Context, input Input, clusterID string, timeout time.Duration) (interface{}, error) {
+	inputCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		inputCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	type inputData struct {
+		result interface{}
+		err    error
+	}
+	dataChan := make(chan *inputData, 1)
+	go func() {
+		result, err := input.GetData(inputCtx, clusterID)
+		dataChan <- &inputData{result: result, err: err}
+	}()
+	select {
+	case data := <-dataChan:
+		if data.err != nil && ctx.Err() == nil && inputCtx.Err() != nil {
+			return nil, fmt.Errorf("timed out after %s: %w", timeout, data.err)
+		}
+		return data.result, data.err
+	case <-inputCtx.Done():
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("timed out after %s: %w", timeout, inputCtx.Err())
+	}
+}
@@ -15,17 +15,23 @@
 package inputs
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"reflect"
 	"sync"
 	"testing"
+	"time"
+
+	"cloud.google.com/go/container/apiv1/containerpb"
+	"github.com/google/gke-policy-automation/internal/inputs/schema"
 )
 
 type inputMock struct {
 	getIDFn             func() string
 	getDescriptionFn    func() string
 	getDataSourceNameFn func() string
-	getDataFn           func(clusterID string) (interface{}, error)
+	getDataFn           func(ctx context.Context, clusterID string) (interface{}, error)
 	closeFn             func() error
 }
 
@@ -41,8 +47,8 @@ func (m inputMock) GetDataSourceName() string {
 	return m.getDataSourceNameFn()
 }
 
-func (m inputMock) GetData(clusterID string) (interface{}, error) {
-	return m.getDataFn(clusterID)
+func (m inputMock) GetData(ctx context.Context, clusterID string) (interface{}, error) {
+	return m.getDataFn(ctx, clusterID)
 }
 
 func (m inputMock) Close() error {
@@ -52,12 +58,12 @@ func (m inputMock) Close() error {
 func TestGetAllInputsData(t *testing.T) {
 	clusterIDs := []string{"cluster-one", "cluster-two", "cluster-three", "cluster-four"}
 	inputs := []Input{
-		&inputMock{getIDFn: func() string { return "gke-api" }, getDataSourceNameFn: func() string { return "gke" }, getDataFn: func(clusterID string) (interface{}, error) { return "data", nil }},
-		&inputMock{getIDFn: func() string { return "kube-api" }, getDataSourceNameFn: func() string { return "k8s" }, getDataFn: func(clusterID string) (interface{}, error) { return "data", nil }},
-		&inputMock{getIDFn: func() string { return "metrics-api" }, getDataSourceNameFn: func() string { return "monitoring" }, getDataFn: func(clusterID string) (interface{}, error) { return "data", nil }},
-		&inputMock{getIDFn: func() string { return "bad-api" }, getDataSourceNameFn: func() string { return "bad" }, getDataFn: func(clusterID string) (interface{}, error) { return nil, errors.New("error") }},
+		&inputMock{getIDFn: func() string { return "gke-api" }, getDataSourceNameFn: func() string { return "gke" }, getDataFn: func(ctx context.Context, clusterID string) (interface{}, error) { return "data", nil }},
+		&inputMock{getIDFn: func() string { return "kube-api" }, getDataSourceNameFn: func() string { return "k8s" }, getDataFn: func(ctx context.Context, clusterID string) (interface{}, error) { return "data", nil }},
+		&inputMock{getIDFn: func() string { return "metrics-api" }, getDataSourceNameFn: func() string { return "monitoring" }, getDataFn: func(ctx context.Context, clusterID string) (interface{}, error) { return "data", nil }},
+		&inputMock{getIDFn: func() string { return "bad-api" }, getDataSourceNameFn: func() string { return "bad" }, getDataFn: func(ctx context.Context, clusterID string) (interface{}, error) { return nil, errors.New("error") }},
 	}
-	results, errors := GetAllInputsData(inputs, clusterIDs)
+	results, errors := GetAllInputsData(context.Background(), inputs, clusterIDs)
 	if len(results) != len(clusterIDs) {
 		t.Fatalf("number of results = %v; want %v", len(results), len(clusterIDs))
 	}
@@ -77,7 +83,7 @@ func TestGetInputData(t *testing.T) {
 			getIDFn: func() string {
 				return "gke-api"
 			},
-			getDataFn: func(clusterID string) (interface{}, error) {
+			getDataFn: func(ctx context.Context, clusterID string) (interface{}, error) {
 				return nil, errors.New("test error")
 			},
 			getDataSourceNameFn: func() string { return "gke" },
@@ -89,7 +95,7 @@ func TestGetInputData(t *testing.T) {
 			getIDFn: func() string {
 				return "gke-api"
 			},
-			getDataFn: func(clusterID string) (interface{}, error) {
+			getDataFn: func(ctx context.Context, clusterID string) (interface{}, error) {
 				return okResult, nil
 			},
 			getDataSourceNameFn: func() string { return "gke" },
@@ -101,7 +107,7 @@ func TestGetInputData(t *testing.T) {
 	errorsChan := make(chan *getDataTaskResult, tasksNo)
 	var wg sync.WaitGroup
 	wg.Add(1)
-	getInputData(0, &wg, tasksChan, resultsChan, errorsChan)
+	getInputData(context.Background(), 0, &wg, tasksChan, resultsChan, errorsChan)
 	close(resultsChan)
 	close(errorsChan)
 	if len(resultsChan) != 1 {
@@ -169,6 +175,57 @@ func TestProcessResults(t *testing.T) {
 	}
 }
 
+func TestGetAllInputsData_notApplicable(t *testing.T) {
+	clusterIDs := []string{"cluster-one", "cluster-two"}
+	inputs := []Input{
+		&inputMock{getIDFn: func() string { return "gke-api" }, getDataSourceNameFn: func() string { return "gke" }, getDataFn: func(ctx context.Context, clusterID string) (interface{}, error) {
+			if clusterID == "cluster-two" {
+				return nil, fmt.Errorf("not a GKE cluster: %w", ErrDataNotApplicable)
+			}
+			return "data", nil
+		}},
+		&inputMock{getIDFn: func() string { return "fleet" }, getDataSourceNameFn: func() string { return "fleet" }, getDataFn: func(ctx context.Context, clusterID string) (interface{}, error) { return "data", nil }},
+	}
+	results, errors := GetAllInputsData(context.Background(), inputs, clusterIDs)
+	if len(errors) != 0 {
+		t.Fatalf("number of errors = %v; want %v", len(errors), 0)
+	}
+	if len(results) != len(clusterIDs) {
+		t.Fatalf("number of results = %v; want %v", len(results), len(clusterIDs))
+	}
+	if _, ok := results["cluster-two"].Data["gke"]; ok {
+		t.Errorf("cluster-two has gke data; want no gke data")
+	}
+	if _, ok := results["cluster-two"].Data["fleet"]; !ok {
+		t.Errorf("cluster-two has no fleet data; want fleet data")
+	}
+}
+
+func TestNormalizeClusterData(t *testing.T) {
+	cluster := &Cluster{
+		Name: "projects/test/locations/europe-west2/clusters/cluster",
+		Data: map[string]interface{}{
+			gkeDataSourceName: &containerpb.Cluster{Name: "cluster", Location: "europe-west2"},
+		},
+	}
+	normalizeClusterData(cluster)
+	if cluster.Normalized == nil {
+		t.Fatalf("normalized cluster is nil")
+	}
+	if cluster.Normalized.ID != cluster.Name {
+		t.Errorf("normalized cluster id = %v; want %v", cluster.Normalized.ID, cluster.Name)
+	}
+	if cluster.Normalized.SchemaVersion != schema.Version {
+		t.Errorf("normalized cluster schemaVersion = %v; want %v", cluster.Normalized.SchemaVersion, schema.Version)
+	}
+
+	cluster = &Cluster{Name: "cluster", Data: map[string]interface{}{"k8s": "data"}}
+	normalizeClusterData(cluster)
+	if cluster.Normalized != nil {
+		t.Errorf("normalized cluster = %v; want nil", cluster.Normalized)
+	}
+}
+
 func TestProcessErrors(t *testing.T) {
 	errorsChan := make(chan *getDataTaskResult, 2)
 	errorsChan <- &getDataTaskResult{
@@ -187,3 +244,147 @@ func TestProcessErrors(t *testing.T) {
 		t.Fatalf("number of errors = %v; want %v", len(errors), 2)
 	}
 }
+
+func TestStreamAllInputsData(t *testing.T) {
+	clusterIDs := []string{"cluster-one", "cluster-two", "cluster-three", "cluster-four"}
+	inputs := []Input{
+		&inputMock{getIDFn: func() string { return "gke-api" }, getDataSourceNameFn: func() string { return "gke" }, getDataFn: func(ctx context.Context, clusterID string) (interface{}, error) { return "data", nil }},
+		&inputMock{getIDFn: func() string { return "bad-api" }, getDataSourceNameFn: func() string { return "bad" }, getDataFn: func(ctx context.Context, clusterID string) (interface{}, error) {
+			if clusterID == "cluster-two" {
+				return nil, errors.New("error")
+			}
+			if clusterID == "cluster-three" {
+				return nil, ErrDataNotApplicable
+			}
+			return "data", nil
+		}},
+	}
+	results := make(map[string]*ClusterData)
+	for result := range StreamAllInputsData(context.Background(), inputs, clusterIDs, 0) {
+		results[result.Cluster.Name] = result
+	}
+	if len(results) != len(clusterIDs) {
+		t.Fatalf("number of results = %v; want %v", len(results), len(clusterIDs))
+	}
+	if results["cluster-two"].Err == nil {
+		t.Errorf("cluster-two error is nil; want error")
+	}
+	if results["cluster-one"].Err != nil {
+		t.Errorf("cluster-one error = %v; want nil", results["cluster-one"].Err)
+	}
+	if len(results["cluster-one"].Cluster.Data) != 2 {
+		t.Errorf("cluster-one data length = %v; want %v", len(results["cluster-one"].Cluster.Data), 2)
+	}
+	if _, ok := results["cluster-three"].Cluster.Data["bad"]; ok {
+		t.Errorf("cluster-three has not applicable input data")
+	}
+}
+
+func TestStreamAllInputsDataWithMaxGoRoutines_bounded(t *testing.T) {
+	maxGoRoutines := 2
+	clusterIDs := []string{"cluster-one", "cluster-two", "cluster-three", "cluster-four", "cluster-five"}
+	var mutex sync.Mutex
+	running, maxRunning := 0, 0
+	inputs := []Input{
+		&inputMock{getIDFn: func() string { return "gke-api" }, getDataSourceNameFn: func() string { return "gke" }, getDataFn: func(ctx context.Context, clusterID string) (interface{}, error) {
+			mutex.Lock()
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			mutex.Unlock()
+			time.Sleep(5 * time.Millisecond)
+			mutex.Lock()
+			running--
+			mutex.Unlock()
+			return "data", nil
+		}},
+	}
+	count := 0
+	for range StreamAllInputsDataWithMaxGoRoutines(context.Background(), inputs, clusterIDs, 0, maxGoRoutines) {
+		count++
+	}
+	if count != len(clusterIDs) {
+		t.Errorf("number of results = %v; want %v", count, len(clusterIDs))
+	}
+	if maxRunning > maxGoRoutines {
+		t.Errorf("max concurrent fetches = %v; want at most %v", maxRunning, maxGoRoutines)
+	}
+}
+
+func TestStreamAllInputsData_cancel(t *testing.T) {
+	clusterIDs := []string{"cluster-one", "cluster-two", "cluster-three"}
+	inputs := []Input{
+		&inputMock{getIDFn: func() string { return "gke-api" }, getDataSourceNameFn: func() string { return "gke" }, getDataFn: func(ctx context.Context, clusterID string) (interface{}, error) { return "data", nil }},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	resultsChan := StreamAllInputsDataWithMaxGoRoutines(ctx, inputs, clusterIDs, 0, 1)
+	<-resultsChan
+	cancel()
+	for range resultsChan {
+	}
+}
+
+func TestStreamAllInputsData_inputTimeout(t *testing.T) {
+	clusterIDs := []string{"cluster-one", "cluster-two"}
+	release := make(chan struct{})
+	defer close(release)
+	inputs := []Input{
+		&inputMock{getIDFn: func() string { return "gke-api" }, getDataSourceNameFn: func() string { return "gke" }, getDataFn: func(ctx context.Context, clusterID string) (interface{}, error) {
+			if clusterID == "cluster-two" {
+				<-release
+			}
+			return "data", nil
+		}},
+	}
+	results := make(map[string]*ClusterData)
+	for result := range StreamAllInputsData(context.Background(), inputs, clusterIDs, 10*time.Millisecond) {
+		results[result.Cluster.Name] = result
+	}
+	if results["cluster-one"].Err != nil {
+		t.Errorf("cluster-one error = %v; want nil", results["cluster-one"].Err)
+	}
+	if !errors.Is(results["cluster-two"].Err, context.DeadlineExceeded) {
+		t.Errorf("cluster-two error = %v; want %v", results["cluster-two"].Err, context.DeadlineExceeded)
+	}
+}
+
+func TestStreamAllInputsData_inputTimeoutCancelsFetch(t *testing.T) {
+	canceled := make(chan error, 1)
+	inputs := []Input{
+		&inputMock{getIDFn: func() string { return "gke-api" }, getDataSourceNameFn: func() string { return "gke" }, getDataFn: func(ctx context.Context, clusterID string) (interface{}, error) {
+			<-ctx.Done()
+			canceled <- ctx.Err()
+			return nil, ctx.Err()
+		}},
+	}
+	for result := range StreamAllInputsData(context.Background(), inputs, []string{"cluster-one"}, 10*time.Millisecond) {
+		if !errors.Is(result.Err, context.DeadlineExceeded) {
+			t.Errorf("error = %v; want %v", result.Err, context.DeadlineExceeded)
+		}
+	}
+	select {
+	case err := <-canceled:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("input context error = %v; want %v", err, context.DeadlineExceeded)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("input context was not canceled on timeout")
+	}
+}
+
+func TestGetAllInputsData_cancel(t *testing.T) {
+	clusterIDs := []string{"cluster-one", "cluster-two", "cluster-three"}
+	inputs := []Input{
+		&inputMock{getIDFn: func() string { return "gke-api" }, getDataSourceNameFn: func() string { return "gke" }, getDataFn: func(ctx context.Context, clusterID string) (interface{}, error) { return "data", nil }},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, errs := GetAllInputsDataWithMaxGoRoutines(ctx, inputs, clusterIDs, 1)
+	if len(errs) == 0 {
+		t.Fatalf("number of errors = %v; want at least %v", len(errs), 1)
+	}
+	if !errors.Is(errs[len(errs)-1], context.Canceled) {
+		t.Errorf("error = %v; want %v", errs[len(errs)-1], context.Canceled)
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"cloud.google.com/go/container/apiv1/containerpb"
 	"github.com/google/gke-policy-automation/internal/inputs/clients"
 	"github.com/google/gke-policy-automation/internal/log"
+	"github.com/google/gke-policy-automation/internal/retry"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 )
 
@@ -49,15 +50,19 @@ type k8sAPIInput struct {
 type k8sInputBuilder struct {
 	ctx             context.Context
 	credentialsFile string
+	gkeRetrier      *retry.Retrier
 	apiVersions     []string
 	maxQPS          int
 	maxGoRoutines   int
 	timeoutSeconds  int
 }
 
-func NewK8sAPIInputBuilder(ctx context.Context, apiVersions []string) *k8sInputBuilder {
+// NewK8sAPIInputBuilder returns the builder of the Kubernetes API input. The retrier of the GKE API
+// is used for fetching the cluster endpoints.
+func NewK8sAPIInputBuilder(ctx context.Context, apiVersions []string, gkeRetrier *retry.Retrier) *k8sInputBuilder {
 	return &k8sInputBuilder{
 		ctx:         ctx,
+		gkeRetrier:  gkeRetrier,
 		apiVersions: apiVersions,
 	}
 }
@@ -92,7 +97,7 @@ func (b *k8sInputBuilder) Build() (Input, error) {
 		if err != nil {
 			return nil, err
 		}
-		gkeInput, err = NewGKEApiInputWithCredentials(b.ctx, b.credentialsFile)
+		gkeInput, err = NewGKEApiInputWithCredentials(b.ctx, b.credentialsFile, b.gkeRetrier)
 		if err != nil {
 			return nil, err
 		}
@@ -101,7 +106,7 @@ func (b *k8sInputBuilder) Build() (Input, error) {
 		if err != nil {
 			return nil, err
 		}
-		gkeInput, err = NewGKEApiInput(b.ctx)
+		gkeInput, err = NewGKEApiInput(b.ctx, b.gkeRetrier)
 		if err != nil {
 			return nil, err
 		}
@@ -132,9 +137,9 @@ func (i *k8sAPIInput) GetDataSourceName() string {
 	return k8sDataSourceName
 }
 
-func (i *k8sAPIInput) GetData(clusterID string) (interface{}, error) {
+func (i *k8sAPIInput) GetData(ctx context.Context, clusterID string) (interface{}, error) {
 	if i.k8sClient == nil {
-		if err := i.createK8SClient(clusterID); err != nil {
+		if err := i.createK8SClient(ctx, clusterID); err != nil {
 			return nil, err
 		}
 	}
@@ -164,12 +169,12 @@ func (i *k8sAPIInput) Close() error {
 	return nil
 }
 
-func (i *k8sAPIInput) createK8SClient(clusterID string) error {
+func (i *k8sAPIInput) createK8SClient(ctx context.Context, clusterID string) error {
 	token, err := i.tokenSource.GetAuthToken()
 	if err != nil {
 		return err
 	}
-	data, err := i.gkeInput.GetData(clusterID)
+	data, err := i.gkeInput.GetData(ctx, clusterID)
 	if err != nil {
 		return err
 	}
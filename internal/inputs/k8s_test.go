@@ -93,7 +93,7 @@ func TestK8sApiInputBuilder(t *testing.T) {
 	credFile := "test-fixtures/test_credentials.json"
 	apiVersions := []string{"policy/v1", "networking.k8s.io/v1"}
 	maxQPS := 69
-	b := NewK8sAPIInputBuilder(context.Background(), apiVersions).
+	b := NewK8sAPIInputBuilder(context.Background(), apiVersions, nil).
 		WithCredentialsFile(credFile).
 		WithMaxQPS(maxQPS)
 
@@ -159,7 +159,7 @@ func TestK8sApiInputGetData(t *testing.T) {
 			},
 		},
 		gkeInput: &inputMock{
-			getDataFn: func(clusterID string) (interface{}, error) {
+			getDataFn: func(ctx context.Context, clusterID string) (interface{}, error) {
 				if clusterID != testClusterID {
 					t.Errorf("clusterID = %v; want %v", clusterID, testClusterID)
 				}
@@ -177,7 +177,7 @@ func TestK8sApiInputGetData(t *testing.T) {
 		apiVersions: []string{"autoscaling/v1"},
 		maxQPS:      testMaxQPS,
 	}
-	_, err := i.GetData(testClusterID)
+	_, err := i.GetData(context.Background(), testClusterID)
 	if err != nil {
 		t.Fatalf("err = %v; want nil", err)
 	}
@@ -0,0 +1,112 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inputs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/google/gke-policy-automation/internal/gke"
+)
+
+const (
+	localInputID          = "local"
+	localInputDescription = "Cluster data of any data source from JSON dump"
+)
+
+type localInput struct {
+	dataSourceName string
+	dump           *gke.ClusterDump
+}
+
+// NewLocalInputs returns local inputs replaying given data sources of the clusters from a dump file
+// produced by the dump cluster command. All data sources captured in a dump are replayed when none
+// are given.
+func NewLocalInputs(dumpFile string, dataSources []string) ([]Input, error) {
+	dump, err := gke.ReadClusterDump(dumpFile)
+	if err != nil {
+		return nil, err
+	}
+	return NewLocalInputsWithDump(dump, dataSources), nil
+}
+
+// NewLocalInputsWithDump returns local inputs replaying given data sources of the clusters from
+// a given, already parsed cluster data dump.
+func NewLocalInputsWithDump(dump *gke.ClusterDump, dataSources []string) []Input {
+	if len(dataSources) == 0 {
+		dataSources = getLocalDumpDataSources(dump)
+	}
+	localInputs := make([]Input, 0, len(dataSources))
+	for _, dataSource := range dataSources {
+		localInputs = append(localInputs, &localInput{
+			dataSourceName: dataSource,
+			dump:           dump,
+		})
+	}
+	return localInputs
+}
+
+func (i *localInput) GetID() string {
+	return fmt.Sprintf("%s-%s", localInputID, i.dataSourceName)
+}
+
+func (i *localInput) GetDescription() string {
+	return localInputDescription
+}
+
+func (i *localInput) GetDataSourceName() string {
+	return i.dataSourceName
+}
+
+func (i *localInput) GetData(ctx context.Context, clusterID string) (interface{}, error) {
+	cluster, err := i.dump.GetCluster(clusterID)
+	if err != nil {
+		return nil, err
+	}
+	data, ok := cluster.Data[i.dataSourceName]
+	if !ok {
+		return nil, fmt.Errorf("cluster %s has no %s data in a dump file: %w", clusterID, i.dataSourceName, ErrDataNotApplicable)
+	}
+	if i.dataSourceName == gkeDataSourceName {
+		return cluster.Cluster, nil
+	}
+	var result interface{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (i *localInput) Close() error {
+	return nil
+}
+
+// getLocalDumpDataSources returns sorted names of all data sources captured in a given dump.
+func getLocalDumpDataSources(dump *gke.ClusterDump) []string {
+	names := make(map[string]bool)
+	for _, cluster := range dump.Clusters() {
+		for name := range cluster.Data {
+			names[name] = true
+		}
+	}
+	dataSources := make([]string, 0, len(names))
+	for name := range names {
+		dataSources = append(dataSources, name)
+	}
+	sort.Strings(dataSources)
+	return dataSources
+}
@@ -0,0 +1,106 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inputs
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"cloud.google.com/go/container/apiv1/containerpb"
+	"github.com/google/gke-policy-automation/internal/gke"
+)
+
+const localDumpData = `[
+	{
+		"name": "projects/p/locations/europe-west2/clusters/one",
+		"data": {
+			"gke": {"name": "one", "current_node_count": 3},
+			"monitoring": {"nodes": {"name": "nodes", "scalar": 3}}
+		}
+	},
+	{
+		"name": "projects/p/locations/europe-west2/clusters/two",
+		"data": {
+			"gke": {"name": "two"},
+			"k8s": [{"kind": "Pod"}]
+		}
+	}
+]`
+
+func TestNewLocalInputs(t *testing.T) {
+	dump, err := gke.ParseClusterDump([]byte(localDumpData))
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	localInputs := NewLocalInputsWithDump(dump, nil)
+	dataSources := make([]string, len(localInputs))
+	for i := range localInputs {
+		dataSources[i] = localInputs[i].GetDataSourceName()
+	}
+	expected := []string{"gke", "k8s", "monitoring"}
+	if !reflect.DeepEqual(dataSources, expected) {
+		t.Errorf("data sources = %v; want %v", dataSources, expected)
+	}
+	if id := localInputs[0].GetID(); id != "local-gke" {
+		t.Errorf("id = %v; want %v", id, "local-gke")
+	}
+	if desc := localInputs[0].GetDescription(); desc != localInputDescription {
+		t.Errorf("description = %v; want %v", desc, localInputDescription)
+	}
+
+	localInputs = NewLocalInputsWithDump(dump, []string{"monitoring"})
+	if len(localInputs) != 1 || localInputs[0].GetDataSourceName() != "monitoring" {
+		t.Errorf("inputs = %v; want single monitoring input", localInputs)
+	}
+}
+
+func TestLocalInputGetData(t *testing.T) {
+	dump, err := gke.ParseClusterDump([]byte(localDumpData))
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	localInputs := NewLocalInputsWithDump(dump, []string{"gke", "monitoring"})
+	gkeInput, monitoringInput := localInputs[0], localInputs[1]
+
+	data, err := gkeInput.GetData(context.Background(), "projects/p/locations/europe-west2/clusters/one")
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	cluster, ok := data.(*containerpb.Cluster)
+	if !ok {
+		t.Fatalf("data is not *containerpb.Cluster")
+	}
+	if cluster.Name != "one" || cluster.CurrentNodeCount != 3 {
+		t.Errorf("cluster = %v; want name one with 3 nodes", cluster)
+	}
+
+	data, err = monitoringInput.GetData(context.Background(), "projects/p/locations/europe-west2/clusters/one")
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	expected := map[string]interface{}{"nodes": map[string]interface{}{"name": "nodes", "scalar": float64(3)}}
+	if !reflect.DeepEqual(data, expected) {
+		t.Errorf("monitoring data = %v; want %v", data, expected)
+	}
+
+	if _, err = monitoringInput.GetData(context.Background(), "projects/p/locations/europe-west2/clusters/two"); !errors.Is(err, ErrDataNotApplicable) {
+		t.Errorf("err = %v; want %v", err, ErrDataNotApplicable)
+	}
+	if _, err = gkeInput.GetData(context.Background(), "projects/p/locations/europe-west2/clusters/three"); err == nil || errors.Is(err, ErrDataNotApplicable) {
+		t.Errorf("err = %v; want cluster not found error", err)
+	}
+}
@@ -204,7 +204,7 @@ func TestMetricsInputGetData(t *testing.T) {
 		metricsClient: metricsClientMock{},
 	}
 
-	_, err := input.GetData(clusterID)
+	_, err := input.GetData(context.Background(), clusterID)
 	if err != nil {
 		t.Fatalf("err = %v; want nil", err)
 	}
@@ -135,7 +135,7 @@ func (i *metricsInput) GetDataSourceName() string {
 	return metricsDataSourceName
 }
 
-func (i *metricsInput) GetData(clusterID string) (interface{}, error) {
+func (i *metricsInput) GetData(ctx context.Context, clusterID string) (interface{}, error) {
 	projectID, _, _, err := gke.SliceAndValidateClusterID(clusterID)
 	if err != nil {
 		log.Errorf("error parsing clusterID: %s", err)
@@ -146,7 +146,7 @@ func (i *metricsInput) GetData(clusterID string) (interface{}, error) {
 	if metricsClient == nil {
 		log.Debugf("global metric client is nil, creating scoped client for cluster %s", clusterID)
 		if metricsClient, err = newMetricsClientFromBuilder(
-			i.ctx, i.credentialsFile, i.address, projectID, i.username, i.password,
+			ctx, i.credentialsFile, i.address, projectID, i.username, i.password,
 			i.maxGoRoutines, i.timeoutSeconds, i.createTokenSourceFn); err != nil {
 			return nil, err
 		}
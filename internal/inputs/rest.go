@@ -63,9 +63,9 @@ func (i *restInput) GetDataSourceName() string {
 	return restDataSourceName
 }
 
-func (i *restInput) GetData(clusterID string) (interface{}, error) {
+func (i *restInput) GetData(ctx context.Context, clusterID string) (interface{}, error) {
 	endpoint := replaceWildcard(i.endpoint, clusterIDWildcard, clusterID)
-	req, err := createGetRequest(i.ctx, endpoint)
+	req, err := createGetRequest(ctx, endpoint)
 	if err != nil {
 		return nil, err
 	}
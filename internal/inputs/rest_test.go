@@ -77,7 +77,7 @@ func TestRestInputGetData(t *testing.T) {
 		},
 	}
 
-	_, err := input.GetData(clusterID)
+	_, err := input.GetData(context.Background(), clusterID)
 	if err != nil {
 		t.Fatalf("err = %v; want nil", err)
 	}
@@ -0,0 +1,335 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package schema implements the normalized, versioned cluster schema exposed to the policies
+// as input.cluster. Unlike the raw GKE API data, the normalized schema does not change with
+// the client library: fields are only added within a schema version.
+package schema
+
+import (
+	"strings"
+
+	"cloud.google.com/go/container/apiv1/containerpb"
+)
+
+const (
+	// Version is the version of the normalized cluster schema.
+	Version = "v1"
+
+	ModeAutopilot = "AUTOPILOT"
+	ModeStandard  = "STANDARD"
+
+	enumUnspecifiedSuffix = "UNSPECIFIED"
+)
+
+// Cluster is the normalized representation of a GKE cluster.
+type Cluster struct {
+	SchemaVersion    string            `json:"schemaVersion"`
+	ID               string            `json:"id"`
+	Name             string            `json:"name"`
+	Location         string            `json:"location"`
+	Locations        []string          `json:"locations"`
+	Mode             string            `json:"mode"`
+	MasterVersion    string            `json:"masterVersion"`
+	ReleaseChannel   string            `json:"releaseChannel"`
+	CurrentNodeCount int               `json:"currentNodeCount"`
+	Labels           map[string]string `json:"labels"`
+	Network          *Network          `json:"network"`
+	Security         *Security         `json:"security"`
+	Addons           *Addons           `json:"addons"`
+	Maintenance      *Maintenance      `json:"maintenance"`
+	Notifications    *Notifications    `json:"notifications"`
+	Logging          *Observability    `json:"logging"`
+	Monitoring       *Observability    `json:"monitoring"`
+	Autoscaling      *Autoscaling      `json:"autoscaling"`
+	NodePools        []*NodePool       `json:"nodePools"`
+}
+
+// Network is the normalized cluster networking configuration.
+type Network struct {
+	Network                         string   `json:"network"`
+	Subnetwork                      string   `json:"subnetwork"`
+	DatapathProvider                string   `json:"datapathProvider"`
+	VPCNative                       bool     `json:"vpcNative"`
+	IntranodeVisibility             bool     `json:"intranodeVisibility"`
+	L4ILBSubsetting                 bool     `json:"l4IlbSubsetting"`
+	PrivateNodes                    bool     `json:"privateNodes"`
+	PrivateEndpoint                 bool     `json:"privateEndpoint"`
+	MasterAuthorizedNetworksEnabled bool     `json:"masterAuthorizedNetworksEnabled"`
+	MasterAuthorizedNetworks        []string `json:"masterAuthorizedNetworks"`
+	NetworkPolicy                   bool     `json:"networkPolicy"`
+}
+
+// Security is the normalized cluster security configuration.
+type Security struct {
+	LegacyABAC                bool   `json:"legacyAbac"`
+	ShieldedNodes             bool   `json:"shieldedNodes"`
+	BinaryAuthorization       bool   `json:"binaryAuthorization"`
+	WorkloadIdentityPool      string `json:"workloadIdentityPool"`
+	SecretsEncryption         bool   `json:"secretsEncryption"`
+	SecretsEncryptionKey      string `json:"secretsEncryptionKey"`
+	SecurityPostureMode       string `json:"securityPostureMode"`
+	VulnerabilityScanningMode string `json:"vulnerabilityScanningMode"`
+	BasicAuthentication       bool   `json:"basicAuthentication"`
+	ClientCertificate         bool   `json:"clientCertificate"`
+	GoogleGroupsSecurityGroup string `json:"googleGroupsSecurityGroup"`
+}
+
+// Addons is the normalized cluster addons configuration.
+type Addons struct {
+	HTTPLoadBalancing          bool `json:"httpLoadBalancing"`
+	NetworkPolicy              bool `json:"networkPolicy"`
+	DNSCache                   bool `json:"dnsCache"`
+	GCEPersistentDiskCSIDriver bool `json:"gcePersistentDiskCsiDriver"`
+}
+
+// Maintenance is the normalized cluster maintenance configuration.
+type Maintenance struct {
+	WindowConfigured bool `json:"windowConfigured"`
+}
+
+// Notifications is the normalized cluster notifications configuration.
+type Notifications struct {
+	PubSubEnabled bool   `json:"pubsubEnabled"`
+	PubSubTopic   string `json:"pubsubTopic"`
+}
+
+// Observability is the normalized cluster logging or monitoring configuration.
+type Observability struct {
+	Components []string `json:"components"`
+}
+
+// Autoscaling is the normalized cluster autoscaling configuration.
+type Autoscaling struct {
+	NodeAutoprovisioning      bool                      `json:"nodeAutoprovisioning"`
+	AutoprovisioningLocations []string                  `json:"autoprovisioningLocations"`
+	AutoprovisioningDefaults  *AutoprovisioningDefaults `json:"autoprovisioningDefaults"`
+}
+
+// AutoprovisioningDefaults is the normalized configuration of the autoprovisioned node pools.
+type AutoprovisioningDefaults struct {
+	ServiceAccount      string `json:"serviceAccount"`
+	ImageType           string `json:"imageType"`
+	SecureBoot          bool   `json:"secureBoot"`
+	IntegrityMonitoring bool   `json:"integrityMonitoring"`
+}
+
+// NodePool is the normalized representation of a GKE node pool.
+type NodePool struct {
+	Name             string               `json:"name"`
+	Version          string               `json:"version"`
+	Locations        []string             `json:"locations"`
+	InitialNodeCount int                  `json:"initialNodeCount"`
+	Autoscaling      *NodePoolAutoscaling `json:"autoscaling"`
+	Management       *NodePoolManagement  `json:"management"`
+	Config           *NodeConfig          `json:"config"`
+	Network          *NodePoolNetwork     `json:"network"`
+}
+
+// NodePoolAutoscaling is the normalized node pool autoscaling configuration.
+type NodePoolAutoscaling struct {
+	Enabled           bool `json:"enabled"`
+	MinNodeCount      int  `json:"minNodeCount"`
+	MaxNodeCount      int  `json:"maxNodeCount"`
+	TotalMinNodeCount int  `json:"totalMinNodeCount"`
+	TotalMaxNodeCount int  `json:"totalMaxNodeCount"`
+}
+
+// NodePoolManagement is the normalized node pool management configuration.
+type NodePoolManagement struct {
+	AutoRepair  bool `json:"autoRepair"`
+	AutoUpgrade bool `json:"autoUpgrade"`
+}
+
+// NodeConfig is the normalized configuration of the node pool nodes.
+type NodeConfig struct {
+	MachineType          string   `json:"machineType"`
+	ImageType            string   `json:"imageType"`
+	DiskType             string   `json:"diskType"`
+	DiskSizeGB           int      `json:"diskSizeGb"`
+	ServiceAccount       string   `json:"serviceAccount"`
+	BootDiskKMSKey       string   `json:"bootDiskKmsKey"`
+	OAuthScopes          []string `json:"oauthScopes"`
+	SecureBoot           bool     `json:"secureBoot"`
+	IntegrityMonitoring  bool     `json:"integrityMonitoring"`
+	WorkloadMetadataMode string   `json:"workloadMetadataMode"`
+}
+
+// NodePoolNetwork is the normalized node pool networking configuration.
+type NodePoolNetwork struct {
+	PodRange         string `json:"podRange"`
+	PodIPv4CIDRBlock string `json:"podIpv4CidrBlock"`
+}
+
+// NormalizeCluster maps GKE API cluster data to the normalized cluster schema.
+func NormalizeCluster(id string, c *containerpb.Cluster) *Cluster {
+	cluster := &Cluster{
+		SchemaVersion:    Version,
+		ID:               id,
+		Name:             c.GetName(),
+		Location:         c.GetLocation(),
+		Locations:        emptyIfNil(c.GetLocations()),
+		Mode:             ModeStandard,
+		MasterVersion:    c.GetCurrentMasterVersion(),
+		ReleaseChannel:   enumName(c.GetReleaseChannel().GetChannel().String()),
+		CurrentNodeCount: int(c.GetCurrentNodeCount()),
+		Labels:           c.GetResourceLabels(),
+		Network:          normalizeNetwork(c),
+		Security:         normalizeSecurity(c),
+		Addons: &Addons{
+			HTTPLoadBalancing:          !c.GetAddonsConfig().GetHttpLoadBalancing().GetDisabled(),
+			NetworkPolicy:              c.GetAddonsConfig().GetNetworkPolicyConfig() != nil && !c.GetAddonsConfig().GetNetworkPolicyConfig().GetDisabled(),
+			DNSCache:                   c.GetAddonsConfig().GetDnsCacheConfig().GetEnabled(),
+			GCEPersistentDiskCSIDriver: c.GetAddonsConfig().GetGcePersistentDiskCsiDriverConfig().GetEnabled(),
+		},
+		Maintenance: &Maintenance{
+			WindowConfigured: c.GetMaintenancePolicy().GetWindow().GetPolicy() != nil,
+		},
+		Notifications: &Notifications{
+			PubSubEnabled: c.GetNotificationConfig().GetPubsub().GetEnabled(),
+			PubSubTopic:   c.GetNotificationConfig().GetPubsub().GetTopic(),
+		},
+		Logging:     &Observability{Components: make([]string, 0)},
+		Monitoring:  &Observability{Components: make([]string, 0)},
+		Autoscaling: normalizeAutoscaling(c.GetAutoscaling()),
+		NodePools:   make([]*NodePool, 0, len(c.GetNodePools())),
+	}
+	if cluster.Labels == nil {
+		cluster.Labels = make(map[string]string)
+	}
+	if c.GetAutopilot().GetEnabled() {
+		cluster.Mode = ModeAutopilot
+	}
+	for _, component := range c.GetLoggingConfig().GetComponentConfig().GetEnableComponents() {
+		cluster.Logging.Components = append(cluster.Logging.Components, component.String())
+	}
+	for _, component := range c.GetMonitoringConfig().GetComponentConfig().GetEnableComponents() {
+		cluster.Monitoring.Components = append(cluster.Monitoring.Components, component.String())
+	}
+	for _, nodePool := range c.GetNodePools() {
+		cluster.NodePools = append(cluster.NodePools, normalizeNodePool(nodePool))
+	}
+	return cluster
+}
+
+func normalizeNetwork(c *containerpb.Cluster) *Network {
+	network := &Network{
+		Network:                         c.GetNetwork(),
+		Subnetwork:                      c.GetSubnetwork(),
+		DatapathProvider:                enumName(c.GetNetworkConfig().GetDatapathProvider().String()),
+		VPCNative:                       c.GetIpAllocationPolicy().GetUseIpAliases(),
+		IntranodeVisibility:             c.GetNetworkConfig().GetEnableIntraNodeVisibility(),
+		L4ILBSubsetting:                 c.GetNetworkConfig().GetEnableL4IlbSubsetting(),
+		PrivateNodes:                    c.GetPrivateClusterConfig().GetEnablePrivateNodes(),
+		PrivateEndpoint:                 c.GetPrivateClusterConfig().GetEnablePrivateEndpoint(),
+		MasterAuthorizedNetworksEnabled: c.GetMasterAuthorizedNetworksConfig().GetEnabled(),
+		MasterAuthorizedNetworks:        make([]string, 0),
+		NetworkPolicy: c.GetNetworkPolicy().GetEnabled() ||
+			c.GetNetworkConfig().GetDatapathProvider() == containerpb.DatapathProvider_ADVANCED_DATAPATH,
+	}
+	for _, block := range c.GetMasterAuthorizedNetworksConfig().GetCidrBlocks() {
+		network.MasterAuthorizedNetworks = append(network.MasterAuthorizedNetworks, block.GetCidrBlock())
+	}
+	return network
+}
+
+func normalizeSecurity(c *containerpb.Cluster) *Security {
+	security := &Security{
+		LegacyABAC:                c.GetLegacyAbac().GetEnabled(),
+		ShieldedNodes:             c.GetShieldedNodes().GetEnabled(),
+		BinaryAuthorization:       c.GetBinaryAuthorization().GetEnabled(),
+		WorkloadIdentityPool:      c.GetWorkloadIdentityConfig().GetWorkloadPool(),
+		SecretsEncryption:         c.GetDatabaseEncryption().GetState() == containerpb.DatabaseEncryption_ENCRYPTED,
+		SecretsEncryptionKey:      c.GetDatabaseEncryption().GetKeyName(),
+		BasicAuthentication:       c.GetMasterAuth().GetUsername() != "" || c.GetMasterAuth().GetPassword() != "",
+		ClientCertificate:         c.GetMasterAuth().GetClientCertificate() != "" || c.GetMasterAuth().GetClientKey() != "",
+		GoogleGroupsSecurityGroup: c.GetAuthenticatorGroupsConfig().GetSecurityGroup(),
+	}
+	if posture := c.GetSecurityPostureConfig(); posture != nil {
+		security.SecurityPostureMode = enumName(posture.GetMode().String())
+		security.VulnerabilityScanningMode = enumName(posture.GetVulnerabilityMode().String())
+	}
+	if c.GetBinaryAuthorization().GetEvaluationMode() == containerpb.BinaryAuthorization_PROJECT_SINGLETON_POLICY_ENFORCE {
+		security.BinaryAuthorization = true
+	}
+	return security
+}
+
+func normalizeAutoscaling(a *containerpb.ClusterAutoscaling) *Autoscaling {
+	autoscaling := &Autoscaling{
+		NodeAutoprovisioning:      a.GetEnableNodeAutoprovisioning(),
+		AutoprovisioningLocations: emptyIfNil(a.GetAutoprovisioningLocations()),
+	}
+	if defaults := a.GetAutoprovisioningNodePoolDefaults(); defaults != nil {
+		autoscaling.AutoprovisioningDefaults = &AutoprovisioningDefaults{
+			ServiceAccount:      defaults.GetServiceAccount(),
+			ImageType:           defaults.GetImageType(),
+			SecureBoot:          defaults.GetShieldedInstanceConfig().GetEnableSecureBoot(),
+			IntegrityMonitoring: defaults.GetShieldedInstanceConfig().GetEnableIntegrityMonitoring(),
+		}
+	}
+	return autoscaling
+}
+
+func normalizeNodePool(n *containerpb.NodePool) *NodePool {
+	nodePool := &NodePool{
+		Name:             n.GetName(),
+		Version:          n.GetVersion(),
+		Locations:        emptyIfNil(n.GetLocations()),
+		InitialNodeCount: int(n.GetInitialNodeCount()),
+		Autoscaling: &NodePoolAutoscaling{
+			Enabled:           n.GetAutoscaling().GetEnabled(),
+			MinNodeCount:      int(n.GetAutoscaling().GetMinNodeCount()),
+			MaxNodeCount:      int(n.GetAutoscaling().GetMaxNodeCount()),
+			TotalMinNodeCount: int(n.GetAutoscaling().GetTotalMinNodeCount()),
+			TotalMaxNodeCount: int(n.GetAutoscaling().GetTotalMaxNodeCount()),
+		},
+		Management: &NodePoolManagement{
+			AutoRepair:  n.GetManagement().GetAutoRepair(),
+			AutoUpgrade: n.GetManagement().GetAutoUpgrade(),
+		},
+		Config: &NodeConfig{
+			MachineType:          n.GetConfig().GetMachineType(),
+			ImageType:            n.GetConfig().GetImageType(),
+			DiskType:             n.GetConfig().GetDiskType(),
+			DiskSizeGB:           int(n.GetConfig().GetDiskSizeGb()),
+			ServiceAccount:       n.GetConfig().GetServiceAccount(),
+			BootDiskKMSKey:       n.GetConfig().GetBootDiskKmsKey(),
+			OAuthScopes:          emptyIfNil(n.GetConfig().GetOauthScopes()),
+			SecureBoot:           n.GetConfig().GetShieldedInstanceConfig().GetEnableSecureBoot(),
+			IntegrityMonitoring:  n.GetConfig().GetShieldedInstanceConfig().GetEnableIntegrityMonitoring(),
+			WorkloadMetadataMode: enumName(n.GetConfig().GetWorkloadMetadataConfig().GetMode().String()),
+		},
+		Network: &NodePoolNetwork{
+			PodRange:         n.GetNetworkConfig().GetPodRange(),
+			PodIPv4CIDRBlock: n.GetNetworkConfig().GetPodIpv4CidrBlock(),
+		},
+	}
+	return nodePool
+}
+
+// enumName returns the name of a GKE API enum value or an empty string for unspecified values.
+func enumName(name string) string {
+	if strings.HasSuffix(name, enumUnspecifiedSuffix) {
+		return ""
+	}
+	return name
+}
+
+func emptyIfNil(values []string) []string {
+	if values == nil {
+		return make([]string, 0)
+	}
+	return values
+}
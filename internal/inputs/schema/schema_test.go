@@ -0,0 +1,194 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/container/apiv1/containerpb"
+)
+
+func TestNormalizeCluster(t *testing.T) {
+	id := "projects/test/locations/europe-west2/clusters/cluster"
+	c := &containerpb.Cluster{
+		Name:                 "cluster",
+		Location:             "europe-west2",
+		CurrentMasterVersion: "1.29.1-gke.100",
+		ReleaseChannel:       &containerpb.ReleaseChannel{Channel: containerpb.ReleaseChannel_REGULAR},
+		Autopilot:            &containerpb.Autopilot{Enabled: true},
+		NetworkConfig:        &containerpb.NetworkConfig{DatapathProvider: containerpb.DatapathProvider_ADVANCED_DATAPATH},
+		DatabaseEncryption:   &containerpb.DatabaseEncryption{State: containerpb.DatabaseEncryption_ENCRYPTED, KeyName: "key"},
+		LoggingConfig: &containerpb.LoggingConfig{ComponentConfig: &containerpb.LoggingComponentConfig{
+			EnableComponents: []containerpb.LoggingComponentConfig_Component{containerpb.LoggingComponentConfig_SYSTEM_COMPONENTS},
+		}},
+		NodePools: []*containerpb.NodePool{
+			{
+				Name:        "pool",
+				Autoscaling: &containerpb.NodePoolAutoscaling{Enabled: true, MinNodeCount: 1, MaxNodeCount: 3},
+				Management:  &containerpb.NodeManagement{AutoRepair: true},
+				Config: &containerpb.NodeConfig{
+					ImageType:              "COS_CONTAINERD",
+					ShieldedInstanceConfig: &containerpb.ShieldedInstanceConfig{EnableSecureBoot: true},
+				},
+			},
+		},
+	}
+	cluster := NormalizeCluster(id, c)
+	if cluster.SchemaVersion != Version {
+		t.Errorf("schemaVersion = %v; want %v", cluster.SchemaVersion, Version)
+	}
+	if cluster.ID != id {
+		t.Errorf("id = %v; want %v", cluster.ID, id)
+	}
+	if cluster.Mode != ModeAutopilot {
+		t.Errorf("mode = %v; want %v", cluster.Mode, ModeAutopilot)
+	}
+	if cluster.ReleaseChannel != "REGULAR" {
+		t.Errorf("releaseChannel = %v; want %v", cluster.ReleaseChannel, "REGULAR")
+	}
+	if !cluster.Network.NetworkPolicy || cluster.Network.DatapathProvider != "ADVANCED_DATAPATH" {
+		t.Errorf("network = %+v; want network policy with advanced datapath", cluster.Network)
+	}
+	if !cluster.Security.SecretsEncryption || cluster.Security.SecretsEncryptionKey != "key" {
+		t.Errorf("security = %+v; want secrets encryption with key", cluster.Security)
+	}
+	if !reflect.DeepEqual(cluster.Logging.Components, []string{"SYSTEM_COMPONENTS"}) {
+		t.Errorf("logging components = %v; want %v", cluster.Logging.Components, []string{"SYSTEM_COMPONENTS"})
+	}
+	if len(cluster.NodePools) != 1 {
+		t.Fatalf("len(nodePools) = %v; want %v", len(cluster.NodePools), 1)
+	}
+	nodePool := cluster.NodePools[0]
+	if !nodePool.Autoscaling.Enabled || nodePool.Autoscaling.MaxNodeCount != 3 {
+		t.Errorf("node pool autoscaling = %+v; want enabled with max 3 nodes", nodePool.Autoscaling)
+	}
+	if !nodePool.Management.AutoRepair || nodePool.Management.AutoUpgrade {
+		t.Errorf("node pool management = %+v; want auto repair only", nodePool.Management)
+	}
+	if !nodePool.Config.SecureBoot || nodePool.Config.IntegrityMonitoring {
+		t.Errorf("node pool config = %+v; want secure boot only", nodePool.Config)
+	}
+	if nodePool.Config.WorkloadMetadataMode != "" {
+		t.Errorf("workload metadata mode = %v; want empty", nodePool.Config.WorkloadMetadataMode)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cluster := NormalizeCluster("projects/p/locations/l/clusters/c", &containerpb.Cluster{
+		Name:      "c",
+		NodePools: []*containerpb.NodePool{{Name: "pool"}},
+	})
+	data, err := json.Marshal(cluster)
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	if err := Validate(data); err != nil {
+		t.Errorf("err = %v; want nil", err)
+	}
+}
+
+func TestValidate_negative(t *testing.T) {
+	cluster := NormalizeCluster("projects/p/locations/l/clusters/c", &containerpb.Cluster{Name: "c"})
+	data, _ := json.Marshal(cluster)
+	var doc map[string]interface{}
+	_ = json.Unmarshal(data, &doc)
+
+	testCases := map[string]func(doc map[string]interface{}){
+		"schemaVersion": func(doc map[string]interface{}) { doc["schemaVersion"] = "v0" },
+		"unknown":       func(doc map[string]interface{}) { doc["unknown"] = true },
+		"missing":       func(doc map[string]interface{}) { delete(doc, "name") },
+		"mode":          func(doc map[string]interface{}) { doc["mode"] = "OTHER" },
+		"type":          func(doc map[string]interface{}) { doc["currentNodeCount"] = "3" },
+		"nested": func(doc map[string]interface{}) {
+			doc["nodePools"] = []interface{}{map[string]interface{}{"name": 1}}
+		},
+	}
+	for name, modify := range testCases {
+		invalid := make(map[string]interface{})
+		for k, v := range doc {
+			invalid[k] = v
+		}
+		modify(invalid)
+		data, _ := json.Marshal(invalid)
+		if err := Validate(data); err == nil {
+			t.Errorf("%s: err = nil; want error", name)
+		}
+	}
+	if err := Validate([]byte("not a json")); err == nil {
+		t.Errorf("err = nil; want error for invalid JSON")
+	}
+}
+
+// jsonSchema is a subset of the JSON Schema keywords used to compare the schema with the normalized types.
+type jsonSchema struct {
+	Properties map[string]*jsonSchema `json:"properties"`
+	Defs       map[string]*jsonSchema `json:"$defs"`
+}
+
+// TestJSONSchemaProperties checks if the JSON Schema is in sync with the normalized types.
+func TestJSONSchemaProperties(t *testing.T) {
+	var root jsonSchema
+	if err := json.Unmarshal(JSONSchema(), &root); err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	types := map[string]interface{}{
+		"":                         Cluster{},
+		"network":                  Network{},
+		"security":                 Security{},
+		"addons":                   Addons{},
+		"maintenance":              Maintenance{},
+		"notifications":            Notifications{},
+		"observability":            Observability{},
+		"autoscaling":              Autoscaling{},
+		"autoprovisioningDefaults": AutoprovisioningDefaults{},
+		"nodePool":                 NodePool{},
+		"nodePoolAutoscaling":      NodePoolAutoscaling{},
+		"nodePoolManagement":       NodePoolManagement{},
+		"nodeConfig":               NodeConfig{},
+		"nodePoolNetwork":          NodePoolNetwork{},
+	}
+	for def, value := range types {
+		s := &root
+		if def != "" {
+			s = root.Defs[def]
+		}
+		if s == nil {
+			t.Errorf("schema definition %q is missing", def)
+			continue
+		}
+		fields := getJSONFieldNames(reflect.TypeOf(value))
+		properties := make([]string, 0, len(s.Properties))
+		for name := range s.Properties {
+			properties = append(properties, name)
+		}
+		sort.Strings(properties)
+		if !reflect.DeepEqual(fields, properties) {
+			t.Errorf("schema definition %q properties = %v; want %v", def, properties, fields)
+		}
+	}
+}
+
+func getJSONFieldNames(typ reflect.Type) []string {
+	names := make([]string, 0, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		names = append(names, strings.Split(typ.Field(i).Tag.Get("json"), ",")[0])
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,59 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+const clusterSchemaURL = "https://github.com/google/gke-policy-automation/schemas/cluster.v1.schema.json"
+
+//go:embed cluster.v1.schema.json
+var clusterSchemaJSON []byte
+
+var compileClusterSchema = sync.OnceValues(func() (*jsonschema.Schema, error) {
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(clusterSchemaJSON))
+	if err != nil {
+		return nil, err
+	}
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(clusterSchemaURL, doc); err != nil {
+		return nil, err
+	}
+	return compiler.Compile(clusterSchemaURL)
+})
+
+// JSONSchema returns the JSON Schema of the normalized cluster.
+func JSONSchema() []byte {
+	return clusterSchemaJSON
+}
+
+// Validate validates a normalized cluster document against the JSON Schema.
+func Validate(data []byte) error {
+	clusterSchema, err := compileClusterSchema()
+	if err != nil {
+		return fmt.Errorf("invalid cluster schema: %w", err)
+	}
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	return clusterSchema.Validate(doc)
+}
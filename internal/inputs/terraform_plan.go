@@ -0,0 +1,449 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inputs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"cloud.google.com/go/container/apiv1/containerpb"
+	"github.com/google/gke-policy-automation/internal/gke"
+	"github.com/google/gke-policy-automation/internal/terraform"
+)
+
+const (
+	terraformPlanInputID          = "terraformPlan"
+	terraformPlanDataSourceName   = "gke"
+	terraformPlanInputDescription = "GKE cluster data from Terraform plan"
+)
+
+type terraformPlanInput struct {
+	readFileFunc func(name string) ([]byte, error)
+	planFile     string
+	once         sync.Once
+	clusters     map[string]*containerpb.Cluster
+	err          error
+}
+
+// NewTerraformPlanInput returns an input that maps GKE resources from a Terraform plan
+// in a JSON format, as produced by the `terraform show -json` command.
+func NewTerraformPlanInput(planFile string) Input {
+	return &terraformPlanInput{
+		readFileFunc: os.ReadFile,
+		planFile:     planFile,
+	}
+}
+
+func (i *terraformPlanInput) GetID() string {
+	return terraformPlanInputID
+}
+
+func (i *terraformPlanInput) GetDescription() string {
+	return terraformPlanInputDescription
+}
+
+func (i *terraformPlanInput) GetDataSourceName() string {
+	return terraformPlanDataSourceName
+}
+
+func (i *terraformPlanInput) GetData(ctx context.Context, clusterID string) (interface{}, error) {
+	i.once.Do(func() {
+		i.clusters, i.err = i.loadClusters()
+	})
+	if i.err != nil {
+		return nil, i.err
+	}
+	cluster, ok := i.clusters[clusterID]
+	if !ok {
+		return nil, fmt.Errorf("cluster %s not found in a Terraform plan", clusterID)
+	}
+	return cluster, nil
+}
+
+func (i *terraformPlanInput) Close() error {
+	return nil
+}
+
+func (i *terraformPlanInput) loadClusters() (map[string]*containerpb.Cluster, error) {
+	data, err := i.readFileFunc(i.planFile)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := terraform.ParsePlan(data)
+	if err != nil {
+		return nil, err
+	}
+	return mapTerraformPlanClusters(plan), nil
+}
+
+// mapTerraformPlanClusters maps planned cluster and node pool resources to the GKE API
+// cluster representation. Node pool resources are assigned to the clusters using either
+// their planned cluster value or the cluster references from the plan configuration.
+func mapTerraformPlanClusters(plan *terraform.Plan) map[string]*containerpb.Cluster {
+	references := plan.References("cluster")
+	clusters := make(map[string]*containerpb.Cluster)
+	clustersByAddress := make(map[string]*containerpb.Cluster)
+	for _, r := range plan.ManagedResources(terraform.ClusterResourceType) {
+		cluster := mapTerraformCluster(r.Values)
+		clusters[gke.GetOfflineClusterID(getTerraformString(r.Values, "project"),
+			getTerraformString(r.Values, "location"), getTerraformString(r.Values, "name"))] = cluster
+		clustersByAddress[r.ResourceAddress()] = cluster
+	}
+	for _, r := range plan.ManagedResources(terraform.NodePoolResourceType) {
+		cluster := findTerraformNodePoolCluster(r, clusters, clustersByAddress, references)
+		if cluster == nil {
+			continue
+		}
+		cluster.NodePools = append(cluster.NodePools, mapTerraformNodePool(r.Values))
+	}
+	return clusters
+}
+
+// findTerraformNodePoolCluster returns the cluster of a given node pool resource. The cluster
+// references from the configuration are resolved in the module instance of the node pool.
+func findTerraformNodePoolCluster(r *terraform.Resource, clusters, clustersByAddress map[string]*containerpb.Cluster, references map[string][]string) *containerpb.Cluster {
+	if clusterValue := getTerraformString(r.Values, "cluster"); clusterValue != "" {
+		if cluster, ok := clusters[clusterValue]; ok {
+			return cluster
+		}
+		for _, cluster := range clusters {
+			if cluster.Name == clusterValue && (cluster.Location == getTerraformString(r.Values, "location") || cluster.Location == "") {
+				return cluster
+			}
+		}
+	}
+	for _, ref := range references[r.ConfigAddress()] {
+		parts := strings.SplitN(ref, ".", 3)
+		if len(parts) < 2 || parts[0] != terraform.ClusterResourceType {
+			continue
+		}
+		if cluster, ok := clustersByAddress[terraform.ModulePrefix(r.ModuleAddress)+parts[0]+"."+parts[1]]; ok {
+			return cluster
+		}
+	}
+	return nil
+}
+
+func mapTerraformCluster(values map[string]interface{}) *containerpb.Cluster {
+	cluster := &containerpb.Cluster{
+		Name:                  getTerraformString(values, "name"),
+		Description:           getTerraformString(values, "description"),
+		Location:              getTerraformString(values, "location"),
+		Locations:             getTerraformStrings(values, "node_locations"),
+		Network:               getTerraformString(values, "network"),
+		Subnetwork:            getTerraformString(values, "subnetwork"),
+		InitialNodeCount:      int32(getTerraformInt(values, "initial_node_count")),
+		InitialClusterVersion: getTerraformString(values, "min_master_version"),
+		CurrentMasterVersion:  getTerraformString(values, "min_master_version"),
+		ResourceLabels:        getTerraformStringMap(values, "resource_labels"),
+	}
+	if v, ok := values["enable_legacy_abac"].(bool); ok {
+		cluster.LegacyAbac = &containerpb.LegacyAbac{Enabled: v}
+	}
+	if v, ok := values["enable_shielded_nodes"].(bool); ok {
+		cluster.ShieldedNodes = &containerpb.ShieldedNodes{Enabled: v}
+	}
+	if v, ok := values["enable_autopilot"].(bool); ok {
+		cluster.Autopilot = &containerpb.Autopilot{Enabled: v}
+	}
+	cluster.NetworkConfig = &containerpb.NetworkConfig{
+		Network:                   cluster.Network,
+		Subnetwork:                cluster.Subnetwork,
+		EnableIntraNodeVisibility: getTerraformBool(values, "enable_intranode_visibility"),
+		EnableL4IlbSubsetting:     getTerraformBool(values, "enable_l4_ilb_subsetting"),
+		DatapathProvider:          containerpb.DatapathProvider(containerpb.DatapathProvider_value[getTerraformString(values, "datapath_provider")]),
+	}
+	if b := getTerraformBlock(values, "release_channel"); b != nil {
+		cluster.ReleaseChannel = &containerpb.ReleaseChannel{
+			Channel: containerpb.ReleaseChannel_Channel(containerpb.ReleaseChannel_Channel_value[getTerraformString(b, "channel")]),
+		}
+	}
+	if b := getTerraformBlock(values, "binary_authorization"); b != nil {
+		mode := containerpb.BinaryAuthorization_EvaluationMode(containerpb.BinaryAuthorization_EvaluationMode_value[getTerraformString(b, "evaluation_mode")])
+		cluster.BinaryAuthorization = &containerpb.BinaryAuthorization{
+			Enabled:        getTerraformBool(b, "enabled") || mode == containerpb.BinaryAuthorization_PROJECT_SINGLETON_POLICY_ENFORCE,
+			EvaluationMode: mode,
+		}
+	}
+	if b := getTerraformBlock(values, "workload_identity_config"); b != nil {
+		cluster.WorkloadIdentityConfig = &containerpb.WorkloadIdentityConfig{WorkloadPool: getTerraformString(b, "workload_pool")}
+	}
+	if b := getTerraformBlock(values, "addons_config"); b != nil {
+		cluster.AddonsConfig = mapTerraformAddonsConfig(b)
+	}
+	if b := getTerraformBlock(values, "network_policy"); b != nil {
+		cluster.NetworkPolicy = &containerpb.NetworkPolicy{
+			Enabled:  getTerraformBool(b, "enabled"),
+			Provider: containerpb.NetworkPolicy_Provider(containerpb.NetworkPolicy_Provider_value[getTerraformString(b, "provider")]),
+		}
+	}
+	if b := getTerraformBlock(values, "private_cluster_config"); b != nil {
+		cluster.PrivateClusterConfig = &containerpb.PrivateClusterConfig{
+			EnablePrivateNodes:    getTerraformBool(b, "enable_private_nodes"),
+			EnablePrivateEndpoint: getTerraformBool(b, "enable_private_endpoint"),
+			MasterIpv4CidrBlock:   getTerraformString(b, "master_ipv4_cidr_block"),
+		}
+	}
+	if b := getTerraformBlock(values, "master_authorized_networks_config"); b != nil {
+		config := &containerpb.MasterAuthorizedNetworksConfig{Enabled: true}
+		for _, block := range getTerraformBlocks(b, "cidr_blocks") {
+			config.CidrBlocks = append(config.CidrBlocks, &containerpb.MasterAuthorizedNetworksConfig_CidrBlock{
+				CidrBlock:   getTerraformString(block, "cidr_block"),
+				DisplayName: getTerraformString(block, "display_name"),
+			})
+		}
+		cluster.MasterAuthorizedNetworksConfig = config
+	}
+	if b := getTerraformBlock(values, "ip_allocation_policy"); b != nil || getTerraformString(values, "networking_mode") == "VPC_NATIVE" {
+		cluster.IpAllocationPolicy = &containerpb.IPAllocationPolicy{
+			UseIpAliases:               true,
+			ClusterSecondaryRangeName:  getTerraformString(b, "cluster_secondary_range_name"),
+			ServicesSecondaryRangeName: getTerraformString(b, "services_secondary_range_name"),
+		}
+	}
+	if b := getTerraformBlock(values, "database_encryption"); b != nil {
+		cluster.DatabaseEncryption = &containerpb.DatabaseEncryption{
+			State:   containerpb.DatabaseEncryption_State(containerpb.DatabaseEncryption_State_value[getTerraformString(b, "state")]),
+			KeyName: getTerraformString(b, "key_name"),
+		}
+	}
+	if b := getTerraformBlock(values, "security_posture_config"); b != nil {
+		config := &containerpb.SecurityPostureConfig{}
+		if v, ok := containerpb.SecurityPostureConfig_Mode_value[getTerraformString(b, "mode")]; ok {
+			config.Mode = containerpb.SecurityPostureConfig_Mode(v).Enum()
+		}
+		if v, ok := containerpb.SecurityPostureConfig_VulnerabilityMode_value[getTerraformString(b, "vulnerability_mode")]; ok {
+			config.VulnerabilityMode = containerpb.SecurityPostureConfig_VulnerabilityMode(v).Enum()
+		}
+		cluster.SecurityPostureConfig = config
+	}
+	if b := getTerraformBlock(values, "maintenance_policy"); b != nil {
+		cluster.MaintenancePolicy = mapTerraformMaintenancePolicy(b)
+	}
+	if b := getTerraformBlock(getTerraformBlock(values, "notification_config"), "pubsub"); b != nil {
+		cluster.NotificationConfig = &containerpb.NotificationConfig{
+			Pubsub: &containerpb.NotificationConfig_PubSub{
+				Enabled: getTerraformBool(b, "enabled"),
+				Topic:   getTerraformString(b, "topic"),
+			},
+		}
+	}
+	if b := getTerraformBlock(values, "authenticator_groups_config"); b != nil {
+		cluster.AuthenticatorGroupsConfig = &containerpb.AuthenticatorGroupsConfig{
+			Enabled:       getTerraformString(b, "security_group") != "",
+			SecurityGroup: getTerraformString(b, "security_group"),
+		}
+	}
+	if b := getTerraformBlock(values, "logging_config"); b != nil {
+		config := &containerpb.LoggingComponentConfig{}
+		for _, c := range getTerraformStrings(b, "enable_components") {
+			config.EnableComponents = append(config.EnableComponents,
+				containerpb.LoggingComponentConfig_Component(containerpb.LoggingComponentConfig_Component_value[c]))
+		}
+		cluster.LoggingConfig = &containerpb.LoggingConfig{ComponentConfig: config}
+	}
+	if b := getTerraformBlock(values, "monitoring_config"); b != nil {
+		config := &containerpb.MonitoringComponentConfig{}
+		for _, c := range getTerraformStrings(b, "enable_components") {
+			config.EnableComponents = append(config.EnableComponents,
+				containerpb.MonitoringComponentConfig_Component(containerpb.MonitoringComponentConfig_Component_value[c]))
+		}
+		cluster.MonitoringConfig = &containerpb.MonitoringConfig{ComponentConfig: config}
+	}
+	if b := getTerraformBlock(values, "cluster_autoscaling"); b != nil {
+		cluster.Autoscaling = mapTerraformClusterAutoscaling(b)
+	}
+	for _, b := range getTerraformBlocks(values, "node_pool") {
+		cluster.NodePools = append(cluster.NodePools, mapTerraformNodePool(b))
+	}
+	return cluster
+}
+
+func mapTerraformAddonsConfig(values map[string]interface{}) *containerpb.AddonsConfig {
+	config := &containerpb.AddonsConfig{}
+	if b := getTerraformBlock(values, "http_load_balancing"); b != nil {
+		config.HttpLoadBalancing = &containerpb.HttpLoadBalancing{Disabled: getTerraformBool(b, "disabled")}
+	}
+	if b := getTerraformBlock(values, "horizontal_pod_autoscaling"); b != nil {
+		config.HorizontalPodAutoscaling = &containerpb.HorizontalPodAutoscaling{Disabled: getTerraformBool(b, "disabled")}
+	}
+	if b := getTerraformBlock(values, "network_policy_config"); b != nil {
+		config.NetworkPolicyConfig = &containerpb.NetworkPolicyConfig{Disabled: getTerraformBool(b, "disabled")}
+	}
+	if b := getTerraformBlock(values, "dns_cache_config"); b != nil {
+		config.DnsCacheConfig = &containerpb.DnsCacheConfig{Enabled: getTerraformBool(b, "enabled")}
+	}
+	if b := getTerraformBlock(values, "gce_persistent_disk_csi_driver_config"); b != nil {
+		config.GcePersistentDiskCsiDriverConfig = &containerpb.GcePersistentDiskCsiDriverConfig{Enabled: getTerraformBool(b, "enabled")}
+	}
+	return config
+}
+
+func mapTerraformMaintenancePolicy(values map[string]interface{}) *containerpb.MaintenancePolicy {
+	window := &containerpb.MaintenanceWindow{}
+	if b := getTerraformBlock(values, "daily_maintenance_window"); b != nil {
+		window.Policy = &containerpb.MaintenanceWindow_DailyMaintenanceWindow{
+			DailyMaintenanceWindow: &containerpb.DailyMaintenanceWindow{StartTime: getTerraformString(b, "start_time")},
+		}
+	}
+	if b := getTerraformBlock(values, "recurring_window"); b != nil {
+		window.Policy = &containerpb.MaintenanceWindow_RecurringWindow{
+			RecurringWindow: &containerpb.RecurringTimeWindow{Recurrence: getTerraformString(b, "recurrence")},
+		}
+	}
+	return &containerpb.MaintenancePolicy{Window: window}
+}
+
+func mapTerraformClusterAutoscaling(values map[string]interface{}) *containerpb.ClusterAutoscaling {
+	autoscaling := &containerpb.ClusterAutoscaling{
+		EnableNodeAutoprovisioning: getTerraformBool(values, "enabled"),
+		AutoprovisioningLocations:  getTerraformStrings(values, "auto_provisioning_locations"),
+	}
+	if b := getTerraformBlock(values, "auto_provisioning_defaults"); b != nil {
+		defaults := &containerpb.AutoprovisioningNodePoolDefaults{
+			ServiceAccount: getTerraformString(b, "service_account"),
+			ImageType:      getTerraformString(b, "image_type"),
+			BootDiskKmsKey: getTerraformString(b, "boot_disk_kms_key"),
+			OauthScopes:    getTerraformStrings(b, "oauth_scopes"),
+		}
+		if s := getTerraformBlock(b, "shielded_instance_config"); s != nil {
+			defaults.ShieldedInstanceConfig = &containerpb.ShieldedInstanceConfig{
+				EnableSecureBoot:          getTerraformBool(s, "enable_secure_boot"),
+				EnableIntegrityMonitoring: getTerraformBool(s, "enable_integrity_monitoring"),
+			}
+		}
+		autoscaling.AutoprovisioningNodePoolDefaults = defaults
+	}
+	return autoscaling
+}
+
+func mapTerraformNodePool(values map[string]interface{}) *containerpb.NodePool {
+	nodePool := &containerpb.NodePool{
+		Name:             getTerraformString(values, "name"),
+		Locations:        getTerraformStrings(values, "node_locations"),
+		Version:          getTerraformString(values, "version"),
+		InitialNodeCount: int32(getTerraformInt(values, "initial_node_count")),
+	}
+	if b := getTerraformBlock(values, "autoscaling"); b != nil {
+		nodePool.Autoscaling = &containerpb.NodePoolAutoscaling{
+			Enabled:           true,
+			MinNodeCount:      int32(getTerraformInt(b, "min_node_count")),
+			MaxNodeCount:      int32(getTerraformInt(b, "max_node_count")),
+			TotalMinNodeCount: int32(getTerraformInt(b, "total_min_node_count")),
+			TotalMaxNodeCount: int32(getTerraformInt(b, "total_max_node_count")),
+		}
+	}
+	if b := getTerraformBlock(values, "management"); b != nil {
+		nodePool.Management = &containerpb.NodeManagement{
+			AutoRepair:  getTerraformBool(b, "auto_repair"),
+			AutoUpgrade: getTerraformBool(b, "auto_upgrade"),
+		}
+	}
+	if b := getTerraformBlock(values, "network_config"); b != nil {
+		nodePool.NetworkConfig = &containerpb.NodeNetworkConfig{
+			PodRange:         getTerraformString(b, "pod_range"),
+			PodIpv4CidrBlock: getTerraformString(b, "pod_ipv4_cidr_block"),
+		}
+	}
+	if b := getTerraformBlock(values, "node_config"); b != nil {
+		config := &containerpb.NodeConfig{
+			MachineType:    getTerraformString(b, "machine_type"),
+			DiskSizeGb:     int32(getTerraformInt(b, "disk_size_gb")),
+			DiskType:       getTerraformString(b, "disk_type"),
+			ImageType:      getTerraformString(b, "image_type"),
+			ServiceAccount: getTerraformString(b, "service_account"),
+			BootDiskKmsKey: getTerraformString(b, "boot_disk_kms_key"),
+			OauthScopes:    getTerraformStrings(b, "oauth_scopes"),
+			Labels:         getTerraformStringMap(b, "labels"),
+		}
+		if s := getTerraformBlock(b, "shielded_instance_config"); s != nil {
+			config.ShieldedInstanceConfig = &containerpb.ShieldedInstanceConfig{
+				EnableSecureBoot:          getTerraformBool(s, "enable_secure_boot"),
+				EnableIntegrityMonitoring: getTerraformBool(s, "enable_integrity_monitoring"),
+			}
+		}
+		if w := getTerraformBlock(b, "workload_metadata_config"); w != nil {
+			config.WorkloadMetadataConfig = &containerpb.WorkloadMetadataConfig{
+				Mode: containerpb.WorkloadMetadataConfig_Mode(containerpb.WorkloadMetadataConfig_Mode_value[getTerraformString(w, "mode")]),
+			}
+		}
+		nodePool.Config = config
+	}
+	return nodePool
+}
+
+func getTerraformString(values map[string]interface{}, key string) string {
+	v, _ := values[key].(string)
+	return v
+}
+
+func getTerraformBool(values map[string]interface{}, key string) bool {
+	v, _ := values[key].(bool)
+	return v
+}
+
+func getTerraformInt(values map[string]interface{}, key string) int64 {
+	v, _ := values[key].(float64)
+	return int64(v)
+}
+
+func getTerraformStrings(values map[string]interface{}, key string) []string {
+	list, _ := values[key].([]interface{})
+	var result []string
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+func getTerraformStringMap(values map[string]interface{}, key string) map[string]string {
+	m, _ := values[key].(map[string]interface{})
+	if len(m) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(m))
+	for k, v := range m {
+		if s, ok := v.(string); ok {
+			result[k] = s
+		}
+	}
+	return result
+}
+
+// getTerraformBlocks returns a list of nested blocks stored under a given key.
+func getTerraformBlocks(values map[string]interface{}, key string) []map[string]interface{} {
+	list, _ := values[key].([]interface{})
+	var result []map[string]interface{}
+	for _, item := range list {
+		if m, ok := item.(map[string]interface{}); ok {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// getTerraformBlock returns the first nested block stored under a given key or nil.
+func getTerraformBlock(values map[string]interface{}, key string) map[string]interface{} {
+	blocks := getTerraformBlocks(values, key)
+	if len(blocks) == 0 {
+		return nil
+	}
+	return blocks[0]
+}
@@ -0,0 +1,136 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inputs
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"cloud.google.com/go/container/apiv1/containerpb"
+)
+
+func TestNewTerraformPlanInput(t *testing.T) {
+	filename := "plan.json"
+	input := NewTerraformPlanInput(filename)
+	planInput, ok := input.(*terraformPlanInput)
+	if !ok {
+		t.Fatalf("input type is not *terraformPlanInput")
+	}
+	if planInput.planFile != filename {
+		t.Errorf("input planFile = %v; want %v", planInput.planFile, filename)
+	}
+	if planInput.GetID() != terraformPlanInputID {
+		t.Errorf("id = %v; want %v", planInput.GetID(), terraformPlanInputID)
+	}
+	if planInput.GetDataSourceName() != gkeLocalDataSourceName {
+		t.Errorf("data source name = %v; want %v", planInput.GetDataSourceName(), gkeLocalDataSourceName)
+	}
+}
+
+func TestTerraformPlanGetData(t *testing.T) {
+	input := &terraformPlanInput{
+		readFileFunc: os.ReadFile,
+		planFile:     "../terraform/test-fixtures/terraform_plan.json",
+	}
+	data, err := input.GetData(context.Background(), "projects/my-project/locations/europe-west2/clusters/cluster-one")
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	cluster, ok := data.(*containerpb.Cluster)
+	if !ok {
+		t.Fatalf("data is not *containerpb.Cluster")
+	}
+	if cluster.Name != "cluster-one" {
+		t.Errorf("name = %v; want %v", cluster.Name, "cluster-one")
+	}
+	if cluster.ReleaseChannel.GetChannel() != containerpb.ReleaseChannel_REGULAR {
+		t.Errorf("release channel = %v; want %v", cluster.ReleaseChannel.GetChannel(), containerpb.ReleaseChannel_REGULAR)
+	}
+	if cluster.NetworkConfig.GetDatapathProvider() != containerpb.DatapathProvider_ADVANCED_DATAPATH {
+		t.Errorf("datapath provider = %v; want %v", cluster.NetworkConfig.GetDatapathProvider(), containerpb.DatapathProvider_ADVANCED_DATAPATH)
+	}
+	if cluster.DatabaseEncryption.GetState() != containerpb.DatabaseEncryption_ENCRYPTED {
+		t.Errorf("database encryption state = %v; want %v", cluster.DatabaseEncryption.GetState(), containerpb.DatabaseEncryption_ENCRYPTED)
+	}
+	if cluster.SecurityPostureConfig.GetMode() != containerpb.SecurityPostureConfig_BASIC {
+		t.Errorf("security posture mode = %v; want %v", cluster.SecurityPostureConfig.GetMode(), containerpb.SecurityPostureConfig_BASIC)
+	}
+	if cluster.MaintenancePolicy.GetWindow().GetDailyMaintenanceWindow().GetStartTime() != "03:00" {
+		t.Errorf("maintenance window start time = %v; want %v", cluster.MaintenancePolicy.GetWindow().GetDailyMaintenanceWindow().GetStartTime(), "03:00")
+	}
+	if len(cluster.LoggingConfig.GetComponentConfig().GetEnableComponents()) != 2 {
+		t.Errorf("logging components = %v; want %v", len(cluster.LoggingConfig.GetComponentConfig().GetEnableComponents()), 2)
+	}
+	if len(cluster.NodePools) != 1 {
+		t.Fatalf("number of node pools = %v; want %v", len(cluster.NodePools), 1)
+	}
+	nodePool := cluster.NodePools[0]
+	if nodePool.Name != "pool-one" {
+		t.Errorf("node pool name = %v; want %v", nodePool.Name, "pool-one")
+	}
+	if !nodePool.Management.GetAutoRepair() || !nodePool.Management.GetAutoUpgrade() {
+		t.Errorf("node pool management = %v; want auto repair and auto upgrade", nodePool.Management)
+	}
+	if !nodePool.Autoscaling.GetEnabled() || nodePool.Autoscaling.GetMaxNodeCount() != 3 {
+		t.Errorf("node pool autoscaling = %v; want enabled with max 3 nodes", nodePool.Autoscaling)
+	}
+	if !nodePool.Config.GetShieldedInstanceConfig().GetEnableSecureBoot() {
+		t.Errorf("node pool secure boot = false; want true")
+	}
+	if nodePool.Config.GetWorkloadMetadataConfig().GetMode() != containerpb.WorkloadMetadataConfig_GKE_METADATA {
+		t.Errorf("workload metadata mode = %v; want %v", nodePool.Config.GetWorkloadMetadataConfig().GetMode(), containerpb.WorkloadMetadataConfig_GKE_METADATA)
+	}
+}
+
+func TestTerraformPlanGetData_module(t *testing.T) {
+	input := &terraformPlanInput{
+		readFileFunc: os.ReadFile,
+		planFile:     "../terraform/test-fixtures/terraform_plan.json",
+	}
+	data, err := input.GetData(context.Background(), "projects/-/locations/us-central1/clusters/cluster-two")
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	cluster := data.(*containerpb.Cluster)
+	if !cluster.LegacyAbac.GetEnabled() {
+		t.Errorf("legacy abac = false; want true")
+	}
+	names := make([]string, 0)
+	for _, nodePool := range cluster.NodePools {
+		names = append(names, nodePool.Name)
+	}
+	if len(names) != 2 || names[0] != "default-pool" || names[1] != "extra-pool" {
+		t.Errorf("node pools = %v; want %v", names, []string{"default-pool", "extra-pool"})
+	}
+}
+
+func TestTerraformPlanGetData_negative(t *testing.T) {
+	input := &terraformPlanInput{
+		readFileFunc: os.ReadFile,
+		planFile:     "../terraform/test-fixtures/terraform_plan.json",
+	}
+	if _, err := input.GetData(context.Background(), "projects/p/locations/l/clusters/missing"); err == nil {
+		t.Errorf("err = nil; want error for missing cluster")
+	}
+	input = &terraformPlanInput{
+		readFileFunc: func(name string) ([]byte, error) {
+			return []byte("not a json"), nil
+		},
+	}
+	if _, err := input.GetData(context.Background(), "projects/p/locations/l/clusters/c"); err == nil {
+		t.Errorf("err = nil; want error for invalid plan")
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package outputs
+
+import (
+	"time"
+
+	"github.com/google/gke-policy-automation/internal/bigquery"
+	"github.com/google/gke-policy-automation/internal/log"
+	"github.com/google/gke-policy-automation/internal/policy"
+	"github.com/google/uuid"
+	bq "google.golang.org/api/bigquery/v2"
+)
+
+const (
+	DefaultBigQueryTable = "validation_results"
+
+	bigQueryPartitionField = "validation_time"
+)
+
+type bigQueryResultCollector struct {
+	client       bigquery.BigQueryClient
+	dataset      string
+	table        string
+	runID        string
+	tableEnsured bool
+	insertedRows int
+	reportMapper ValidationReportMapper
+}
+
+// NewBigQueryResultCollector returns collector that streams a row per each cluster and
+// policy evaluation to a given BigQuery table. The table is created when missing.
+func NewBigQueryResultCollector(client bigquery.BigQueryClient, dataset string, table string) ValidationResultCollector {
+	if table == "" {
+		table = DefaultBigQueryTable
+	}
+	return &bigQueryResultCollector{
+		client:       client,
+		dataset:      dataset,
+		table:        table,
+		runID:        uuid.NewString(),
+		reportMapper: NewValidationReportMapper(),
+	}
+}
+
+func (p *bigQueryResultCollector) RegisterResult(results []*policy.PolicyEvaluationResult) error {
+	p.reportMapper.AddResults(results)
+	return nil
+}
+
+// Flush inserts the rows of the results registered since the last flush.
+func (p *bigQueryResultCollector) Flush() error {
+	report := p.reportMapper.GetReport()
+	rows := mapReportToBigQueryRows(report, p.runID)
+	if len(rows) == 0 {
+		return nil
+	}
+	if !p.tableEnsured {
+		if err := p.client.EnsureTable(p.dataset, p.table, bigQueryResultSchema(), bigQueryPartitionField); err != nil {
+			return err
+		}
+		p.tableEnsured = true
+	}
+	if err := p.client.InsertRows(p.dataset, p.table, rows); err != nil {
+		return err
+	}
+	p.insertedRows += len(rows)
+	p.reportMapper = newValidationReportMapperWithTime(report.ValidationTime)
+	return nil
+}
+
+func (p *bigQueryResultCollector) Close() error {
+	if err := p.Flush(); err != nil {
+		return err
+	}
+	log.Infof("Inserted %d validation results to BigQuery table [%s.%s]", p.insertedRows, p.dataset, p.table)
+	return p.client.Close()
+}
+
+func (p *bigQueryResultCollector) Name() string {
+	return p.dataset + "." + p.table + " BigQuery table"
+}
+
+// mapReportToBigQueryRows returns a row per each cluster and policy evaluation. The insert IDs
+// identify the evaluations in a run, so BigQuery deduplicates the rows inserted again.
+func mapReportToBigQueryRows(report *ValidationReport, runID string) []*bigquery.Row {
+	validationTime := report.ValidationTime.UTC().Format(time.RFC3339Nano)
+	rows := make([]*bigquery.Row, 0)
+	for _, reportPolicy := range report.Policies {
+		for _, evaluation := range reportPolicy.ClusterEvaluations {
+			violationDetails := make([]map[string]interface{}, 0, len(evaluation.ViolationDetails))
+			for _, detail := range evaluation.ViolationDetails {
+				violationDetails = append(violationDetails, map[string]interface{}{
+					"msg":         detail.Message,
+					"resource":    detail.Resource,
+					"field":       detail.Field,
+					"remediation": detail.Remediation,
+				})
+			}
+			values := map[string]bq.JsonValue{
+				"run_id":             runID,
+				"validation_time":    validationTime,
+				"cluster":            evaluation.ClusterID,
+				"policy_name":        reportPolicy.PolicyName,
+				"policy_group":       reportPolicy.PolicyGroup,
+				"policy_title":       reportPolicy.PolicyTitle,
+				"policy_description": reportPolicy.PolicyDescription,
+				"recommendation":     reportPolicy.Recommendation,
+				"external_uri":       reportPolicy.ExternalURI,
+				"severity":           reportPolicy.Severity,
+				"is_valid":           evaluation.Valid,
+				"is_errored":         evaluation.Errored,
+				"violations":         nonNilStrings(evaluation.Violations),
+				"violation_details":  violationDetails,
+				"errors":             nonNilStrings(evaluation.ProcessingErrors),
+			}
+			rows = append(rows, &bigquery.Row{
+				InsertID: bigquery.InsertID(runID, evaluation.ClusterID, reportPolicy.PolicyName),
+				Values:   values,
+			})
+		}
+	}
+	return rows
+}
+
+func bigQueryResultSchema() *bq.TableSchema {
+	return &bq.TableSchema{
+		Fields: []*bq.TableFieldSchema{
+			{Name: "run_id", Type: "STRING", Mode: "REQUIRED"},
+			{Name: "validation_time", Type: "TIMESTAMP", Mode: "REQUIRED"},
+			{Name: "cluster", Type: "STRING", Mode: "REQUIRED"},
+			{Name: "policy_name", Type: "STRING", Mode: "REQUIRED"},
+			{Name: "policy_group", Type: "STRING"},
+			{Name: "policy_title", Type: "STRING"},
+			{Name: "policy_description", Type: "STRING"},
+			{Name: "recommendation", Type: "STRING"},
+			{Name: "external_uri", Type: "STRING"},
+			{Name: "severity", Type: "STRING"},
+			{Name: "is_valid", Type: "BOOLEAN", Mode: "REQUIRED"},
+			{Name: "is_errored", Type: "BOOLEAN", Mode: "REQUIRED"},
+			{Name: "violations", Type: "STRING", Mode: "REPEATED"},
+			{Name: "violation_details", Type: "RECORD", Mode: "REPEATED", Fields: []*bq.TableFieldSchema{
+				{Name: "msg", Type: "STRING"},
+				{Name: "resource", Type: "STRING"},
+				{Name: "field", Type: "STRING"},
+				{Name: "remediation", Type: "STRING"},
+			}},
+			{Name: "errors", Type: "STRING", Mode: "REPEATED"},
+		},
+	}
+}
+
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
@@ -0,0 +1,157 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package outputs
+
+import (
+	"testing"
+
+	"github.com/google/gke-policy-automation/internal/bigquery"
+	"github.com/google/gke-policy-automation/internal/policy"
+	"github.com/stretchr/testify/mock"
+	bq "google.golang.org/api/bigquery/v2"
+)
+
+type BigQueryMock struct {
+	mock.Mock
+}
+
+func (m *BigQueryMock) EnsureTable(dataset, table string, schema *bq.TableSchema, partitionField string) error {
+	args := m.Called(dataset, table, schema, partitionField)
+	return args.Error(0)
+}
+
+func (m *BigQueryMock) InsertRows(dataset, table string, rows []*bigquery.Row) error {
+	args := m.Called(dataset, table, rows)
+	return args.Error(0)
+}
+
+func (m *BigQueryMock) Query(query string, params []*bq.QueryParameter) ([]map[string]interface{}, error) {
+	args := m.Called(query, params)
+	return args.Get(0).([]map[string]interface{}), args.Error(1)
+}
+
+func (m *BigQueryMock) Close() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func TestInsertingToBigQuery(t *testing.T) {
+	evalResults := []*policy.PolicyEvaluationResult{
+		{
+			ClusterID: "cluster-one",
+			Policies: []*policy.Policy{
+				{Name: "policy-one", Title: "title", Group: "group", Severity: "High", Valid: true},
+				{Name: "policy-two", Title: "title", Group: "group", Valid: false, Violations: []string{"violation"}},
+			},
+		},
+	}
+	dataset := "my-dataset"
+
+	mockBigQuery := &BigQueryMock{}
+	mockBigQuery.On("EnsureTable", dataset, DefaultBigQueryTable, mock.Anything, bigQueryPartitionField).Return(nil)
+	mockBigQuery.On("InsertRows", dataset, DefaultBigQueryTable, mock.MatchedBy(func(rows []*bigquery.Row) bool {
+		return len(rows) == 2 && rows[0].InsertID != "" && rows[0].InsertID != rows[1].InsertID
+	})).Return(nil)
+	mockBigQuery.On("Close").Return(nil)
+
+	collector := NewBigQueryResultCollector(mockBigQuery, dataset, "")
+	if err := collector.RegisterResult(evalResults); err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	if err := collector.Close(); err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	mockBigQuery.AssertExpectations(t)
+}
+
+func TestInsertingToBigQuery_streaming(t *testing.T) {
+	dataset := "my-dataset"
+	mockBigQuery := &BigQueryMock{}
+	mockBigQuery.On("EnsureTable", dataset, DefaultBigQueryTable, mock.Anything, bigQueryPartitionField).Return(nil).Once()
+	mockBigQuery.On("InsertRows", dataset, DefaultBigQueryTable, mock.MatchedBy(func(rows []*bigquery.Row) bool {
+		return len(rows) == 1
+	})).Return(nil).Twice()
+	mockBigQuery.On("Close").Return(nil)
+
+	collector := NewBigQueryResultCollector(mockBigQuery, dataset, "")
+	streamingCollector, ok := collector.(StreamingResultCollector)
+	if !ok {
+		t.Fatalf("collector is not StreamingResultCollector")
+	}
+	for _, clusterID := range []string{"cluster-one", "cluster-two"} {
+		if err := collector.RegisterResult([]*policy.PolicyEvaluationResult{{
+			ClusterID: clusterID,
+			Policies:  []*policy.Policy{{Name: "policy-one", Valid: true}},
+		}}); err != nil {
+			t.Fatalf("err = %v; want nil", err)
+		}
+		if err := streamingCollector.Flush(); err != nil {
+			t.Fatalf("err = %v; want nil", err)
+		}
+	}
+	if err := collector.Close(); err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	mockBigQuery.AssertExpectations(t)
+}
+
+func TestMapReportToBigQueryRows(t *testing.T) {
+	mapper := NewValidationReportMapper()
+	mapper.AddResult(&policy.PolicyEvaluationResult{
+		ClusterID: "cluster-one",
+		Policies: []*policy.Policy{
+			{
+				Name:       "policy-one",
+				Group:      "group",
+				Severity:   "Critical",
+				Valid:      false,
+				Violations: []string{"violation"},
+				ViolationDetails: []*policy.Violation{
+					{Message: "violation", Resource: "resource"},
+				},
+			},
+		},
+	})
+	rows := mapReportToBigQueryRows(mapper.GetReport(), "run-id")
+	if len(rows) != 1 {
+		t.Fatalf("number of rows = %v; want %v", len(rows), 1)
+	}
+	if expected := bigquery.InsertID("run-id", "cluster-one", "policy-one"); rows[0].InsertID != expected {
+		t.Errorf("insert ID = %v; want %v", rows[0].InsertID, expected)
+	}
+	row := rows[0].Values
+	if row["run_id"] != "run-id" {
+		t.Errorf("run_id = %v; want %v", row["run_id"], "run-id")
+	}
+	if row["cluster"] != "cluster-one" {
+		t.Errorf("cluster = %v; want %v", row["cluster"], "cluster-one")
+	}
+	if row["policy_name"] != "policy-one" {
+		t.Errorf("policy_name = %v; want %v", row["policy_name"], "policy-one")
+	}
+	if row["is_valid"] != false {
+		t.Errorf("is_valid = %v; want %v", row["is_valid"], false)
+	}
+	if errs, ok := row["errors"].([]string); !ok || errs == nil {
+		t.Errorf("errors = %v; want empty list", row["errors"])
+	}
+	details, ok := row["violation_details"].([]map[string]interface{})
+	if !ok || len(details) != 1 {
+		t.Fatalf("violation_details = %v; want one detail", row["violation_details"])
+	}
+	if details[0]["resource"] != "resource" {
+		t.Errorf("violation_details resource = %v; want %v", details[0]["resource"], "resource")
+	}
+}
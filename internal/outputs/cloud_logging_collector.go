@@ -0,0 +1,201 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package outputs
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/gke-policy-automation/internal/bigquery"
+	"github.com/google/gke-policy-automation/internal/gke"
+	"github.com/google/gke-policy-automation/internal/log"
+	"github.com/google/gke-policy-automation/internal/policy"
+	"github.com/google/uuid"
+	logging "google.golang.org/api/logging/v2"
+)
+
+const (
+	DefaultCloudLoggingLogID = "gke-policy-automation"
+
+	cloudLoggingResourceCluster = "k8s_cluster"
+	cloudLoggingResourceGlobal  = "global"
+	cloudLoggingOfflineValue    = "-"
+)
+
+type CloudLoggingClient interface {
+	WriteEntries(entries []*logging.LogEntry) error
+	Close() error
+}
+
+// CloudLoggingPayload is the JSON payload of a log entry with a policy evaluation on a cluster.
+type CloudLoggingPayload struct {
+	RunID          string    `json:"runID"`
+	ValidationTime time.Time `json:"validationDate"`
+	ClusterID      string    `json:"cluster"`
+	PolicyName     string    `json:"policy"`
+	PolicyTitle    string    `json:"title,omitempty"`
+	PolicyGroup    string    `json:"group,omitempty"`
+	Severity       string    `json:"severity,omitempty"`
+	Valid          bool      `json:"valid"`
+	Errored        bool      `json:"errored"`
+	Violations     []string  `json:"violations,omitempty"`
+	Errors         []string  `json:"errors,omitempty"`
+}
+
+type cloudLoggingResultCollector struct {
+	client       CloudLoggingClient
+	project      string
+	logID        string
+	runID        string
+	writtenCount int
+	reportMapper ValidationReportMapper
+}
+
+// NewCloudLoggingResultCollector returns collector that writes a structured log entry per each
+// cluster and policy evaluation, with the k8s_cluster monitored resource of the cluster.
+// The entries are written to the cluster project, unless a project is given.
+func NewCloudLoggingResultCollector(client CloudLoggingClient, project string, logID string) ValidationResultCollector {
+	if logID == "" {
+		logID = DefaultCloudLoggingLogID
+	}
+	return &cloudLoggingResultCollector{
+		client:       client,
+		project:      project,
+		logID:        logID,
+		runID:        uuid.NewString(),
+		reportMapper: NewValidationReportMapper(),
+	}
+}
+
+func (p *cloudLoggingResultCollector) RegisterResult(results []*policy.PolicyEvaluationResult) error {
+	p.reportMapper.AddResults(results)
+	return nil
+}
+
+// Flush writes the log entries of the results registered since the last flush.
+func (p *cloudLoggingResultCollector) Flush() error {
+	report := p.reportMapper.GetReport()
+	entries, err := p.mapReportToLogEntries(report)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	if err := p.client.WriteEntries(entries); err != nil {
+		return err
+	}
+	p.writtenCount += len(entries)
+	p.reportMapper = newValidationReportMapperWithTime(report.ValidationTime)
+	return nil
+}
+
+func (p *cloudLoggingResultCollector) Close() error {
+	if err := p.Flush(); err != nil {
+		return err
+	}
+	log.Infof("Wrote %d validation results to Cloud Logging log [%s]", p.writtenCount, p.logID)
+	return p.client.Close()
+}
+
+func (p *cloudLoggingResultCollector) Name() string {
+	return p.logID + " Cloud Logging log"
+}
+
+func (p *cloudLoggingResultCollector) mapReportToLogEntries(report *ValidationReport) ([]*logging.LogEntry, error) {
+	timestamp := report.ValidationTime.UTC().Format(time.RFC3339Nano)
+	entries := make([]*logging.LogEntry, 0)
+	for _, reportPolicy := range report.Policies {
+		for _, evaluation := range reportPolicy.ClusterEvaluations {
+			payload, err := json.Marshal(&CloudLoggingPayload{
+				RunID:          p.runID,
+				ValidationTime: report.ValidationTime,
+				ClusterID:      evaluation.ClusterID,
+				PolicyName:     reportPolicy.PolicyName,
+				PolicyTitle:    reportPolicy.PolicyTitle,
+				PolicyGroup:    reportPolicy.PolicyGroup,
+				Severity:       reportPolicy.Severity,
+				Valid:          evaluation.Valid,
+				Errored:        evaluation.Errored,
+				Violations:     evaluation.Violations,
+				Errors:         evaluation.ProcessingErrors,
+			})
+			if err != nil {
+				return nil, err
+			}
+			project, resource := p.getLogResource(evaluation.ClusterID)
+			if project == "" {
+				return nil, fmt.Errorf("project of cluster %s is unknown, set the Cloud Logging output project", evaluation.ClusterID)
+			}
+			// deterministic insert ID lets Cloud Logging drop duplicates of retried writes
+			entries = append(entries, &logging.LogEntry{
+				InsertId:    bigquery.InsertID(p.runID, evaluation.ClusterID, reportPolicy.PolicyName),
+				LogName:     fmt.Sprintf("projects/%s/logs/%s", project, p.logID),
+				Resource:    resource,
+				Timestamp:   timestamp,
+				Severity:    getCloudLoggingSeverity(reportPolicy, evaluation),
+				JsonPayload: payload,
+			})
+		}
+	}
+	return entries, nil
+}
+
+// getLogResource returns the project to write the log entry to and the monitored resource
+// of a given cluster. The global resource is used for clusters with unknown project or location,
+// i.e. the offline clusters from Terraform plans.
+func (p *cloudLoggingResultCollector) getLogResource(clusterID string) (string, *logging.MonitoredResource) {
+	project, location, name, err := gke.SliceAndValidateClusterID(clusterID)
+	if err == nil && project != cloudLoggingOfflineValue && location != cloudLoggingOfflineValue {
+		logProject := project
+		if p.project != "" {
+			logProject = p.project
+		}
+		return logProject, &logging.MonitoredResource{
+			Type: cloudLoggingResourceCluster,
+			Labels: map[string]string{
+				"project_id":   project,
+				"location":     location,
+				"cluster_name": name,
+			},
+		}
+	}
+	return p.project, &logging.MonitoredResource{
+		Type:   cloudLoggingResourceGlobal,
+		Labels: map[string]string{"project_id": p.project},
+	}
+}
+
+// getCloudLoggingSeverity maps the evaluation to the log entry severity. Violations are logged
+// with the severity corresponding to the policy severity.
+func getCloudLoggingSeverity(reportPolicy *ValidationReportPolicy, evaluation *ValidationReportClusterEvaluation) string {
+	if evaluation.Errored {
+		return "ERROR"
+	}
+	if evaluation.Valid {
+		return "INFO"
+	}
+	switch reportPolicy.SeverityNumber {
+	case SeverityCritical:
+		return "CRITICAL"
+	case SeverityHigh:
+		return "ERROR"
+	case SeverityLow:
+		return "NOTICE"
+	default:
+		return "WARNING"
+	}
+}
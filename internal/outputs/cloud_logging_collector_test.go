@@ -0,0 +1,146 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package outputs
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/gke-policy-automation/internal/policy"
+	"github.com/stretchr/testify/mock"
+	logging "google.golang.org/api/logging/v2"
+)
+
+type CloudLoggingMock struct {
+	mock.Mock
+}
+
+func (m *CloudLoggingMock) WriteEntries(entries []*logging.LogEntry) error {
+	args := m.Called(entries)
+	return args.Error(0)
+}
+
+func (m *CloudLoggingMock) Close() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func TestWritingToCloudLogging(t *testing.T) {
+	mockLogging := &CloudLoggingMock{}
+	mockLogging.On("WriteEntries", mock.MatchedBy(func(entries []*logging.LogEntry) bool {
+		return len(entries) == 5
+	})).Return(nil)
+	mockLogging.On("Close").Return(nil)
+
+	collector := NewCloudLoggingResultCollector(mockLogging, "", "")
+	collector.RegisterResult(getTestResults())
+	if err := collector.Close(); err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	mockLogging.AssertExpectations(t)
+}
+
+func TestWritingToCloudLogging_streaming(t *testing.T) {
+	mockLogging := &CloudLoggingMock{}
+	mockLogging.On("WriteEntries", mock.MatchedBy(func(entries []*logging.LogEntry) bool {
+		return len(entries) == 5
+	})).Return(nil).Once()
+	mockLogging.On("Close").Return(nil)
+
+	collector := NewCloudLoggingResultCollector(mockLogging, "", "")
+	collector.RegisterResult(getTestResults())
+	if err := collector.(StreamingResultCollector).Flush(); err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	if err := collector.Close(); err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	mockLogging.AssertExpectations(t)
+}
+
+func TestMapReportToLogEntries(t *testing.T) {
+	collector := NewCloudLoggingResultCollector(&CloudLoggingMock{}, "", "").(*cloudLoggingResultCollector)
+	mapper := NewValidationReportMapper()
+	mapper.AddResults(getTestResults())
+	entries, err := collector.mapReportToLogEntries(mapper.GetReport())
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	if len(entries) != 5 {
+		t.Fatalf("number of entries = %v; want %v", len(entries), 5)
+	}
+	entry := entries[0]
+	if entry.LogName != "projects/project/logs/"+DefaultCloudLoggingLogID {
+		t.Errorf("logName = %v; want %v", entry.LogName, "projects/project/logs/"+DefaultCloudLoggingLogID)
+	}
+	if entry.Resource.Type != cloudLoggingResourceCluster {
+		t.Errorf("resource type = %v; want %v", entry.Resource.Type, cloudLoggingResourceCluster)
+	}
+	expectedLabels := map[string]string{"project_id": "project", "location": "europe-west1", "cluster_name": "cluster-one"}
+	for k, v := range expectedLabels {
+		if entry.Resource.Labels[k] != v {
+			t.Errorf("resource label %s = %v; want %v", k, entry.Resource.Labels[k], v)
+		}
+	}
+	if entry.Severity != "CRITICAL" || entries[1].Severity != "INFO" {
+		t.Errorf("severities = %v, %v; want %v, %v", entry.Severity, entries[1].Severity, "CRITICAL", "INFO")
+	}
+	insertIDs := make(map[string]bool)
+	for _, e := range entries {
+		insertIDs[e.InsertId] = true
+	}
+	if len(insertIDs) != len(entries) || insertIDs[""] {
+		t.Errorf("insert IDs = %v; want unique non-empty ID per entry", insertIDs)
+	}
+	retried, err := collector.mapReportToLogEntries(mapper.GetReport())
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	if retried[0].InsertId != entry.InsertId {
+		t.Errorf("insert ID = %v; want %v", retried[0].InsertId, entry.InsertId)
+	}
+	var payload CloudLoggingPayload
+	if err := json.Unmarshal(entry.JsonPayload, &payload); err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	if payload.PolicyName != "policy-one" || payload.PolicyGroup != "Security" || payload.Valid || len(payload.Violations) != 2 {
+		t.Errorf("payload = %+v; want violated policy-one payload", payload)
+	}
+}
+
+func TestMapReportToLogEntries_offlineCluster(t *testing.T) {
+	results := []*policy.PolicyEvaluationResult{
+		{
+			ClusterID: "projects/-/locations/-/clusters/cluster",
+			Policies:  []*policy.Policy{{Name: "policy-one", Valid: true}},
+		},
+	}
+	mapper := NewValidationReportMapper()
+	mapper.AddResults(results)
+
+	collector := NewCloudLoggingResultCollector(&CloudLoggingMock{}, "", "").(*cloudLoggingResultCollector)
+	if _, err := collector.mapReportToLogEntries(mapper.GetReport()); err == nil {
+		t.Errorf("expected error when project of offline cluster is unknown")
+	}
+
+	collector = NewCloudLoggingResultCollector(&CloudLoggingMock{}, "log-project", "my-log").(*cloudLoggingResultCollector)
+	entries, err := collector.mapReportToLogEntries(mapper.GetReport())
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	if entries[0].LogName != "projects/log-project/logs/my-log" || entries[0].Resource.Type != cloudLoggingResourceGlobal {
+		t.Errorf("logName = %v, resource = %v; want %v, %v", entries[0].LogName, entries[0].Resource.Type, "projects/log-project/logs/my-log", cloudLoggingResourceGlobal)
+	}
+}
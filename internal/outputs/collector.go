@@ -19,12 +19,23 @@ import (
 	"github.com/google/gke-policy-automation/internal/policy"
 )
 
+// ValidationResultCollector collects the policy evaluation results. The RegisterResult
+// is called with the results of each cluster as soon as the cluster is evaluated, and the Close
+// is called once all clusters are evaluated.
 type ValidationResultCollector interface {
 	RegisterResult(results []*policy.PolicyEvaluationResult) error
 	Close() error
 	Name() string
 }
 
+// StreamingResultCollector is implemented by the collectors that can write the results
+// incrementally. The Flush is called after each RegisterResult, so the results don't need to be
+// kept in memory until Close.
+type StreamingResultCollector interface {
+	ValidationResultCollector
+	Flush() error
+}
+
 type ClusterDumpCollector interface {
 	RegisterCluster(cluster *inputs.Cluster)
 	Close() error
@@ -16,6 +16,7 @@ package outputs
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/fatih/color"
@@ -24,14 +25,32 @@ import (
 	"github.com/google/gke-policy-automation/internal/policy"
 )
 
+// ConsoleOptions define the content and the layout of the console output.
+type ConsoleOptions struct {
+	// ViolationsOnly hides valid policy evaluations.
+	ViolationsOnly bool
+	// MinSeverity hides policies with a lower severity.
+	MinSeverity string
+	// GroupByCluster lists the evaluations by cluster instead of by policy.
+	GroupByCluster bool
+	// Summary prints only the summary table with cluster statistics.
+	Summary bool
+}
+
 type consoleResultCollector struct {
 	out          *Output
+	options      ConsoleOptions
 	reportMapper ValidationReportMapper
 }
 
 func NewConsoleResultCollector(output *Output) ValidationResultCollector {
+	return NewConsoleResultCollectorWithOptions(output, ConsoleOptions{})
+}
+
+func NewConsoleResultCollectorWithOptions(output *Output, options ConsoleOptions) ValidationResultCollector {
 	return &consoleResultCollector{
 		out:          output,
+		options:      options,
 		reportMapper: NewValidationReportMapper(),
 	}
 }
@@ -43,66 +62,103 @@ func (p *consoleResultCollector) RegisterResult(results []*policy.PolicyEvaluati
 
 func (p *consoleResultCollector) Close() error {
 	report := p.reportMapper.GetReport()
+	for _, policy := range report.Policies {
+		for _, evaluation := range policy.ClusterEvaluations {
+			log.Infof("Policy: %s, Cluster: %s, Valid: %v", policy.PolicyName, evaluation.ClusterID, evaluation.Valid)
+		}
+	}
+	stats := make([]*ValidationReportClusterStats, len(report.ClusterStats))
+	copy(stats, report.ClusterStats)
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].ClusterID < stats[j].ClusterID
+	})
 	p.out.Printf("\n")
-	p.out.InitTabs(0, 4)
-	for i, policy := range report.Policies {
-		severityf := severitySprintfFunc(policy.Severity)
-		ruleTitleF := color.New(color.Bold, color.FgHiWhite).SprintfFunc()
-		p.out.Printf("%s #%d %s %s",
-			IconMagnifier,
-			i+1,
-			severityf("%s", strings.ToUpper(policy.Severity)),
-			ruleTitleF("%s", policy.PolicyTitle),
-		)
-		if policy.ExternalURI != "" {
-			extURI := fmt.Sprintf("(\x1b]8;;%s\x07%s\x1b]8;;\x07)", policy.ExternalURI, "documentation")
-			p.out.Printf(" %s\n", extURI)
+	if !p.options.Summary {
+		policies := filterConsolePolicies(report.Policies, p.options)
+		if p.options.GroupByCluster {
+			p.printClusters(policies, stats)
 		} else {
-			p.out.Printf("\n")
+			p.printPolicies(policies)
 		}
+	}
+	summaryf := color.New(color.Bold, color.FgHiWhite).Sprintf
+	p.out.Printf("%s %s",
+		IconInfo,
+		summaryf("Evaluated %d policies on %d clusters\n", len(report.Policies), len(report.ClusterStats)),
+	)
+	if p.options.Summary {
+		p.printSummaryTable(stats)
+	} else {
+		p.printClusterStats(stats)
+	}
+	p.out.Printf("\n")
+	return nil
+}
+
+func (p *consoleResultCollector) printPolicies(policies []*ValidationReportPolicy) {
+	p.out.InitTabs(0, 4)
+	for i, policy := range policies {
+		p.out.Printf("%s #%d %s\n", IconMagnifier, i+1, formatConsolePolicyTitle(policy))
 		for _, evaluation := range policy.ClusterEvaluations {
 			statusf := evalStatusSprintfFunc(*evaluation)
-			clusterDataf := color.New(color.FgCyan).Sprintf
-			project, location, cluster := gke.MustSliceClusterID(evaluation.ClusterID)
-
-			p.out.TabPrintf("   - projects/%s/locations/%s/clusters/%s\t[%s]\n",
-				clusterDataf("%s", project),
-				clusterDataf("%s", location),
-				clusterDataf("%s", cluster),
+			p.out.TabPrintf("   - %s\t[%s]\n",
+				formatConsoleClusterID(evaluation.ClusterID),
 				statusf("%s", evalStatusString(*evaluation)),
 			)
-
 			if !evaluation.Valid {
-				violationF := color.New(color.Italic, color.FgRed).Sprintf
 				for _, violation := range evaluation.Violations {
-					p.out.TabPrintf("      %s\t\n",
-						violationF("%s %s", IconMiddleDot, violation),
-					)
+					p.out.TabPrintf("      %s\t\n", consoleViolationf("%s %s", IconMiddleDot, violation))
 				}
 			}
-			log.Infof("Policy: %s, Cluster: %s, Valid: %v", policy.PolicyName, evaluation.ClusterID, evaluation.Valid)
 		}
 		p.out.TabFlush()
 		p.out.Printf("\n")
 	}
-	summaryf := color.New(color.Bold, color.FgHiWhite).Sprintf
-	p.out.Printf("%s %s",
-		IconInfo,
-		summaryf("Evaluated %d policies on %d clusters\n", len(report.Policies), len(report.ClusterStats)),
-	)
+}
+
+func (p *consoleResultCollector) printClusters(policies []*ValidationReportPolicy, stats []*ValidationReportClusterStats) {
+	type clusterPolicyEvaluation struct {
+		policy     *ValidationReportPolicy
+		evaluation *ValidationReportClusterEvaluation
+	}
+	evaluations := make(map[string][]clusterPolicyEvaluation)
+	for _, policy := range policies {
+		for _, evaluation := range policy.ClusterEvaluations {
+			evaluations[evaluation.ClusterID] = append(evaluations[evaluation.ClusterID], clusterPolicyEvaluation{policy, evaluation})
+		}
+	}
+	for _, stat := range stats {
+		clusterEvaluations, ok := evaluations[stat.ClusterID]
+		if !ok {
+			continue
+		}
+		p.out.Printf("%s %s\n", IconMagnifier, formatConsoleClusterID(stat.ClusterID))
+		for _, item := range clusterEvaluations {
+			statusf := evalStatusSprintfFunc(*item.evaluation)
+			p.out.Printf("   - [%s] %s\n",
+				statusf("%s", evalStatusString(*item.evaluation)),
+				formatConsolePolicyTitle(item.policy),
+			)
+			if !item.evaluation.Valid {
+				for _, violation := range item.evaluation.Violations {
+					p.out.Printf("      %s\n", consoleViolationf("%s %s", IconMiddleDot, violation))
+				}
+			}
+		}
+		p.out.Printf("\n")
+	}
+}
+
+func (p *consoleResultCollector) printClusterStats(stats []*ValidationReportClusterStats) {
 	p.out.InitTabs(0, 2)
-	for _, stat := range report.ClusterStats {
-		clusterDataf := color.New(color.FgCyan).Sprintf
+	for _, stat := range stats {
 		criticalf := color.New(color.FgHiRed).Sprintf
 		highf := color.New(color.FgRed).Sprintf
 		mediumf := color.New(color.FgYellow).Sprintf
 		lowf := color.New(color.FgHiWhite).Sprintf
-		project, location, cluster := gke.MustSliceClusterID(stat.ClusterID)
 
-		p.out.TabPrintf("  - projects/%s/locations/%s/clusters/%s\t: %s, %s, %s, %s\n",
-			clusterDataf("%s", project),
-			clusterDataf("%s", location),
-			clusterDataf("%s", cluster),
+		p.out.TabPrintf("  - %s\t: %s, %s, %s, %s\n",
+			formatConsoleClusterID(stat.ClusterID),
 			criticalf("%d Critical", stat.ViolatedCriticalCount),
 			highf("%d High", stat.ViolatedHighCount),
 			mediumf("%d Medium", stat.ViolatedMediumCount),
@@ -110,8 +166,97 @@ func (p *consoleResultCollector) Close() error {
 		)
 	}
 	p.out.TabFlush()
+}
+
+// printSummaryTable prints the compact table with the number of valid, violated and errored
+// policies per cluster. Cells are not colored, so the columns stay aligned.
+func (p *consoleResultCollector) printSummaryTable(stats []*ValidationReportClusterStats) {
 	p.out.Printf("\n")
-	return nil
+	p.out.InitTabs(0, 2)
+	p.out.TabPrintf("CLUSTER\tVALID\tVIOLATED\tERRORED\tCRITICAL\tHIGH\tMEDIUM\tLOW\n")
+	for _, stat := range stats {
+		p.out.TabPrintf("%s\t%d\t%d\t%d\t%d\t%d\t%d\t%d\n",
+			stat.ClusterID,
+			stat.ValidPoliciesCount,
+			stat.ViolatedPoliciesCount,
+			stat.ErroredPoliciesCount,
+			stat.ViolatedCriticalCount,
+			stat.ViolatedHighCount,
+			stat.ViolatedMediumCount,
+			stat.ViolatedLowCount,
+		)
+	}
+	p.out.TabFlush()
+}
+
+// filterConsolePolicies returns the policies and cluster evaluations matching the console options.
+// Policies without any matching evaluation are skipped.
+func filterConsolePolicies(policies []*ValidationReportPolicy, options ConsoleOptions) []*ValidationReportPolicy {
+	minSeverityNumber := mapSeverityToNumber(options.MinSeverity)
+	filtered := make([]*ValidationReportPolicy, 0, len(policies))
+	for _, policy := range policies {
+		if policy.SeverityNumber < minSeverityNumber {
+			continue
+		}
+		if !options.ViolationsOnly {
+			filtered = append(filtered, policy)
+			continue
+		}
+		evaluations := make([]*ValidationReportClusterEvaluation, 0, len(policy.ClusterEvaluations))
+		for _, evaluation := range policy.ClusterEvaluations {
+			if !evaluation.Valid {
+				evaluations = append(evaluations, evaluation)
+			}
+		}
+		if len(evaluations) == 0 {
+			continue
+		}
+		filteredPolicy := *policy
+		filteredPolicy.ClusterEvaluations = evaluations
+		filtered = append(filtered, &filteredPolicy)
+	}
+	return filtered
+}
+
+func formatConsolePolicyTitle(policy *ValidationReportPolicy) string {
+	severityf := severitySprintfFunc(policy.Severity)
+	ruleTitleF := color.New(color.Bold, color.FgHiWhite).SprintfFunc()
+	title := fmt.Sprintf("%s %s", severityf("%s", strings.ToUpper(policy.Severity)), ruleTitleF("%s", policy.PolicyTitle))
+	if policy.ExternalURI != "" {
+		title += " " + formatConsoleLink(policy.ExternalURI, "documentation")
+	}
+	return title
+}
+
+func consoleViolationf(format string, a ...interface{}) string {
+	return color.New(color.Italic, color.FgRed).Sprintf(format, a...)
+}
+
+// formatConsoleClusterID returns the cluster identifier with highlighted project, location
+// and name. Fleet membership names and other identifiers are highlighted as a whole.
+func formatConsoleClusterID(clusterID string) string {
+	clusterDataf := color.New(color.FgCyan).Sprintf
+	if gke.IsFleetMembershipName(clusterID) {
+		return clusterDataf("%s", clusterID)
+	}
+	project, location, cluster, err := gke.SliceAndValidateClusterID(clusterID)
+	if err != nil {
+		return clusterDataf("%s", clusterID)
+	}
+	return fmt.Sprintf("projects/%s/locations/%s/clusters/%s",
+		clusterDataf("%s", project),
+		clusterDataf("%s", location),
+		clusterDataf("%s", cluster),
+	)
+}
+
+// formatConsoleLink returns the terminal hyperlink escape sequence, or the plain URI
+// when colors are disabled, i.e. for NO_COLOR or non-TTY output.
+func formatConsoleLink(uri string, text string) string {
+	if color.NoColor {
+		return fmt.Sprintf("(%s)", uri)
+	}
+	return fmt.Sprintf("(\x1b]8;;%s\x07%s\x1b]8;;\x07)", uri, text)
 }
 
 func (p *consoleResultCollector) Name() string {
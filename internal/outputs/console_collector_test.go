@@ -16,9 +16,11 @@ package outputs
 
 import (
 	"bytes"
+	"strings"
 	"testing"
 	"text/tabwriter"
 
+	"github.com/fatih/color"
 	"github.com/google/gke-policy-automation/internal/policy"
 )
 
@@ -61,3 +63,164 @@ func TestConsoleResultCollector(t *testing.T) {
 		t.Errorf("nothing was written to the output buffer")
 	}
 }
+
+func TestConsoleResultCollector_fleetMembership(t *testing.T) {
+	var buff bytes.Buffer
+	out := &Output{w: &buff, tabWriter: tabwriter.NewWriter(&buff, 0, 0, 0, ' ', 0)}
+	membership := "projects/fleet-proj/locations/global/memberships/attached-one"
+	reportMapperMock := &validationReportMapperMock{
+		addResultsFn: func(results []*policy.PolicyEvaluationResult) {},
+		getReportFn: func() *ValidationReport {
+			return &ValidationReport{
+				Policies: []*ValidationReportPolicy{
+					{
+						PolicyName:  "test-policy",
+						PolicyTitle: "test-title",
+						ClusterEvaluations: []*ValidationReportClusterEvaluation{
+							{ClusterID: membership, Violations: []string{"violation"}},
+						},
+					},
+				},
+				ClusterStats: []*ValidationReportClusterStats{
+					{ClusterID: membership, ViolatedPoliciesCount: 1},
+				},
+			}
+		},
+	}
+	for _, options := range []ConsoleOptions{{}, {GroupByCluster: true}, {Summary: true}} {
+		buff.Reset()
+		collector := &consoleResultCollector{out: out, options: options, reportMapper: reportMapperMock}
+		if err := collector.Close(); err != nil {
+			t.Fatalf("err on Close = %v; want nil", err)
+		}
+		if !strings.Contains(buff.String(), membership) {
+			t.Errorf("output with options %+v does not contain membership name: %s", options, buff.String())
+		}
+	}
+}
+
+func getConsoleTestReport() *ValidationReport {
+	return &ValidationReport{
+		Policies: []*ValidationReportPolicy{
+			{
+				PolicyName:     "critical-policy",
+				PolicyTitle:    "critical-title",
+				Severity:       "Critical",
+				SeverityNumber: SeverityCritical,
+				ClusterEvaluations: []*ValidationReportClusterEvaluation{
+					{ClusterID: "projects/test-proj/locations/europe-central2/clusters/cluster-one", Valid: true},
+					{ClusterID: "projects/test-proj/locations/europe-central2/clusters/cluster-two", Violations: []string{"critical-violation"}},
+				},
+			},
+			{
+				PolicyName:     "low-policy",
+				PolicyTitle:    "low-title",
+				Severity:       "Low",
+				SeverityNumber: SeverityLow,
+				ClusterEvaluations: []*ValidationReportClusterEvaluation{
+					{ClusterID: "projects/test-proj/locations/europe-central2/clusters/cluster-one", Violations: []string{"low-violation"}},
+				},
+			},
+			{
+				PolicyName:     "valid-policy",
+				PolicyTitle:    "valid-title",
+				Severity:       "High",
+				SeverityNumber: SeverityHigh,
+				ClusterEvaluations: []*ValidationReportClusterEvaluation{
+					{ClusterID: "projects/test-proj/locations/europe-central2/clusters/cluster-one", Valid: true},
+				},
+			},
+		},
+		ClusterStats: []*ValidationReportClusterStats{
+			{ClusterID: "projects/test-proj/locations/europe-central2/clusters/cluster-two", ViolatedPoliciesCount: 1, ViolatedCriticalCount: 1},
+			{ClusterID: "projects/test-proj/locations/europe-central2/clusters/cluster-one", ValidPoliciesCount: 2, ViolatedPoliciesCount: 1, ViolatedLowCount: 1},
+		},
+	}
+}
+
+func getConsoleCollectorOutput(t *testing.T, options ConsoleOptions) string {
+	var buff bytes.Buffer
+	out := &Output{w: &buff, tabWriter: tabwriter.NewWriter(&buff, 0, 0, 0, ' ', 0)}
+	reportMapperMock := &validationReportMapperMock{
+		addResultsFn: func(results []*policy.PolicyEvaluationResult) {},
+		getReportFn:  getConsoleTestReport,
+	}
+	collector := &consoleResultCollector{out: out, options: options, reportMapper: reportMapperMock}
+	if err := collector.Close(); err != nil {
+		t.Fatalf("err on Close = %v; want nil", err)
+	}
+	return buff.String()
+}
+
+func TestConsoleResultCollector_violationsOnly(t *testing.T) {
+	result := getConsoleCollectorOutput(t, ConsoleOptions{ViolationsOnly: true, MinSeverity: "high"})
+	if !strings.Contains(result, "critical-violation") {
+		t.Errorf("output does not contain critical policy violation")
+	}
+	for _, text := range []string{"low-title", "valid-title", "[ VALID ]"} {
+		if strings.Contains(result, text) {
+			t.Errorf("output contains filtered out %q", text)
+		}
+	}
+}
+
+func TestConsoleResultCollector_groupByCluster(t *testing.T) {
+	result := getConsoleCollectorOutput(t, ConsoleOptions{GroupByCluster: true})
+	clusterOne := strings.Index(result, "clusters/cluster-one\n")
+	clusterTwo := strings.Index(result, "clusters/cluster-two\n")
+	lowViolation := strings.Index(result, "low-violation")
+	criticalViolation := strings.Index(result, "critical-violation")
+	if clusterOne < 0 || clusterTwo < 0 {
+		t.Fatalf("output does not contain cluster headers: %s", result)
+	}
+	if !(clusterOne < lowViolation && lowViolation < clusterTwo && clusterTwo < criticalViolation) {
+		t.Errorf("violations are not grouped by cluster: %s", result)
+	}
+}
+
+func TestConsoleResultCollector_summary(t *testing.T) {
+	result := getConsoleCollectorOutput(t, ConsoleOptions{Summary: true})
+	if strings.Contains(result, "critical-violation") {
+		t.Errorf("summary output contains policy violations")
+	}
+	if !strings.Contains(result, "CLUSTER") || !strings.Contains(result, "CRITICAL") {
+		t.Errorf("summary output does not contain table header: %s", result)
+	}
+	clusterOne := strings.Index(result, "projects/test-proj/locations/europe-central2/clusters/cluster-one ")
+	clusterTwo := strings.Index(result, "projects/test-proj/locations/europe-central2/clusters/cluster-two ")
+	if clusterOne < 0 || clusterTwo < 0 || clusterOne > clusterTwo {
+		t.Errorf("summary table rows are missing or not sorted: %s", result)
+	}
+}
+
+func TestFilterConsolePolicies(t *testing.T) {
+	policies := getConsoleTestReport().Policies
+	result := filterConsolePolicies(policies, ConsoleOptions{ViolationsOnly: true})
+	if len(result) != 2 {
+		t.Fatalf("len(result) = %v; want %v", len(result), 2)
+	}
+	if len(result[0].ClusterEvaluations) != 1 {
+		t.Errorf("len(clusterEvaluations) = %v; want %v", len(result[0].ClusterEvaluations), 1)
+	}
+	if len(policies[0].ClusterEvaluations) != 2 {
+		t.Errorf("source policy evaluations were modified")
+	}
+	result = filterConsolePolicies(policies, ConsoleOptions{MinSeverity: "Critical"})
+	if len(result) != 1 || result[0].PolicyName != "critical-policy" {
+		t.Errorf("result = %v; want only critical-policy", result)
+	}
+}
+
+func TestFormatConsoleLink(t *testing.T) {
+	noColor := color.NoColor
+	defer func() { color.NoColor = noColor }()
+
+	color.NoColor = true
+	if result := formatConsoleLink("https://example.com", "documentation"); result != "(https://example.com)" {
+		t.Errorf("result = %q; want %q", result, "(https://example.com)")
+	}
+	color.NoColor = false
+	if result := formatConsoleLink("https://example.com", "documentation"); !strings.HasPrefix(result, "(\x1b]8;;https://example.com") {
+		t.Errorf("result = %q; want hyperlink escape sequence", result)
+	}
+}
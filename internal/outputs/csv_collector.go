@@ -0,0 +1,123 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package outputs
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"time"
+
+	"github.com/google/gke-policy-automation/internal/log"
+	"github.com/google/gke-policy-automation/internal/policy"
+)
+
+const csvValuesSeparator = "; "
+
+var csvHeader = []string{
+	"validationDate",
+	"cluster",
+	"policy",
+	"title",
+	"group",
+	"severity",
+	"status",
+	"violations",
+	"errors",
+	"recommendation",
+	"externalURI",
+}
+
+type CSVResultCollector struct {
+	fileWriter   FileWriter
+	filename     string
+	reportMapper ValidationReportMapper
+}
+
+// NewCSVResultToFileCollector returns collector that writes a CSV file with a row per
+// each cluster and policy evaluation.
+func NewCSVResultToFileCollector(filename string) ValidationResultCollector {
+	return NewCSVResultToCustomWriterCollector(filename, OSFileWriter{})
+}
+
+func NewCSVResultToCustomWriterCollector(filename string, writer FileWriter) ValidationResultCollector {
+	return &CSVResultCollector{
+		filename:     filename,
+		fileWriter:   writer,
+		reportMapper: NewValidationReportMapper(),
+	}
+}
+
+func (p *CSVResultCollector) RegisterResult(results []*policy.PolicyEvaluationResult) error {
+	p.reportMapper.AddResults(results)
+	return nil
+}
+
+func (p *CSVResultCollector) Close() error {
+	data, err := mapReportToCSV(p.reportMapper.GetReport())
+	if err != nil {
+		return err
+	}
+	if err = p.fileWriter.WriteFile(p.filename, data, 0644); err != nil {
+		return err
+	}
+	log.Infof("Validation results written to the [%s] file", p.filename)
+	return nil
+}
+
+func (p *CSVResultCollector) Name() string {
+	return p.filename + " file"
+}
+
+func mapReportToCSV(report *ValidationReport) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(csvHeader); err != nil {
+		return nil, err
+	}
+	validationTime := report.ValidationTime.UTC().Format(time.RFC3339)
+	for _, reportPolicy := range report.Policies {
+		for _, evaluation := range reportPolicy.ClusterEvaluations {
+			record := []string{
+				validationTime,
+				evaluation.ClusterID,
+				reportPolicy.PolicyName,
+				reportPolicy.PolicyTitle,
+				reportPolicy.PolicyGroup,
+				reportPolicy.Severity,
+				getEvaluationStatus(evaluation),
+				strings.Join(evaluation.Violations, csvValuesSeparator),
+				strings.Join(evaluation.ProcessingErrors, csvValuesSeparator),
+				reportPolicy.Recommendation,
+				reportPolicy.ExternalURI,
+			}
+			if err := w.Write(record); err != nil {
+				return nil, err
+			}
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+func getEvaluationStatus(evaluation *ValidationReportClusterEvaluation) string {
+	if evaluation.Errored {
+		return "errored"
+	}
+	if evaluation.Valid {
+		return "valid"
+	}
+	return "violated"
+}
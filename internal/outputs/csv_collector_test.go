@@ -0,0 +1,64 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package outputs
+
+import (
+	"bytes"
+	"encoding/csv"
+	"os"
+	"testing"
+)
+
+type bufferFileWriter struct {
+	filename string
+	data     bytes.Buffer
+}
+
+func (w *bufferFileWriter) WriteFile(filename string, data []byte, perm os.FileMode) error {
+	w.filename = filename
+	w.data.Write(data)
+	return nil
+}
+
+func TestCSVResultCollector(t *testing.T) {
+	writer := &bufferFileWriter{}
+	collector := NewCSVResultToCustomWriterCollector("results.csv", writer)
+	collector.RegisterResult(getTestResults())
+	if err := collector.Close(); err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	if writer.filename != "results.csv" {
+		t.Errorf("filename = %v; want %v", writer.filename, "results.csv")
+	}
+	records, err := csv.NewReader(&writer.data).ReadAll()
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	if len(records) != 6 {
+		t.Fatalf("number of records = %v; want %v", len(records), 6)
+	}
+	for i, column := range csvHeader {
+		if records[0][i] != column {
+			t.Errorf("header column [%d] = %v; want %v", i, records[0][i], column)
+		}
+	}
+	violated := records[1]
+	if violated[1] != "projects/project/locations/europe-west1/clusters/cluster-one" || violated[2] != "policy-one" {
+		t.Errorf("record cluster = %v, policy = %v; want cluster-one, policy-one", violated[1], violated[2])
+	}
+	if violated[6] != "violated" || violated[7] != "first; second" {
+		t.Errorf("record status = %v, violations = %v; want %v, %v", violated[6], violated[7], "violated", "first; second")
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package outputs
+
+import (
+	"github.com/google/gke-policy-automation/internal/history"
+	"github.com/google/gke-policy-automation/internal/log"
+	"github.com/google/gke-policy-automation/internal/policy"
+	"github.com/google/uuid"
+)
+
+// HistoryStoreOpener opens history store, it is called only when the results are stored.
+type HistoryStoreOpener func() (history.Store, error)
+
+type historyResultCollector struct {
+	openStore    HistoryStoreOpener
+	runID        string
+	reportMapper ValidationReportMapper
+}
+
+// NewHistoryResultCollector returns collector that appends validation results to the
+// history store, along with a unique run ID.
+func NewHistoryResultCollector(openStore HistoryStoreOpener) ValidationResultCollector {
+	return &historyResultCollector{
+		openStore:    openStore,
+		runID:        uuid.NewString(),
+		reportMapper: NewValidationReportMapper(),
+	}
+}
+
+func (p *historyResultCollector) RegisterResult(results []*policy.PolicyEvaluationResult) error {
+	p.reportMapper.AddResults(results)
+	return nil
+}
+
+func (p *historyResultCollector) Close() error {
+	records := mapReportToHistoryRecords(p.reportMapper.GetReport(), p.runID)
+	if len(records) == 0 {
+		return nil
+	}
+	store, err := p.openStore()
+	if err != nil {
+		return err
+	}
+	if err := store.Append(records); err != nil {
+		store.Close()
+		return err
+	}
+	log.Infof("Validation results stored in history store with run ID [%s]", p.runID)
+	return store.Close()
+}
+
+func (p *historyResultCollector) Name() string {
+	return "history store"
+}
+
+func mapReportToHistoryRecords(report *ValidationReport, runID string) []*history.Record {
+	records := make([]*history.Record, 0)
+	for _, reportPolicy := range report.Policies {
+		for _, evaluation := range reportPolicy.ClusterEvaluations {
+			status := history.StatusViolated
+			if evaluation.Errored {
+				status = history.StatusErrored
+			} else if evaluation.Valid {
+				status = history.StatusValid
+			}
+			records = append(records, &history.Record{
+				RunID:       runID,
+				Time:        report.ValidationTime,
+				ClusterID:   evaluation.ClusterID,
+				PolicyName:  reportPolicy.PolicyName,
+				PolicyGroup: reportPolicy.PolicyGroup,
+				Severity:    reportPolicy.Severity,
+				Status:      status,
+			})
+		}
+	}
+	return records
+}
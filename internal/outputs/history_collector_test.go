@@ -0,0 +1,75 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package outputs
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/gke-policy-automation/internal/history"
+	"github.com/google/gke-policy-automation/internal/policy"
+)
+
+func TestHistoryResultCollector(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "history.db")
+	openStore := func() (history.Store, error) {
+		return history.NewSQLiteStore(file)
+	}
+	results := []*policy.PolicyEvaluationResult{
+		{
+			ClusterID: "cluster-one",
+			Policies: []*policy.Policy{
+				{Name: "policy-one", Group: "Security", Severity: "High", Valid: true},
+				{Name: "policy-two", Group: "Security", Valid: false, Violations: []string{"violation"}},
+				{Name: "policy-three", Group: "Management", ProcessingErrors: []error{errors.New("error")}},
+			},
+		},
+	}
+	collector := NewHistoryResultCollector(openStore)
+	if err := collector.RegisterResult(results); err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	if err := collector.Close(); err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+
+	store, err := openStore()
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	defer store.Close()
+	records, err := store.Records(history.Filter{})
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	expected := map[string]string{
+		"policy-one":   history.StatusValid,
+		"policy-two":   history.StatusViolated,
+		"policy-three": history.StatusErrored,
+	}
+	if len(records) != len(expected) {
+		t.Fatalf("number of records = %v; want %v", len(records), len(expected))
+	}
+	runID := records[0].RunID
+	for _, record := range records {
+		if record.Status != expected[record.PolicyName] {
+			t.Errorf("policy %s status = %v; want %v", record.PolicyName, record.Status, expected[record.PolicyName])
+		}
+		if record.RunID != runID || record.ClusterID != "cluster-one" {
+			t.Errorf("record run ID = %v, cluster = %v; want %v, %v", record.RunID, record.ClusterID, runID, "cluster-one")
+		}
+	}
+}
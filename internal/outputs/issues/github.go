@@ -0,0 +1,164 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package issues
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	DefaultGitHubURL = "https://api.github.com"
+
+	gitHubPageSize   = 100
+	gitHubAPIVersion = "2022-11-28"
+)
+
+type gitHubIssue struct {
+	Number      int      `json:"number,omitempty"`
+	Title       string   `json:"title,omitempty"`
+	Body        string   `json:"body,omitempty"`
+	State       string   `json:"state,omitempty"`
+	StateReason string   `json:"state_reason,omitempty"`
+	Labels      []string `json:"labels,omitempty"`
+}
+
+type gitHubIssueResponse struct {
+	Number      int         `json:"number"`
+	Title       string      `json:"title"`
+	Body        string      `json:"body"`
+	State       string      `json:"state"`
+	PullRequest interface{} `json:"pull_request"`
+}
+
+type gitHubComment struct {
+	Body string `json:"body"`
+}
+
+type gitHubClient struct {
+	rest       *restClient
+	baseURL    string
+	repository string
+	labels     []string
+}
+
+// NewGitHubClient returns GitHub Issues client for a given repository in owner/name format.
+// The issues are listed by the first of given labels.
+func NewGitHubClient(ctx context.Context, baseURL, repository, token string, labels []string) (IssueTrackerClient, error) {
+	if strings.Count(repository, "/") != 1 {
+		return nil, fmt.Errorf("invalid GitHub repository %q - should be in owner/name format", repository)
+	}
+	if baseURL == "" {
+		baseURL = DefaultGitHubURL
+	}
+	if len(labels) == 0 {
+		labels = []string{DefaultLabel}
+	}
+	return &gitHubClient{
+		rest: newRestClient(ctx, func(req *http.Request) {
+			req.Header.Set("Accept", "application/vnd.github+json")
+			req.Header.Set("X-GitHub-Api-Version", gitHubAPIVersion)
+			if token != "" {
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+		}),
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		repository: repository,
+		labels:     labels,
+	}, nil
+}
+
+func (c *gitHubClient) ListIssues() ([]*Issue, error) {
+	issues := make([]*Issue, 0)
+	for page := 1; ; page++ {
+		query := url.Values{}
+		query.Set("state", "all")
+		query.Set("labels", c.labels[0])
+		query.Set("per_page", strconv.Itoa(gitHubPageSize))
+		query.Set("page", strconv.Itoa(page))
+		var results []*gitHubIssueResponse
+		if err := c.rest.do(http.MethodGet, c.issuesURL()+"?"+query.Encode(), nil, &results); err != nil {
+			return nil, err
+		}
+		for _, result := range results {
+			if result.PullRequest != nil {
+				continue
+			}
+			issues = append(issues, &Issue{
+				ID:          strconv.Itoa(result.Number),
+				Title:       result.Title,
+				Body:        result.Body,
+				Open:        result.State == "open",
+				Fingerprint: ParseFingerprint(result.Body),
+			})
+		}
+		if len(results) < gitHubPageSize {
+			return issues, nil
+		}
+	}
+}
+
+func (c *gitHubClient) CreateIssue(issue *Issue) error {
+	var result gitHubIssueResponse
+	request := &gitHubIssue{Title: issue.Title, Body: issue.Body, Labels: c.labels}
+	if err := c.rest.do(http.MethodPost, c.issuesURL(), request, &result); err != nil {
+		return err
+	}
+	issue.ID = strconv.Itoa(result.Number)
+	issue.Open = true
+	return nil
+}
+
+func (c *gitHubClient) UpdateIssue(issue *Issue) error {
+	return c.rest.do(http.MethodPatch, c.issueURL(issue.ID), &gitHubIssue{Title: issue.Title, Body: issue.Body}, nil)
+}
+
+func (c *gitHubClient) ReopenIssue(issue *Issue) error {
+	if err := c.rest.do(http.MethodPatch, c.issueURL(issue.ID), &gitHubIssue{State: "open"}, nil); err != nil {
+		return err
+	}
+	issue.Open = true
+	return nil
+}
+
+func (c *gitHubClient) CloseIssue(issue *Issue, comment string) error {
+	if comment != "" {
+		if err := c.rest.do(http.MethodPost, c.issueURL(issue.ID)+"/comments", &gitHubComment{Body: comment}, nil); err != nil {
+			return err
+		}
+	}
+	request := &gitHubIssue{State: "closed", StateReason: "completed"}
+	if err := c.rest.do(http.MethodPatch, c.issueURL(issue.ID), request, nil); err != nil {
+		return err
+	}
+	issue.Open = false
+	return nil
+}
+
+func (c *gitHubClient) Close() error {
+	return nil
+}
+
+func (c *gitHubClient) issuesURL() string {
+	return fmt.Sprintf("%s/repos/%s/issues", c.baseURL, c.repository)
+}
+
+func (c *gitHubClient) issueURL(id string) string {
+	return fmt.Sprintf("%s/%s", c.issuesURL(), id)
+}
@@ -0,0 +1,144 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package issues implements issue tracker clients for GitHub Issues and Jira
+package issues
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/google/gke-policy-automation/internal/version"
+)
+
+const (
+	DefaultLabel = "gke-policy-automation"
+
+	fingerprintPrefix = "gke-policy-fingerprint:"
+	clientTimeout     = 30 * time.Second
+	maxErrorBodySize  = 1024
+)
+
+var fingerprintRegexp = regexp.MustCompile(fingerprintPrefix + `\s*([0-9a-f]+)`)
+
+// Issue is a tracker issue for a policy violated on a cluster. The issue is identified
+// by a fingerprint stored in its body.
+type Issue struct {
+	ID          string
+	Title       string
+	Body        string
+	Open        bool
+	Fingerprint string
+}
+
+// IssueTrackerClient manages the issues created by GKE Policy Automation.
+type IssueTrackerClient interface {
+	ListIssues() ([]*Issue, error)
+	CreateIssue(issue *Issue) error
+	UpdateIssue(issue *Issue) error
+	ReopenIssue(issue *Issue) error
+	CloseIssue(issue *Issue, comment string) error
+	Close() error
+}
+
+// Fingerprint returns stable identifier of a policy violation on a cluster.
+func Fingerprint(clusterID, policyName string) string {
+	sum := sha256.Sum256([]byte(clusterID + "/" + policyName))
+	return hex.EncodeToString(sum[:])[:32]
+}
+
+// FingerprintLine returns the line with a given fingerprint for the issue body.
+func FingerprintLine(fingerprint string) string {
+	return fingerprintPrefix + " " + fingerprint
+}
+
+// ParseFingerprint returns the fingerprint from a given issue body, or an empty string
+// if the body has no fingerprint.
+func ParseFingerprint(body string) string {
+	matches := fingerprintRegexp.FindStringSubmatch(body)
+	if len(matches) < 2 {
+		return ""
+	}
+	return matches[1]
+}
+
+// StatusError is an error response of the issue tracker API.
+type StatusError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s %s responded with status %d: %s", e.Method, e.URL, e.StatusCode, e.Body)
+}
+
+type restClient struct {
+	ctx        context.Context
+	client     *http.Client
+	setHeaders func(req *http.Request)
+}
+
+func newRestClient(ctx context.Context, setHeaders func(req *http.Request)) *restClient {
+	return &restClient{
+		ctx:        ctx,
+		client:     &http.Client{Timeout: clientTimeout},
+		setHeaders: setHeaders,
+	}
+}
+
+// do sends a request with a given JSON body and decodes JSON response to a given result,
+// if not nil.
+func (c *restClient) do(method, url string, body interface{}, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(c.ctx, method, url, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", version.UserAgent)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	c.setHeaders(req)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+		return &StatusError{Method: method, URL: url, StatusCode: resp.StatusCode, Body: string(respBody)}
+	}
+	if result == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
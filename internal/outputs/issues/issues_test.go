@@ -0,0 +1,328 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package issues
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// gitHubStandIn is an in-memory stand-in of the GitHub Issues REST API.
+type gitHubStandIn struct {
+	mu       sync.Mutex
+	issues   []*gitHubIssueResponse
+	labels   map[int][]string
+	comments map[int][]string
+	token    string
+}
+
+func (s *gitHubStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != "" && r.Header.Get("Authorization") != "Bearer "+s.token {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	path := strings.TrimPrefix(r.URL.Path, "/repos/owner/repo/issues")
+	switch {
+	case path == "" && r.Method == http.MethodGet:
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+		results := make([]*gitHubIssueResponse, 0)
+		for _, issue := range s.issues {
+			for _, label := range s.labels[issue.Number] {
+				if label == r.URL.Query().Get("labels") {
+					results = append(results, issue)
+				}
+			}
+		}
+		start := min((page-1)*perPage, len(results))
+		end := min(start+perPage, len(results))
+		json.NewEncoder(w).Encode(results[start:end])
+	case path == "" && r.Method == http.MethodPost:
+		var request gitHubIssue
+		json.NewDecoder(r.Body).Decode(&request)
+		issue := &gitHubIssueResponse{Number: len(s.issues) + 1, Title: request.Title, Body: request.Body, State: "open"}
+		s.issues = append(s.issues, issue)
+		s.labels[issue.Number] = request.Labels
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(issue)
+	default:
+		parts := strings.Split(strings.TrimPrefix(path, "/"), "/")
+		number, err := strconv.Atoi(parts[0])
+		if err != nil || number < 1 || number > len(s.issues) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		issue := s.issues[number-1]
+		if len(parts) == 2 && parts[1] == "comments" && r.Method == http.MethodPost {
+			var comment gitHubComment
+			json.NewDecoder(r.Body).Decode(&comment)
+			s.comments[number] = append(s.comments[number], comment.Body)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(comment)
+			return
+		}
+		if r.Method != http.MethodPatch {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		var request gitHubIssue
+		json.NewDecoder(r.Body).Decode(&request)
+		if request.Title != "" {
+			issue.Title = request.Title
+		}
+		if request.Body != "" {
+			issue.Body = request.Body
+		}
+		if request.State != "" {
+			issue.State = request.State
+		}
+		json.NewEncoder(w).Encode(issue)
+	}
+}
+
+func TestGitHubClient(t *testing.T) {
+	standIn := &gitHubStandIn{labels: make(map[int][]string), comments: make(map[int][]string), token: "token"}
+	server := httptest.NewServer(standIn)
+	defer server.Close()
+	client, err := NewGitHubClient(context.Background(), server.URL, "owner/repo", "token", nil)
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	issue := &Issue{Title: "title", Body: "body\n" + FingerprintLine("abc123")}
+	if err := client.CreateIssue(issue); err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	if issue.ID != "1" || !issue.Open {
+		t.Errorf("issue ID = %v, open = %v; want %v, %v", issue.ID, issue.Open, "1", true)
+	}
+	if err := client.CloseIssue(issue, "fixed"); err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	if standIn.issues[0].State != "closed" || len(standIn.comments[1]) != 1 {
+		t.Errorf("issue state = %v, comments = %v; want %v, %v", standIn.issues[0].State, len(standIn.comments[1]), "closed", 1)
+	}
+	if err := client.ReopenIssue(issue); err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	issue.Title = "new title"
+	if err := client.UpdateIssue(issue); err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	issues, err := client.ListIssues()
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	if len(issues) != 1 {
+		t.Fatalf("number of issues = %v; want %v", len(issues), 1)
+	}
+	if issues[0].Title != "new title" || !issues[0].Open || issues[0].Fingerprint != "abc123" {
+		t.Errorf("issue = %+v; want open issue with new title and fingerprint", issues[0])
+	}
+}
+
+func TestGitHubClient_listPages(t *testing.T) {
+	standIn := &gitHubStandIn{labels: make(map[int][]string), comments: make(map[int][]string)}
+	for i := 1; i <= gitHubPageSize+1; i++ {
+		standIn.issues = append(standIn.issues, &gitHubIssueResponse{Number: i, State: "closed", Body: FingerprintLine(fmt.Sprintf("%x", i))})
+		standIn.labels[i] = []string{DefaultLabel}
+	}
+	server := httptest.NewServer(standIn)
+	defer server.Close()
+	client, _ := NewGitHubClient(context.Background(), server.URL, "owner/repo", "", nil)
+	issues, err := client.ListIssues()
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	if len(issues) != gitHubPageSize+1 {
+		t.Errorf("number of issues = %v; want %v", len(issues), gitHubPageSize+1)
+	}
+}
+
+func TestNewGitHubClient_badRepository(t *testing.T) {
+	if _, err := NewGitHubClient(context.Background(), "", "repo", "", nil); err == nil {
+		t.Errorf("expected error on invalid repository")
+	}
+}
+
+// jiraStandIn is an in-memory stand-in of the Jira REST API.
+type jiraStandIn struct {
+	mu          sync.Mutex
+	issues      []*jiraIssue
+	comments    map[string][]string
+	legacyOnly  bool
+	searchCalls int
+}
+
+var jiraStandInTransitions = []*jiraTransition{
+	newJiraTransition("11", "To Do", jiraStatusCategoryNew),
+	newJiraTransition("21", "In Progress", "indeterminate"),
+	newJiraTransition("31", "Done", jiraStatusCategoryDone),
+}
+
+func newJiraTransition(id, name, category string) *jiraTransition {
+	transition := &jiraTransition{ID: id, Name: name}
+	transition.To.StatusCategory.Key = category
+	return transition
+}
+
+func (s *jiraStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if user, token, ok := r.BasicAuth(); !ok || user != "user" || token != "token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	switch {
+	case r.URL.Path == "/rest/api/2/search/jql" && !s.legacyOnly:
+		s.searchCalls++
+		json.NewEncoder(w).Encode(&jiraSearchResponse{Issues: s.issues, IsLast: true})
+	case r.URL.Path == "/rest/api/2/search" && s.legacyOnly:
+		s.searchCalls++
+		startAt, _ := strconv.Atoi(r.URL.Query().Get("startAt"))
+		end := min(startAt+1, len(s.issues))
+		json.NewEncoder(w).Encode(&jiraSearchResponse{StartAt: startAt, Total: len(s.issues), Issues: s.issues[startAt:end]})
+	case r.URL.Path == "/rest/api/2/issue" && r.Method == http.MethodPost:
+		var request jiraIssue
+		json.NewDecoder(r.Body).Decode(&request)
+		request.Key = fmt.Sprintf("%s-%d", request.Fields.Project.Key, len(s.issues)+1)
+		request.Fields.Status = &jiraStatus{StatusCategory: jiraStatusCategory{Key: jiraStatusCategoryNew}}
+		s.issues = append(s.issues, &request)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(&jiraIssue{Key: request.Key})
+	case strings.HasPrefix(r.URL.Path, "/rest/api/2/issue/"):
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/rest/api/2/issue/"), "/")
+		var issue *jiraIssue
+		for _, i := range s.issues {
+			if i.Key == parts[0] {
+				issue = i
+			}
+		}
+		if issue == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch {
+		case len(parts) == 1 && r.Method == http.MethodPut:
+			var request jiraIssue
+			json.NewDecoder(r.Body).Decode(&request)
+			issue.Fields.Summary = request.Fields.Summary
+			issue.Fields.Description = request.Fields.Description
+			w.WriteHeader(http.StatusNoContent)
+		case parts[1] == "comment":
+			var comment jiraComment
+			json.NewDecoder(r.Body).Decode(&comment)
+			s.comments[issue.Key] = append(s.comments[issue.Key], comment.Body)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(comment)
+		case parts[1] == "transitions" && r.Method == http.MethodGet:
+			json.NewEncoder(w).Encode(&jiraTransitionsResponse{Transitions: jiraStandInTransitions})
+		case parts[1] == "transitions" && r.Method == http.MethodPost:
+			var request jiraTransitionRequest
+			json.NewDecoder(r.Body).Decode(&request)
+			for _, transition := range jiraStandInTransitions {
+				if transition.ID == request.Transition.ID {
+					issue.Fields.Status.StatusCategory.Key = transition.To.StatusCategory.Key
+				}
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestJiraClient(t *testing.T) {
+	for _, legacyOnly := range []bool{false, true} {
+		standIn := &jiraStandIn{comments: make(map[string][]string), legacyOnly: legacyOnly}
+		server := httptest.NewServer(standIn)
+		client, err := NewJiraClient(context.Background(), JiraOptions{
+			BaseURL: server.URL,
+			Project: "GKE",
+			User:    "user",
+			Token:   "token",
+		})
+		if err != nil {
+			t.Fatalf("err = %v; want nil", err)
+		}
+		first := &Issue{Title: "first", Body: "body\n" + FingerprintLine("abc123")}
+		second := &Issue{Title: "second", Body: "body\n" + FingerprintLine("def456")}
+		for _, issue := range []*Issue{first, second} {
+			if err := client.CreateIssue(issue); err != nil {
+				t.Fatalf("err = %v; want nil", err)
+			}
+		}
+		if first.ID != "GKE-1" {
+			t.Errorf("issue ID = %v; want %v", first.ID, "GKE-1")
+		}
+		if err := client.CloseIssue(first, "fixed"); err != nil {
+			t.Fatalf("err = %v; want nil", err)
+		}
+		if standIn.issues[0].Fields.Status.StatusCategory.Key != jiraStatusCategoryDone {
+			t.Errorf("issue status category = %v; want %v", standIn.issues[0].Fields.Status.StatusCategory.Key, jiraStatusCategoryDone)
+		}
+		issues, err := client.ListIssues()
+		if err != nil {
+			t.Fatalf("err = %v; want nil", err)
+		}
+		if len(issues) != 2 {
+			t.Fatalf("number of issues = %v; want %v", len(issues), 2)
+		}
+		if issues[0].Open || issues[0].Fingerprint != "abc123" || !issues[1].Open {
+			t.Errorf("issues = %+v, %+v; want closed first and open second issue", issues[0], issues[1])
+		}
+		if err := client.ReopenIssue(first); err != nil {
+			t.Fatalf("err = %v; want nil", err)
+		}
+		if standIn.issues[0].Fields.Status.StatusCategory.Key != jiraStatusCategoryNew {
+			t.Errorf("issue status category = %v; want %v", standIn.issues[0].Fields.Status.StatusCategory.Key, jiraStatusCategoryNew)
+		}
+		server.Close()
+	}
+}
+
+func TestFindJiraTransition(t *testing.T) {
+	if transition := findJiraTransition(jiraStandInTransitions, "in progress", false); transition == nil || transition.ID != "21" {
+		t.Errorf("transition = %v; want %v", transition, "21")
+	}
+	if transition := findJiraTransition(jiraStandInTransitions, "bogus", false); transition != nil {
+		t.Errorf("transition = %v; want nil", transition)
+	}
+	if transition := findJiraTransition(jiraStandInTransitions, "", true); transition == nil || transition.ID != "31" {
+		t.Errorf("transition = %v; want %v", transition, "31")
+	}
+}
+
+func TestParseFingerprint(t *testing.T) {
+	fingerprint := Fingerprint("projects/p/locations/l/clusters/c", "gke.policy.policy")
+	if len(fingerprint) != 32 {
+		t.Errorf("fingerprint length = %v; want %v", len(fingerprint), 32)
+	}
+	body := "some description\n\n" + FingerprintLine(fingerprint) + "\n"
+	if result := ParseFingerprint(body); result != fingerprint {
+		t.Errorf("fingerprint = %v; want %v", result, fingerprint)
+	}
+	if result := ParseFingerprint("no fingerprint"); result != "" {
+		t.Errorf("fingerprint = %v; want empty", result)
+	}
+}
@@ -0,0 +1,316 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package issues
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	DefaultJiraIssueType = "Task"
+
+	jiraPageSize           = 100
+	jiraSearchFields       = "summary,description,status"
+	jiraStatusCategoryDone = "done"
+	jiraStatusCategoryNew  = "new"
+)
+
+// JiraOptions configures the Jira project and workflow used for the issues.
+type JiraOptions struct {
+	BaseURL          string
+	Project          string
+	IssueType        string
+	User             string
+	Token            string
+	Labels           []string
+	CloseTransition  string
+	ReopenTransition string
+}
+
+type jiraStatusCategory struct {
+	Key string `json:"key"`
+}
+
+type jiraStatus struct {
+	StatusCategory jiraStatusCategory `json:"statusCategory"`
+}
+
+type jiraFields struct {
+	Project     *jiraKey    `json:"project,omitempty"`
+	IssueType   *jiraName   `json:"issuetype,omitempty"`
+	Summary     string      `json:"summary,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Labels      []string    `json:"labels,omitempty"`
+	Status      *jiraStatus `json:"status,omitempty"`
+}
+
+type jiraKey struct {
+	Key string `json:"key"`
+}
+
+type jiraName struct {
+	Name string `json:"name"`
+}
+
+type jiraIssue struct {
+	Key    string     `json:"key,omitempty"`
+	Fields jiraFields `json:"fields"`
+}
+
+type jiraSearchResponse struct {
+	StartAt       int          `json:"startAt"`
+	Total         int          `json:"total"`
+	Issues        []*jiraIssue `json:"issues"`
+	NextPageToken string       `json:"nextPageToken"`
+	IsLast        bool         `json:"isLast"`
+}
+
+type jiraTransition struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	To   struct {
+		StatusCategory jiraStatusCategory `json:"statusCategory"`
+	} `json:"to"`
+}
+
+type jiraTransitionsResponse struct {
+	Transitions []*jiraTransition `json:"transitions"`
+}
+
+type jiraTransitionRequest struct {
+	Transition struct {
+		ID string `json:"id"`
+	} `json:"transition"`
+}
+
+type jiraComment struct {
+	Body string `json:"body"`
+}
+
+type jiraClient struct {
+	rest             *restClient
+	baseURL          string
+	project          string
+	issueType        string
+	labels           []string
+	closeTransition  string
+	reopenTransition string
+}
+
+// NewJiraClient returns Jira client for a given project. The user and API token are used
+// for basic authentication, while the token alone is used as a personal access token.
+func NewJiraClient(ctx context.Context, opts JiraOptions) (IssueTrackerClient, error) {
+	if opts.BaseURL == "" {
+		return nil, errors.New("jira URL is not set")
+	}
+	if opts.Project == "" {
+		return nil, errors.New("jira project is not set")
+	}
+	issueType := opts.IssueType
+	if issueType == "" {
+		issueType = DefaultJiraIssueType
+	}
+	labels := opts.Labels
+	if len(labels) == 0 {
+		labels = []string{DefaultLabel}
+	}
+	return &jiraClient{
+		rest: newRestClient(ctx, func(req *http.Request) {
+			if opts.User != "" {
+				req.SetBasicAuth(opts.User, opts.Token)
+			} else if opts.Token != "" {
+				req.Header.Set("Authorization", "Bearer "+opts.Token)
+			}
+		}),
+		baseURL:          strings.TrimSuffix(opts.BaseURL, "/"),
+		project:          opts.Project,
+		issueType:        issueType,
+		labels:           labels,
+		closeTransition:  opts.CloseTransition,
+		reopenTransition: opts.ReopenTransition,
+	}, nil
+}
+
+// ListIssues searches issues with the JQL search API. When the enhanced search endpoint
+// is not available, i.e. on Jira Data Center, the paginated search endpoint is used.
+func (c *jiraClient) ListIssues() ([]*Issue, error) {
+	jql := fmt.Sprintf("project = %q AND labels = %q", c.project, c.labels[0])
+	results, err := c.searchJQL(jql)
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound {
+		results, err = c.search(jql)
+	}
+	if err != nil {
+		return nil, err
+	}
+	issues := make([]*Issue, 0, len(results))
+	for _, result := range results {
+		open := true
+		if result.Fields.Status != nil {
+			open = result.Fields.Status.StatusCategory.Key != jiraStatusCategoryDone
+		}
+		issues = append(issues, &Issue{
+			ID:          result.Key,
+			Title:       result.Fields.Summary,
+			Body:        result.Fields.Description,
+			Open:        open,
+			Fingerprint: ParseFingerprint(result.Fields.Description),
+		})
+	}
+	return issues, nil
+}
+
+func (c *jiraClient) searchJQL(jql string) ([]*jiraIssue, error) {
+	issues := make([]*jiraIssue, 0)
+	pageToken := ""
+	for {
+		query := url.Values{}
+		query.Set("jql", jql)
+		query.Set("fields", jiraSearchFields)
+		query.Set("maxResults", strconv.Itoa(jiraPageSize))
+		if pageToken != "" {
+			query.Set("nextPageToken", pageToken)
+		}
+		var result jiraSearchResponse
+		if err := c.rest.do(http.MethodGet, c.baseURL+"/rest/api/2/search/jql?"+query.Encode(), nil, &result); err != nil {
+			return nil, err
+		}
+		issues = append(issues, result.Issues...)
+		if result.IsLast || result.NextPageToken == "" {
+			return issues, nil
+		}
+		pageToken = result.NextPageToken
+	}
+}
+
+func (c *jiraClient) search(jql string) ([]*jiraIssue, error) {
+	issues := make([]*jiraIssue, 0)
+	for {
+		query := url.Values{}
+		query.Set("jql", jql)
+		query.Set("fields", jiraSearchFields)
+		query.Set("maxResults", strconv.Itoa(jiraPageSize))
+		query.Set("startAt", strconv.Itoa(len(issues)))
+		var result jiraSearchResponse
+		if err := c.rest.do(http.MethodGet, c.baseURL+"/rest/api/2/search?"+query.Encode(), nil, &result); err != nil {
+			return nil, err
+		}
+		issues = append(issues, result.Issues...)
+		if len(result.Issues) == 0 || len(issues) >= result.Total {
+			return issues, nil
+		}
+	}
+}
+
+func (c *jiraClient) CreateIssue(issue *Issue) error {
+	request := &jiraIssue{Fields: jiraFields{
+		Project:     &jiraKey{Key: c.project},
+		IssueType:   &jiraName{Name: c.issueType},
+		Summary:     issue.Title,
+		Description: issue.Body,
+		Labels:      c.labels,
+	}}
+	var result jiraIssue
+	if err := c.rest.do(http.MethodPost, c.baseURL+"/rest/api/2/issue", request, &result); err != nil {
+		return err
+	}
+	issue.ID = result.Key
+	issue.Open = true
+	return nil
+}
+
+func (c *jiraClient) UpdateIssue(issue *Issue) error {
+	request := &jiraIssue{Fields: jiraFields{
+		Summary:     issue.Title,
+		Description: issue.Body,
+	}}
+	return c.rest.do(http.MethodPut, c.issueURL(issue.ID), request, nil)
+}
+
+func (c *jiraClient) ReopenIssue(issue *Issue) error {
+	if err := c.transition(issue.ID, c.reopenTransition, false); err != nil {
+		return err
+	}
+	issue.Open = true
+	return nil
+}
+
+func (c *jiraClient) CloseIssue(issue *Issue, comment string) error {
+	if comment != "" {
+		if err := c.rest.do(http.MethodPost, c.issueURL(issue.ID)+"/comment", &jiraComment{Body: comment}, nil); err != nil {
+			return err
+		}
+	}
+	if err := c.transition(issue.ID, c.closeTransition, true); err != nil {
+		return err
+	}
+	issue.Open = false
+	return nil
+}
+
+func (c *jiraClient) Close() error {
+	return nil
+}
+
+// transition moves the issue with a transition of a given name. When the name is not set,
+// the first transition to the done status category, or out of it, is used.
+func (c *jiraClient) transition(key string, name string, done bool) error {
+	var transitions jiraTransitionsResponse
+	if err := c.rest.do(http.MethodGet, c.issueURL(key)+"/transitions", nil, &transitions); err != nil {
+		return err
+	}
+	transition := findJiraTransition(transitions.Transitions, name, done)
+	if transition == nil {
+		return fmt.Errorf("jira issue %s has no matching transition", key)
+	}
+	var request jiraTransitionRequest
+	request.Transition.ID = transition.ID
+	return c.rest.do(http.MethodPost, c.issueURL(key)+"/transitions", &request, nil)
+}
+
+func (c *jiraClient) issueURL(key string) string {
+	return c.baseURL + "/rest/api/2/issue/" + url.PathEscape(key)
+}
+
+func findJiraTransition(transitions []*jiraTransition, name string, done bool) *jiraTransition {
+	if name != "" {
+		for _, transition := range transitions {
+			if strings.EqualFold(transition.Name, name) {
+				return transition
+			}
+		}
+		return nil
+	}
+	var candidate *jiraTransition
+	for _, transition := range transitions {
+		category := transition.To.StatusCategory.Key
+		if done && category == jiraStatusCategoryDone {
+			return transition
+		}
+		if !done && category == jiraStatusCategoryNew {
+			return transition
+		}
+		if !done && category != jiraStatusCategoryDone && candidate == nil {
+			candidate = transition
+		}
+	}
+	return candidate
+}
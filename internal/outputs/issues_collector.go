@@ -0,0 +1,162 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package outputs
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/gke-policy-automation/internal/log"
+	"github.com/google/gke-policy-automation/internal/outputs/issues"
+	"github.com/google/gke-policy-automation/internal/policy"
+)
+
+type issuesResultCollector struct {
+	client       issues.IssueTrackerClient
+	minSeverity  string
+	reportMapper ValidationReportMapper
+}
+
+// NewIssuesResultCollector returns collector that opens an issue per policy violated on
+// a cluster, reopens the issue when the violation comes back and closes it when the
+// policy becomes valid. Issues are matched by the fingerprint stored in their body.
+func NewIssuesResultCollector(client issues.IssueTrackerClient, minSeverity string) ValidationResultCollector {
+	return &issuesResultCollector{
+		client:       client,
+		minSeverity:  minSeverity,
+		reportMapper: NewValidationReportMapper(),
+	}
+}
+
+func (p *issuesResultCollector) RegisterResult(results []*policy.PolicyEvaluationResult) error {
+	p.reportMapper.AddResults(results)
+	return nil
+}
+
+func (p *issuesResultCollector) Close() (err error) {
+	defer func() {
+		if closeErr := p.client.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+	existing, err := p.client.ListIssues()
+	if err != nil {
+		return err
+	}
+	issuesByFingerprint := make(map[string]*issues.Issue)
+	for _, issue := range existing {
+		if issue.Fingerprint == "" {
+			continue
+		}
+		if current, ok := issuesByFingerprint[issue.Fingerprint]; ok && current.Open {
+			continue
+		}
+		issuesByFingerprint[issue.Fingerprint] = issue
+	}
+	report := p.reportMapper.GetReport()
+	minSeverityNumber := mapSeverityToNumber(p.minSeverity)
+	var errors []error
+	count := 0
+	for _, reportPolicy := range report.Policies {
+		if reportPolicy.SeverityNumber < minSeverityNumber {
+			continue
+		}
+		for _, evaluation := range reportPolicy.ClusterEvaluations {
+			if evaluation.Errored {
+				continue
+			}
+			fingerprint := issues.Fingerprint(evaluation.ClusterID, reportPolicy.PolicyName)
+			issue := issuesByFingerprint[fingerprint]
+			if err := p.upsertIssue(issue, fingerprint, reportPolicy, evaluation); err != nil {
+				log.Warnf("failed to upsert issue (cluster=%s policy=%s): %s", evaluation.ClusterID, reportPolicy.PolicyName, err)
+				errors = append(errors, err)
+			}
+			count++
+		}
+	}
+	if len(errors) > 0 {
+		return fmt.Errorf("failed to upsert all issues: %d out of %d failed: %w", len(errors), count, errors[0])
+	}
+	return nil
+}
+
+func (p *issuesResultCollector) Name() string {
+	return "issue tracker"
+}
+
+func (p *issuesResultCollector) upsertIssue(issue *issues.Issue, fingerprint string, reportPolicy *ValidationReportPolicy, evaluation *ValidationReportClusterEvaluation) error {
+	if evaluation.Valid {
+		if issue == nil || !issue.Open {
+			return nil
+		}
+		log.Debugf("closing issue %s of policy %s on cluster %s", issue.ID, reportPolicy.PolicyName, evaluation.ClusterID)
+		comment := fmt.Sprintf("Policy %s is valid on cluster %s.", reportPolicy.PolicyName, evaluation.ClusterID)
+		return p.client.CloseIssue(issue, comment)
+	}
+	title := getIssueTitle(reportPolicy, evaluation)
+	body := getIssueBody(fingerprint, reportPolicy, evaluation)
+	if issue == nil {
+		log.Debugf("creating issue for policy %s on cluster %s", reportPolicy.PolicyName, evaluation.ClusterID)
+		return p.client.CreateIssue(&issues.Issue{Title: title, Body: body, Fingerprint: fingerprint})
+	}
+	if !issue.Open {
+		log.Debugf("reopening issue %s of policy %s on cluster %s", issue.ID, reportPolicy.PolicyName, evaluation.ClusterID)
+		if err := p.client.ReopenIssue(issue); err != nil {
+			return err
+		}
+	}
+	if issue.Title == title && strings.TrimSpace(issue.Body) == strings.TrimSpace(body) {
+		return nil
+	}
+	issue.Title = title
+	issue.Body = body
+	return p.client.UpdateIssue(issue)
+}
+
+func getIssueTitle(reportPolicy *ValidationReportPolicy, evaluation *ValidationReportClusterEvaluation) string {
+	name := reportPolicy.PolicyTitle
+	if name == "" {
+		name = reportPolicy.PolicyName
+	}
+	return fmt.Sprintf("GKE policy violation: %s on %s", name, getClusterShortName(evaluation.ClusterID))
+}
+
+func getIssueBody(fingerprint string, reportPolicy *ValidationReportPolicy, evaluation *ValidationReportClusterEvaluation) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Policy %s is violated on cluster %s.\n\n", reportPolicy.PolicyName, evaluation.ClusterID)
+	if reportPolicy.PolicyGroup != "" {
+		fmt.Fprintf(&sb, "Group: %s\n", reportPolicy.PolicyGroup)
+	}
+	if reportPolicy.Severity != "" {
+		fmt.Fprintf(&sb, "Severity: %s\n", reportPolicy.Severity)
+	}
+	if reportPolicy.PolicyDescription != "" {
+		fmt.Fprintf(&sb, "\n%s\n", reportPolicy.PolicyDescription)
+	}
+	if len(evaluation.Violations) > 0 {
+		sb.WriteString("\nViolations:\n")
+		for _, violation := range evaluation.Violations {
+			fmt.Fprintf(&sb, "- %s\n", violation)
+		}
+	}
+	if reportPolicy.Recommendation != "" {
+		fmt.Fprintf(&sb, "\nRecommendation: %s\n", reportPolicy.Recommendation)
+	}
+	if reportPolicy.ExternalURI != "" {
+		fmt.Fprintf(&sb, "\nMore information: %s\n", reportPolicy.ExternalURI)
+	}
+	fmt.Fprintf(&sb, "\n%s\n", issues.FingerprintLine(fingerprint))
+	return sb.String()
+}
@@ -0,0 +1,158 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package outputs
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/google/gke-policy-automation/internal/outputs/issues"
+	"github.com/google/gke-policy-automation/internal/policy"
+)
+
+type issueTrackerFake struct {
+	issues       []*issues.Issue
+	created      int
+	updated      int
+	reopened     int
+	closed       int
+	createErr    error
+	clientClosed bool
+}
+
+func (f *issueTrackerFake) ListIssues() ([]*issues.Issue, error) {
+	result := make([]*issues.Issue, 0, len(f.issues))
+	for _, issue := range f.issues {
+		copy := *issue
+		result = append(result, &copy)
+	}
+	return result, nil
+}
+
+func (f *issueTrackerFake) CreateIssue(issue *issues.Issue) error {
+	if f.createErr != nil {
+		return f.createErr
+	}
+	f.created++
+	issue.ID = strconv.Itoa(len(f.issues) + 1)
+	issue.Open = true
+	issue.Fingerprint = issues.ParseFingerprint(issue.Body)
+	copy := *issue
+	f.issues = append(f.issues, &copy)
+	return nil
+}
+
+func (f *issueTrackerFake) UpdateIssue(issue *issues.Issue) error {
+	f.updated++
+	stored := f.get(issue.ID)
+	stored.Title = issue.Title
+	stored.Body = issue.Body
+	return nil
+}
+
+func (f *issueTrackerFake) ReopenIssue(issue *issues.Issue) error {
+	f.reopened++
+	f.get(issue.ID).Open = true
+	issue.Open = true
+	return nil
+}
+
+func (f *issueTrackerFake) CloseIssue(issue *issues.Issue, comment string) error {
+	f.closed++
+	f.get(issue.ID).Open = false
+	issue.Open = false
+	return nil
+}
+
+func (f *issueTrackerFake) Close() error {
+	f.clientClosed = true
+	return nil
+}
+
+func (f *issueTrackerFake) get(id string) *issues.Issue {
+	for _, issue := range f.issues {
+		if issue.ID == id {
+			return issue
+		}
+	}
+	return nil
+}
+
+// getIssuesTestResults returns the first cluster of the shared test results with
+// its two critical policies set to the given validity and an errored policy.
+func getIssuesTestResults(firstValid, secondValid bool) []*policy.PolicyEvaluationResult {
+	results := getTestResults()[:1]
+	setValid := func(p *policy.Policy, valid bool) {
+		p.Valid = valid
+		p.Violations = nil
+		if !valid {
+			p.Violations = []string{p.Name + " violation"}
+		}
+	}
+	setValid(results[0].Policies[0], firstValid)
+	setValid(results[0].Policies[2], secondValid)
+	results[0].Policies = append(results[0].Policies,
+		&policy.Policy{Name: "policy-errored", Severity: "High", ProcessingErrors: []error{errors.New("error")}})
+	return results
+}
+
+func runIssuesCollector(t *testing.T, tracker *issueTrackerFake, results []*policy.PolicyEvaluationResult) {
+	collector := NewIssuesResultCollector(tracker, "High")
+	collector.RegisterResult(results)
+	if err := collector.Close(); err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+}
+
+func TestIssuesResultCollector(t *testing.T) {
+	tracker := &issueTrackerFake{}
+
+	runIssuesCollector(t, tracker, getIssuesTestResults(false, true))
+	if tracker.created != 1 || len(tracker.issues) != 1 {
+		t.Fatalf("created issues = %v; want %v", tracker.created, 1)
+	}
+	if !tracker.issues[0].Open || tracker.issues[0].Fingerprint == "" {
+		t.Errorf("issue = %+v; want open issue with fingerprint", tracker.issues[0])
+	}
+
+	runIssuesCollector(t, tracker, getIssuesTestResults(false, true))
+	if tracker.created != 1 || tracker.updated != 0 {
+		t.Errorf("created, updated issues = %v, %v; want %v, %v", tracker.created, tracker.updated, 1, 0)
+	}
+
+	runIssuesCollector(t, tracker, getIssuesTestResults(true, false))
+	if tracker.closed != 1 || tracker.created != 2 || tracker.issues[0].Open {
+		t.Errorf("closed, created issues = %v, %v; want %v, %v", tracker.closed, tracker.created, 1, 2)
+	}
+
+	runIssuesCollector(t, tracker, getIssuesTestResults(false, false))
+	if tracker.reopened != 1 || tracker.created != 2 || !tracker.issues[0].Open {
+		t.Errorf("reopened, created issues = %v, %v; want %v, %v", tracker.reopened, tracker.created, 1, 2)
+	}
+}
+
+func TestIssuesResultCollector_upsertError(t *testing.T) {
+	createErr := errors.New("create error")
+	tracker := &issueTrackerFake{createErr: createErr}
+	collector := NewIssuesResultCollector(tracker, "High")
+	collector.RegisterResult(getIssuesTestResults(false, true))
+	if err := collector.Close(); !errors.Is(err, createErr) {
+		t.Errorf("err = %v; want %v", err, createErr)
+	}
+	if !tracker.clientClosed {
+		t.Errorf("tracker client was not closed")
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package logging implements Cloud Logging client
+package logging
+
+import (
+	"context"
+
+	"github.com/google/gke-policy-automation/internal/retry"
+	"github.com/google/gke-policy-automation/internal/version"
+	logging "google.golang.org/api/logging/v2"
+	"google.golang.org/api/option"
+)
+
+const maxWriteEntries = 1000
+
+type CloudLoggingClient struct {
+	ctx     context.Context
+	svc     *logging.Service
+	retrier *retry.Retrier
+}
+
+func NewCloudLoggingClient(ctx context.Context, retrier *retry.Retrier) (*CloudLoggingClient, error) {
+	return newCloudLoggingClient(ctx, retrier)
+}
+
+func NewCloudLoggingClientWithCredentialsFile(ctx context.Context, credentialsFile string, retrier *retry.Retrier) (*CloudLoggingClient, error) {
+	return newCloudLoggingClient(ctx, retrier, option.WithCredentialsFile(credentialsFile))
+}
+
+func newCloudLoggingClient(ctx context.Context, retrier *retry.Retrier, opts ...option.ClientOption) (*CloudLoggingClient, error) {
+	opts = append(opts, option.WithUserAgent(version.UserAgent))
+	svc, err := logging.NewService(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &CloudLoggingClient{
+		ctx:     ctx,
+		svc:     svc,
+		retrier: retrier,
+	}, nil
+}
+
+// WriteEntries writes given log entries in batches. Each entry has to have the log name
+// and monitored resource set.
+func (c *CloudLoggingClient) WriteEntries(entries []*logging.LogEntry) error {
+	for start := 0; start < len(entries); start += maxWriteEntries {
+		end := min(start+maxWriteEntries, len(entries))
+		request := &logging.WriteLogEntriesRequest{
+			Entries: entries[start:end],
+		}
+		err := c.retrier.Do(c.ctx, "entries.write", func(ctx context.Context) error {
+			_, err := c.svc.Entries.Write(request).Context(ctx).Do()
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *CloudLoggingClient) Close() error {
+	return nil
+}
@@ -0,0 +1,124 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package outputs
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/gke-policy-automation/internal/log"
+	"github.com/google/gke-policy-automation/internal/policy"
+)
+
+type MarkdownResultCollector struct {
+	fileWriter   FileWriter
+	filename     string
+	reportMapper ValidationReportMapper
+}
+
+// NewMarkdownResultToFileCollector returns collector that writes a Markdown file with
+// the summary table of clusters and the list of violations for each cluster.
+func NewMarkdownResultToFileCollector(filename string) ValidationResultCollector {
+	return NewMarkdownResultToCustomWriterCollector(filename, OSFileWriter{})
+}
+
+func NewMarkdownResultToCustomWriterCollector(filename string, writer FileWriter) ValidationResultCollector {
+	return &MarkdownResultCollector{
+		filename:     filename,
+		fileWriter:   writer,
+		reportMapper: NewValidationReportMapper(),
+	}
+}
+
+func (p *MarkdownResultCollector) RegisterResult(results []*policy.PolicyEvaluationResult) error {
+	p.reportMapper.AddResults(results)
+	return nil
+}
+
+func (p *MarkdownResultCollector) Close() error {
+	data := mapReportToMarkdown(p.reportMapper.GetReport())
+	if err := p.fileWriter.WriteFile(p.filename, data, 0644); err != nil {
+		return err
+	}
+	log.Infof("Validation results written to the [%s] file", p.filename)
+	return nil
+}
+
+func (p *MarkdownResultCollector) Name() string {
+	return p.filename + " file"
+}
+
+func mapReportToMarkdown(report *ValidationReport) []byte {
+	var sb strings.Builder
+	sb.WriteString("# GKE Policy Automation report\n\n")
+	fmt.Fprintf(&sb, "Validation date: %s\n\n", report.ValidationTime.UTC().Format(time.RFC3339))
+
+	stats := make([]*ValidationReportClusterStats, len(report.ClusterStats))
+	copy(stats, report.ClusterStats)
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].ClusterID < stats[j].ClusterID
+	})
+	sb.WriteString("## Summary\n\n")
+	sb.WriteString("| Cluster | Valid | Violated | Errored | Critical | High | Medium | Low |\n")
+	sb.WriteString("|---|---:|---:|---:|---:|---:|---:|---:|\n")
+	for _, stat := range stats {
+		fmt.Fprintf(&sb, "| %s | %d | %d | %d | %d | %d | %d | %d |\n",
+			escapeMarkdownCell(stat.ClusterID), stat.ValidPoliciesCount, stat.ViolatedPoliciesCount, stat.ErroredPoliciesCount,
+			stat.ViolatedCriticalCount, stat.ViolatedHighCount, stat.ViolatedMediumCount, stat.ViolatedLowCount)
+	}
+
+	violations := make(map[string][]string)
+	for _, reportPolicy := range report.Policies {
+		for _, evaluation := range reportPolicy.ClusterEvaluations {
+			if evaluation.Valid || evaluation.Errored {
+				continue
+			}
+			title := reportPolicy.PolicyTitle
+			if title == "" {
+				title = reportPolicy.PolicyName
+			}
+			row := fmt.Sprintf("| %s | %s | %s | %s |",
+				escapeMarkdownCell(reportPolicy.Severity),
+				escapeMarkdownCell(title),
+				escapeMarkdownCell(reportPolicy.PolicyGroup),
+				escapeMarkdownCell(strings.Join(evaluation.Violations, "<br>")))
+			violations[evaluation.ClusterID] = append(violations[evaluation.ClusterID], row)
+		}
+	}
+	sb.WriteString("\n## Violations\n")
+	if len(violations) == 0 {
+		sb.WriteString("\nNo violations found.\n")
+	}
+	for _, stat := range stats {
+		rows, ok := violations[stat.ClusterID]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(&sb, "\n### %s\n\n", stat.ClusterID)
+		sb.WriteString("| Severity | Policy | Group | Violations |\n")
+		sb.WriteString("|---|---|---|---|\n")
+		for _, row := range rows {
+			sb.WriteString(row + "\n")
+		}
+	}
+	return []byte(sb.String())
+}
+
+func escapeMarkdownCell(value string) string {
+	value = strings.ReplaceAll(value, "|", "\\|")
+	return strings.ReplaceAll(value, "\n", " ")
+}
@@ -0,0 +1,55 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package outputs
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarkdownResultCollector(t *testing.T) {
+	writer := &bufferFileWriter{}
+	collector := NewMarkdownResultToCustomWriterCollector("results.md", writer)
+	results := getTestResults()
+	results[1].Policies[1].Valid = true
+	results[1].Policies[1].Violations = nil
+	collector.RegisterResult(results)
+	if err := collector.Close(); err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	markdown := writer.data.String()
+	expected := []string{
+		"## Summary",
+		"| projects/project/locations/europe-west1/clusters/cluster-one | 1 | 2 | 0 | 1 | 0 | 0 | 1 |",
+		"| projects/project/locations/europe-west1/clusters/cluster-two | 2 | 0 | 0 | 0 | 0 | 0 | 0 |",
+		"### projects/project/locations/europe-west1/clusters/cluster-one",
+		"| Critical | Policy one | Security | first<br>second |",
+		"| Low | Policy \\| two | Security | violation |",
+	}
+	for _, line := range expected {
+		if !strings.Contains(markdown, line) {
+			t.Errorf("markdown does not contain %q:\n%s", line, markdown)
+		}
+	}
+	if strings.Contains(markdown, "### projects/project/locations/europe-west1/clusters/cluster-two") {
+		t.Errorf("markdown contains violations section of a cluster without violations")
+	}
+}
+
+func TestEscapeMarkdownCell(t *testing.T) {
+	if result := escapeMarkdownCell("a | b\nc"); result != "a \\| b c" {
+		t.Errorf("escapeMarkdownCell = %q; want %q", result, "a \\| b c")
+	}
+}
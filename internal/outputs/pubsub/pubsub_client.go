@@ -17,28 +17,49 @@ package pubsub
 
 import (
 	"context"
+	"fmt"
 
 	"cloud.google.com/go/pubsub"
+	vkit "cloud.google.com/go/pubsub/apiv1"
+	"github.com/google/gke-policy-automation/internal/retry"
 	"github.com/google/gke-policy-automation/internal/version"
 	"google.golang.org/api/option"
 )
 
+const (
+	publishCountThreshold = 100
+	publishByteThreshold  = 1e6
+)
+
+// Message is a Pub/Sub message with attributes for filtering and an optional ordering key.
+type Message struct {
+	Data        []byte
+	Attributes  map[string]string
+	OrderingKey string
+}
+
 type CollectorPubSubClient struct {
 	ctx    context.Context
 	client *pubsub.Client
 }
 
-func NewPubSubClient(ctx context.Context, project string) (*CollectorPubSubClient, error) {
-	return newPubSubClient(ctx, project)
+func NewPubSubClient(ctx context.Context, project string, retrier *retry.Retrier) (*CollectorPubSubClient, error) {
+	return newPubSubClient(ctx, project, retrier)
 }
 
-func NewPubSubClientWithCredentialsFile(ctx context.Context, project string, credentialsFile string) (*CollectorPubSubClient, error) {
-	return newPubSubClient(ctx, project, option.WithCredentialsFile(credentialsFile))
+func NewPubSubClientWithCredentialsFile(ctx context.Context, project string, credentialsFile string, retrier *retry.Retrier) (*CollectorPubSubClient, error) {
+	return newPubSubClient(ctx, project, retrier, option.WithCredentialsFile(credentialsFile))
 }
 
-func newPubSubClient(ctx context.Context, project string, opts ...option.ClientOption) (*CollectorPubSubClient, error) {
+func newPubSubClient(ctx context.Context, project string, retrier *retry.Retrier, opts ...option.ClientOption) (*CollectorPubSubClient, error) {
 	opts = append(opts, option.WithUserAgent(version.UserAgent))
-	client, err := pubsub.NewClient(ctx, project, opts...)
+	opts = append(opts, retrier.ClientOptions()...)
+	config := &pubsub.ClientConfig{
+		PublisherCallOptions: &vkit.PublisherCallOptions{
+			Publish: retrier.CallOptions(),
+		},
+	}
+	client, err := pubsub.NewClientWithConfig(ctx, project, config, opts...)
 
 	if err != nil {
 		return nil, err
@@ -58,6 +79,50 @@ func (c *CollectorPubSubClient) Publish(topicName string, message []byte) (strin
 	return pubResult.Get(c.ctx)
 }
 
+// PublishMessages publishes given messages in batches and returns their message IDs.
+// Messages with the same ordering key are delivered in the publishing order. Publishing of
+// an ordering key is paused after a failed message, so it is resumed once the results are known.
+func (c *CollectorPubSubClient) PublishMessages(topicName string, messages []*Message) ([]string, error) {
+	topic := c.client.Topic(topicName)
+	defer topic.Stop()
+	topic.EnableMessageOrdering = true
+	topic.PublishSettings.CountThreshold = publishCountThreshold
+	topic.PublishSettings.ByteThreshold = publishByteThreshold
+	results := make([]*pubsub.PublishResult, 0, len(messages))
+	for _, message := range messages {
+		results = append(results, topic.Publish(c.ctx, &pubsub.Message{
+			Data:        message.Data,
+			Attributes:  message.Attributes,
+			OrderingKey: message.OrderingKey,
+		}))
+	}
+	ids := make([]string, 0, len(results))
+	var firstErr error
+	errCount := 0
+	pausedKeys := make(map[string]bool)
+	for i, result := range results {
+		id, err := result.Get(c.ctx)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			errCount++
+			if key := messages[i].OrderingKey; key != "" {
+				pausedKeys[key] = true
+			}
+			continue
+		}
+		ids = append(ids, id)
+	}
+	for key := range pausedKeys {
+		topic.ResumePublish(key)
+	}
+	if errCount > 0 {
+		return ids, fmt.Errorf("failed to publish %d out of %d messages: %w", errCount, len(messages), firstErr)
+	}
+	return ids, nil
+}
+
 func (c *CollectorPubSubClient) Close() error {
 	return c.client.Close()
 }
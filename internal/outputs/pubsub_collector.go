@@ -15,27 +15,85 @@
 package outputs
 
 import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	cfg "github.com/google/gke-policy-automation/internal/config"
 	"github.com/google/gke-policy-automation/internal/log"
+	"github.com/google/gke-policy-automation/internal/outputs/pubsub"
 	"github.com/google/gke-policy-automation/internal/policy"
 )
 
+const (
+	pubSubAttributeCluster  = "cluster"
+	pubSubAttributePolicy   = "policy"
+	pubSubAttributeSeverity = "severity"
+	pubSubAttributeState    = "state"
+
+	pubSubStateValid    = "valid"
+	pubSubStateViolated = "violated"
+	pubSubStateErrored  = "errored"
+)
+
 type PubSubClient interface {
 	Publish(topicName string, message []byte) (string, error)
+	PublishMessages(topicName string, messages []*pubsub.Message) ([]string, error)
 	Close() error
 }
 
+// PubSubClusterMessage is a message with the validation results of a single cluster.
+type PubSubClusterMessage struct {
+	ValidationTime time.Time                     `json:"validationDate"`
+	ClusterID      string                        `json:"cluster"`
+	Statistics     *ValidationReportClusterStats `json:"statistics"`
+	Policies       []*ValidationReportPolicy     `json:"policies"`
+}
+
+// PubSubViolationMessage is a message with a single policy violation on a cluster.
+type PubSubViolationMessage struct {
+	ValidationTime    time.Time                    `json:"validationDate"`
+	ClusterID         string                       `json:"cluster"`
+	PolicyName        string                       `json:"name"`
+	PolicyGroup       string                       `json:"group"`
+	PolicyTitle       string                       `json:"title"`
+	PolicyDescription string                       `json:"description"`
+	Recommendation    string                       `json:"recommendation,omitempty"`
+	ExternalURI       string                       `json:"externalURI,omitempty"`
+	Severity          string                       `json:"severity,omitempty"`
+	Violations        []string                     `json:"violations,omitempty"`
+	ViolationDetails  []*ValidationReportViolation `json:"violationDetails,omitempty"`
+}
+
 type pubSubResultCollector struct {
-	client       PubSubClient
-	project      string
-	topic        string
-	reportMapper ValidationReportMapper
+	client         PubSubClient
+	project        string
+	topic          string
+	mode           string
+	publishedCount int
+	reportMapper   ValidationReportMapper
 }
 
+// NewPubSubResultCollector returns collector that publishes the whole validation report
+// as a single message.
 func NewPubSubResultCollector(client PubSubClient, project string, topic string) ValidationResultCollector {
+	return NewPubSubResultCollectorWithMode(client, project, topic, cfg.PubSubModeReport)
+}
+
+// NewPubSubResultCollectorWithMode returns collector that publishes the validation results
+// as a single report message, a message per cluster or a message per policy violation, as set
+// by a given configuration mode.
+// Cluster and violation messages have attributes for filtering, are ordered per cluster and
+// are published as the results are registered.
+func NewPubSubResultCollectorWithMode(client PubSubClient, project string, topic string, mode string) ValidationResultCollector {
+	if mode == "" {
+		mode = cfg.PubSubModeReport
+	}
 	return &pubSubResultCollector{
 		client:       client,
 		project:      project,
 		topic:        topic,
+		mode:         mode,
 		reportMapper: NewValidationReportMapper(),
 	}
 }
@@ -45,7 +103,43 @@ func (p *pubSubResultCollector) RegisterResult(results []*policy.PolicyEvaluatio
 	return nil
 }
 
+// Flush publishes the cluster or violation messages of the results registered since the last flush.
+// The report mode publishes a single message on Close, so nothing is published here.
+func (p *pubSubResultCollector) Flush() error {
+	if p.mode == cfg.PubSubModeReport {
+		return nil
+	}
+	report := p.reportMapper.GetReport()
+	var messages []*pubsub.Message
+	var err error
+	if p.mode == cfg.PubSubModeCluster {
+		messages, err = mapReportToPubSubClusterMessages(report)
+	} else {
+		messages, err = mapReportToPubSubViolationMessages(report)
+	}
+	if err != nil {
+		return err
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+	ids, err := p.client.PublishMessages(p.topic, messages)
+	if err != nil {
+		return err
+	}
+	p.publishedCount += len(ids)
+	p.reportMapper = newValidationReportMapperWithTime(report.ValidationTime)
+	return nil
+}
+
 func (p *pubSubResultCollector) Close() error {
+	if p.mode != cfg.PubSubModeReport {
+		if err := p.Flush(); err != nil {
+			return err
+		}
+		log.Infof("Validation results published to Pub/Sub topic [%s] in %d messages", p.topic, p.publishedCount)
+		return p.client.Close()
+	}
 	reportData, err := p.reportMapper.GetJSONReport()
 	if err != nil {
 		return err
@@ -61,3 +155,110 @@ func (p *pubSubResultCollector) Close() error {
 func (p *pubSubResultCollector) Name() string {
 	return p.topic + " Pub/Sub topic"
 }
+
+func mapReportToPubSubClusterMessages(report *ValidationReport) ([]*pubsub.Message, error) {
+	clusterMessages := make(map[string]*PubSubClusterMessage)
+	for _, stats := range report.ClusterStats {
+		clusterMessages[stats.ClusterID] = &PubSubClusterMessage{
+			ValidationTime: report.ValidationTime,
+			ClusterID:      stats.ClusterID,
+			Statistics:     stats,
+			Policies:       make([]*ValidationReportPolicy, 0),
+		}
+	}
+	for _, reportPolicy := range report.Policies {
+		for _, evaluation := range reportPolicy.ClusterEvaluations {
+			clusterMessage, ok := clusterMessages[evaluation.ClusterID]
+			if !ok {
+				continue
+			}
+			clusterPolicy := *reportPolicy
+			clusterPolicy.ClusterEvaluations = []*ValidationReportClusterEvaluation{evaluation}
+			clusterMessage.Policies = append(clusterMessage.Policies, &clusterPolicy)
+		}
+	}
+	messages := make([]*pubsub.Message, 0, len(clusterMessages))
+	for _, stats := range report.ClusterStats {
+		clusterMessage := clusterMessages[stats.ClusterID]
+		data, err := json.Marshal(clusterMessage)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, &pubsub.Message{
+			Data: data,
+			Attributes: map[string]string{
+				pubSubAttributeCluster:  stats.ClusterID,
+				pubSubAttributeSeverity: getClusterStatsSeverity(stats),
+				pubSubAttributeState:    getClusterStatsState(stats),
+			},
+			OrderingKey: stats.ClusterID,
+		})
+	}
+	return messages, nil
+}
+
+func mapReportToPubSubViolationMessages(report *ValidationReport) ([]*pubsub.Message, error) {
+	messages := make([]*pubsub.Message, 0)
+	for _, reportPolicy := range report.Policies {
+		for _, evaluation := range reportPolicy.ClusterEvaluations {
+			if evaluation.Valid || evaluation.Errored {
+				continue
+			}
+			data, err := json.Marshal(&PubSubViolationMessage{
+				ValidationTime:    report.ValidationTime,
+				ClusterID:         evaluation.ClusterID,
+				PolicyName:        reportPolicy.PolicyName,
+				PolicyGroup:       reportPolicy.PolicyGroup,
+				PolicyTitle:       reportPolicy.PolicyTitle,
+				PolicyDescription: reportPolicy.PolicyDescription,
+				Recommendation:    reportPolicy.Recommendation,
+				ExternalURI:       reportPolicy.ExternalURI,
+				Severity:          reportPolicy.Severity,
+				Violations:        evaluation.Violations,
+				ViolationDetails:  evaluation.ViolationDetails,
+			})
+			if err != nil {
+				return nil, err
+			}
+			messages = append(messages, &pubsub.Message{
+				Data: data,
+				Attributes: map[string]string{
+					pubSubAttributeCluster:  evaluation.ClusterID,
+					pubSubAttributePolicy:   reportPolicy.PolicyName,
+					pubSubAttributeSeverity: reportPolicy.Severity,
+					pubSubAttributeState:    pubSubStateViolated,
+				},
+				OrderingKey: evaluation.ClusterID,
+			})
+		}
+	}
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].OrderingKey < messages[j].OrderingKey
+	})
+	return messages, nil
+}
+
+// getClusterStatsSeverity returns the highest severity of the policies violated on a cluster.
+func getClusterStatsSeverity(stats *ValidationReportClusterStats) string {
+	switch {
+	case stats.ViolatedCriticalCount > 0:
+		return "Critical"
+	case stats.ViolatedHighCount > 0:
+		return "High"
+	case stats.ViolatedMediumCount > 0:
+		return "Medium"
+	case stats.ViolatedLowCount > 0:
+		return "Low"
+	}
+	return ""
+}
+
+func getClusterStatsState(stats *ValidationReportClusterStats) string {
+	switch {
+	case stats.ViolatedPoliciesCount > 0:
+		return pubSubStateViolated
+	case stats.ErroredPoliciesCount > 0:
+		return pubSubStateErrored
+	}
+	return pubSubStateValid
+}
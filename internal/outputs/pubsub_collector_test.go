@@ -15,8 +15,11 @@
 package outputs
 
 import (
+	"encoding/json"
 	"testing"
 
+	cfg "github.com/google/gke-policy-automation/internal/config"
+	"github.com/google/gke-policy-automation/internal/outputs/pubsub"
 	"github.com/google/gke-policy-automation/internal/policy"
 	"github.com/stretchr/testify/mock"
 )
@@ -30,6 +33,11 @@ func (m *PubSubMock) Publish(topicName string, message []byte) (string, error) {
 	return args.String(0), args.Error(1)
 }
 
+func (m *PubSubMock) PublishMessages(topicName string, messages []*pubsub.Message) ([]string, error) {
+	args := m.Called(topicName, messages)
+	return args.Get(0).([]string), args.Error(1)
+}
+
 func (m *PubSubMock) Close() error {
 	args := m.Called()
 	return args.Error(0)
@@ -64,3 +72,135 @@ func TestPublishingToPubSub(t *testing.T) {
 
 	mockPubSub.AssertExpectations(t)
 }
+
+func TestPublishingToPubSub_clusterMode(t *testing.T) {
+	topicName := "my-topic"
+	mockPubSub := &PubSubMock{}
+	mockPubSub.On("PublishMessages", topicName, mock.Anything).Return([]string{"id1", "id2"}, nil)
+	mockPubSub.On("Close").Return(nil)
+
+	collector := NewPubSubResultCollectorWithMode(mockPubSub, "my-project", topicName, cfg.PubSubModeCluster)
+	collector.RegisterResult(getTestResults())
+	if err := collector.Close(); err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	mockPubSub.AssertExpectations(t)
+	mockPubSub.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+}
+
+func TestPublishingToPubSub_violationModeStreaming(t *testing.T) {
+	topicName := "my-topic"
+	mockPubSub := &PubSubMock{}
+	mockPubSub.On("PublishMessages", topicName, mock.MatchedBy(func(messages []*pubsub.Message) bool {
+		return len(messages) == 2
+	})).Return([]string{"id1", "id2"}, nil).Once()
+	mockPubSub.On("PublishMessages", topicName, mock.MatchedBy(func(messages []*pubsub.Message) bool {
+		return len(messages) == 1
+	})).Return([]string{"id3"}, nil).Once()
+	mockPubSub.On("Close").Return(nil)
+
+	collector := NewPubSubResultCollectorWithMode(mockPubSub, "my-project", topicName, cfg.PubSubModeViolation)
+	for _, result := range getTestResults() {
+		collector.RegisterResult([]*policy.PolicyEvaluationResult{result})
+		if err := collector.(StreamingResultCollector).Flush(); err != nil {
+			t.Fatalf("err = %v; want nil", err)
+		}
+	}
+	if err := collector.Close(); err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	mockPubSub.AssertExpectations(t)
+}
+
+func TestPublishingToPubSub_reportModeFlush(t *testing.T) {
+	topicName := "my-topic"
+	mockPubSub := &PubSubMock{}
+	mockPubSub.On("Publish", topicName, mock.Anything).Return("id", nil).Once()
+	mockPubSub.On("Close").Return(nil)
+
+	collector := NewPubSubResultCollector(mockPubSub, "my-project", topicName)
+	collector.RegisterResult(getTestResults())
+	if err := collector.(StreamingResultCollector).Flush(); err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	mockPubSub.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+	if err := collector.Close(); err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	mockPubSub.AssertExpectations(t)
+}
+
+func TestMapReportToPubSubClusterMessages(t *testing.T) {
+	mapper := NewValidationReportMapper()
+	mapper.AddResults(getTestResults())
+	messages, err := mapReportToPubSubClusterMessages(mapper.GetReport())
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	if len(messages) != 2 {
+		t.Fatalf("number of messages = %v; want %v", len(messages), 2)
+	}
+	expectedSeverities := map[string]string{
+		"projects/project/locations/europe-west1/clusters/cluster-one": "Critical",
+		"projects/project/locations/europe-west1/clusters/cluster-two": "Low",
+	}
+	for _, message := range messages {
+		cluster := message.Attributes[pubSubAttributeCluster]
+		if message.OrderingKey != cluster {
+			t.Errorf("message orderingKey = %v; want %v", message.OrderingKey, cluster)
+		}
+		if message.Attributes[pubSubAttributeSeverity] != expectedSeverities[cluster] {
+			t.Errorf("cluster %s severity = %v; want %v", cluster, message.Attributes[pubSubAttributeSeverity], expectedSeverities[cluster])
+		}
+		if message.Attributes[pubSubAttributeState] != pubSubStateViolated {
+			t.Errorf("cluster %s state = %v; want %v", cluster, message.Attributes[pubSubAttributeState], pubSubStateViolated)
+		}
+		var data PubSubClusterMessage
+		if err := json.Unmarshal(message.Data, &data); err != nil {
+			t.Fatalf("err = %v; want nil", err)
+		}
+		if data.ClusterID != cluster {
+			t.Errorf("message data cluster = %v; want %v", data.ClusterID, cluster)
+		}
+		for _, p := range data.Policies {
+			if len(p.ClusterEvaluations) != 1 || p.ClusterEvaluations[0].ClusterID != cluster {
+				t.Errorf("cluster %s policy %s has evaluations of other clusters", cluster, p.PolicyName)
+			}
+		}
+	}
+}
+
+func TestMapReportToPubSubViolationMessages(t *testing.T) {
+	mapper := NewValidationReportMapper()
+	mapper.AddResults(getTestResults())
+	messages, err := mapReportToPubSubViolationMessages(mapper.GetReport())
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	expected := []struct {
+		cluster  string
+		policy   string
+		severity string
+	}{
+		{"projects/project/locations/europe-west1/clusters/cluster-one", "policy-one", "Critical"},
+		{"projects/project/locations/europe-west1/clusters/cluster-one", "policy-two", "Low"},
+		{"projects/project/locations/europe-west1/clusters/cluster-two", "policy-two", "Low"},
+	}
+	if len(messages) != len(expected) {
+		t.Fatalf("number of messages = %v; want %v", len(messages), len(expected))
+	}
+	for i, message := range messages {
+		if message.OrderingKey != expected[i].cluster {
+			t.Errorf("message [%d] orderingKey = %v; want %v", i, message.OrderingKey, expected[i].cluster)
+		}
+		if message.Attributes[pubSubAttributePolicy] != expected[i].policy {
+			t.Errorf("message [%d] policy = %v; want %v", i, message.Attributes[pubSubAttributePolicy], expected[i].policy)
+		}
+		if message.Attributes[pubSubAttributeSeverity] != expected[i].severity {
+			t.Errorf("message [%d] severity = %v; want %v", i, message.Attributes[pubSubAttributeSeverity], expected[i].severity)
+		}
+		if message.Attributes[pubSubAttributeState] != pubSubStateViolated {
+			t.Errorf("message [%d] state = %v; want %v", i, message.Attributes[pubSubAttributeState], pubSubStateViolated)
+		}
+	}
+}
@@ -27,6 +27,7 @@ import (
 	scc "cloud.google.com/go/securitycenter/apiv1"
 	sccpb "cloud.google.com/go/securitycenter/apiv1/securitycenterpb"
 	"github.com/google/gke-policy-automation/internal/log"
+	"github.com/google/gke-policy-automation/internal/retry"
 	"github.com/google/gke-policy-automation/internal/version"
 	gax "github.com/googleapis/gax-go/v2"
 	"google.golang.org/api/iterator"
@@ -99,6 +100,17 @@ type Finding struct {
 	SourcePolicyGroup string
 	ExternalURI       string
 	Recommendation    string
+	Violations        []*FindingViolation
+}
+
+// FindingViolation is a single policy violation, optionally referencing a cluster sub-resource.
+// Supported sub-resource references are "nodePools/NAME" for node pools and
+// "namespaces/NAMESPACE/KIND/NAME" for Kubernetes objects.
+type FindingViolation struct {
+	Message     string
+	Resource    string
+	Field       string
+	Remediation string
 }
 
 type securityCommandCenterClientImpl struct {
@@ -106,18 +118,20 @@ type securityCommandCenterClientImpl struct {
 	organizationNumber string
 	sourcesSearchLimit int
 	client             sccAPIClient
+	retrier            *retry.Retrier
 }
 
-func NewSecurityCommandCenterClient(ctx context.Context, organizationNumber string) (SecurityCommandCenterClient, error) {
-	return newSecurityCommandCenterClient(ctx, organizationNumber)
+func NewSecurityCommandCenterClient(ctx context.Context, organizationNumber string, retrier *retry.Retrier) (SecurityCommandCenterClient, error) {
+	return newSecurityCommandCenterClient(ctx, organizationNumber, retrier)
 }
 
-func NewSecurityCommandCenterClientWithCredentialsFile(ctx context.Context, organizationNumber string, credsFile string) (SecurityCommandCenterClient, error) {
-	return newSecurityCommandCenterClient(ctx, organizationNumber, option.WithCredentialsFile(credsFile))
+func NewSecurityCommandCenterClientWithCredentialsFile(ctx context.Context, organizationNumber string, credsFile string, retrier *retry.Retrier) (SecurityCommandCenterClient, error) {
+	return newSecurityCommandCenterClient(ctx, organizationNumber, retrier, option.WithCredentialsFile(credsFile))
 }
 
-func newSecurityCommandCenterClient(ctx context.Context, organizationNumber string, opts ...option.ClientOption) (SecurityCommandCenterClient, error) {
+func newSecurityCommandCenterClient(ctx context.Context, organizationNumber string, retrier *retry.Retrier, opts ...option.ClientOption) (SecurityCommandCenterClient, error) {
 	opts = append(opts, option.WithUserAgent(version.UserAgent))
+	opts = append(opts, retrier.ClientOptions()...)
 	c, err := scc.NewClient(ctx, opts...)
 	if err != nil {
 		return nil, err
@@ -127,6 +141,7 @@ func newSecurityCommandCenterClient(ctx context.Context, organizationNumber stri
 		organizationNumber: organizationNumber,
 		sourcesSearchLimit: defaultSourceSearchLimit,
 		client:             c,
+		retrier:            retrier,
 	}, nil
 }
 
@@ -138,7 +153,7 @@ func (c *securityCommandCenterClientImpl) CreateSource() (string, error) {
 			Description: sourceDescription,
 		},
 	}
-	source, err := c.client.CreateSource(c.ctx, req)
+	source, err := c.client.CreateSource(c.ctx, req, c.retrier.CallOptions()...)
 	if err != nil {
 		return "", err
 	}
@@ -149,7 +164,7 @@ func (c *securityCommandCenterClientImpl) FindSource() (*string, error) {
 	listReq := &sccpb.ListSourcesRequest{
 		Parent: "organizations/" + c.organizationNumber,
 	}
-	sourcesIterator := c.client.ListSources(c.ctx, listReq)
+	sourcesIterator := c.client.ListSources(c.ctx, listReq, c.retrier.CallOptions()...)
 	return c.findSourceNameByDisplayName(sourceDisplayName, sourcesIterator)
 }
 
@@ -198,7 +213,7 @@ func (c *securityCommandCenterClientImpl) getFinding(source, name string) (*sccp
 		Parent: source,
 		Filter: fmt.Sprintf("name=%q", name),
 	}
-	it := c.client.ListFindings(c.ctx, req)
+	it := c.client.ListFindings(c.ctx, req, c.retrier.CallOptions()...)
 	results, err := resourceIteratorToSlice[*sccpb.ListFindingsResponse_ListFindingsResult](
 		it,
 		c.sourcesSearchLimit,
@@ -223,7 +238,7 @@ func (c *securityCommandCenterClientImpl) upsertFinding(finding *sccpb.Finding)
 		Finding: finding,
 	}
 	log.Debugf("SCC finding update with req: %+v", req)
-	return c.client.UpdateFinding(c.ctx, req)
+	return c.client.UpdateFinding(c.ctx, req, c.retrier.CallOptions()...)
 }
 
 // resourceIteratorToSlice iterates using given resource iterator, up to the given limit, and returns list of resources.
@@ -276,6 +291,19 @@ func mapFindingSourceProperties(finding *Finding) map[string]*structpb.Value {
 	result["Recommendation"] = structpb.NewStringValue(finding.Recommendation)
 	result["GKEPolicyAutomationVersion"] = structpb.NewStringValue(version.Version)
 
+	if len(finding.Violations) > 0 {
+		violations := make([]*structpb.Value, len(finding.Violations))
+		for i, v := range finding.Violations {
+			violations[i] = structpb.NewStructValue(&structpb.Struct{Fields: map[string]*structpb.Value{
+				"msg":         structpb.NewStringValue(v.Message),
+				"resource":    structpb.NewStringValue(v.Resource),
+				"field":       structpb.NewStringValue(v.Field),
+				"remediation": structpb.NewStringValue(v.Remediation),
+			}})
+		}
+		result["Violations"] = structpb.NewListValue(&structpb.ListValue{Values: violations})
+	}
+
 	if finding.CisID != "" && finding.CisVersion != "" {
 		standards := map[string]interface{}{
 			"cis_gke": []interface{}{
@@ -305,6 +333,33 @@ func mapFindingCompliances(finding *Finding) []*sccpb.Compliance {
 	}
 }
 
+// mapFindingKubernetes maps violated sub-resources of a finding to the SCC Kubernetes
+// node pools and objects. When no sub-resources are referenced, nil is returned.
+func mapFindingKubernetes(finding *Finding) *sccpb.Kubernetes {
+	var nodePools []*sccpb.Kubernetes_NodePool
+	var objects []*sccpb.Kubernetes_Object
+	seen := make(map[string]bool)
+	for _, v := range finding.Violations {
+		if v.Resource == "" || seen[v.Resource] {
+			continue
+		}
+		seen[v.Resource] = true
+		parts := strings.Split(v.Resource, "/")
+		switch {
+		case len(parts) == 2 && parts[0] == "nodePools":
+			nodePools = append(nodePools, &sccpb.Kubernetes_NodePool{Name: parts[1]})
+		case len(parts) == 4 && parts[0] == "namespaces":
+			objects = append(objects, &sccpb.Kubernetes_Object{Ns: parts[1], Kind: parts[2], Name: parts[3]})
+		default:
+			log.Debugf("violation resource %q is not mapped to SCC Kubernetes resource", v.Resource)
+		}
+	}
+	if len(nodePools) == 0 && len(objects) == 0 {
+		return nil
+	}
+	return &sccpb.Kubernetes{NodePools: nodePools, Objects: objects}
+}
+
 // calculateFindingID generates identifier (hash) from resource name and finding category
 func calculateFindingID(resourceName, findingCategory string) string {
 	val := resourceName + "/" + findingCategory
@@ -329,6 +384,7 @@ func mapFindingToAPI(sourceName string, finding *Finding) *sccpb.Finding {
 		Compliances:      mapFindingCompliances(finding),
 		ExternalUri:      finding.ExternalURI,
 		NextSteps:        finding.Recommendation,
+		Kubernetes:       mapFindingKubernetes(finding),
 	}
 }
 
@@ -500,3 +500,54 @@ func TestMapFindingStateString(t *testing.T) {
 		}
 	}
 }
+
+func TestMapFindingKubernetes(t *testing.T) {
+	finding := &Finding{
+		Violations: []*FindingViolation{
+			{Message: "msg-one", Resource: "nodePools/default"},
+			{Message: "msg-two", Resource: "nodePools/default"},
+			{Message: "msg-three", Resource: "namespaces/default/Deployment/nginx"},
+			{Message: "msg-four", Resource: "unsupported"},
+			{Message: "msg-five"},
+		},
+	}
+	result := mapFindingKubernetes(finding)
+	if result == nil {
+		t.Fatalf("result is nil; want kubernetes struct")
+	}
+	if len(result.NodePools) != 1 {
+		t.Fatalf("number of node pools = %v; want %v", len(result.NodePools), 1)
+	}
+	if result.NodePools[0].Name != "default" {
+		t.Errorf("node pool name = %v; want %v", result.NodePools[0].Name, "default")
+	}
+	if len(result.Objects) != 1 {
+		t.Fatalf("number of objects = %v; want %v", len(result.Objects), 1)
+	}
+	obj := result.Objects[0]
+	if obj.Ns != "default" || obj.Kind != "Deployment" || obj.Name != "nginx" {
+		t.Errorf("object = %v; want ns=default, kind=Deployment, name=nginx", obj)
+	}
+	if r := mapFindingKubernetes(&Finding{}); r != nil {
+		t.Errorf("result for finding without violations = %v; want nil", r)
+	}
+}
+
+func TestMapFindingSourceProperties_violations(t *testing.T) {
+	finding := &Finding{
+		Violations: []*FindingViolation{
+			{Message: "msg", Resource: "nodePools/default", Field: "management.auto_repair", Remediation: "fix it"},
+		},
+	}
+	result := mapFindingSourceProperties(finding)
+	violations := result["Violations"].GetListValue()
+	if violations == nil || len(violations.Values) != 1 {
+		t.Fatalf("violations = %v; want list with one element", result["Violations"])
+	}
+	fields := violations.Values[0].GetStructValue().GetFields()
+	for k, v := range map[string]string{"msg": "msg", "resource": "nodePools/default", "field": "management.auto_repair", "remediation": "fix it"} {
+		if fields[k].GetStringValue() != v {
+			t.Errorf("violation %s = %v; want %v", k, fields[k].GetStringValue(), v)
+		}
+	}
+}
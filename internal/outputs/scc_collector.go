@@ -22,9 +22,11 @@ import (
 	"sync"
 	"time"
 
+	"github.com/google/gke-policy-automation/internal/gke"
 	"github.com/google/gke-policy-automation/internal/log"
 	"github.com/google/gke-policy-automation/internal/outputs/scc"
 	"github.com/google/gke-policy-automation/internal/policy"
+	"github.com/google/gke-policy-automation/internal/retry"
 )
 
 const (
@@ -40,13 +42,13 @@ type sccCollector struct {
 	findings     []*scc.Finding
 }
 
-func NewSccCollector(ctx context.Context, orgNumber string, createSource bool, credsFile string) (ValidationResultCollector, error) {
+func NewSccCollector(ctx context.Context, orgNumber string, createSource bool, credsFile string, retrier *retry.Retrier) (ValidationResultCollector, error) {
 	var cli scc.SecurityCommandCenterClient
 	var err error
 	if credsFile != "" {
-		cli, err = scc.NewSecurityCommandCenterClient(ctx, orgNumber)
+		cli, err = scc.NewSecurityCommandCenterClient(ctx, orgNumber, retrier)
 	} else {
-		cli, err = scc.NewSecurityCommandCenterClientWithCredentialsFile(ctx, orgNumber, credsFile)
+		cli, err = scc.NewSecurityCommandCenterClientWithCredentialsFile(ctx, orgNumber, credsFile, retrier)
 	}
 	if err != nil {
 		return nil, err
@@ -118,10 +120,14 @@ func (c *sccCollector) processFindings(source string) []error {
 
 	log.Debugf("starting finding producing goroutine")
 	go func() {
+		defer close(findingsChan)
 		for _, finding := range c.findings {
-			findingsChan <- finding
+			select {
+			case findingsChan <- finding:
+			case <-c.ctx.Done():
+				return
+			}
 		}
-		close(findingsChan)
 	}()
 
 	log.Debugf("starting finding consuming goroutine")
@@ -139,12 +145,19 @@ func (c *sccCollector) processFindings(source string) []error {
 	for err := range errorsChan {
 		errors = append(errors, err)
 	}
+	if err := c.ctx.Err(); err != nil {
+		log.Warnf("findings processing interrupted: %s", err)
+		errors = append(errors, err)
+	}
 	return errors
 }
 
 func (c *sccCollector) upsertFinding(i int, wg *sync.WaitGroup, findings chan *scc.Finding, source string, errors chan error) {
 	defer wg.Done()
 	for finding := range findings {
+		if c.ctx.Err() != nil {
+			break
+		}
 		log.Debugf("goroutine %d processing finding (resName=%v category=%v)", i, finding.ResourceName, finding.Category)
 		if err := c.cli.UpsertFinding(source, finding); err != nil {
 			log.Warnf("failed to upsert finding (resName=%v category=%v): %s", finding.ResourceName, finding.Category, err)
@@ -154,10 +167,19 @@ func (c *sccCollector) upsertFinding(i int, wg *sync.WaitGroup, findings chan *s
 	log.Debugf("Upsert goroutine %d finished", i)
 }
 
+// getFindingResourceName returns the full resource name of a given cluster, that is a GKE cluster
+// or a Fleet membership of other clusters, i.e. attached or multi-cloud clusters.
+func getFindingResourceName(clusterID string) string {
+	if gke.IsFleetMembershipName(clusterID) {
+		return fmt.Sprintf("//gkehub.googleapis.com/%s", clusterID)
+	}
+	return fmt.Sprintf("//container.googleapis.com/%s", clusterID)
+}
+
 func mapPolicyToFinding(resourceName string, eventTime time.Time, policy *policy.Policy) *scc.Finding {
 	return &scc.Finding{
 		Time:              eventTime,
-		ResourceName:      fmt.Sprintf("//container.googleapis.com/%s", resourceName),
+		ResourceName:      getFindingResourceName(resourceName),
 		Category:          policy.Category,
 		Description:       policy.Description,
 		State:             mapPolicyEvaluationToFindingState(policy),
@@ -169,7 +191,24 @@ func mapPolicyToFinding(resourceName string, eventTime time.Time, policy *policy
 		CisID:             policy.CisID,
 		ExternalURI:       policy.ExternalURI,
 		Recommendation:    policy.Recommendation,
+		Violations:        mapPolicyViolationsToFinding(policy),
+	}
+}
+
+func mapPolicyViolationsToFinding(policy *policy.Policy) []*scc.FindingViolation {
+	if policy.Valid || len(policy.ViolationDetails) == 0 {
+		return nil
+	}
+	violations := make([]*scc.FindingViolation, len(policy.ViolationDetails))
+	for i, v := range policy.ViolationDetails {
+		violations[i] = &scc.FindingViolation{
+			Message:     v.Message,
+			Resource:    v.Resource,
+			Field:       v.Field,
+			Remediation: v.Remediation,
+		}
 	}
+	return violations
 }
 
 func mapPolicyEvaluationToFindingState(policy *policy.Policy) string {
@@ -17,6 +17,7 @@ package outputs
 import (
 	"context"
 	"errors"
+	"reflect"
 	"sync"
 	"testing"
 	"time"
@@ -95,7 +96,7 @@ func TestProcessFindings(t *testing.T) {
 			return findingsToErr[finding.Category]
 		},
 	}
-	c := &sccCollector{cli: mock, findings: findings, goRoutinesNo: 2}
+	c := &sccCollector{ctx: context.TODO(), cli: mock, findings: findings, goRoutinesNo: 2}
 	result := c.processFindings(source)
 	if len(result) != len(findingsToErr) {
 		t.Fatalf("number of results = %v; want %v", len(result), len(findingsToErr))
@@ -105,6 +106,33 @@ func TestProcessFindings(t *testing.T) {
 	}
 }
 
+func TestProcessFindings_canceled(t *testing.T) {
+	findings := []*scc.Finding{
+		{Category: "category1"},
+		{Category: "category2"},
+	}
+	upserted := 0
+	mock := &sccClientMock{
+		UpsertFindingFn: func(sourceName string, finding *scc.Finding) error {
+			upserted++
+			return nil
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	c := &sccCollector{ctx: ctx, cli: mock, findings: findings, goRoutinesNo: 1}
+	result := c.processFindings("src")
+	if len(result) != 1 {
+		t.Fatalf("number of results = %v; want %v", len(result), 1)
+	}
+	if result[0] != context.Canceled {
+		t.Errorf("result error = %v; want %v", result[0], context.Canceled)
+	}
+	if upserted != 0 {
+		t.Errorf("number of upserted findings = %v; want %v", upserted, 0)
+	}
+}
+
 func TestSccCollectorRegisterResult(t *testing.T) {
 	results := []*policy.PolicyEvaluationResult{
 		{
@@ -144,7 +172,7 @@ func TestUpsertFindings(t *testing.T) {
 	findings := []*scc.Finding{
 		{Category: "test-one"},
 	}
-	c := &sccCollector{cli: &mock, goRoutinesNo: 1}
+	c := &sccCollector{ctx: context.TODO(), cli: &mock, goRoutinesNo: 1}
 	findingsChan := make(chan *scc.Finding, c.goRoutinesNo)
 	errorsChan := make(chan error, c.goRoutinesNo)
 	for _, finding := range findings {
@@ -204,17 +232,18 @@ func TestMapPolicyToFinding(t *testing.T) {
 	resourceName := "projects/tst/locations/europe-west3/clusters/tst"
 	time := time.Now()
 	policy := &policy.Policy{
-		Violations:     []string{"invalid"},
-		Category:       "category",
-		Severity:       "LOW",
-		Description:    "description",
-		Group:          "Security",
-		File:           "file.rego",
-		Name:           "gke.policy.test",
-		CisVersion:     "1.2",
-		CisID:          "6.2.3",
-		ExternalURI:    "https://external-uri",
-		Recommendation: "A good recommendation",
+		Violations:       []string{"invalid"},
+		ViolationDetails: []*policy.Violation{{Message: "invalid", Resource: "nodePools/default"}},
+		Category:         "category",
+		Severity:         "LOW",
+		Description:      "description",
+		Group:            "Security",
+		File:             "file.rego",
+		Name:             "gke.policy.test",
+		CisVersion:       "1.2",
+		CisID:            "6.2.3",
+		ExternalURI:      "https://external-uri",
+		Recommendation:   "A good recommendation",
 	}
 	finding := mapPolicyToFinding(resourceName, time, policy)
 	if finding.Time != time {
@@ -257,6 +286,26 @@ func TestMapPolicyToFinding(t *testing.T) {
 	if finding.Recommendation != policy.Recommendation {
 		t.Errorf("finding recommendation = %v; want %v", finding.Recommendation, policy.Recommendation)
 	}
+	expectedViolations := []*scc.FindingViolation{{Message: "invalid", Resource: "nodePools/default"}}
+	if !reflect.DeepEqual(finding.Violations, expectedViolations) {
+		t.Errorf("finding violations = %v; want %v", finding.Violations, expectedViolations)
+	}
+}
+
+func TestGetFindingResourceName(t *testing.T) {
+	input := []string{
+		"projects/tst/locations/europe-west3/clusters/tst",
+		"projects/tst/locations/global/memberships/attached",
+	}
+	expected := []string{
+		"//container.googleapis.com/projects/tst/locations/europe-west3/clusters/tst",
+		"//gkehub.googleapis.com/projects/tst/locations/global/memberships/attached",
+	}
+	for i := range input {
+		if result := getFindingResourceName(input[i]); result != expected[i] {
+			t.Errorf("getFindingResourceName(%q) = %v; want %v", input[i], result, expected[i])
+		}
+	}
 }
 
 func TestMapPolicyEvaluationToFindingState(t *testing.T) {
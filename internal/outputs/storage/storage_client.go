@@ -19,24 +19,26 @@ import (
 	"context"
 
 	"cloud.google.com/go/storage"
+	"github.com/google/gke-policy-automation/internal/retry"
 	"github.com/google/gke-policy-automation/internal/version"
 	"google.golang.org/api/option"
 )
 
 type CloudStorageClient struct {
-	ctx    context.Context
-	client *storage.Client
+	ctx     context.Context
+	client  *storage.Client
+	retrier *retry.Retrier
 }
 
-func NewCloudStorageClient(ctx context.Context) (*CloudStorageClient, error) {
-	return newCloudStorageClient(ctx)
+func NewCloudStorageClient(ctx context.Context, retrier *retry.Retrier) (*CloudStorageClient, error) {
+	return newCloudStorageClient(ctx, retrier)
 }
 
-func NewCloudStorageClientWithCredentialsFile(ctx context.Context, credentialsFile string) (*CloudStorageClient, error) {
-	return newCloudStorageClient(ctx, option.WithCredentialsFile(credentialsFile))
+func NewCloudStorageClientWithCredentialsFile(ctx context.Context, credentialsFile string, retrier *retry.Retrier) (*CloudStorageClient, error) {
+	return newCloudStorageClient(ctx, retrier, option.WithCredentialsFile(credentialsFile))
 }
 
-func newCloudStorageClient(ctx context.Context, opts ...option.ClientOption) (*CloudStorageClient, error) {
+func newCloudStorageClient(ctx context.Context, retrier *retry.Retrier, opts ...option.ClientOption) (*CloudStorageClient, error) {
 	opts = append(opts, option.WithUserAgent(version.UserAgent))
 	client, err := storage.NewClient(ctx, opts...)
 
@@ -45,8 +47,9 @@ func newCloudStorageClient(ctx context.Context, opts ...option.ClientOption) (*C
 	}
 
 	return &CloudStorageClient{
-		ctx:    ctx,
-		client: client,
+		ctx:     ctx,
+		client:  client,
+		retrier: retrier,
 	}, nil
 }
 
@@ -56,14 +59,16 @@ func (c *CloudStorageClient) BucketExists(bucketName string) bool {
 }
 
 func (c *CloudStorageClient) Write(bucketName, objectName string, content []byte) error {
-	w := c.client.Bucket(bucketName).Object(objectName).NewWriter(c.ctx)
+	return c.retrier.Do(c.ctx, "objects.insert", func(ctx context.Context) error {
+		w := c.client.Bucket(bucketName).Object(objectName).NewWriter(ctx)
 
-	if _, err := w.Write(content); err != nil {
-		w.Close()
-		return err
-	}
+		if _, err := w.Write(content); err != nil {
+			w.Close()
+			return err
+		}
 
-	return w.Close()
+		return w.Close()
+	})
 }
 
 func (c *CloudStorageClient) Close() error {
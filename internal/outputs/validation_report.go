@@ -50,11 +50,19 @@ type ValidationReportPolicy struct {
 }
 
 type ValidationReportClusterEvaluation struct {
-	ClusterID        string   `json:"cluster"`
-	Valid            bool     `json:"isValid"`
-	Errored          bool     `json:"isErrored"`
-	Violations       []string `json:"violations,omitempty"`
-	ProcessingErrors []string `json:"errors,omitempty"`
+	ClusterID        string                       `json:"cluster"`
+	Valid            bool                         `json:"isValid"`
+	Errored          bool                         `json:"isErrored"`
+	Violations       []string                     `json:"violations,omitempty"`
+	ViolationDetails []*ValidationReportViolation `json:"violationDetails,omitempty"`
+	ProcessingErrors []string                     `json:"errors,omitempty"`
+}
+
+type ValidationReportViolation struct {
+	Message     string `json:"msg"`
+	Resource    string `json:"resource,omitempty"`
+	Field       string `json:"field,omitempty"`
+	Remediation string `json:"remediation,omitempty"`
 }
 
 type ValidationReportClusterStats struct {
@@ -83,11 +91,17 @@ type validationReportMapperImpl struct {
 }
 
 func NewValidationReportMapper() ValidationReportMapper {
+	return newValidationReportMapperWithTime(time.Now())
+}
+
+// newValidationReportMapperWithTime returns the mapper with a given validation time, i.e. to keep
+// the validation time of the streaming collectors that map the results incrementally.
+func newValidationReportMapperWithTime(validationTime time.Time) ValidationReportMapper {
 	return &validationReportMapperImpl{
 		policies:        make(map[string]*ValidationReportPolicy),
 		clusterStats:    make(map[string]*ValidationReportClusterStats),
 		jsonMarshalFunc: json.Marshal,
-		validationTime:  time.Now(),
+		validationTime:  validationTime,
 	}
 }
 
@@ -188,6 +202,7 @@ func mapResultPolicyToReportClusterEvaluation(policy *policy.Policy, clusterName
 		ClusterID:        clusterName,
 		Valid:            policy.Valid,
 		Violations:       policy.Violations,
+		ViolationDetails: mapViolationDetails(policy.ViolationDetails),
 		ProcessingErrors: mapErrorSliceToStringSlice(policy.ProcessingErrors),
 	}
 
@@ -197,6 +212,31 @@ func mapResultPolicyToReportClusterEvaluation(policy *policy.Policy, clusterName
 	return clusterEvaluation
 }
 
+// mapViolationDetails maps policy violations to the report violations. The nil is returned
+// when none of the violations carries details other than a message.
+func mapViolationDetails(violations []*policy.Violation) []*ValidationReportViolation {
+	structured := false
+	for _, v := range violations {
+		if v.Resource != "" || v.Field != "" || v.Remediation != "" {
+			structured = true
+			break
+		}
+	}
+	if !structured {
+		return nil
+	}
+	details := make([]*ValidationReportViolation, len(violations))
+	for i, v := range violations {
+		details[i] = &ValidationReportViolation{
+			Message:     v.Message,
+			Resource:    v.Resource,
+			Field:       v.Field,
+			Remediation: v.Remediation,
+		}
+	}
+	return details
+}
+
 func mapErrorSliceToStringSlice(errors []error) []string {
 	strings := make([]string, len(errors))
 	for i := range errors {
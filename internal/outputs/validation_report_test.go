@@ -45,6 +45,27 @@ func (m validationReportMapperMock) GetJSONReport() ([]byte, error) {
 	return m.getJSONReportFn()
 }
 
+// getTestResults returns evaluation results of two clusters shared by the output collector tests.
+func getTestResults() []*policy.PolicyEvaluationResult {
+	return []*policy.PolicyEvaluationResult{
+		{
+			ClusterID: "projects/project/locations/europe-west1/clusters/cluster-one",
+			Policies: []*policy.Policy{
+				{Name: "policy-one", Title: "Policy one", Group: "Security", Severity: "Critical", Valid: false, Violations: []string{"first", "second"}},
+				{Name: "policy-two", Title: "Policy | two", Group: "Security", Severity: "Low", Valid: false, Violations: []string{"violation"}},
+				{Name: "policy-three", Title: "Policy three", Group: "Security", Severity: "Critical", Valid: true},
+			},
+		},
+		{
+			ClusterID: "projects/project/locations/europe-west1/clusters/cluster-two",
+			Policies: []*policy.Policy{
+				{Name: "policy-one", Title: "Policy one", Group: "Security", Severity: "Critical", Valid: true},
+				{Name: "policy-two", Title: "Policy | two", Group: "Security", Severity: "Low", Valid: false, Violations: []string{"violation"}},
+			},
+		},
+	}
+}
+
 func TestGetReport(t *testing.T) {
 	clusterOneName := "cluster-one"
 	clusterTwoName := "cluster-two"
@@ -172,3 +193,22 @@ func TestMapErrorSliceToStringSlice(t *testing.T) {
 	result := mapErrorSliceToStringSlice(errors)
 	assert.ElementsMatch(t, expected, result, "mapped slice of strings matches")
 }
+
+func TestMapViolationDetails(t *testing.T) {
+	violations := []*policy.Violation{
+		{Message: "violation-one"},
+		{Message: "violation-two", Resource: "nodePools/default", Field: "management.auto_repair", Remediation: "enable auto-repair"},
+	}
+	expected := []*ValidationReportViolation{
+		{Message: "violation-one"},
+		{Message: "violation-two", Resource: "nodePools/default", Field: "management.auto_repair", Remediation: "enable auto-repair"},
+	}
+	result := mapViolationDetails(violations)
+	assert.Equal(t, expected, result, "mapped violation details match")
+}
+
+func TestMapViolationDetails_messagesOnly(t *testing.T) {
+	violations := []*policy.Violation{{Message: "violation-one"}, {Message: "violation-two"}}
+	result := mapViolationDetails(violations)
+	assert.Nil(t, result, "violation details are nil when violations have messages only")
+}
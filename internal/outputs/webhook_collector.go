@@ -0,0 +1,267 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package outputs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/google/gke-policy-automation/internal/gke"
+	"github.com/google/gke-policy-automation/internal/log"
+	"github.com/google/gke-policy-automation/internal/policy"
+	"github.com/google/gke-policy-automation/internal/retry"
+	"github.com/google/gke-policy-automation/internal/version"
+)
+
+const (
+	DefaultWebhookTemplate = `{{ len .Violations }} policy violations{{ with .ClusterName }} on {{ . }}{{ end }}` +
+		`{{ with .MinSeverity }} (severity {{ . }} or higher){{ end }}
+{{ range .Violations }}- [{{ .Severity }}] {{ or .PolicyTitle .PolicyName }}{{ if not $.ClusterName }} on {{ .ClusterName }}{{ end }}
+{{ end }}`
+	DefaultWebhookMaxRetries = 3
+
+	webhookClientTimeout  = 30 * time.Second
+	webhookInitialBackoff = time.Second
+	webhookMaxBackoff     = 30 * time.Second
+)
+
+// WebhookOptions configures the message format, filtering and delivery of the webhook collector.
+type WebhookOptions struct {
+	Template       string
+	MinSeverity    string
+	GroupByCluster bool
+	MaxRetries     int
+}
+
+// WebhookMessage is the data of the webhook message template. The cluster ID and name
+// are set only when the violations are grouped by cluster.
+type WebhookMessage struct {
+	ValidationTime time.Time
+	ClusterID      string
+	ClusterName    string
+	MinSeverity    string
+	Violations     []*WebhookViolation
+}
+
+// WebhookViolation is a policy violated on a cluster.
+type WebhookViolation struct {
+	ClusterID   string
+	ClusterName string
+	PolicyName  string
+	PolicyTitle string
+	PolicyGroup string
+	Severity    string
+	Violations  []string
+}
+
+type webhookPayload struct {
+	Text string `json:"text"`
+}
+
+type webhookStatusError struct {
+	statusCode int
+	body       string
+}
+
+func (e *webhookStatusError) Error() string {
+	return fmt.Sprintf("webhook responded with status %d: %s", e.statusCode, e.body)
+}
+
+type webhookResultCollector struct {
+	ctx            context.Context
+	client         *http.Client
+	url            string
+	template       *template.Template
+	minSeverity    string
+	groupByCluster bool
+	backoff        retry.Backoff
+	reportMapper   ValidationReportMapper
+}
+
+// NewWebhookResultCollector returns collector that posts the policy violations to a given
+// webhook URL as a text message rendered from a Go template. The message payload is
+// compatible with Slack, Google Chat and Microsoft Teams incoming webhooks.
+func NewWebhookResultCollector(ctx context.Context, url string, opts WebhookOptions) (ValidationResultCollector, error) {
+	text := opts.Template
+	if text == "" {
+		text = DefaultWebhookTemplate
+	}
+	tmpl, err := template.New("webhook").Funcs(template.FuncMap{
+		"lower": strings.ToLower,
+		"upper": strings.ToUpper,
+		"join":  strings.Join,
+	}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse webhook message template: %w", err)
+	}
+	maxRetries := opts.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = DefaultWebhookMaxRetries
+	}
+	return &webhookResultCollector{
+		ctx:            ctx,
+		client:         &http.Client{Timeout: webhookClientTimeout},
+		url:            url,
+		template:       tmpl,
+		minSeverity:    opts.MinSeverity,
+		groupByCluster: opts.GroupByCluster,
+		backoff: retry.Backoff{
+			MaxRetries:     maxRetries,
+			InitialBackoff: webhookInitialBackoff,
+			MaxBackoff:     webhookMaxBackoff,
+		},
+		reportMapper: NewValidationReportMapper(),
+	}, nil
+}
+
+func (p *webhookResultCollector) RegisterResult(results []*policy.PolicyEvaluationResult) error {
+	p.reportMapper.AddResults(results)
+	return nil
+}
+
+func (p *webhookResultCollector) Close() error {
+	messages := mapReportToWebhookMessages(p.reportMapper.GetReport(), p.minSeverity, p.groupByCluster)
+	for _, message := range messages {
+		var text bytes.Buffer
+		if err := p.template.Execute(&text, message); err != nil {
+			return fmt.Errorf("could not render webhook message: %w", err)
+		}
+		body, err := json.Marshal(&webhookPayload{Text: text.String()})
+		if err != nil {
+			return err
+		}
+		if err := p.post(body); err != nil {
+			return err
+		}
+	}
+	log.Infof("Posted %d messages to webhook", len(messages))
+	return nil
+}
+
+func (p *webhookResultCollector) Name() string {
+	return "webhook"
+}
+
+// post sends a given body to the webhook, retrying with exponential backoff on
+// network errors, throttling and server errors.
+func (p *webhookResultCollector) post(body []byte) error {
+	return retry.DoWithBackoff(p.ctx, "webhook request", p.backoff, nil, isWebhookErrorRetryable, func(ctx context.Context) error {
+		return p.postOnce(ctx, body)
+	})
+}
+
+func (p *webhookResultCollector) postOnce(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", version.UserAgent)
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return &webhookStatusError{statusCode: resp.StatusCode, body: string(respBody)}
+}
+
+func isWebhookErrorRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	statusErr, ok := err.(*webhookStatusError)
+	if !ok {
+		return true
+	}
+	return statusErr.statusCode == http.StatusTooManyRequests || statusErr.statusCode >= 500
+}
+
+// mapReportToWebhookMessages returns messages with the policy violations of a given or higher
+// severity. Messages without violations are skipped.
+func mapReportToWebhookMessages(report *ValidationReport, minSeverity string, groupByCluster bool) []*WebhookMessage {
+	minSeverityNumber := mapSeverityToNumber(minSeverity)
+	violations := make([]*WebhookViolation, 0)
+	for _, reportPolicy := range report.Policies {
+		if reportPolicy.SeverityNumber < minSeverityNumber {
+			continue
+		}
+		for _, evaluation := range reportPolicy.ClusterEvaluations {
+			if evaluation.Valid || evaluation.Errored {
+				continue
+			}
+			violations = append(violations, &WebhookViolation{
+				ClusterID:   evaluation.ClusterID,
+				ClusterName: getClusterShortName(evaluation.ClusterID),
+				PolicyName:  reportPolicy.PolicyName,
+				PolicyTitle: reportPolicy.PolicyTitle,
+				PolicyGroup: reportPolicy.PolicyGroup,
+				Severity:    reportPolicy.Severity,
+				Violations:  evaluation.Violations,
+			})
+		}
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	if !groupByCluster {
+		return []*WebhookMessage{{
+			ValidationTime: report.ValidationTime,
+			MinSeverity:    minSeverity,
+			Violations:     violations,
+		}}
+	}
+	clusterMessages := make(map[string]*WebhookMessage)
+	for _, violation := range violations {
+		message, ok := clusterMessages[violation.ClusterID]
+		if !ok {
+			message = &WebhookMessage{
+				ValidationTime: report.ValidationTime,
+				ClusterID:      violation.ClusterID,
+				ClusterName:    violation.ClusterName,
+				MinSeverity:    minSeverity,
+			}
+			clusterMessages[violation.ClusterID] = message
+		}
+		message.Violations = append(message.Violations, violation)
+	}
+	messages := make([]*WebhookMessage, 0, len(clusterMessages))
+	for _, message := range clusterMessages {
+		messages = append(messages, message)
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].ClusterID < messages[j].ClusterID
+	})
+	return messages
+}
+
+func getClusterShortName(clusterID string) string {
+	if _, _, name, err := gke.SliceAndValidateClusterID(clusterID); err == nil {
+		return name
+	}
+	return clusterID
+}
@@ -0,0 +1,180 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package outputs
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWebhookResultCollector(t *testing.T) {
+	texts := make([]string, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("method = %v; want %v", r.Method, http.MethodPost)
+		}
+		var payload webhookPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("err = %v; want nil", err)
+		}
+		texts = append(texts, payload.Text)
+	}))
+	defer server.Close()
+
+	collector, err := NewWebhookResultCollector(context.Background(), server.URL, WebhookOptions{
+		Template:       "{{ len .Violations }} {{ lower .MinSeverity }} violations on {{ .ClusterName }}",
+		MinSeverity:    "Critical",
+		GroupByCluster: true,
+	})
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	collector.RegisterResult(getTestResults())
+	if err := collector.Close(); err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	expected := []string{"1 critical violations on cluster-one"}
+	if len(texts) != len(expected) || texts[0] != expected[0] {
+		t.Errorf("messages = %v; want %v", texts, expected)
+	}
+}
+
+func TestWebhookResultCollector_retry(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	collector, err := NewWebhookResultCollector(context.Background(), server.URL, WebhookOptions{})
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	collector.(*webhookResultCollector).backoff.InitialBackoff = time.Millisecond
+	collector.RegisterResult(getTestResults())
+	if err := collector.Close(); err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	if requests != 3 {
+		t.Errorf("number of requests = %v; want %v", requests, 3)
+	}
+}
+
+func TestWebhookResultCollector_retryCanceled(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	collector, err := NewWebhookResultCollector(ctx, server.URL, WebhookOptions{})
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	collector.(*webhookResultCollector).backoff.InitialBackoff = time.Hour
+	collector.RegisterResult(getTestResults())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	start := time.Now()
+	if err := collector.Close(); err == nil {
+		t.Errorf("expected error on canceled context")
+	}
+	if elapsed := time.Since(start); elapsed > time.Minute {
+		t.Errorf("elapsed = %v; want backoff to end on canceled context", elapsed)
+	}
+	if requests != 1 {
+		t.Errorf("number of requests = %v; want %v", requests, 1)
+	}
+}
+
+func TestWebhookResultCollector_noRetryOnClientError(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	collector, err := NewWebhookResultCollector(context.Background(), server.URL, WebhookOptions{})
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	collector.RegisterResult(getTestResults())
+	if err := collector.Close(); err == nil {
+		t.Errorf("expected error on client error response")
+	}
+	if requests != 1 {
+		t.Errorf("number of requests = %v; want %v", requests, 1)
+	}
+}
+
+func TestNewWebhookResultCollector_badTemplate(t *testing.T) {
+	if _, err := NewWebhookResultCollector(context.Background(), "http://localhost", WebhookOptions{Template: "{{ .Bogus"}); err == nil {
+		t.Errorf("expected error on invalid template")
+	}
+}
+
+func TestMapReportToWebhookMessages(t *testing.T) {
+	mapper := NewValidationReportMapper()
+	mapper.AddResults(getTestResults())
+	report := mapper.GetReport()
+
+	messages := mapReportToWebhookMessages(report, "", false)
+	if len(messages) != 1 {
+		t.Fatalf("number of messages = %v; want %v", len(messages), 1)
+	}
+	if len(messages[0].Violations) != 3 {
+		t.Errorf("number of violations = %v; want %v", len(messages[0].Violations), 3)
+	}
+
+	messages = mapReportToWebhookMessages(report, "", true)
+	if len(messages) != 2 {
+		t.Fatalf("number of messages = %v; want %v", len(messages), 2)
+	}
+	if messages[0].ClusterName != "cluster-one" || len(messages[0].Violations) != 2 {
+		t.Errorf("message cluster = %v, violations = %v; want %v, %v", messages[0].ClusterName, len(messages[0].Violations), "cluster-one", 2)
+	}
+
+	if messages := mapReportToWebhookMessages(report, "Critical", true); len(messages) != 1 {
+		t.Errorf("number of messages = %v; want %v", len(messages), 1)
+	}
+}
+
+func TestDefaultWebhookTemplate(t *testing.T) {
+	collector, err := NewWebhookResultCollector(context.Background(), "http://localhost", WebhookOptions{GroupByCluster: true})
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	mapper := NewValidationReportMapper()
+	mapper.AddResults(getTestResults())
+	messages := mapReportToWebhookMessages(mapper.GetReport(), "", true)
+	var text strings.Builder
+	if err := collector.(*webhookResultCollector).template.Execute(&text, messages[0]); err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	expected := "2 policy violations on cluster-one\n- [Critical] Policy one\n- [Low] Policy | two\n"
+	if text.String() != expected {
+		t.Errorf("message = %q; want %q", text.String(), expected)
+	}
+}
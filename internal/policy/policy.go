@@ -17,25 +17,35 @@ package policy
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"strings"
+	"sync"
 
 	cfg "github.com/google/gke-policy-automation/internal/config"
 	"github.com/google/gke-policy-automation/internal/log"
 	"github.com/open-policy-agent/opa/v1/ast"
 	"github.com/open-policy-agent/opa/v1/rego"
+	"github.com/open-policy-agent/opa/v1/storage"
+	"github.com/open-policy-agent/opa/v1/storage/inmem"
 )
 
-const regoTestFileSuffix = "_test.rego"
+const (
+	regoTestFileSuffix = "_test.rego"
+	regoParametersKey  = "parameters"
+)
 
 type PolicyAgent interface {
 	Compile(files []*PolicyFile) error
 	WithFiles(files []*PolicyFile, excludes cfg.ConfigPolicyExclusions) error
+	WithParameters(parameters map[string]interface{}) error
 	Evaluate(input interface{}, packageBase string) (*PolicyEvaluationResult, error)
 	GetPolicies() []*Policy
 }
 
+// GKEPolicyAgent evaluates the compiled policies against the inputs. The Evaluate is safe
+// for concurrent use once the policies and parameters are set.
 type GKEPolicyAgent struct {
 	ctx               context.Context
 	compiler          *ast.Compiler
@@ -43,23 +53,39 @@ type GKEPolicyAgent struct {
 	evalCache         map[string]*Policy
 	excludes          cfg.ConfigPolicyExclusions
 	parserIgnoredPkgs []string
+	store             storage.Store
+	preparedQueries   map[string]*rego.PreparedEvalQuery
+	mutex             sync.Mutex
 }
 
 type Policy struct {
-	Name             string
-	File             string
-	Title            string
-	Description      string
-	Group            string
-	Severity         string
-	Category         string
-	Valid            bool
-	Violations       []string
-	ProcessingErrors []error
-	CisVersion       string
-	CisID            string
-	ExternalURI      string
-	Recommendation   string
+	Name                 string
+	File                 string
+	Title                string
+	Description          string
+	Group                string
+	Severity             string
+	Category             string
+	Valid                bool
+	Violations           []string
+	ViolationDetails     []*Violation
+	ProcessingErrors     []error
+	CisVersion           string
+	CisID                string
+	ExternalURI          string
+	Recommendation       string
+	RemediationGcloud    string
+	RemediationTerraform string
+}
+
+// Violation represents a single policy violation. Besides a message, the violation
+// may reference the specific cluster sub-resource (i.e. node pool or Kubernetes object),
+// the field path that caused it and the remediation hint.
+type Violation struct {
+	Message     string
+	Resource    string
+	Field       string
+	Remediation string
 }
 
 type PolicyEvaluationResult struct {
@@ -68,16 +94,16 @@ type PolicyEvaluationResult struct {
 }
 
 type RegoEvaluationResult struct {
-	Name       string
-	Valid      bool
-	Violations []string
+	Name             string
+	Valid            bool
+	Violations       []string
+	ViolationDetails []*Violation
 }
 
 func NewPolicyAgent(ctx context.Context) PolicyAgent {
 	return &GKEPolicyAgent{
 		ctx:               ctx,
 		policies:          make([]*Policy, 0),
-		evalCache:         make(map[string]*Policy),
 		parserIgnoredPkgs: []string{"gke.rule"},
 	}
 }
@@ -93,6 +119,7 @@ func (pa *GKEPolicyAgent) Compile(files []*PolicyFile) error {
 		return err
 	}
 	pa.compiler = compiler
+	pa.resetPreparedQueries()
 	return nil
 }
 
@@ -190,6 +217,9 @@ module:
 			pa.policies = append(pa.policies, &policy)
 		}
 	}
+	pa.mutex.Lock()
+	pa.evalCache = nil
+	pa.mutex.Unlock()
 	return errors
 }
 
@@ -208,32 +238,75 @@ func (pa *GKEPolicyAgent) WithFiles(files []*PolicyFile, excludes cfg.ConfigPoli
 	return nil
 }
 
+// WithParameters sets the policy parameters that are available to the policies
+// as data.parameters document.
+func (pa *GKEPolicyAgent) WithParameters(parameters map[string]interface{}) error {
+	// round trip through JSON to convert the values to the types supported by the store
+	data, err := json.Marshal(parameters)
+	if err != nil {
+		return fmt.Errorf("invalid policy parameters: %w", err)
+	}
+	var values map[string]interface{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("invalid policy parameters: %w", err)
+	}
+	pa.store = inmem.NewFromObject(map[string]interface{}{regoParametersKey: values})
+	pa.resetPreparedQueries()
+	return nil
+}
+
 func (pa *GKEPolicyAgent) Evaluate(input interface{}, packageBase string) (*PolicyEvaluationResult, error) {
-	query := getRegoQueryForPackageBase(packageBase)
-	var rgo *rego.Rego
-	if pa.compiler == nil {
-		rgo = rego.New(
-			rego.Input(input),
-			rego.Query(query))
-	} else {
-		rgo = rego.New(
-			rego.Compiler(pa.compiler),
-			rego.Input(input),
-			rego.Query(query))
-	}
-	results, err := rgo.Eval(pa.ctx)
+	query, err := pa.getPreparedQuery(packageBase)
+	if err != nil {
+		return nil, err
+	}
+	results, err := query.Eval(pa.ctx, rego.EvalInput(input))
 	if err != nil {
 		return nil, fmt.Errorf("failed to evaluate rego: %s", err)
 	}
 	return pa.processRegoResultSet(packageBase, results)
 }
 
+// getPreparedQuery returns the query for a given package base, prepared for evaluation
+// on the first use. The prepared queries are safe for concurrent use.
+func (pa *GKEPolicyAgent) getPreparedQuery(packageBase string) (*rego.PreparedEvalQuery, error) {
+	pa.mutex.Lock()
+	defer pa.mutex.Unlock()
+	if query, ok := pa.preparedQueries[packageBase]; ok {
+		return query, nil
+	}
+	opts := []func(*rego.Rego){
+		rego.Query(getRegoQueryForPackageBase(packageBase)),
+	}
+	if pa.compiler != nil {
+		opts = append(opts, rego.Compiler(pa.compiler))
+	}
+	if pa.store != nil {
+		opts = append(opts, rego.Store(pa.store))
+	}
+	query, err := rego.New(opts...).PrepareForEval(pa.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare rego: %s", err)
+	}
+	if pa.preparedQueries == nil {
+		pa.preparedQueries = make(map[string]*rego.PreparedEvalQuery)
+	}
+	pa.preparedQueries[packageBase] = &query
+	return &query, nil
+}
+
+func (pa *GKEPolicyAgent) resetPreparedQueries() {
+	pa.mutex.Lock()
+	defer pa.mutex.Unlock()
+	pa.preparedQueries = nil
+}
+
 func (pa *GKEPolicyAgent) GetPolicies() []*Policy {
 	return pa.policies
 }
 
 func (pa *GKEPolicyAgent) processRegoResultSet(packageBase string, results rego.ResultSet) (*PolicyEvaluationResult, error) {
-	pa.initEvalCache()
+	evalCache := pa.getEvalCache()
 	evalResults := &PolicyEvaluationResult{}
 	for i, result := range results {
 		value, bindings, err := getResultDataForEval(result)
@@ -252,11 +325,13 @@ func (pa *GKEPolicyAgent) processRegoResultSet(packageBase string, results rego.
 		}
 		policy := NewPolicyFromEvalResult(&regoEvalResult, regoEvalResultErrors)
 		policyName := packageBase + "." + regoEvalResult.Name
-		if compiledPolicy, ok := pa.evalCache[policyName]; ok {
-			compiledPolicy.Valid = policy.Valid
-			compiledPolicy.Violations = policy.Violations
-			compiledPolicy.ProcessingErrors = policy.ProcessingErrors
-			policy = compiledPolicy
+		if compiledPolicy, ok := evalCache[policyName]; ok {
+			evalPolicy := *compiledPolicy
+			evalPolicy.Valid = policy.Valid
+			evalPolicy.Violations = policy.Violations
+			evalPolicy.ViolationDetails = policy.ViolationDetails
+			evalPolicy.ProcessingErrors = policy.ProcessingErrors
+			policy = &evalPolicy
 		} else {
 			log.Warnf("rego policy %q has no match with any compiled policy", policyName)
 		}
@@ -265,6 +340,18 @@ func (pa *GKEPolicyAgent) processRegoResultSet(packageBase string, results rego.
 	return evalResults, nil
 }
 
+// getEvalCache returns the compiled policies by name, initialized on the first use.
+// The cached policies are copied by the evaluations and never modified, so the cache
+// is safe for concurrent use.
+func (pa *GKEPolicyAgent) getEvalCache() map[string]*Policy {
+	pa.mutex.Lock()
+	defer pa.mutex.Unlock()
+	if pa.evalCache == nil {
+		pa.initEvalCache()
+	}
+	return pa.evalCache
+}
+
 func (pa *GKEPolicyAgent) initEvalCache() {
 	pa.evalCache = make(map[string]*Policy)
 	for _, policy := range pa.policies {
@@ -310,11 +397,15 @@ func (r *RegoEvaluationResult) mapExpressionValue(value interface{}) error {
 		return err
 	}
 	r.Valid = valid
-	r.Violations = violations
+	r.Violations = make([]string, len(violations))
+	for i := range violations {
+		r.Violations[i] = violations[i].Message
+	}
+	r.ViolationDetails = violations
 	return nil
 }
 
-func parseRegoPolicyData(data interface{}) (valid bool, violations []string, err error) {
+func parseRegoPolicyData(data interface{}) (valid bool, violations []*Violation, err error) {
 	dataMap, ok := data.(map[string]interface{})
 	if !ok {
 		err = fmt.Errorf("failed to convert value of type %q to map[string]interface{}", reflect.TypeOf(data))
@@ -323,7 +414,7 @@ func parseRegoPolicyData(data interface{}) (valid bool, violations []string, err
 	if valid, err = getBoolFromInterfaceMap("valid", dataMap); err != nil {
 		return
 	}
-	if violations, err = getStringListFromInterfaceMap("violation", dataMap); err != nil {
+	if violations, err = getViolationListFromInterfaceMap("violation", dataMap); err != nil {
 		return
 	}
 	return
@@ -331,9 +422,10 @@ func parseRegoPolicyData(data interface{}) (valid bool, violations []string, err
 
 func NewPolicyFromEvalResult(result *RegoEvaluationResult, errors []error) *Policy {
 	policy := &Policy{
-		Name:       result.Name,
-		Valid:      result.Valid,
-		Violations: result.Violations,
+		Name:             result.Name,
+		Valid:            result.Valid,
+		Violations:       result.Violations,
+		ViolationDetails: result.ViolationDetails,
 	}
 	if len(errors) > 0 {
 		policy.ProcessingErrors = errors
@@ -375,6 +467,16 @@ func (p *Policy) mapModule(module *ast.Module) {
 		if externalURI, ok := getStringFromInterfaceMap("externalURI", annot.Custom); ok {
 			p.ExternalURI = externalURI
 		}
+		if remediation, ok := annot.Custom["remediation"]; ok {
+			if remediationMap, ok := remediation.(map[string]interface{}); ok {
+				if gcloud, ok := getStringFromInterfaceMap("gcloud", remediationMap); ok {
+					p.RemediationGcloud = gcloud
+				}
+				if terraform, ok := getStringFromInterfaceMap("terraform", remediationMap); ok {
+					p.RemediationTerraform = terraform
+				}
+			}
+		}
 	}
 }
 
@@ -416,7 +518,10 @@ func getBoolFromInterfaceMap(name string, m map[string]interface{}) (bool, error
 	return vBool, nil
 }
 
-func getStringListFromInterfaceMap(name string, m map[string]interface{}) ([]string, error) {
+// getViolationListFromInterfaceMap returns list of violations from a given map key.
+// The list elements are either violation message strings or violation objects
+// with "msg", "resource", "field" and "remediation" keys.
+func getViolationListFromInterfaceMap(name string, m map[string]interface{}) ([]*Violation, error) {
 	v, ok := m[name]
 	if !ok {
 		return nil, fmt.Errorf("map does not contain key: %q", name)
@@ -425,15 +530,45 @@ func getStringListFromInterfaceMap(name string, m map[string]interface{}) ([]str
 	if !ok {
 		return nil, fmt.Errorf("key %q type is %q (not a []interface{})", name, reflect.ValueOf(v))
 	}
-	vStringList := make([]string, len(vList))
+	violations := make([]*Violation, len(vList))
 	for i := range vList {
-		vStringListItem, ok := vList[i].(string)
+		violation, err := mapViolation(vList[i])
+		if err != nil {
+			return nil, fmt.Errorf("key's %q list element %d is not a valid violation: %s", name, i, err)
+		}
+		violations[i] = violation
+	}
+	return violations, nil
+}
+
+func mapViolation(v interface{}) (*Violation, error) {
+	switch value := v.(type) {
+	case string:
+		return &Violation{Message: value}, nil
+	case map[string]interface{}:
+		msg, ok := getStringFromInterfaceMap("msg", value)
 		if !ok {
-			return nil, fmt.Errorf("key's %q list element %d is not a string", name, i)
+			return nil, fmt.Errorf("violation object has no string %q key", "msg")
+		}
+		violation := &Violation{Message: msg}
+		for key, dst := range map[string]*string{
+			"resource":    &violation.Resource,
+			"field":       &violation.Field,
+			"remediation": &violation.Remediation,
+		} {
+			if _, ok := value[key]; !ok {
+				continue
+			}
+			str, ok := getStringFromInterfaceMap(key, value)
+			if !ok {
+				return nil, fmt.Errorf("violation object key %q is not a string", key)
+			}
+			*dst = str
 		}
-		vStringList[i] = vStringListItem
+		return violation, nil
+	default:
+		return nil, fmt.Errorf("type is %q (expected string or map[string]interface{})", reflect.TypeOf(v))
 	}
-	return vStringList, nil
 }
 
 func getRegoQueryForPackageBase(packageBase string) string {
@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"sync"
 	"testing"
 
 	cfg "github.com/google/gke-policy-automation/internal/config"
@@ -166,6 +167,97 @@ func TestWithFiles(t *testing.T) {
 	}
 }
 
+func TestEvaluate_parameters(t *testing.T) {
+	content := "# METADATA\n" +
+		"# title: Node count\n" +
+		"# description: Test\n" +
+		"# custom:\n" +
+		"#   group: Test\n" +
+		"#   severity: High\n" +
+		"#   sccCategory: Category\n" +
+		"package gke.policy.node_count\n" +
+		"default valid := false\n" +
+		"valid if {\n" +
+		"  count(violation) == 0\n" +
+		"}\n" +
+		"violation contains msg if {\n" +
+		"  input.nodes > data.parameters.max_nodes\n" +
+		"  msg := \"too many nodes\"\n" +
+		"}"
+	pa := NewPolicyAgent(context.Background())
+	if err := pa.WithFiles([]*PolicyFile{{"node_count.rego", "folder/node_count.rego", content}}, cfg.ConfigPolicyExclusions{}); err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	if err := pa.WithParameters(map[string]interface{}{"max_nodes": 3}); err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	result, err := pa.Evaluate(map[string]interface{}{"nodes": 5}, "gke.policy")
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	if len(result.Policies) != 1 {
+		t.Fatalf("len(result.Policies) = %v; want %v", len(result.Policies), 1)
+	}
+	if result.Policies[0].Valid {
+		t.Errorf("policy valid = %v; want %v", result.Policies[0].Valid, false)
+	}
+	if len(result.Policies[0].Violations) != 1 {
+		t.Errorf("len(policy violations) = %v; want %v", len(result.Policies[0].Violations), 1)
+	}
+}
+
+func TestEvaluate_concurrent(t *testing.T) {
+	content := "# METADATA\n" +
+		"# title: Node count\n" +
+		"# description: Test\n" +
+		"# custom:\n" +
+		"#   group: Test\n" +
+		"#   severity: High\n" +
+		"#   sccCategory: Category\n" +
+		"package gke.policy.node_count\n" +
+		"default valid := false\n" +
+		"valid if {\n" +
+		"  count(violation) == 0\n" +
+		"}\n" +
+		"violation contains msg if {\n" +
+		"  input.nodes > 3\n" +
+		"  msg := \"too many nodes\"\n" +
+		"}"
+	pa := NewPolicyAgent(context.Background())
+	if err := pa.WithFiles([]*PolicyFile{{"node_count.rego", "folder/node_count.rego", content}}, cfg.ConfigPolicyExclusions{}); err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	nodes := []int{1, 2, 3, 4, 5, 6, 7, 8}
+	results := make([]*PolicyEvaluationResult, len(nodes))
+	errors := make([]error, len(nodes))
+	var wg sync.WaitGroup
+	for i := range nodes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errors[i] = pa.Evaluate(map[string]interface{}{"nodes": nodes[i]}, "gke.policy")
+		}(i)
+	}
+	wg.Wait()
+	for i := range nodes {
+		if errors[i] != nil {
+			t.Fatalf("err [%d] = %v; want nil", i, errors[i])
+		}
+		if len(results[i].Policies) != 1 {
+			t.Fatalf("len(result.Policies) [%d] = %v; want %v", i, len(results[i].Policies), 1)
+		}
+		if valid := results[i].Policies[0].Valid; valid != (nodes[i] <= 3) {
+			t.Errorf("policy valid [%d] = %v; want %v", i, valid, nodes[i] <= 3)
+		}
+		if results[i].Policies[0].Title != "Node count" {
+			t.Errorf("policy title [%d] = %v; want %v", i, results[i].Policies[0].Title, "Node count")
+		}
+	}
+	if results[0].Policies[0] == results[1].Policies[0] {
+		t.Errorf("evaluations share the same policy instance")
+	}
+}
+
 func TestProcessRegoResultSet(t *testing.T) {
 	regoPackageBase := "gke.policy"
 	policyOneCompiled := &Policy{
@@ -337,6 +429,7 @@ func TestMapExpressionValue(t *testing.T) {
 	}
 	expectedValid := true
 	expectedViolations := []string{"violation"}
+	expectedViolationDetails := []*Violation{{Message: "violation"}}
 
 	result := RegoEvaluationResult{}
 	if err := result.mapExpressionValue(input); err != nil {
@@ -348,6 +441,9 @@ func TestMapExpressionValue(t *testing.T) {
 	if !reflect.DeepEqual(result.Violations, expectedViolations) {
 		t.Errorf("valid = %v; want %v", result.Violations, expectedViolations)
 	}
+	if !reflect.DeepEqual(result.ViolationDetails, expectedViolationDetails) {
+		t.Errorf("violation details = %v; want %v", result.ViolationDetails, expectedViolationDetails)
+	}
 }
 
 func TestParseRegoPolicyData(t *testing.T) {
@@ -356,7 +452,7 @@ func TestParseRegoPolicyData(t *testing.T) {
 		"violation": []interface{}{"violation"},
 	}
 	expectedValid := true
-	expectedViolations := []string{"violation"}
+	expectedViolations := []*Violation{{Message: "violation"}}
 
 	valid, violations, err := parseRegoPolicyData(input)
 	if err != nil {
@@ -382,6 +478,8 @@ func TestMapModule(t *testing.T) {
 	cisID := "4.1.3"
 	recommendation := "do this and that"
 	externalURI := "https://cloud.google.com/kubernetes-engine"
+	remediationGcloud := "gcloud container clusters update $CLUSTER_NAME --enable-feature"
+	remediationTerraform := "enable_feature = true"
 
 	content := fmt.Sprintf("# METADATA\n"+
 		"# title: %s\n"+
@@ -395,8 +493,12 @@ func TestMapModule(t *testing.T) {
 		"#     id: %q\n"+
 		"#   recommendation: %s\n"+
 		"#   externalURI: %s\n"+
+		"#   remediation:\n"+
+		"#     gcloud: %s\n"+
+		"#     terraform: %s\n"+
 		"package %s\n"+
-		"p = 1", title, desc, group, severity, category, cisVersion, cisID, recommendation, externalURI, pkg)
+		"p = 1", title, desc, group, severity, category, cisVersion, cisID, recommendation, externalURI,
+		remediationGcloud, remediationTerraform, pkg)
 
 	modules := map[string]string{file: content}
 	compiler := ast.MustCompileModulesWithOpts(modules,
@@ -438,6 +540,12 @@ func TestMapModule(t *testing.T) {
 	if policy.Recommendation != recommendation {
 		t.Errorf("recommendation = %v; want %v", policy.Recommendation, recommendation)
 	}
+	if policy.RemediationGcloud != remediationGcloud {
+		t.Errorf("remediation gcloud = %v; want %v", policy.RemediationGcloud, remediationGcloud)
+	}
+	if policy.RemediationTerraform != remediationTerraform {
+		t.Errorf("remediation terraform = %v; want %v", policy.RemediationTerraform, remediationTerraform)
+	}
 }
 
 func TestMetadataErrors(t *testing.T) {
@@ -492,12 +600,23 @@ func TestGetBoolFromInterfaceMap_negative(t *testing.T) {
 	}
 }
 
-func TestGetStringListFromInterfaceMap(t *testing.T) {
+func TestGetViolationListFromInterfaceMap(t *testing.T) {
 	inputName := "test"
-	inputMap := map[string]interface{}{"test": []interface{}{"str1", "str2"}}
-	expected := []string{"str1", "str2"}
+	inputMap := map[string]interface{}{"test": []interface{}{
+		"str1",
+		map[string]interface{}{
+			"msg":         "str2",
+			"resource":    "nodePools/default",
+			"field":       "management.auto_repair",
+			"remediation": "enable auto-repair",
+		},
+	}}
+	expected := []*Violation{
+		{Message: "str1"},
+		{Message: "str2", Resource: "nodePools/default", Field: "management.auto_repair", Remediation: "enable auto-repair"},
+	}
 
-	result, err := getStringListFromInterfaceMap(inputName, inputMap)
+	result, err := getViolationListFromInterfaceMap(inputName, inputMap)
 	if err != nil {
 		t.Errorf("err = %q; want nil", err)
 	}
@@ -506,14 +625,16 @@ func TestGetStringListFromInterfaceMap(t *testing.T) {
 	}
 }
 
-func TestGetStringListFromInterfaceMap_negative(t *testing.T) {
-	inputNames := []string{"testTwo", "testThree", "missing"}
+func TestGetViolationListFromInterfaceMap_negative(t *testing.T) {
+	inputNames := []string{"testTwo", "testThree", "testFour", "testFive", "missing"}
 	inputMaps := []map[string]interface{}{
 		{"testTwo": nil},
 		{"testThree": []interface{}{"str1", 100}},
+		{"testFour": []interface{}{map[string]interface{}{"resource": "nodePools/default"}}},
+		{"testFive": []interface{}{map[string]interface{}{"msg": "str1", "field": 100}}},
 		nil}
 	for i := range inputNames {
-		_, err := getStringListFromInterfaceMap(inputNames[i], inputMaps[i])
+		_, err := getViolationListFromInterfaceMap(inputNames[i], inputMaps[i])
 		if err == nil {
 			t.Errorf("err = nil; want error")
 		}
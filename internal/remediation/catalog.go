@@ -0,0 +1,88 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remediation
+
+const (
+	gcloudClusterUpdate  = "gcloud container clusters update $CLUSTER_NAME --location $CLUSTER_LOCATION --project $CLUSTER_PROJECT "
+	gcloudNodePoolUpdate = "gcloud container node-pools update $NODE_POOL --cluster $CLUSTER_NAME --location $CLUSTER_LOCATION --project $CLUSTER_PROJECT "
+)
+
+// defaultCatalog is a built-in catalog of remediation actions keyed by policy name.
+var defaultCatalog = map[string]*Action{
+	"gke.policy.cluster_binary_authorization": {
+		Gcloud:    gcloudClusterUpdate + "--binauthz-evaluation-mode=PROJECT_SINGLETON_POLICY_ENFORCE",
+		Terraform: "binary_authorization {\n  evaluation_mode = \"PROJECT_SINGLETON_POLICY_ENFORCE\"\n}",
+	},
+	"gke.policy.cluster_enable_security_posture": {
+		Gcloud:    gcloudClusterUpdate + "--security-posture=standard",
+		Terraform: "security_posture_config {\n  mode = \"BASIC\"\n}",
+	},
+	"gke.policy.cluster_enable_workload_scanning": {
+		Gcloud:    gcloudClusterUpdate + "--workload-vulnerability-scanning=standard",
+		Terraform: "security_posture_config {\n  vulnerability_mode = \"VULNERABILITY_BASIC\"\n}",
+	},
+	"gke.policy.cluster_gce_csi_driver": {
+		Gcloud:    gcloudClusterUpdate + "--update-addons=GcePersistentDiskCsiDriver=ENABLED",
+		Terraform: "addons_config {\n  gce_persistent_disk_csi_driver_config {\n    enabled = true\n  }\n}",
+	},
+	"gke.policy.cluster_release_channels": {
+		Gcloud:    gcloudClusterUpdate + "--release-channel=regular",
+		Terraform: "release_channel {\n  channel = \"REGULAR\"\n}",
+	},
+	"gke.policy.control_plane_disable_legacy_authorization": {
+		Gcloud:    gcloudClusterUpdate + "--no-enable-legacy-authorization",
+		Terraform: "enable_legacy_abac = false",
+	},
+	"gke.policy.ilb_subsetting": {
+		Gcloud:    gcloudClusterUpdate + "--enable-l4-ilb-subsetting",
+		Terraform: "enable_l4_ilb_subsetting = true",
+	},
+	"gke.policy.intranode_visibility": {
+		Gcloud:    gcloudClusterUpdate + "--enable-intra-node-visibility",
+		Terraform: "enable_intranode_visibility = true",
+	},
+	"gke.policy.node_local_dns_cache": {
+		Gcloud:    gcloudClusterUpdate + "--update-addons=NodeLocalDNS=ENABLED",
+		Terraform: "addons_config {\n  dns_cache_config {\n    enabled = true\n  }\n}",
+	},
+	"gke.policy.shielded_nodes": {
+		Gcloud:    gcloudClusterUpdate + "--enable-shielded-nodes",
+		Terraform: "enable_shielded_nodes = true",
+	},
+	"gke.policy.workload_identity": {
+		Gcloud:    gcloudClusterUpdate + "--workload-pool=$CLUSTER_PROJECT.svc.id.goog",
+		Terraform: "workload_identity_config {\n  workload_pool = \"$CLUSTER_PROJECT.svc.id.goog\"\n}",
+	},
+	"gke.policy.node_pool_autorepair": {
+		Gcloud:    gcloudNodePoolUpdate + "--enable-autorepair",
+		Terraform: "management {\n  auto_repair = true\n}",
+		NodePool:  true,
+	},
+	"gke.policy.node_pool_autoupgrade": {
+		Gcloud:    gcloudNodePoolUpdate + "--enable-autoupgrade",
+		Terraform: "management {\n  auto_upgrade = true\n}",
+		NodePool:  true,
+	},
+	"gke.policy.node_pool_integrity_monitoring": {
+		Gcloud:    gcloudNodePoolUpdate + "--shielded-integrity-monitoring",
+		Terraform: "node_config {\n  shielded_instance_config {\n    enable_integrity_monitoring = true\n  }\n}",
+		NodePool:  true,
+	},
+	"gke.policy.node_pool_secure_boot": {
+		Gcloud:    gcloudNodePoolUpdate + "--shielded-secure-boot",
+		Terraform: "node_config {\n  shielded_instance_config {\n    enable_secure_boot = true\n  }\n}",
+		NodePool:  true,
+	},
+}
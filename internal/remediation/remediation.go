@@ -0,0 +1,305 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package remediation implements mapping of violated policies to the remediation plans
+package remediation
+
+import (
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/google/gke-policy-automation/internal/gke"
+	"github.com/google/gke-policy-automation/internal/log"
+	"github.com/google/gke-policy-automation/internal/policy"
+)
+
+const (
+	FormatGcloud    = "gcloud"
+	FormatTerraform = "terraform"
+
+	gcloudBinary = "gcloud"
+
+	clusterNameWildcard     = "$CLUSTER_NAME"
+	clusterLocationWildcard = "$CLUSTER_LOCATION"
+	clusterProjectWildcard  = "$CLUSTER_PROJECT"
+	nodePoolWildcard        = "$NODE_POOL"
+	nodePoolPlaceholder     = "<NODE_POOL>"
+	nodePoolResourcePrefix  = "nodePools/"
+
+	terraformClusterResource  = "google_container_cluster"
+	terraformNodePoolResource = "google_container_node_pool"
+)
+
+// Action defines remediation templates for a given policy. The gcloud template is a command
+// and the terraform template is a set of resource attributes. Templates can use $CLUSTER_NAME,
+// $CLUSTER_LOCATION, $CLUSTER_PROJECT and $NODE_POOL wildcards.
+type Action struct {
+	Gcloud    string
+	Terraform string
+	NodePool  bool
+}
+
+// Step is a single remediation change for a given cluster.
+type Step struct {
+	PolicyName  string `json:"policy"`
+	PolicyTitle string `json:"title"`
+	Resource    string `json:"resource,omitempty"`
+	Change      string `json:"change"`
+}
+
+// Plan is a remediation plan for a given cluster.
+type Plan struct {
+	ClusterID string   `json:"cluster"`
+	Format    string   `json:"format"`
+	Steps     []*Step  `json:"steps"`
+	Unmapped  []string `json:"unmapped,omitempty"`
+}
+
+// CommandRunner runs a given command with arguments and returns its combined output.
+type CommandRunner func(name string, args ...string) ([]byte, error)
+
+type Planner struct {
+	format  string
+	catalog map[string]*Action
+}
+
+// NewPlanner returns planner producing remediation plans in a given format
+// using the built-in remediation catalog.
+func NewPlanner(format string) (*Planner, error) {
+	return newPlanner(format, defaultCatalog)
+}
+
+func newPlanner(format string, catalog map[string]*Action) (*Planner, error) {
+	if format != FormatGcloud && format != FormatTerraform {
+		return nil, fmt.Errorf("unsupported remediation format %q", format)
+	}
+	return &Planner{format: format, catalog: catalog}, nil
+}
+
+// Plan creates a remediation plan for violated policies of a given evaluation result.
+// Remediation defined in policy metadata takes precedence over the built-in catalog.
+func (p *Planner) Plan(result *policy.PolicyEvaluationResult) (*Plan, error) {
+	project, location, name, err := gke.SliceAndValidateClusterID(result.ClusterID)
+	if err != nil {
+		return nil, err
+	}
+	replacer := strings.NewReplacer(
+		clusterNameWildcard, name,
+		clusterLocationWildcard, location,
+		clusterProjectWildcard, project,
+	)
+	plan := &Plan{ClusterID: result.ClusterID, Format: p.format}
+	policies := make([]*policy.Policy, len(result.Policies))
+	copy(policies, result.Policies)
+	sort.SliceStable(policies, func(i, j int) bool {
+		return policies[i].Name < policies[j].Name
+	})
+	for _, pol := range policies {
+		if pol.Valid || len(pol.ProcessingErrors) > 0 {
+			continue
+		}
+		action := p.getAction(pol)
+		template := action.template(p.format)
+		if template == "" {
+			plan.Unmapped = append(plan.Unmapped, pol.Name)
+			continue
+		}
+		nodePools := getViolatedNodePools(pol)
+		if !action.NodePool && len(nodePools) == 0 {
+			plan.Steps = append(plan.Steps, &Step{
+				PolicyName:  pol.Name,
+				PolicyTitle: pol.Title,
+				Change:      p.render(replacer.Replace(template), terraformClusterResource, name),
+			})
+			continue
+		}
+		if len(nodePools) == 0 {
+			nodePools = []string{nodePoolPlaceholder}
+		}
+		for _, nodePool := range nodePools {
+			change := strings.ReplaceAll(replacer.Replace(template), nodePoolWildcard, nodePool)
+			plan.Steps = append(plan.Steps, &Step{
+				PolicyName:  pol.Name,
+				PolicyTitle: pol.Title,
+				Resource:    nodePoolResourcePrefix + nodePool,
+				Change:      p.render(change, terraformNodePoolResource, nodePool),
+			})
+		}
+	}
+	return plan, nil
+}
+
+func (p *Planner) getAction(pol *policy.Policy) *Action {
+	action := &Action{}
+	if catalogAction, ok := p.catalog[pol.Name]; ok {
+		*action = *catalogAction
+	}
+	if pol.RemediationGcloud != "" {
+		action.Gcloud = pol.RemediationGcloud
+	}
+	if pol.RemediationTerraform != "" {
+		action.Terraform = pol.RemediationTerraform
+	}
+	if strings.Contains(action.Gcloud, nodePoolWildcard) {
+		action.NodePool = true
+	}
+	return action
+}
+
+// render renders the change in a planner's format. Terraform attributes are wrapped
+// with a resource block and presented as a diff.
+func (p *Planner) render(change, resourceType, resourceName string) string {
+	if p.format != FormatTerraform {
+		return change
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "~ resource %q %q {\n", resourceType, resourceName)
+	for _, line := range strings.Split(strings.TrimSpace(change), "\n") {
+		fmt.Fprintf(&sb, "+   %s\n", line)
+	}
+	sb.WriteString("  }")
+	return sb.String()
+}
+
+func (a *Action) template(format string) string {
+	if format == FormatTerraform {
+		return a.Terraform
+	}
+	return a.Gcloud
+}
+
+// getViolatedNodePools returns names of the node pools referenced by policy violations.
+func getViolatedNodePools(pol *policy.Policy) []string {
+	nodePools := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, v := range pol.ViolationDetails {
+		if !strings.HasPrefix(v.Resource, nodePoolResourcePrefix) {
+			continue
+		}
+		nodePool := strings.TrimPrefix(v.Resource, nodePoolResourcePrefix)
+		if !seen[nodePool] {
+			seen[nodePool] = true
+			nodePools = append(nodePools, nodePool)
+		}
+	}
+	return nodePools
+}
+
+// Apply runs the plan steps using a given command runner. Only plans in gcloud
+// format can be applied. Steps with unidentified node pools are skipped. All steps are
+// validated before running any of them: each has to be a gcloud command, as the templates
+// can come from the policy metadata of remote policy sources.
+func (p *Plan) Apply(run CommandRunner) error {
+	if p.Format != FormatGcloud {
+		return fmt.Errorf("remediation plan in %q format can't be applied", p.Format)
+	}
+	commands := make(map[*Step][]string)
+	for _, step := range p.Steps {
+		if strings.Contains(step.Change, nodePoolPlaceholder) {
+			continue
+		}
+		args, err := getGcloudArgs(step.Change)
+		if err != nil {
+			return fmt.Errorf("remediation of policy %s can't be applied: %w", step.PolicyName, err)
+		}
+		commands[step] = args
+	}
+	for _, step := range p.Steps {
+		args, ok := commands[step]
+		if !ok {
+			log.Warnf("skipping remediation of policy %s on cluster %s: node pool was not identified", step.PolicyName, p.ClusterID)
+			continue
+		}
+		log.Infof("Running remediation of policy %s on cluster %s", step.PolicyName, p.ClusterID)
+		out, err := run(gcloudBinary, args...)
+		log.Debugf("remediation command output: %s", out)
+		if err != nil {
+			return fmt.Errorf("remediation of policy %s failed: %s", step.PolicyName, err)
+		}
+	}
+	return nil
+}
+
+// getGcloudArgs returns the arguments of a given gcloud command, without the gcloud binary.
+// An error is returned when the command is empty or runs other binary.
+func getGcloudArgs(command string) ([]string, error) {
+	args, err := splitCommand(command)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("remediation command is empty")
+	}
+	if args[0] != gcloudBinary {
+		return nil, fmt.Errorf("remediation command %q is not a %s command", args[0], gcloudBinary)
+	}
+	return args[1:], nil
+}
+
+// splitCommand splits a given command into arguments using shell-like quoting: arguments
+// are separated by whitespace, single quotes preserve the quoted text literally, and backslash
+// escapes the next character outside of the single quotes.
+func splitCommand(command string) ([]string, error) {
+	args := make([]string, 0)
+	var current strings.Builder
+	inArg, escaped := false, false
+	var quote rune
+	for _, r := range command {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\\':
+			escaped, inArg = true, true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case unicode.IsSpace(r):
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, fmt.Errorf("unterminated quote or escape in command %q", command)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+// RunCommand is a CommandRunner that executes commands in the operating system.
+func RunCommand(name string, args ...string) ([]byte, error) {
+	return exec.Command(name, args...).CombinedOutput()
+}
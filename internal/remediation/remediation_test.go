@@ -0,0 +1,235 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remediation
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/gke-policy-automation/internal/policy"
+)
+
+var testCatalog = map[string]*Action{
+	"gke.policy.cluster_policy": {
+		Gcloud:    "gcloud container clusters update $CLUSTER_NAME --location $CLUSTER_LOCATION --project $CLUSTER_PROJECT --enable-feature",
+		Terraform: "enable_feature = true",
+	},
+	"gke.policy.node_pool_policy": {
+		Gcloud:    "gcloud container node-pools update $NODE_POOL --cluster $CLUSTER_NAME --enable-feature",
+		Terraform: "management {\n  feature = true\n}",
+		NodePool:  true,
+	},
+}
+
+func TestNewPlanner(t *testing.T) {
+	if _, err := NewPlanner(FormatGcloud); err != nil {
+		t.Errorf("err = %v; want nil", err)
+	}
+	if _, err := NewPlanner("bogus"); err == nil {
+		t.Errorf("err = nil; want error")
+	}
+}
+
+func TestPlan_gcloud(t *testing.T) {
+	result := &policy.PolicyEvaluationResult{
+		ClusterID: "projects/prj/locations/europe-west2/clusters/cls",
+		Policies: []*policy.Policy{
+			{Name: "gke.policy.valid_policy", Valid: true},
+			{Name: "gke.policy.node_pool_policy", Title: "Node pool", Violations: []string{"one", "two"},
+				ViolationDetails: []*policy.Violation{
+					{Message: "one", Resource: "nodePools/pool-one"},
+					{Message: "two", Resource: "nodePools/pool-two"},
+				}},
+			{Name: "gke.policy.cluster_policy", Title: "Cluster", Violations: []string{"violation"}},
+			{Name: "gke.policy.unmapped_policy", Violations: []string{"violation"}},
+			{Name: "gke.policy.metadata_policy", Violations: []string{"violation"},
+				RemediationGcloud: "gcloud container clusters update $CLUSTER_NAME --enable-other"},
+		},
+	}
+	planner, _ := newPlanner(FormatGcloud, testCatalog)
+	plan, err := planner.Plan(result)
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	expected := []*Step{
+		{PolicyName: "gke.policy.cluster_policy", PolicyTitle: "Cluster",
+			Change: "gcloud container clusters update cls --location europe-west2 --project prj --enable-feature"},
+		{PolicyName: "gke.policy.metadata_policy",
+			Change: "gcloud container clusters update cls --enable-other"},
+		{PolicyName: "gke.policy.node_pool_policy", PolicyTitle: "Node pool", Resource: "nodePools/pool-one",
+			Change: "gcloud container node-pools update pool-one --cluster cls --enable-feature"},
+		{PolicyName: "gke.policy.node_pool_policy", PolicyTitle: "Node pool", Resource: "nodePools/pool-two",
+			Change: "gcloud container node-pools update pool-two --cluster cls --enable-feature"},
+	}
+	if !reflect.DeepEqual(plan.Steps, expected) {
+		t.Errorf("steps = %v; want %v", plan.Steps, expected)
+	}
+	if !reflect.DeepEqual(plan.Unmapped, []string{"gke.policy.unmapped_policy"}) {
+		t.Errorf("unmapped = %v; want %v", plan.Unmapped, []string{"gke.policy.unmapped_policy"})
+	}
+}
+
+func TestPlan_terraform(t *testing.T) {
+	result := &policy.PolicyEvaluationResult{
+		ClusterID: "projects/prj/locations/europe-west2/clusters/cls",
+		Policies: []*policy.Policy{
+			{Name: "gke.policy.cluster_policy", Violations: []string{"violation"}},
+			{Name: "gke.policy.node_pool_policy", Violations: []string{"violation"}},
+		},
+	}
+	planner, _ := newPlanner(FormatTerraform, testCatalog)
+	plan, err := planner.Plan(result)
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	if len(plan.Steps) != 2 {
+		t.Fatalf("number of steps = %v; want %v", len(plan.Steps), 2)
+	}
+	expectedCluster := "~ resource \"google_container_cluster\" \"cls\" {\n+   enable_feature = true\n  }"
+	if plan.Steps[0].Change != expectedCluster {
+		t.Errorf("cluster change = %q; want %q", plan.Steps[0].Change, expectedCluster)
+	}
+	expectedNodePool := "~ resource \"google_container_node_pool\" \"<NODE_POOL>\" {\n+   management {\n+     feature = true\n+   }\n  }"
+	if plan.Steps[1].Change != expectedNodePool {
+		t.Errorf("node pool change = %q; want %q", plan.Steps[1].Change, expectedNodePool)
+	}
+}
+
+func TestPlan_invalidClusterID(t *testing.T) {
+	planner, _ := newPlanner(FormatGcloud, testCatalog)
+	if _, err := planner.Plan(&policy.PolicyEvaluationResult{ClusterID: "invalid"}); err == nil {
+		t.Errorf("err = nil; want error")
+	}
+}
+
+func TestApply(t *testing.T) {
+	plan := &Plan{
+		Format: FormatGcloud,
+		Steps: []*Step{
+			{PolicyName: "one", Change: "gcloud container clusters update cls --enable-feature"},
+			{PolicyName: "two", Change: "gcloud container node-pools update <NODE_POOL> --cluster cls"},
+		},
+	}
+	commands := make([]string, 0)
+	runner := func(name string, args ...string) ([]byte, error) {
+		commands = append(commands, name+" "+strings.Join(args, " "))
+		return nil, nil
+	}
+	if err := plan.Apply(runner); err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	expected := []string{"gcloud container clusters update cls --enable-feature"}
+	if !reflect.DeepEqual(commands, expected) {
+		t.Errorf("commands = %v; want %v", commands, expected)
+	}
+}
+
+func TestApply_negative(t *testing.T) {
+	plan := &Plan{Format: FormatTerraform}
+	if err := plan.Apply(RunCommand); err == nil {
+		t.Errorf("err = nil; want error for terraform plan")
+	}
+	plan = &Plan{Format: FormatGcloud, Steps: []*Step{{PolicyName: "one", Change: "gcloud update"}}}
+	runner := func(name string, args ...string) ([]byte, error) {
+		return nil, errors.New("failed")
+	}
+	if err := plan.Apply(runner); err == nil {
+		t.Errorf("err = nil; want error")
+	}
+}
+
+func TestApply_quotedArgs(t *testing.T) {
+	plan := &Plan{
+		Format: FormatGcloud,
+		Steps: []*Step{
+			{PolicyName: "one", Change: `gcloud container clusters update cls --update-labels='team=a b' --description "my \"prod\" cluster"`},
+		},
+	}
+	var name string
+	var args []string
+	runner := func(n string, a ...string) ([]byte, error) {
+		name, args = n, a
+		return nil, nil
+	}
+	if err := plan.Apply(runner); err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	expected := []string{"container", "clusters", "update", "cls", "--update-labels=team=a b", "--description", `my "prod" cluster`}
+	if name != gcloudBinary {
+		t.Errorf("name = %v; want %v", name, gcloudBinary)
+	}
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("args = %q; want %q", args, expected)
+	}
+}
+
+func TestApply_invalidCommands(t *testing.T) {
+	input := []string{
+		"",
+		"   ",
+		"rm -rf /",
+		"/usr/bin/gcloud container clusters update cls",
+		"gcloud container clusters update 'cls",
+	}
+	for i := range input {
+		plan := &Plan{
+			Format: FormatGcloud,
+			Steps: []*Step{
+				{PolicyName: "one", Change: "gcloud container clusters update cls --enable-feature"},
+				{PolicyName: "two", Change: input[i]},
+			},
+		}
+		calls := 0
+		runner := func(name string, args ...string) ([]byte, error) {
+			calls++
+			return nil, nil
+		}
+		if err := plan.Apply(runner); err == nil {
+			t.Errorf("[%d] err = nil; want error", i)
+		}
+		if calls != 0 {
+			t.Errorf("[%d] calls = %v; want %v", i, calls, 0)
+		}
+	}
+}
+
+func TestSplitCommand(t *testing.T) {
+	input := []string{
+		"gcloud  container\tclusters update",
+		`gcloud --flag='a "b" c'`,
+		`gcloud --flag="a 'b' c"`,
+		`gcloud a\ b ""`,
+	}
+	expected := [][]string{
+		{"gcloud", "container", "clusters", "update"},
+		{"gcloud", `--flag=a "b" c`},
+		{"gcloud", "--flag=a 'b' c"},
+		{"gcloud", "a b", ""},
+	}
+	for i := range input {
+		result, err := splitCommand(input[i])
+		if err != nil {
+			t.Fatalf("[%d] err = %v; want nil", i, err)
+		}
+		if !reflect.DeepEqual(result, expected[i]) {
+			t.Errorf("[%d] splitCommand = %q; want %q", i, result, expected[i])
+		}
+	}
+	if _, err := splitCommand(`gcloud "unterminated`); err == nil {
+		t.Errorf("err = nil; want error for unterminated quote")
+	}
+}
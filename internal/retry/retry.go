@@ -0,0 +1,231 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package retry implements retries with exponential backoff and client-side rate limiting
+// of the GCP API calls. A retrier is created for each API and shared by all clients of the API,
+// so the rate limit applies to the API as a whole.
+package retry
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/google/gke-policy-automation/internal/log"
+	"github.com/googleapis/gax-go/v2"
+	"golang.org/x/time/rate"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	DefaultMaxRetries     = 5
+	DefaultInitialBackoff = time.Second
+	DefaultMaxBackoff     = 32 * time.Second
+)
+
+// Settings define the retries and the client-side rate limit of the API calls.
+// The API calls are not rate limited when the QPS is not set.
+type Settings struct {
+	MaxRetries     int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	QPS            float64
+}
+
+// DefaultSettings returns the settings with default retries and no rate limit.
+func DefaultSettings() Settings {
+	return Settings{
+		MaxRetries:     DefaultMaxRetries,
+		InitialBackoff: DefaultInitialBackoff,
+		MaxBackoff:     DefaultMaxBackoff,
+	}
+}
+
+// Retrier applies the retry and rate limit settings to the calls of a single API.
+// A nil retrier calls the API once, without the rate limit.
+type Retrier struct {
+	api     string
+	backoff Backoff
+	limiter *rate.Limiter
+}
+
+// NewRetrier returns the retrier of a given API. Zero values of the retry settings
+// are replaced with the defaults.
+func NewRetrier(api string, s Settings) *Retrier {
+	r := &Retrier{
+		api: api,
+		backoff: Backoff{
+			MaxRetries:     s.MaxRetries,
+			InitialBackoff: s.InitialBackoff,
+			MaxBackoff:     s.MaxBackoff,
+		},
+	}
+	if r.backoff.MaxRetries <= 0 {
+		r.backoff.MaxRetries = DefaultMaxRetries
+	}
+	if r.backoff.InitialBackoff <= 0 {
+		r.backoff.InitialBackoff = DefaultInitialBackoff
+	}
+	if r.backoff.MaxBackoff <= 0 {
+		r.backoff.MaxBackoff = DefaultMaxBackoff
+	}
+	if s.QPS > 0 {
+		log.Debugf("limiting %s API calls to %v QPS", api, s.QPS)
+		r.limiter = rate.NewLimiter(rate.Limit(s.QPS), int(math.Max(1, math.Ceil(s.QPS))))
+	}
+	return r
+}
+
+// Do calls a given function, waiting for the rate limiter of the API before each attempt.
+// The calls failed with the retryable errors are retried with exponential backoff.
+// It is used for the HTTP APIs, the gRPC clients are set with the client and call options.
+func (r *Retrier) Do(ctx context.Context, call string, fn func(ctx context.Context) error) error {
+	if r == nil {
+		return fn(ctx)
+	}
+	return DoWithBackoff(ctx, r.api+" API call "+call, r.backoff, r.limiter, IsRetryable, fn)
+}
+
+// ClientOptions returns the gRPC client options waiting for the rate limiter of the API
+// before each call attempt.
+func (r *Retrier) ClientOptions() []option.ClientOption {
+	if r == nil {
+		return nil
+	}
+	return []option.ClientOption{
+		option.WithGRPCDialOption(grpc.WithChainUnaryInterceptor(r.UnaryClientInterceptor())),
+	}
+}
+
+// UnaryClientInterceptor returns the gRPC interceptor waiting for the rate limiter of the API
+// before each unary call attempt. The calls are not retried by the interceptor, as the attempts
+// would multiply with the retries of the client libraries.
+func (r *Retrier) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if r.limiter != nil {
+			if err := r.limiter.Wait(ctx); err != nil {
+				return err
+			}
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// CallOptions returns the gRPC call options that replace the default retries of the client
+// libraries with the retries of the API settings.
+func (r *Retrier) CallOptions() []gax.CallOption {
+	if r == nil {
+		return nil
+	}
+	return []gax.CallOption{
+		gax.WithRetry(func() gax.Retryer {
+			return &grpcRetryer{api: r.api, backoff: r.backoff, pause: r.backoff.InitialBackoff}
+		}),
+	}
+}
+
+// grpcRetryer retries the gRPC calls failed with the retryable errors with exponential backoff.
+type grpcRetryer struct {
+	api     string
+	backoff Backoff
+	retries int
+	pause   time.Duration
+}
+
+func (g *grpcRetryer) Retry(err error) (time.Duration, bool) {
+	if !IsRetryable(err) || g.retries >= g.backoff.MaxRetries {
+		return 0, false
+	}
+	g.retries++
+	pause := jitter(g.pause)
+	log.Debugf("%s API call failed, retry %d of %d in %s: %s", g.api, g.retries, g.backoff.MaxRetries, pause, err)
+	g.pause = min(g.pause*2, g.backoff.MaxBackoff)
+	return pause, true
+}
+
+// Backoff defines the retries of the failed calls with exponential backoff.
+type Backoff struct {
+	MaxRetries     int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// DoWithBackoff calls a given function, waiting for a given rate limiter before each attempt
+// when it is set. The calls failed with the errors accepted by a given retryable function are
+// retried with exponential backoff. The retries stop when a given context is done.
+func DoWithBackoff(ctx context.Context, name string, b Backoff, limiter *rate.Limiter, retryable func(err error) bool, fn func(ctx context.Context) error) error {
+	backoff := b.InitialBackoff
+	for retries := 0; ; retries++ {
+		if limiter != nil {
+			if err := limiter.Wait(ctx); err != nil {
+				return err
+			}
+		}
+		err := fn(ctx)
+		if err == nil {
+			if retries > 0 {
+				log.Debugf("%s succeeded after %d retries", name, retries)
+			}
+			return nil
+		}
+		if !retryable(err) || retries >= b.MaxRetries {
+			if retries > 0 {
+				log.Debugf("%s failed after %d retries: %s", name, retries, err)
+			}
+			return err
+		}
+		pause := jitter(backoff)
+		log.Debugf("%s failed, retry %d of %d in %s: %s", name, retries+1, b.MaxRetries, pause, err)
+		timer := time.NewTimer(pause)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		}
+		if b.MaxBackoff > 0 {
+			backoff = min(backoff*2, b.MaxBackoff)
+		} else {
+			backoff *= 2
+		}
+	}
+}
+
+// IsRetryable checks if a given error of the API call is caused by exhausted quota, or by
+// temporary unavailability of the API.
+func IsRetryable(err error) bool {
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Code == http.StatusTooManyRequests || apiErr.Code >= http.StatusInternalServerError
+	}
+	code := status.Code(err)
+	return code == codes.ResourceExhausted || code == codes.Unavailable
+}
+
+// jitter returns a random duration between the half and the full of a given backoff,
+// so the concurrent calls don't retry at the same time.
+func jitter(backoff time.Duration) time.Duration {
+	half := backoff / 2
+	if half <= 0 {
+		return backoff
+	}
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
@@ -0,0 +1,232 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/googleapis/gax-go/v2"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestNewRetrier_defaults(t *testing.T) {
+	r := NewRetrier("container", Settings{QPS: 5})
+	if r.backoff.MaxRetries != DefaultMaxRetries {
+		t.Errorf("max retries = %v; want %v", r.backoff.MaxRetries, DefaultMaxRetries)
+	}
+	if r.backoff.InitialBackoff != DefaultInitialBackoff {
+		t.Errorf("initial backoff = %v; want %v", r.backoff.InitialBackoff, DefaultInitialBackoff)
+	}
+	if r.backoff.MaxBackoff != DefaultMaxBackoff {
+		t.Errorf("max backoff = %v; want %v", r.backoff.MaxBackoff, DefaultMaxBackoff)
+	}
+	if r.limiter == nil || r.limiter.Limit() != 5 {
+		t.Errorf("limiter = %v; want limit of 5 QPS", r.limiter)
+	}
+	if r := NewRetrier("pubsub", DefaultSettings()); r.limiter != nil {
+		t.Errorf("pubsub limiter is set; want nil")
+	}
+}
+
+func TestRetrierDo_retries(t *testing.T) {
+	r := NewRetrier("container", Settings{MaxRetries: 3, InitialBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond})
+	calls := 0
+	err := r.Do(context.Background(), "GetCluster", func(ctx context.Context) error {
+		calls++
+		if calls < 3 {
+			return status.Error(codes.ResourceExhausted, "quota exceeded")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	if calls != 3 {
+		t.Errorf("calls = %v; want %v", calls, 3)
+	}
+}
+
+func TestRetrierDo_maxRetries(t *testing.T) {
+	r := NewRetrier("storage", Settings{MaxRetries: 2, InitialBackoff: time.Millisecond})
+	calls := 0
+	callErr := &googleapi.Error{Code: 503}
+	err := r.Do(context.Background(), "objects.insert", func(ctx context.Context) error {
+		calls++
+		return callErr
+	})
+	if err != callErr {
+		t.Errorf("err = %v; want %v", err, callErr)
+	}
+	if calls != 3 {
+		t.Errorf("calls = %v; want %v", calls, 3)
+	}
+}
+
+func TestRetrierDo_notRetryable(t *testing.T) {
+	r := NewRetrier("container", DefaultSettings())
+	calls := 0
+	callErr := status.Error(codes.PermissionDenied, "denied")
+	err := r.Do(context.Background(), "GetCluster", func(ctx context.Context) error {
+		calls++
+		return callErr
+	})
+	if err != callErr {
+		t.Errorf("err = %v; want %v", err, callErr)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %v; want %v", calls, 1)
+	}
+}
+
+func TestRetrierDo_canceled(t *testing.T) {
+	r := NewRetrier("container", DefaultSettings())
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	callErr := status.Error(codes.ResourceExhausted, "quota exceeded")
+	err := r.Do(ctx, "GetCluster", func(ctx context.Context) error {
+		calls++
+		cancel()
+		return callErr
+	})
+	if err != callErr {
+		t.Errorf("err = %v; want %v", err, callErr)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %v; want %v", calls, 1)
+	}
+}
+
+func TestRetrierDo_rateLimit(t *testing.T) {
+	r := NewRetrier("securitycenter", Settings{QPS: 20})
+	start := time.Now()
+	for i := 0; i < 25; i++ {
+		if err := r.Do(context.Background(), "UpdateFinding", func(ctx context.Context) error { return nil }); err != nil {
+			t.Fatalf("err = %v; want nil", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("elapsed = %v; want at least %v", elapsed, 200*time.Millisecond)
+	}
+}
+
+func TestRetrierUnaryClientInterceptor(t *testing.T) {
+	r := NewRetrier("container", Settings{QPS: 20})
+	calls := 0
+	callErr := status.Error(codes.ResourceExhausted, "quota exceeded")
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		calls++
+		return callErr
+	}
+	interceptor := r.UnaryClientInterceptor()
+	start := time.Now()
+	for i := 0; i < 25; i++ {
+		if err := interceptor(context.Background(), "/google.container.v1.ClusterManager/GetCluster", nil, nil, nil, invoker); err != callErr {
+			t.Fatalf("err = %v; want %v", err, callErr)
+		}
+	}
+	if calls != 25 {
+		t.Errorf("calls = %v; want %v", calls, 25)
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("elapsed = %v; want at least %v", elapsed, 200*time.Millisecond)
+	}
+}
+
+func TestRetrierCallOptions(t *testing.T) {
+	r := NewRetrier("container", Settings{MaxRetries: 2, InitialBackoff: time.Millisecond})
+	calls := 0
+	err := gax.Invoke(context.Background(), func(ctx context.Context, settings gax.CallSettings) error {
+		calls++
+		return status.Error(codes.Unavailable, "unavailable")
+	}, r.CallOptions()...)
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("err = %v; want %v", err, codes.Unavailable)
+	}
+	if calls != 3 {
+		t.Errorf("calls = %v; want %v", calls, 3)
+	}
+}
+
+func TestRetrier_nil(t *testing.T) {
+	var r *Retrier
+	calls := 0
+	callErr := status.Error(codes.ResourceExhausted, "quota exceeded")
+	err := r.Do(context.Background(), "GetCluster", func(ctx context.Context) error {
+		calls++
+		return callErr
+	})
+	if err != callErr {
+		t.Errorf("err = %v; want %v", err, callErr)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %v; want %v", calls, 1)
+	}
+	if opts := r.ClientOptions(); len(opts) != 0 {
+		t.Errorf("client options = %v; want none", opts)
+	}
+	if opts := r.CallOptions(); len(opts) != 0 {
+		t.Errorf("call options = %v; want none", opts)
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	input := []error{
+		status.Error(codes.ResourceExhausted, "quota exceeded"),
+		status.Error(codes.Unavailable, "unavailable"),
+		status.Error(codes.NotFound, "not found"),
+		&googleapi.Error{Code: 429},
+		fmt.Errorf("wrapped: %w", &googleapi.Error{Code: 503}),
+		&googleapi.Error{Code: 403},
+		errors.New("other error"),
+	}
+	expected := []bool{true, true, false, true, true, false, false}
+	for i := range input {
+		if result := IsRetryable(input[i]); result != expected[i] {
+			t.Errorf("isRetryable [%d] = %v; want %v", i, result, expected[i])
+		}
+	}
+}
+
+func TestJitter(t *testing.T) {
+	backoff := 100 * time.Millisecond
+	for i := 0; i < 10; i++ {
+		if pause := jitter(backoff); pause < backoff/2 || pause > backoff {
+			t.Errorf("jitter = %v; want between %v and %v", pause, backoff/2, backoff)
+		}
+	}
+}
+
+func TestDoWithBackoff(t *testing.T) {
+	calls := 0
+	callErr := errors.New("connection reset")
+	retryable := func(err error) bool { return err == callErr }
+	err := DoWithBackoff(context.Background(), "webhook request", Backoff{MaxRetries: 2, InitialBackoff: time.Millisecond}, nil, retryable, func(ctx context.Context) error {
+		calls++
+		return callErr
+	})
+	if err != callErr {
+		t.Errorf("err = %v; want %v", err, callErr)
+	}
+	if calls != 3 {
+		t.Errorf("calls = %v; want %v", calls, 3)
+	}
+}
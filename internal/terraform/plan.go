@@ -0,0 +1,157 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package terraform implements parsing of the Terraform plans in a JSON format, as produced
+// by the `terraform show -json` command.
+package terraform
+
+import (
+	"encoding/json"
+	"regexp"
+)
+
+const (
+	ModeManaged          = "managed"
+	ClusterResourceType  = "google_container_cluster"
+	NodePoolResourceType = "google_container_node_pool"
+)
+
+var instanceKeyRegexp = regexp.MustCompile(`\[[^\]]*\]`)
+
+// Plan is a Terraform plan with the planned values and the configuration of the resources.
+type Plan struct {
+	PlannedValues struct {
+		RootModule Module `json:"root_module"`
+	} `json:"planned_values"`
+	Configuration struct {
+		RootModule ConfigModule `json:"root_module"`
+	} `json:"configuration"`
+}
+
+// Module is a module instance with the planned values of the resources.
+type Module struct {
+	Address      string      `json:"address"`
+	Resources    []*Resource `json:"resources"`
+	ChildModules []*Module   `json:"child_modules"`
+}
+
+// Resource is a resource instance with the planned values.
+type Resource struct {
+	Address       string                 `json:"address"`
+	Mode          string                 `json:"mode"`
+	Type          string                 `json:"type"`
+	Name          string                 `json:"name"`
+	Values        map[string]interface{} `json:"values"`
+	ModuleAddress string                 `json:"-"`
+}
+
+// ConfigModule is a module configuration with the expressions of the resources.
+type ConfigModule struct {
+	Resources   []*ConfigResource      `json:"resources"`
+	ModuleCalls map[string]*ModuleCall `json:"module_calls"`
+}
+
+// ModuleCall is a configuration of a called module.
+type ModuleCall struct {
+	Module ConfigModule `json:"module"`
+}
+
+// ConfigResource is a resource configuration with the expressions of the attributes.
+type ConfigResource struct {
+	Address     string                       `json:"address"`
+	Expressions map[string]*ConfigExpression `json:"expressions"`
+}
+
+// ConfigExpression is an attribute expression with the references to other objects.
+type ConfigExpression struct {
+	References []string `json:"references"`
+}
+
+// ParsePlan parses a given Terraform plan in a JSON format.
+func ParsePlan(data []byte) (*Plan, error) {
+	plan := &Plan{}
+	if err := json.Unmarshal(data, plan); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// ManagedResources returns the planned managed resources of a given type from all modules.
+func (p *Plan) ManagedResources(resourceType string) []*Resource {
+	return getModuleResources(&p.PlannedValues.RootModule, resourceType)
+}
+
+// References returns the references of a given attribute of the configured resources, keyed
+// by the resource address without the module and resource instance keys.
+func (p *Plan) References(attribute string) map[string][]string {
+	return getModuleReferences(&p.Configuration.RootModule, "", attribute)
+}
+
+// ResourceAddress returns the address of a resource without the resource instance key,
+// but with the instance keys of the modules. The resources created with count or for_each
+// in a same module instance share the resource address.
+func (r *Resource) ResourceAddress() string {
+	return ModulePrefix(r.ModuleAddress) + r.Type + "." + r.Name
+}
+
+// ConfigAddress returns the address of a resource in the configuration, without any
+// module and resource instance keys.
+func (r *Resource) ConfigAddress() string {
+	return StripInstanceKeys(r.ResourceAddress())
+}
+
+// ModulePrefix returns the prefix of the resource addresses in a given module instance.
+func ModulePrefix(moduleAddress string) string {
+	if moduleAddress == "" {
+		return ""
+	}
+	return moduleAddress + "."
+}
+
+// StripInstanceKeys removes all module and resource instance keys from a given address,
+// i.e. module.gke["a"].google_container_node_pool.pool[0] becomes
+// module.gke.google_container_node_pool.pool.
+func StripInstanceKeys(address string) string {
+	return instanceKeyRegexp.ReplaceAllString(address, "")
+}
+
+func getModuleResources(module *Module, resourceType string) []*Resource {
+	resources := make([]*Resource, 0)
+	for _, r := range module.Resources {
+		if r.Mode != ModeManaged || r.Type != resourceType {
+			continue
+		}
+		r.ModuleAddress = module.Address
+		resources = append(resources, r)
+	}
+	for _, child := range module.ChildModules {
+		resources = append(resources, getModuleResources(child, resourceType)...)
+	}
+	return resources
+}
+
+func getModuleReferences(module *ConfigModule, prefix string, attribute string) map[string][]string {
+	references := make(map[string][]string)
+	for _, r := range module.Resources {
+		if expr, ok := r.Expressions[attribute]; ok {
+			references[prefix+r.Address] = expr.References
+		}
+	}
+	for name, call := range module.ModuleCalls {
+		for k, v := range getModuleReferences(&call.Module, prefix+"module."+name+".", attribute) {
+			references[k] = v
+		}
+	}
+	return references
+}
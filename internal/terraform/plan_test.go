@@ -0,0 +1,72 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terraform
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestParsePlan(t *testing.T) {
+	data, err := os.ReadFile("test-fixtures/terraform_plan.json")
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	plan, err := ParsePlan(data)
+	if err != nil {
+		t.Fatalf("err = %v; want nil", err)
+	}
+	addresses := make([]string, 0)
+	for _, r := range plan.ManagedResources(NodePoolResourceType) {
+		addresses = append(addresses, r.ResourceAddress()+" "+r.ConfigAddress())
+	}
+	expected := []string{
+		"google_container_node_pool.pool google_container_node_pool.pool",
+		"module.gke[0].google_container_node_pool.extra module.gke.google_container_node_pool.extra",
+	}
+	if !reflect.DeepEqual(addresses, expected) {
+		t.Errorf("node pool addresses = %v; want %v", addresses, expected)
+	}
+	references := plan.References("cluster")
+	expectedRefs := []string{"google_container_cluster.this.id", "google_container_cluster.this"}
+	if refs := references["module.gke.google_container_node_pool.extra"]; !reflect.DeepEqual(refs, expectedRefs) {
+		t.Errorf("references = %v; want %v", refs, expectedRefs)
+	}
+}
+
+func TestParsePlan_negative(t *testing.T) {
+	if _, err := ParsePlan([]byte("not a json")); err == nil {
+		t.Errorf("err = nil; want error")
+	}
+}
+
+func TestStripInstanceKeys(t *testing.T) {
+	input := []string{
+		"google_container_node_pool.pool[0]",
+		`module.gke["a"].module.pools[1].google_container_node_pool.pool["b"]`,
+		"module.gke.google_container_cluster.this",
+	}
+	expected := []string{
+		"google_container_node_pool.pool",
+		"module.gke.module.pools.google_container_node_pool.pool",
+		"module.gke.google_container_cluster.this",
+	}
+	for i := range input {
+		if result := StripInstanceKeys(input[i]); result != expected[i] {
+			t.Errorf("stripInstanceKeys(%q) = %v; want %v", input[i], result, expected[i])
+		}
+	}
+}
@@ -15,8 +15,11 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/google/gke-policy-automation/internal/app"
 )
@@ -29,7 +32,15 @@ func main() {
 		}
 	}()
 
-	if err := app.NewPolicyAutomationCli(app.NewPolicyAutomationApp()).Run(os.Args); err != nil {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		// restore default signal handling, so the second signal terminates immediately
+		<-ctx.Done()
+		stop()
+	}()
+
+	if err := app.NewPolicyAutomationCli(app.NewPolicyAutomationAppWithContext(ctx)).Run(os.Args); err != nil {
 		fmt.Printf("\nError: %s\n", err)
 		os.Exit(1)
 	}